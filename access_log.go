@@ -0,0 +1,22 @@
+package main
+
+import (
+	"log"
+	"net/http"
+)
+
+// middlewareAccessLog logs a line per request with its method, path,
+// response status and the client's device_platform, on every platform.
+// It must run inside middlewareDevice for the platform to be known; an
+// unrecognized client is logged as "-".
+func middlewareAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		platform := deviceInfoFromContext(r.Context()).Platform
+		if platform == "" {
+			platform = "-"
+		}
+		log.Printf("%s %s %d device_platform=%s", r.Method, r.URL.Path, rec.status, platform)
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/netip"
+)
+
+const defaultAdminAllowedCIDR = "127.0.0.1/32,::1/128"
+
+// parseAdminAllowedCIDR parses ADMIN_ALLOWED_CIDR. 0.0.0.0/0 is taken to
+// mean every client, so it also admits IPv6 addresses.
+func parseAdminAllowedCIDR(s string) ([]netip.Prefix, error) {
+	prefixes, err := parsePrefixList(s, "admin allowed CIDR")
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range prefixes {
+		if p.Bits() == 0 && p.Addr().Is4() {
+			prefixes = append(prefixes, netip.MustParsePrefix("::/0"))
+			break
+		}
+	}
+	return prefixes, nil
+}
+
+// middlewareAdminAllowlist rejects requests whose real client IP falls
+// outside cfg.adminAllowedCIDRs. It answers 403 rather than 404 so an
+// operator locked out by the allowlist can tell why.
+func (cfg *apiConfig) middlewareAdminAllowlist(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := realIP(r, cfg)
+		if !ok || !cfg.adminIPAllowed(addr) {
+			respondWithError(w, http.StatusForbidden, "admin access not allowed from this address")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (cfg *apiConfig) adminIPAllowed(addr netip.Addr) bool {
+	for _, p := range cfg.adminAllowedCIDRs {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
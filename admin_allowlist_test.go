@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAllowlist(t *testing.T) {
+	proxies, err := parseTrustedProxies("10.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		cidrs      string
+		remoteAddr string
+		forwarded  string
+		wantStatus int
+	}{
+		{"loopback allowed by default", defaultAdminAllowedCIDR, "127.0.0.1:5000", "", http.StatusOK},
+		{"ipv6 loopback allowed by default", defaultAdminAllowedCIDR, "[::1]:5000", "", http.StatusOK},
+		{"public address denied by default", defaultAdminAllowedCIDR, "203.0.113.9:5000", "", http.StatusForbidden},
+		{"address inside custom range", "192.168.0.0/16, 127.0.0.1/32", "192.168.4.2:5000", "", http.StatusOK},
+		{"address outside custom range", "192.168.0.0/16", "192.169.0.1:5000", "", http.StatusForbidden},
+		{"wildcard admits ipv4", "0.0.0.0/0", "203.0.113.9:5000", "", http.StatusOK},
+		{"wildcard admits ipv6", "0.0.0.0/0", "[2001:db8::1]:5000", "", http.StatusOK},
+		{"forwarded client behind trusted proxy", "127.0.0.1/32", "10.0.0.1:5000", "127.0.0.1", http.StatusOK},
+		{"forwarded public client denied", "10.0.0.0/8", "10.0.0.1:5000", "203.0.113.9", http.StatusForbidden},
+		{"spoofed hop ignored", "127.0.0.1/32", "10.0.0.1:5000", "127.0.0.1, 203.0.113.9", http.StatusForbidden},
+		{"untrusted peer cannot forward", "127.0.0.1/32", "203.0.113.9:5000", "127.0.0.1", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cidrs, err := parseAdminAllowedCIDR(tt.cidrs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := newTestConfig(newMemStore())
+			cfg.trustedProxies = proxies
+			cfg.adminAllowedCIDRs = cidrs
+			req := httptest.NewRequest("GET", "/admin/metrics", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			rec := httptest.NewRecorder()
+			newServer("0", cfg).Handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestParseAdminAllowedCIDRInvalid(t *testing.T) {
+	if _, err := parseAdminAllowedCIDR("127.0.0.1/33"); err == nil {
+		t.Error("expected an error for an invalid prefix")
+	}
+}
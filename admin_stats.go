@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	adminStatsTimeout  = 5 * time.Second
+	adminStatsCacheTTL = 60 * time.Second
+)
+
+type adminStatsResp struct {
+	TotalUsers         int64   `json:"total_users"`
+	ActiveUsersLast30d int64   `json:"active_users_last_30d"`
+	TotalChirps        int64   `json:"total_chirps"`
+	ChirpsLast24h      int64   `json:"chirps_last_24h"`
+	AvgChirpLength     float64 `json:"avg_chirp_length"`
+	TotalLikes         int64   `json:"total_likes"`
+	TotalFollows       int64   `json:"total_follows"`
+}
+
+type adminStatsEntry struct {
+	stats     adminStatsResp
+	expiresAt time.Time
+}
+
+// collectAdminStats runs each stats query concurrently, giving up on all of
+// them after adminStatsTimeout or as soon as one fails.
+func (cfg *apiConfig) collectAdminStats(ctx context.Context, now time.Time) (adminStatsResp, error) {
+	ctx, cancel := context.WithTimeout(ctx, adminStatsTimeout)
+	defer cancel()
+	g, ctx := errgroup.WithContext(ctx)
+	var stats adminStatsResp
+	count := func(dst *int64, query func(context.Context) (int64, error)) {
+		g.Go(func() (err error) {
+			*dst, err = query(ctx)
+			return err
+		})
+	}
+	count(&stats.TotalUsers, cfg.db.CountUsers)
+	count(&stats.ActiveUsersLast30d, func(ctx context.Context) (int64, error) {
+		return cfg.db.CountActiveUsersSince(ctx, now.Add(-30*24*time.Hour))
+	})
+	count(&stats.TotalChirps, cfg.db.CountChirps)
+	count(&stats.ChirpsLast24h, func(ctx context.Context) (int64, error) {
+		return cfg.db.CountChirpsSince(ctx, now.Add(-24*time.Hour))
+	})
+	g.Go(func() (err error) {
+		stats.AvgChirpLength, err = cfg.db.GetAvgChirpLength(ctx)
+		return err
+	})
+	count(&stats.TotalLikes, cfg.db.CountLikes)
+	count(&stats.TotalFollows, cfg.db.CountFollows)
+	return stats, g.Wait()
+}
+
+// handlerAdminStats serves aggregate site statistics, recomputed at most
+// once every adminStatsCacheTTL.
+func (cfg *apiConfig) handlerAdminStats(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	now := time.Now()
+	if entry := cfg.adminStats.Load(); entry != nil && now.Before(entry.expiresAt) {
+		respondWithJSON(w, http.StatusOK, entry.stats)
+		return
+	}
+	stats, err := cfg.collectAdminStats(r.Context(), now)
+	if err != nil {
+		log.Printf("Error collecting admin stats: %s", err)
+		respondWithError(w, http.StatusServiceUnavailable, "stats unavailable")
+		return
+	}
+	cfg.adminStats.Store(&adminStatsEntry{stats: stats, expiresAt: now.Add(adminStatsCacheTTL)})
+	respondWithJSON(w, http.StatusOK, stats)
+}
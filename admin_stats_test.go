@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// statsStore answers the admin stats queries with fixed values, recording
+// each call and failing the one named in fail.
+type statsStore struct {
+	*memStore
+	fail  string
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *statsStore) count(name string, n int64) (int64, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, name)
+	s.mu.Unlock()
+	if name == s.fail {
+		return 0, errors.New(name + " failed")
+	}
+	return n, nil
+}
+
+func (s *statsStore) CountUsers(ctx context.Context) (int64, error) {
+	return s.count("CountUsers", 10)
+}
+
+func (s *statsStore) CountActiveUsersSince(ctx context.Context, since time.Time) (int64, error) {
+	return s.count("CountActiveUsersSince", 4)
+}
+
+func (s *statsStore) CountChirps(ctx context.Context) (int64, error) {
+	return s.count("CountChirps", 50)
+}
+
+func (s *statsStore) CountChirpsSince(ctx context.Context, since time.Time) (int64, error) {
+	return s.count("CountChirpsSince", 7)
+}
+
+func (s *statsStore) GetAvgChirpLength(ctx context.Context) (float64, error) {
+	_, err := s.count("GetAvgChirpLength", 0)
+	return 42.5, err
+}
+
+func (s *statsStore) CountLikes(ctx context.Context) (int64, error) {
+	return s.count("CountLikes", 30)
+}
+
+func (s *statsStore) CountFollows(ctx context.Context) (int64, error) {
+	return s.count("CountFollows", 20)
+}
+
+func newStatsServer(t *testing.T, fail string) (http.Handler, *statsStore, *apiConfig, string) {
+	t.Helper()
+	store := &statsStore{memStore: newMemStore(), fail: fail}
+	cfg := newTestConfig(store)
+	_, token := seedAdmin(t, cfg, store.memStore, "admin@example.com")
+	return newServer("0", cfg).Handler, store, cfg, token
+}
+
+func TestAdminStatsCallsEveryQuery(t *testing.T) {
+	h, store, _, token := newStatsServer(t, "")
+	rec := serve(h, "GET", "/admin/stats", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	var got adminStatsResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := adminStatsResp{
+		TotalUsers:         10,
+		ActiveUsersLast30d: 4,
+		TotalChirps:        50,
+		ChirpsLast24h:      7,
+		AvgChirpLength:     42.5,
+		TotalLikes:         30,
+		TotalFollows:       20,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	slices.Sort(store.calls)
+	wantCalls := []string{"CountActiveUsersSince", "CountChirps", "CountChirpsSince", "CountFollows", "CountLikes", "CountUsers", "GetAvgChirpLength"}
+	if !slices.Equal(store.calls, wantCalls) {
+		t.Errorf("got calls %v, want %v", store.calls, wantCalls)
+	}
+}
+
+func TestAdminStatsQueryFailure(t *testing.T) {
+	for _, name := range []string{"CountUsers", "CountChirpsSince", "GetAvgChirpLength"} {
+		h, _, cfg, token := newStatsServer(t, name)
+		if rec := serve(h, "GET", "/admin/stats", "", token); rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s failing: got status %d, want 503", name, rec.Code)
+		}
+		if cfg.adminStats.Load() != nil {
+			t.Errorf("%s failing: a failed collection should not be cached", name)
+		}
+	}
+}
+
+func TestAdminStatsCached(t *testing.T) {
+	h, store, cfg, token := newStatsServer(t, "")
+	serve(h, "GET", "/admin/stats", "", token)
+	serve(h, "GET", "/admin/stats", "", token)
+	if len(store.calls) != 7 {
+		t.Errorf("got %d query calls for two requests, want 7", len(store.calls))
+	}
+
+	cfg.adminStats.Load().expiresAt = time.Now().Add(-time.Second)
+	serve(h, "GET", "/admin/stats", "", token)
+	if len(store.calls) != 14 {
+		t.Errorf("after expiry got %d query calls, want 14", len(store.calls))
+	}
+}
+
+func TestAdminStatsRequiresAdmin(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "user@example.com")
+	if rec := serve(h, "GET", "/admin/stats", "", token); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+)
+
+var supportedAPIVersions = []string{"1.0"}
+
+// middlewareAPIVersion stamps every response with the server's API version
+// and rejects requests that pin a version this server can't speak.
+func (cfg *apiConfig) middlewareAPIVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Api-Version", cfg.apiVersion)
+		if v := r.Header.Get("Accept-Version"); v != "" && !slices.Contains(supportedAPIVersions, v) {
+			type versionErrResp struct {
+				Error             string   `json:"error"`
+				SupportedVersions []string `json:"supported_versions"`
+			}
+			respondWithJSON(w, http.StatusNotAcceptable, versionErrResp{
+				Error:             "unsupported API version",
+				SupportedVersions: supportedAPIVersions,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIVersionHeader(t *testing.T) {
+	handler := newServer("0", newTestConfig(newMemStore())).Handler
+	tests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{"GET", "/api/healthz", ""},
+		{"GET", "/admin/metrics", ""},
+		{"GET", "/admin/audit-log", ""},
+		{"POST", "/api/users", `{"email":"v@example.com","password":"pw"}`},
+		{"POST", "/api/chirps", `{"body":"unauthenticated"}`},
+		{"GET", "/api/chirps/not-a-uuid", ""},
+		{"POST", "/api/refresh", ""},
+		{"POST", "/api/polka/webhooks", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if got := rec.Header().Get("X-Api-Version"); got != "1.0" {
+				t.Errorf("got X-Api-Version=%q, want=%q", got, "1.0")
+			}
+		})
+	}
+}
+
+func TestAcceptVersion(t *testing.T) {
+	handler := newServer("0", newTestConfig(newMemStore())).Handler
+	tests := []struct {
+		name     string
+		version  string
+		wantCode int
+	}{
+		{"no preference", "", http.StatusOK},
+		{"supported", "1.0", http.StatusOK},
+		{"unsupported", "2.0", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/healthz", nil)
+			if tt.version != "" {
+				req.Header.Set("Accept-Version", tt.version)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusNotAcceptable {
+				return
+			}
+			var body struct {
+				SupportedVersions []string `json:"supported_versions"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding body: %v", err)
+			}
+			if len(body.SupportedVersions) != 1 || body.SupportedVersions[0] != "1.0" {
+				t.Errorf("got supported_versions=%v, want [1.0]", body.SupportedVersions)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+)
+
+// appPushAssets are pushed alongside /app/. They are listed with the /app
+// prefix because that is where the file server exposes the assets directory.
+var appPushAssets = []string{"/app/assets/app.js", "/app/assets/app.css"}
+
+// middlewareAppPush sends HTTP/2 push promises for appPushAssets when the
+// root of /app/ is requested over a connection that supports push. On
+// HTTP/1.1, or when the client has disabled push, it does nothing.
+func (cfg *apiConfig) middlewareAppPush(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.http2Push && r.URL.Path == "/app/" {
+			if pusher, ok := w.(http.Pusher); ok {
+				for _, target := range appPushAssets {
+					err := pusher.Push(target, nil)
+					if errors.Is(err, http.ErrNotSupported) {
+						break
+					}
+					if err != nil {
+						log.Printf("Error pushing %s: %s", target, err)
+					}
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// pushRecorder is a ResponseWriter that supports server push, recording
+// each promised target.
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+func TestAppPush(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		path    string
+		want    []string
+	}{
+		{"root", true, "/app/", appPushAssets},
+		{"sub-page", true, "/app/index.html", nil},
+		{"asset", true, "/app/assets/logo.png", nil},
+		{"disabled", false, "/app/", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(newMemStore())
+			cfg.http2Push = tt.enabled
+			h := newServer("0", cfg).Handler
+			rec := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+			h.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if !slices.Equal(rec.pushed, tt.want) {
+				t.Errorf("pushed %v, want %v", rec.pushed, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppPushHTTP1Fallback(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.http2Push = true
+	rec := serve(newServer("0", cfg).Handler, "GET", "/app/", "", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want 200", rec.Code)
+	}
+}
+
+// Go's HTTP/2 client turns push off, so the server is refused and must
+// carry on serving the page.
+func TestAppPushOverHTTP2(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.http2Push = true
+	var sawPusher bool
+	inner := newServer("0", cfg).Handler
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, sawPusher = w.(http.Pusher)
+		inner.ServeHTTP(w, r)
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	res, err := srv.Client().Get(srv.URL + "/app/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.ProtoMajor != 2 || res.StatusCode != http.StatusOK {
+		t.Errorf("got %s %d, want HTTP/2 200", res.Proto, res.StatusCode)
+	}
+	if !sawPusher {
+		t.Error("HTTP/2 ResponseWriter should implement http.Pusher")
+	}
+}
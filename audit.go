@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+type contextKey int
+
+const (
+	actorIDKey contextKey = iota
+	clientIPKey
+)
+
+// withActor records the authenticated user on ctx so audit entries written
+// further down the request can attribute the change.
+func withActor(ctx context.Context, actorID uuid.UUID) context.Context {
+	return context.WithValue(ctx, actorIDKey, actorID)
+}
+
+func actorFromContext(ctx context.Context) (uuid.UUID, bool) {
+	actorID, ok := ctx.Value(actorIDKey).(uuid.UUID)
+	return actorID, ok
+}
+
+func clientIPFromContext(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPKey).(string)
+	return ip, ok
+}
+
+func middlewareClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientIPKey, ip)))
+	})
+}
+
+// audit writes an audit_log row for a completed mutation. Failures are logged
+// rather than returned so that a broken audit trail never fails the request.
+func (cfg *apiConfig) audit(ctx context.Context, action, entityType string, entityID uuid.UUID, details any) {
+	dat := []byte("{}")
+	if details != nil {
+		var err error
+		dat, err = json.Marshal(details)
+		if err != nil {
+			log.Printf("Error encoding audit details for %s: %s", action, err)
+			dat = []byte("{}")
+		}
+	}
+	actorID, hasActor := actorFromContext(ctx)
+	ip, hasIP := clientIPFromContext(ctx)
+	_, err := cfg.db.CreateAuditLog(ctx, database.CreateAuditLogParams{
+		ActorID: uuid.NullUUID{
+			UUID:  actorID,
+			Valid: hasActor,
+		},
+		Action:     action,
+		EntityType: entityType,
+		EntityID: uuid.NullUUID{
+			UUID:  entityID,
+			Valid: entityID != uuid.Nil,
+		},
+		Details: dat,
+		IpAddress: sql.NullString{
+			String: ip,
+			Valid:  hasIP,
+		},
+	})
+	if err != nil {
+		log.Printf("Error writing audit log for %s: %s", action, err)
+	}
+}
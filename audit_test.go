@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func TestAuditLogEntries(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+
+	do := func(method, path, body, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do("POST", "/api/users", `{"email":"a@example.com","password":"pw"}`, ""); rec.Code != 201 {
+		t.Fatalf("create user: got status %d", rec.Code)
+	}
+	if rec := do("POST", "/api/login", `{"email":"a@example.com","password":"wrong"}`, ""); rec.Code != 401 {
+		t.Fatalf("bad login: got status %d", rec.Code)
+	}
+	if rec := do("POST", "/api/login", `{"email":"a@example.com","password":"pw"}`, ""); rec.Code != 200 {
+		t.Fatalf("login: got status %d", rec.Code)
+	}
+	userID := store.users[0].ID
+	token, _ := auth.MakeJWT(userID, cfg.tokenSecret, time.Hour)
+	if rec := do("POST", "/api/chirps", `{"body":"hello"}`, token); rec.Code != 201 {
+		t.Fatalf("create chirp: got status %d", rec.Code)
+	}
+	chirpID := store.chirps[0].ID
+	if rec := do("DELETE", "/api/chirps/"+chirpID.String(), "", token); rec.Code != 204 {
+		t.Fatalf("delete chirp: got status %d", rec.Code)
+	}
+
+	want := []struct {
+		action   string
+		hasActor bool
+	}{
+		{"user.created", false},
+		{"user.login_failed", false},
+		{"user.login", true},
+		{"chirp.created", true},
+		{"chirp.deleted", true},
+	}
+	if len(store.auditLogs) != len(want) {
+		t.Fatalf("got %d audit entries, want %d", len(store.auditLogs), len(want))
+	}
+	for i, w := range want {
+		e := store.auditLogs[i]
+		if e.Action != w.action {
+			t.Errorf("entry %d: got action=%q, want=%q", i, e.Action, w.action)
+		}
+		if e.ActorID.Valid != w.hasActor || (w.hasActor && e.ActorID.UUID != userID) {
+			t.Errorf("entry %d: got actor=%v, want actor=%v", i, e.ActorID, w.hasActor)
+		}
+		if !e.IpAddress.Valid {
+			t.Errorf("entry %d: missing ip address", i)
+		}
+	}
+
+	rec := do("GET", "/admin/audit-log?from="+time.Now().Add(-time.Hour).Format(time.RFC3339), "", "")
+	if rec.Code != 200 {
+		t.Fatalf("audit log: got status %d", rec.Code)
+	}
+	var entries []auditLogResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("decoding audit log: %v", err)
+	}
+	if len(entries) != len(want) || entries[0].Action != "chirp.deleted" {
+		t.Errorf("got %d entries starting with %q, want %d newest first", len(entries), entries[0].Action, len(want))
+	}
+
+	rec = do("GET", "/admin/audit-log?to="+time.Now().Add(-time.Hour).Format(time.RFC3339), "", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil || len(entries) != 0 {
+		t.Errorf("got %d entries before range, want 0 (err=%v)", len(entries), err)
+	}
+}
+
+// refreshFailStore fails every CreateRefreshToken call.
+type refreshFailStore struct {
+	*memStore
+}
+
+func (s refreshFailStore) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) (database.RefreshToken, error) {
+	return database.RefreshToken{}, errors.New("refresh tokens unavailable")
+}
+
+func TestAuditFailedLogins(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	if rec := serve(h, "POST", "/api/users", `{"email":"a@example.com","password":"pw"}`, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("create user: got status %d", rec.Code)
+	}
+
+	if rec := serve(h, "POST", "/api/login", `{"email":"nobody@example.com","password":"pw"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unknown email: got status %d, want 401", rec.Code)
+	}
+	last := store.auditLogs[len(store.auditLogs)-1]
+	if last.Action != "user.login_failed" || last.EntityID.Valid || !strings.Contains(string(last.Details), `"reason":"unknown_email"`) {
+		t.Errorf("unknown email: got audit entry %s %s", last.Action, last.Details)
+	}
+
+	// A login whose tokens cannot be stored did not happen.
+	cfg.db = refreshFailStore{store}
+	h = newServer("0", cfg).Handler
+	if rec := serve(h, "POST", "/api/login", `{"email":"a@example.com","password":"pw"}`, ""); rec.Code == http.StatusOK {
+		t.Fatal("login succeeded without a refresh token")
+	}
+	for _, e := range store.auditLogs {
+		if e.Action == "user.login" {
+			t.Error("recorded a login whose tokens were never issued")
+		}
+	}
+	last = store.auditLogs[len(store.auditLogs)-1]
+	if last.Action != "user.login_failed" || !strings.Contains(string(last.Details), `"reason":"token_error"`) {
+		t.Errorf("token failure: got audit entry %s %s", last.Action, last.Details)
+	}
+}
+
+func TestAuditLogRequiresDev(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.platform = "prod"
+	rec := httptest.NewRecorder()
+	newServer("0", cfg).Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/audit-log", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type userContextKey struct{}
+
+// errInsufficientScope is returned for a scoped access token used for a
+// request its scope does not allow.
+var errInsufficientScope = errors.New("token scope does not allow this request")
+
+// scopeAllows reports whether an access token with scope may be used for
+// a request with method. Unscoped tokens may be used for anything; tokens
+// with the OAuth read scope only for safe methods.
+func scopeAllows(scope, method string) bool {
+	if scope == "" {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// authenticateRequest is validateJWT for the access token r carries,
+// failing with errInsufficientScope when its scope does not allow r's
+// method.
+func (cfg *apiConfig) authenticateRequest(r *http.Request, token string) (uuid.UUID, error) {
+	claims, err := cfg.parseAccessToken(r.Context(), token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !scopeAllows(claims.Scope, r.Method) {
+		return uuid.Nil, errInsufficientScope
+	}
+	return claims.UserID, nil
+}
+
+func withAuthUser(ctx context.Context, user database.User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// authUserFromContext returns the user requireAuth authenticated the
+// request as. It is false for requests that did not go through requireAuth.
+func authUserFromContext(ctx context.Context) (database.User, bool) {
+	user, ok := ctx.Value(userContextKey{}).(database.User)
+	return user, ok
+}
+
+// requireAuth serves next only for requests with a valid access token,
+// with the token's user loaded into the context. A token for a user that
+// no longer exists is unauthorized like any other bad token; one whose
+// scope does not allow the request is forbidden.
+func (cfg *apiConfig) requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearerToken, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		userId, err := cfg.authenticateRequest(r, bearerToken)
+		if errors.Is(err, errInsufficientScope) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+		if errors.Is(err, sql.ErrNoRows) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withAuthUser(r.Context(), user.User)))
+	})
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/google/uuid"
+)
+
+func TestRequireAuth(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	ghostToken, err := auth.MakeJWT(uuid.New(), cfg.tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+
+	var got uuid.UUID
+	h := cfg.requireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authUserFromContext(r.Context())
+		if !ok {
+			t.Error("requireAuth passed the request on without a user")
+		}
+		got = user.ID
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, c := range []struct {
+		name, token string
+		want        int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"invalid token", "not-a-jwt", http.StatusUnauthorized},
+		{"deleted user", ghostToken, http.StatusUnauthorized},
+		{"valid token", token, http.StatusNoContent},
+	} {
+		got = uuid.Nil
+		rec := serve(h, "GET", "/", "", c.token)
+		if rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+		if c.want != http.StatusNoContent && got != uuid.Nil {
+			t.Errorf("%s: next was called", c.name)
+		}
+	}
+	serve(h, "GET", "/", "", token)
+	if got != alice.ID {
+		t.Errorf("next got user %s, want %s", got, alice.ID)
+	}
+}
+
+func TestRequireAuthUserReachesHandlers(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+
+	rec := serve(h, "GET", "/api/users/me", "", bobToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/users/me: got status %d, want 200", rec.Code)
+	}
+	var me userResp
+	json.NewDecoder(rec.Body).Decode(&me)
+	if me.ID != bob.ID || me.Email != "bob@example.com" {
+		t.Errorf("GET /api/users/me as bob: got %s %q", me.ID, me.Email)
+	}
+
+	chirp := postChirp(t, h, `{"body":"hello"}`, aliceToken)
+	if rec := serve(h, "DELETE", "/api/chirps/"+chirp.ID.String(), "", bobToken); rec.Code != http.StatusForbidden {
+		t.Errorf("bob deleting alice's chirp: got status %d, want 403", rec.Code)
+	}
+	if rec := serve(h, "DELETE", "/api/chirps/"+chirp.ID.String(), "", aliceToken); rec.Code != http.StatusNoContent {
+		t.Errorf("alice deleting her chirp: got status %d, want 204", rec.Code)
+	}
+}
+
+func TestProtectedHandlerWithoutRequireAuth(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	// A handler mounted without requireAuth must not trust the header.
+	rec := serve(http.HandlerFunc(cfg.handlerGetMe), "GET", "/api/users/me", "", "anything")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", rec.Code)
+	}
+}
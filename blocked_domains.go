@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+const blockedDomainsTTL = time.Minute
+
+// domainBlocklist caches blocked_email_domains so registration does not
+// query the table on every request.
+type domainBlocklist struct {
+	mu       sync.RWMutex
+	domains  map[string]bool
+	loadedAt time.Time
+}
+
+func (b *domainBlocklist) invalidate() {
+	b.mu.Lock()
+	b.loadedAt = time.Time{}
+	b.mu.Unlock()
+}
+
+func emailDomain(email string) string {
+	i := strings.LastIndex(email, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(email[i+1:]))
+}
+
+func (cfg *apiConfig) isEmailDomainBlocked(ctx context.Context, email string) (bool, error) {
+	domain := emailDomain(email)
+	if domain == "" {
+		return false, nil
+	}
+	b := &cfg.blockedDomains
+	b.mu.RLock()
+	if time.Since(b.loadedAt) < blockedDomainsTTL {
+		blocked := b.domains[domain]
+		b.mu.RUnlock()
+		return blocked, nil
+	}
+	b.mu.RUnlock()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if time.Since(b.loadedAt) >= blockedDomainsTTL {
+		rows, err := cfg.db.GetBlockedEmailDomains(ctx)
+		if err != nil {
+			return false, err
+		}
+		b.domains = make(map[string]bool, len(rows))
+		for _, d := range rows {
+			b.domains[strings.ToLower(d)] = true
+		}
+		b.loadedAt = time.Now()
+	}
+	return b.domains[domain], nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// maxLoggedBodyBytes caps how much of a body middlewareBodyLog keeps, so an
+// upload is not held in memory twice.
+const maxLoggedBodyBytes = 64 << 10
+
+// bodyLogRedactedFields are JSON fields whose values are never logged, at
+// any depth and in any case.
+var bodyLogRedactedFields = map[string]bool{"password": true, "hashed_password": true, "token": true, "secret": true}
+
+// cappedBuffer keeps the first maxLoggedBodyBytes written to it.
+type cappedBuffer struct {
+	bytes.Buffer
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := maxLoggedBodyBytes - b.Len(); len(p) > room {
+		b.truncated = true
+		b.Buffer.Write(p[:max(room, 0)])
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// redactBody returns body as JSON with bodyLogRedactedFields replaced by
+// [REDACTED], or [non-json body] when it is not JSON.
+func redactBody(body []byte) string {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "[non-json body]"
+	}
+	dat, err := json.Marshal(redactJSON(v))
+	if err != nil {
+		return "[non-json body]"
+	}
+	return string(dat)
+}
+
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if bodyLogRedactedFields[strings.ToLower(k)] {
+				v[k] = redactedValue
+				continue
+			}
+			v[k] = redactJSON(field)
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+// middlewareBodyLog logs each request body in dev, with secrets redacted,
+// for debugging requests like a failing login. It copies the body as the
+// handler reads it and logs once the handler is done. Off dev it does
+// nothing.
+func (cfg *apiConfig) middlewareBodyLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.platform != "dev" || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		var buf cappedBuffer
+		body := r.Body
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(body, &buf), body}
+		next.ServeHTTP(w, r)
+		if buf.Len() == 0 {
+			return
+		}
+		logged := "[non-json body]"
+		if !buf.truncated {
+			logged = redactBody(buf.Bytes())
+		}
+		log.Printf("%s %s body: %s", r.Method, r.URL.Path, logged)
+	})
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// captureLog sends the standard logger to a buffer for the rest of the
+// test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+	return &buf
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`{"email":"a@example.com","password":"hunter2"}`, `{"email":"a@example.com","password":"[REDACTED]"}`},
+		{`{"Token":"abc","nested":{"secret":"s","keep":1},"list":[{"hashed_password":"h"}]}`, `{"Token":"[REDACTED]","list":[{"hashed_password":"[REDACTED]"}],"nested":{"keep":1,"secret":"[REDACTED]"}}`},
+		{`email=a@example.com&password=hunter2`, `[non-json body]`},
+	}
+	for _, c := range cases {
+		if got := redactBody([]byte(c.in)); got != c.want {
+			t.Errorf("redactBody(%s) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestBodyLogDevOnly(t *testing.T) {
+	logs := captureLog(t)
+	cfg := newTestConfig(newMemStore())
+	h := newServer("0", cfg).Handler
+
+	serve(h, "POST", "/api/login", `{"email":"a@example.com","password":"hunter2"}`, "")
+	out := logs.String()
+	if !strings.Contains(out, `POST /api/login body: {"email":"a@example.com","password":"[REDACTED]"}`) {
+		t.Errorf("dev log missing the redacted body:\n%s", out)
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("dev log contains the password:\n%s", out)
+	}
+
+	logs.Reset()
+	cfg.platform = "prod"
+	serve(newServer("0", cfg).Handler, "POST", "/api/login", `{"email":"a@example.com","password":"hunter2"}`, "")
+	if strings.Contains(logs.String(), "body:") {
+		t.Errorf("logged a body off dev:\n%s", logs.String())
+	}
+}
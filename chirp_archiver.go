@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// runChirpArchiver moves chirps older than cfg.chirpRetention into
+// chirps_archive each time tick fires. It returns when tick is closed or ctx
+// is cancelled.
+func (cfg *apiConfig) runChirpArchiver(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-tick:
+			if !ok {
+				return
+			}
+			n, err := cfg.archiveChirps(ctx, t)
+			if err != nil {
+				log.Printf("Error archiving chirps: %s", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Archived %d chirps", n)
+				// Archived chirps no longer count towards quotas.
+				cfg.chirpCounts.Clear()
+			}
+		}
+	}
+}
+
+func (cfg *apiConfig) archiveChirps(ctx context.Context, now time.Time) (int64, error) {
+	return cfg.db.ArchiveChirpsBefore(ctx, sql.NullTime{
+		Time:  now.Add(-cfg.chirpRetention),
+		Valid: true,
+	})
+}
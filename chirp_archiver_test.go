@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func TestRunChirpArchiver(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpRetention = 2 * time.Hour
+
+	base := time.Now()
+	old := database.Chirp{
+		ID:              uuid.New(),
+		CreatedAt:       sql.NullTime{Time: base.Add(-3 * time.Hour), Valid: true},
+		ImpressionCount: 7,
+		ThreadDepth:     2,
+		ContentWarning:  sql.NullString{String: "spoilers", Valid: true},
+		AdminEdited:     true,
+		ImportanceScore: 1.5,
+	}
+	recent := database.Chirp{ID: uuid.New(), CreatedAt: sql.NullTime{Time: base.Add(-time.Hour), Valid: true}}
+	store.chirps = []database.Chirp{old, recent}
+	store.media = []database.ChirpMedium{
+		{ID: uuid.New(), ChirpID: old.ID, Url: "https://cdn.example.com/old.png", MimeType: "image/png"},
+		{ID: uuid.New(), ChirpID: recent.ID, Url: "https://cdn.example.com/recent.png", MimeType: "image/png"},
+	}
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		cfg.runChirpArchiver(context.Background(), tick)
+		close(done)
+	}()
+	tick <- base
+	close(tick)
+	<-done
+
+	if len(store.chirps) != 1 || store.chirps[0].ID != recent.ID {
+		t.Errorf("got active chirps %v, want only the recent one", store.chirps)
+	}
+	if len(store.archive) != 1 || store.archive[0].ID != old.ID {
+		t.Fatalf("got archived chirps %v, want only the old one", store.archive)
+	}
+	if !store.archive[0].ArchivedAt.Valid {
+		t.Errorf("archived chirp missing archived_at")
+	}
+	if got := store.archive[0]; got.ImpressionCount != 7 || got.ThreadDepth != 2 || got.ContentWarning.String != "spoilers" || !got.AdminEdited || got.ImportanceScore != 1.5 {
+		t.Errorf("got archived chirp %+v, want its columns carried over", got)
+	}
+	if len(store.mediaArchive) != 1 || store.mediaArchive[0].ChirpID != old.ID || store.mediaArchive[0].Url != "https://cdn.example.com/old.png" {
+		t.Errorf("got archived media %+v, want the old chirp's attachment", store.mediaArchive)
+	}
+	if len(store.media) != 1 || store.media[0].ChirpID != recent.ID {
+		t.Errorf("got active media %+v, want only the recent chirp's", store.media)
+	}
+
+	rec := httptest.NewRecorder()
+	newServer("0", cfg).Handler.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/chirps/archive", nil))
+	var resp []archivedChirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding archive: %v", err)
+	}
+	if len(resp) != 1 || resp[0].ID != old.ID || resp[0].ImpressionCount != 7 || resp[0].ContentWarning != "spoilers" || !resp[0].AdminEdited {
+		t.Errorf("got archive response %v, want the old chirp", resp)
+	}
+}
+
+func TestArchivedChirpAutoLinksStillResolve(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpRetention = time.Hour
+
+	old := database.Chirp{ID: uuid.New(), CreatedAt: sql.NullTime{Time: time.Now().Add(-2 * time.Hour), Valid: true}}
+	store.chirps = []database.Chirp{old}
+	chirpID := uuid.NullUUID{UUID: old.ID, Valid: true}
+	store.shortLinks = []database.ShortLink{
+		{Code: "auto", ChirpID: chirpID, TargetUrl: sql.NullString{String: "https://example.com/a/long/path", Valid: true}, AutoGenerated: true},
+		{Code: "share", ChirpID: chirpID},
+	}
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		cfg.runChirpArchiver(context.Background(), tick)
+		close(done)
+	}()
+	tick <- time.Now()
+	close(tick)
+	<-done
+	if len(store.archive) != 1 {
+		t.Fatalf("got %d archived chirps, want 1", len(store.archive))
+	}
+
+	h := newServer("0", cfg).Handler
+	rec := serve(h, "GET", "/s/auto", "", "")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "https://example.com/a/long/path" {
+		t.Errorf("auto link: got status %d to %q, want a redirect to its target", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := serve(h, "GET", "/s/share", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("share link to an archived chirp: got status %d, want 404", rec.Code)
+	}
+}
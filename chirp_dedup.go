@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// duplicateChirpWindow is how long a repeated POST /api/chirps is
+	// treated as a client retry rather than a new chirp.
+	duplicateChirpWindow = 5 * time.Second
+	fingerprintRetention = 10 * time.Second
+)
+
+// chirpFingerprint identifies a chirp submission by its author, parent and
+// a SHA-256 of its body. The parent is included so identical replies to
+// different chirps are not mistaken for retries.
+func chirpFingerprint(userID uuid.UUID, parentID *uuid.UUID, body string) string {
+	sum := sha256.Sum256([]byte(body))
+	fp := userID.String() + ":" + hex.EncodeToString(sum[:])
+	if parentID != nil {
+		fp += ":" + parentID.String()
+	}
+	return fp
+}
+
+// findDuplicateChirp returns the chirp created for fingerprint within the
+// last duplicateChirpWindow, if any.
+func (cfg *apiConfig) findDuplicateChirp(ctx context.Context, fingerprint string) (database.GetChirpByIDRow, bool, error) {
+	chirpID, err := cfg.db.GetRecentFingerprintChirp(ctx, database.GetRecentFingerprintChirpParams{
+		Fingerprint: fingerprint,
+		Since:       time.Now().Add(-duplicateChirpWindow),
+	})
+	if err == nil {
+		var chirp database.GetChirpByIDRow
+		chirp, err = cfg.db.GetChirpByID(ctx, database.GetChirpByIDParams{ID: chirpID, Namespace: namespaceOf(ctx)})
+		if err == nil {
+			return chirp, true, nil
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.GetChirpByIDRow{}, false, nil
+	}
+	return database.GetChirpByIDRow{}, false, err
+}
+
+// runFingerprintPurger deletes expired request fingerprints each time tick
+// fires. It returns when tick is closed or ctx is cancelled.
+func (cfg *apiConfig) runFingerprintPurger(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-tick:
+			if !ok {
+				return
+			}
+			if _, err := cfg.db.DeleteRequestFingerprintsBefore(ctx, t.Add(-fingerprintRetention)); err != nil {
+				log.Printf("Error purging request fingerprints: %s", err)
+			}
+		}
+	}
+}
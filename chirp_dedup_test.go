@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestChirpDeduplication(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+
+	first := serve(h, "POST", "/api/chirps", `{"body":"hello"}`, alice)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first post: got status %d, want 201", first.Code)
+	}
+	retry := serve(h, "POST", "/api/chirps", `{"body":"hello"}`, alice)
+	if retry.Code != http.StatusOK {
+		t.Errorf("retry: got status %d, want 200", retry.Code)
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("retry returned %s, want the original %s", retry.Body.String(), first.Body.String())
+	}
+	if len(store.chirps) != 1 {
+		t.Fatalf("got %d chirps stored, want 1", len(store.chirps))
+	}
+
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"hello!"}`, alice); rec.Code != http.StatusCreated {
+		t.Errorf("different body: got status %d, want 201", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"hello"}`, bob); rec.Code != http.StatusCreated {
+		t.Errorf("different user: got status %d, want 201", rec.Code)
+	}
+	parent := store.chirps[0].ID.String()
+	for i, want := range []int{http.StatusCreated, http.StatusOK} {
+		if rec := serve(h, "POST", "/api/chirps", `{"body":"hello","parent_id":"`+parent+`"}`, alice); rec.Code != want {
+			t.Errorf("reply %d: got status %d, want %d", i, rec.Code, want)
+		}
+	}
+}
+
+func TestChirpDeduplicationWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		age        time.Duration
+		wantStatus int
+	}{
+		{"just inside the window", duplicateChirpWindow - 100*time.Millisecond, http.StatusOK},
+		{"just outside the window", duplicateChirpWindow + 100*time.Millisecond, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			cfg := newTestConfig(store)
+			h := newServer("0", cfg).Handler
+			_, token := seedUser(t, cfg, store, "alice@example.com")
+			postChirp(t, h, `{"body":"hello"}`, token)
+			store.fingerprints[0].CreatedAt = time.Now().Add(-tt.age)
+			if rec := serve(h, "POST", "/api/chirps", `{"body":"hello"}`, token); rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestFingerprintPurger(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"old"}`, token)
+	postChirp(t, h, `{"body":"new"}`, token)
+	now := time.Now()
+	store.fingerprints[0].CreatedAt = now.Add(-fingerprintRetention - time.Second)
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		cfg.runFingerprintPurger(t.Context(), tick)
+		close(done)
+	}()
+	tick <- now
+	close(tick)
+	<-done
+
+	if len(store.fingerprints) != 1 || store.fingerprints[0].ChirpID == uuid.Nil {
+		t.Fatalf("got %d fingerprints after purge, want 1", len(store.fingerprints))
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"old"}`, token); rec.Code != http.StatusCreated {
+		t.Errorf("purged fingerprint still deduplicated: got status %d", rec.Code)
+	}
+}
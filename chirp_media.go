@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+const mediaURLExpiry = time.Hour
+
+type mediaResp struct {
+	ID       uuid.UUID `json:"id"`
+	URL      string    `json:"url"`
+	MimeType string    `json:"mime_type"`
+	AltText  string    `json:"alt_text"`
+}
+
+// parseMediaAllowedOrigins parses the comma-separated MEDIA_ALLOWED_ORIGINS
+// list of hosts. An empty value or "*" allows any origin, which it reports
+// as a nil list.
+func parseMediaAllowedOrigins(s string) []string {
+	var hosts []string
+	for _, h := range strings.Split(s, ",") {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "*" {
+			return nil
+		}
+		if h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// mediaOriginAllowed reports whether rawURL is served from one of
+// cfg.mediaAllowedOrigins. URLs that do not parse, or have no host, are
+// only allowed when every origin is.
+func (cfg *apiConfig) mediaOriginAllowed(rawURL string) bool {
+	if cfg.mediaAllowedOrigins == nil {
+		return true
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return slices.Contains(cfg.mediaAllowedOrigins, strings.ToLower(u.Host))
+}
+
+// attachMedia loads the media for chirps in one query and fills in each
+// chirp's Media with freshly signed URLs, along with its ExpandedURLs.
+func (cfg *apiConfig) attachMedia(ctx context.Context, chirps []chirpResp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		ids = append(ids, c.ID)
+	}
+	rows, err := cfg.db.GetMediaForChirps(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byChirp := make(map[uuid.UUID][]mediaResp)
+	for _, m := range rows {
+		signed, err := media.GenerateSignedURL(m.Url, mediaURLExpiry, cfg.mediaSigningKey)
+		if err != nil {
+			return err
+		}
+		byChirp[m.ChirpID] = append(byChirp[m.ChirpID], mediaResp{
+			ID:       m.ID,
+			URL:      signed,
+			MimeType: m.MimeType,
+			AltText:  m.AltText,
+		})
+	}
+	for i := range chirps {
+		chirps[i].Media = byChirp[chirps[i].ID]
+	}
+	return cfg.attachExpandedURLs(ctx, chirps)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseMediaAllowedOrigins(t *testing.T) {
+	if got := parseMediaAllowedOrigins(""); got != nil {
+		t.Errorf("got %v for an unset list, want nil", got)
+	}
+	if got := parseMediaAllowedOrigins("*"); got != nil {
+		t.Errorf("got %v for *, want nil", got)
+	}
+	got := parseMediaAllowedOrigins(" cdn.chirpy.example , Media.Chirpy.Example,")
+	if want := []string{"cdn.chirpy.example", "media.chirpy.example"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMediaAllowedOrigins(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins string
+		url     string
+		want    int
+	}{
+		{"matching host", "cdn.chirpy.example", "https://cdn.chirpy.example/a.png", http.StatusCreated},
+		{"matching host, other case", "cdn.chirpy.example", "https://CDN.chirpy.example/a.png", http.StatusCreated},
+		{"other host", "cdn.chirpy.example", "https://evil.example/a.png", http.StatusUnprocessableEntity},
+		{"subdomain of allowed host", "chirpy.example", "https://cdn.chirpy.example/a.png", http.StatusUnprocessableEntity},
+		{"malformed", "cdn.chirpy.example", "https://cdn.chirpy.example:port/a.png", http.StatusUnprocessableEntity},
+		{"no host", "cdn.chirpy.example", "/a.png", http.StatusUnprocessableEntity},
+		{"wildcard", "*", "https://anywhere.example/a.png", http.StatusCreated},
+		{"unset", "", "https://anywhere.example/a.png", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			cfg := newTestConfig(store)
+			cfg.mediaAllowedOrigins = parseMediaAllowedOrigins(tt.origins)
+			h := newServer("0", cfg).Handler
+			_, token := seedUser(t, cfg, store, "alice@example.com")
+			body := fmt.Sprintf(`{"body":"look","media":[{"url":%q,"mime_type":"image/png"}]}`, tt.url)
+			rec := serve(h, "POST", "/api/chirps", body, token)
+			if rec.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+			if tt.want == http.StatusUnprocessableEntity && !strings.Contains(rec.Body.String(), "media URL origin not allowed") {
+				t.Errorf("got body %s, want the origin error", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAltTextHints(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	tests := []struct {
+		name  string
+		media string
+		want  string
+	}{
+		{"image without alt text", `[{"url":"https://cdn.example/a.png","mime_type":"image/png"},{"url":"https://cdn.example/b.mp4","mime_type":"video/mp4"},{"url":"https://cdn.example/c.jpg","mime_type":"image/jpeg","alt_text":"  "}]`,
+			`"warnings":["media item 0: alt_text is empty","media item 2: alt_text is empty"]`},
+		{"image with alt text", `[{"url":"https://cdn.example/a.png","mime_type":"image/png","alt_text":"a cat asleep"}]`, `"warnings":null`},
+		{"no media", `null`, `"warnings":null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, "POST", "/api/chirps", `{"body":"look at `+tt.name+`","media":`+tt.media+`}`, token)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("got status %d, want 201: %s", rec.Code, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("got body %s, want %s", rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
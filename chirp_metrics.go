@@ -0,0 +1,32 @@
+package main
+
+import "unicode"
+
+const readingWordsPerMinute = 200
+
+// chirpMetrics counts the words in body and estimates how long it takes to
+// read. A word is a run of letters or numbers, so punctuation and emoji
+// never count. Han, Hiragana and Katakana are written without spaces, so
+// each of those characters counts as a word. Any non-empty text reads in
+// at least one second.
+func chirpMetrics(body string) (wordCount, readingTimeSec int) {
+	inWord := false
+	for _, r := range body {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			wordCount++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if !inWord {
+				wordCount++
+				inWord = true
+			}
+		case inWord && (unicode.IsMark(r) || r == '\'' || r == '’'):
+			// Combining marks and apostrophes ("don't") stay in the word.
+		default:
+			inWord = false
+		}
+	}
+	readingTimeSec = (wordCount*60 + readingWordsPerMinute - 1) / readingWordsPerMinute
+	return wordCount, readingTimeSec
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChirpMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantWords   int
+		wantSeconds int
+	}{
+		{"empty", "", 0, 0},
+		{"whitespace only", "  \n\t ", 0, 0},
+		{"english", "The quick brown fox jumps over the lazy dog.", 9, 3},
+		{"punctuation and numbers", "Ship v2.0 by 5pm -- don't wait!", 7, 3},
+		{"chinese", "我爱北京天安门", 7, 3},
+		{"japanese mixed scripts", "東京タワーに行きました", 11, 4},
+		{"korean uses spaces", "안녕하세요 세계", 2, 1},
+		{"emoji only", "🎉🔥🚀", 0, 0},
+		{"emoji heavy", "🎉 party 🎉 time 🔥🔥", 2, 1},
+		{"accented word", "café résumé naïve", 3, 1},
+		{"two hundred words", strings.Repeat("word ", 200), 200, 60},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, seconds := chirpMetrics(tt.body)
+			if words != tt.wantWords || seconds != tt.wantSeconds {
+				t.Errorf("chirpMetrics(%q) = %d, %d; want %d, %d", tt.body, words, seconds, tt.wantWords, tt.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestChirpMetricsStoredOnCreate(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"one two three four"}`, token)
+	if c.WordCount != 4 || c.ReadingTimeSeconds != 2 {
+		t.Errorf("create response: got %d words, %ds", c.WordCount, c.ReadingTimeSeconds)
+	}
+	if store.chirps[0].WordCount != 4 {
+		t.Errorf("stored word count %d, want 4", store.chirps[0].WordCount)
+	}
+	rec := serve(h, "GET", "/api/chirps/"+c.ID.String(), "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"word_count":4`) {
+		t.Errorf("get response: %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetChirpsMinSentimentFilter(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	happy := postChirp(t, h, `{"body":"what a wonderful day"}`, token)
+	postChirp(t, h, `{"body":"the bus was late"}`, token)
+	postChirp(t, h, `{"body":"terrible coffee"}`, token)
+	if happy.Sentiment != 0.9 {
+		t.Errorf("got sentiment %v on create, want 0.9", happy.Sentiment)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 3},
+		{"?min_sentiment=0.5", 1},
+		{"?min_sentiment=0", 2},
+		{"?min_sentiment=-1", 3},
+	}
+	for _, tt := range tests {
+		var chirps []chirpResp
+		json.Unmarshal(serve(h, "GET", "/api/chirps"+tt.query, "", "").Body.Bytes(), &chirps)
+		if len(chirps) != tt.want {
+			t.Errorf("%q: got %d chirps, want %d", tt.query, len(chirps), tt.want)
+		}
+	}
+	for _, bad := range []string{"high", "1.5", "-2"} {
+		if rec := serve(h, "GET", "/api/chirps?min_sentiment="+bad, "", ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("min_sentiment=%s: got status %d, want 400", bad, rec.Code)
+		}
+	}
+}
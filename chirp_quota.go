@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxChirpsPerUser        = 10000
+	defaultMaxChirpsPerPremiumUser = 100000
+)
+
+// chirpCount returns the cached number of live chirps userID has, loading
+// it from the database on first use. Handlers that create, delete or
+// restore chirps keep it current through adjustChirpCount.
+func (cfg *apiConfig) chirpCount(ctx context.Context, userID uuid.UUID) (*atomic.Int64, error) {
+	if v, ok := cfg.chirpCounts.Load(userID); ok {
+		return v.(*atomic.Int64), nil
+	}
+	n, err := cfg.db.CountUserChirps(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	count := &atomic.Int64{}
+	count.Store(n)
+	v, _ := cfg.chirpCounts.LoadOrStore(userID, count)
+	return v.(*atomic.Int64), nil
+}
+
+// adjustChirpCount applies delta to userID's cached count. A user with
+// nothing cached is left alone; their count is loaded fresh when needed.
+func (cfg *apiConfig) adjustChirpCount(userID uuid.UUID, delta int64) {
+	if v, ok := cfg.chirpCounts.Load(userID); ok {
+		v.(*atomic.Int64).Add(delta)
+	}
+}
+
+// chirpQuotaReached reports whether userID already has as many chirps as
+// their tier allows, along with that limit. Chirpy Red members get
+// cfg.maxChirpsPerPremiumUser and everyone else cfg.maxChirpsPerUser; zero
+// means unlimited.
+func (cfg *apiConfig) chirpQuotaReached(ctx context.Context, userID uuid.UUID) (bool, int, error) {
+	if cfg.maxChirpsPerUser <= 0 && cfg.maxChirpsPerPremiumUser <= 0 {
+		return false, 0, nil
+	}
+	user, err := cfg.db.GetUserById(ctx, database.GetUserByIdParams{ID: userID, Namespace: namespaceOf(ctx)})
+	if err != nil {
+		return false, 0, err
+	}
+	limit := cfg.maxChirpsPerUser
+	if user.User.IsChirpyRed {
+		limit = cfg.maxChirpsPerPremiumUser
+	}
+	if limit <= 0 {
+		return false, 0, nil
+	}
+	count, err := cfg.chirpCount(ctx, userID)
+	if err != nil {
+		return false, 0, err
+	}
+	return count.Load() >= int64(limit), limit, nil
+}
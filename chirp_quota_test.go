@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestChirpQuotaStandardUser(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxChirpsPerUser = 2
+	cfg.maxChirpsPerPremiumUser = 5
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "a@example.com")
+
+	for i := range 2 {
+		postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+	rec := serve(h, "POST", "/api/chirps", `{"body":"one too many"}`, token)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want 429 at the limit", rec.Code)
+	}
+	var got struct {
+		Error string `json:"error"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Error != "chirp quota reached" || got.Limit != 2 {
+		t.Errorf("got %+v, want the quota error with limit 2", got)
+	}
+	if store.chirpCountQueries != 1 {
+		t.Errorf("got %d count queries, want the count cached after the first", store.chirpCountQueries)
+	}
+}
+
+func TestChirpQuotaPremiumUser(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxChirpsPerUser = 2
+	cfg.maxChirpsPerPremiumUser = 3
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "red@example.com")
+	store.users[0].IsChirpyRed = true
+
+	for i := range 3 {
+		postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"one too many"}`, token); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d, want 429 at the premium limit", rec.Code)
+	}
+}
+
+func TestChirpQuotaAfterDeletes(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxChirpsPerUser = 3
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "a@example.com")
+
+	var ids []string
+	for i := range 3 {
+		ids = append(ids, postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token).ID.String())
+	}
+	serve(h, "DELETE", "/api/chirps/"+ids[0], "", token)
+	serve(h, "DELETE", "/api/chirps", `{"ids":["`+ids[1]+`"]}`, token)
+	if count, _ := cfg.chirpCount(t.Context(), store.users[0].ID); count.Load() != 1 {
+		t.Fatalf("got cached count %d after two deletes, want 1", count.Load())
+	}
+
+	postChirp(t, h, `{"body":"chirp 3"}`, token)
+	if rec := serve(h, "POST", "/api/chirps/"+ids[0]+"/restore", "", token); rec.Code != http.StatusOK {
+		t.Fatalf("restore: got status %d", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"chirp 4"}`, token); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d, want 429 once the restore refilled the quota", rec.Code)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const chirpReadBufferSize = 1024
+
+// chirpRead is one read receipt waiting in cfg.chirpReads.
+type chirpRead struct {
+	userID  uuid.UUID
+	chirpID uuid.UUID
+	seenAt  time.Time
+}
+
+type unreadChirpsResp struct {
+	Chirps     []chirpResp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// recordChirpRead queues a read receipt for the recorder so the request
+// does not wait on the write. Receipts are dropped when the buffer is full;
+// they are analytics, and a later read or mark-read records the chirp.
+func (cfg *apiConfig) recordChirpRead(userID, chirpID uuid.UUID) {
+	if userID == uuid.Nil || cfg.chirpReads == nil {
+		return
+	}
+	select {
+	case cfg.chirpReads <- chirpRead{userID: userID, chirpID: chirpID, seenAt: cfg.timeNow()}:
+	default:
+		log.Printf("Read receipt buffer full, dropping read of %s", chirpID)
+	}
+}
+
+// runChirpReadRecorder writes the read receipts sent on reads. It returns
+// when reads is closed or ctx is cancelled.
+func (cfg *apiConfig) runChirpReadRecorder(ctx context.Context, reads <-chan chirpRead) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case read, ok := <-reads:
+			if !ok {
+				return
+			}
+			err := cfg.db.RecordChirpRead(ctx, database.RecordChirpReadParams{
+				UserID:  read.userID,
+				ChirpID: read.chirpID,
+				SeenAt:  read.seenAt,
+			})
+			if err != nil {
+				log.Printf("Error recording read of chirp %s: %s", read.chirpID, err)
+			}
+		}
+	}
+}
+
+// handlerMarkChirpRead records that the caller has read a chirp, for clients
+// that show chirps without fetching each one.
+func (cfg *apiConfig) handlerMarkChirpRead(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpUUId, Namespace: namespaceOf(r.Context())})
+	if err == nil && chirp.Chirp.IsHidden {
+		err = sql.ErrNoRows
+	}
+	if err == nil {
+		var visible bool
+		if visible, err = cfg.canViewChirp(r.Context(), chirp.Chirp, userId); err == nil && !visible {
+			err = sql.ErrNoRows
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	err = cfg.db.RecordChirpRead(r.Context(), database.RecordChirpReadParams{
+		UserID:  userId,
+		ChirpID: chirpUUId,
+		SeenAt:  cfg.timeNow(),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerGetUnreadChirps pages through chirps by people the caller follows
+// that the caller has not read yet, newest first.
+func (cfg *apiConfig) handlerGetUnreadChirps(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.GetUnreadChirpsParams{
+		UserID:          userId,
+		CursorCreatedAt: farFuture,
+		CursorID:        uuid.Max,
+		PageSize:        pageSize + 1,
+		Namespace:       namespaceOf(r.Context()),
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		params.CursorCreatedAt, params.CursorID, err = decodeCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	chirps, err := cfg.db.GetUnreadChirps(r.Context(), params)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := unreadChirpsResp{Chirps: make([]chirpResp, 0, len(chirps))}
+	if len(chirps) > int(pageSize) {
+		chirps = chirps[:pageSize]
+		last := chirps[len(chirps)-1].Chirp
+		resp.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	for _, c := range chirps {
+		cr := newChirpResp(c.Chirp)
+		cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+		cr.Flagged = flaggedFor(c.Chirp, userId)
+		resp.Chirps = append(resp.Chirps, cr)
+	}
+	if err := cfg.attachMedia(r.Context(), resp.Chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
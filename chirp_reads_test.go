@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestChirpReadIsRecordedOnce(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	cfg.chirpReads = make(chan chirpRead, chirpReadBufferSize)
+	done := make(chan struct{})
+	go func() {
+		cfg.runChirpReadRecorder(context.Background(), cfg.chirpReads)
+		close(done)
+	}()
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	c := postChirp(t, h, `{"body":"read me"}`, alice)
+
+	first := clock.t
+	serve(h, "GET", "/api/chirps/"+c.ID.String(), "", bobToken)
+	clock.t = clock.t.Add(time.Minute)
+	serve(h, "GET", "/api/chirps/"+c.ID.String(), "", bobToken)
+	serve(h, "GET", "/api/chirps/"+c.ID.String(), "", "")
+	close(cfg.chirpReads)
+	<-done
+
+	if len(store.reads) != 1 {
+		t.Fatalf("got %d read rows, want 1", len(store.reads))
+	}
+	r := store.reads[0]
+	if r.UserID != bob.ID || r.ChirpID != c.ID {
+		t.Errorf("got read %+v, want bob reading the chirp", r)
+	}
+	if !r.FirstSeenAt.Equal(first) || !r.LastSeenAt.Equal(clock.t) || r.ViewCount != 2 {
+		t.Errorf("got first %v, last %v, count %d; want %v, %v, 2", r.FirstSeenAt, r.LastSeenAt, r.ViewCount, first, clock.t)
+	}
+}
+
+func TestUnreadChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	var ids []string
+	for i := range 3 {
+		ids = append(ids, postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), aliceToken).ID.String())
+	}
+	if rec := serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken); rec.Code >= 300 {
+		t.Fatalf("follow: got status %d", rec.Code)
+	}
+
+	unread := func(query string) unreadChirpsResp {
+		t.Helper()
+		rec := serve(h, "GET", "/api/users/me/unread-chirps"+query, "", bobToken)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp unreadChirpsResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if got := unread(""); len(got.Chirps) != 3 {
+		t.Fatalf("got %d unread chirps, want 3", len(got.Chirps))
+	}
+
+	if rec := serve(h, "POST", "/api/chirps/"+ids[2]+"/mark-read", "", bobToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("mark-read: got status %d", rec.Code)
+	}
+	serve(h, "POST", "/api/chirps/"+ids[2]+"/mark-read", "", bobToken)
+	page := unread("?limit=1")
+	if len(page.Chirps) != 1 || page.Chirps[0].ID.String() != ids[1] || page.NextCursor == "" {
+		t.Fatalf("got first page %+v, want chirp 1 and a cursor", page)
+	}
+	page = unread("?limit=1&cursor=" + page.NextCursor)
+	if len(page.Chirps) != 1 || page.Chirps[0].ID.String() != ids[0] || page.NextCursor != "" {
+		t.Errorf("got second page %+v, want chirp 0 and no cursor", page)
+	}
+	if len(store.reads) != 1 || store.reads[0].ViewCount != 2 {
+		t.Errorf("got reads %+v, want one row marked twice", store.reads)
+	}
+
+	if rec := serve(h, "POST", "/api/chirps/"+ids[0]+"/mark-read", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous mark-read: got status %d, want 401", rec.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// chirpRecycleRetention is how long a deleted chirp can be restored
+	// before it is purged for good.
+	chirpRecycleRetention = 30 * 24 * time.Hour
+	chirpPurgeInterval    = time.Hour
+)
+
+type deletedChirpResp struct {
+	chirpResp
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// handlerGetDeletedChirps lists the caller's chirps still in the recycle
+// bin, most recently deleted first.
+func (cfg *apiConfig) handlerGetDeletedChirps(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirps, err := cfg.db.GetDeletedChirpsByUser(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]deletedChirpResp, 0, len(chirps))
+	for _, c := range chirps {
+		resp = append(resp, deletedChirpResp{
+			chirpResp: newChirpResp(c),
+			DeletedAt: c.DeletedAt.Time,
+			PurgeAt:   c.DeletedAt.Time.Add(chirpRecycleRetention),
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerRestoreChirp takes one of the caller's chirps out of the recycle
+// bin. Chirps deleted more than chirpRecycleRetention ago are treated as
+// gone even if the purger has not reached them yet.
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirp, err := cfg.db.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:           chirpUUId,
+		UserID:       userId,
+		DeletedSince: cfg.timeNow().Add(-chirpRecycleRetention),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "no deleted chirp to restore")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.adjustChirpCount(userId, 1)
+	cfg.audit(withActor(r.Context(), userId), "chirp.restored", "chirp", chirpUUId, nil)
+	respondWithJSON(w, http.StatusOK, newChirpResp(chirp))
+}
+
+// runDeletedChirpPurger permanently deletes chirps that have been in the
+// recycle bin longer than chirpRecycleRetention each time tick fires. It
+// returns when tick is closed or ctx is cancelled.
+func (cfg *apiConfig) runDeletedChirpPurger(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-tick:
+			if !ok {
+				return
+			}
+			n, err := cfg.purgeDeletedChirps(ctx, t)
+			if err != nil {
+				log.Printf("Error purging deleted chirps: %s", err)
+				continue
+			}
+			if n > 0 {
+				log.Printf("Purged %d deleted chirps", n)
+			}
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeDeletedChirps(ctx context.Context, now time.Time) (int64, error) {
+	return cfg.db.PurgeDeletedChirpsBefore(ctx, now.Add(-chirpRecycleRetention))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func TestDeleteAndRestoreChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+	c := postChirp(t, h, `{"body":"oops"}`, alice)
+
+	if rec := serve(h, "DELETE", "/api/chirps/"+c.ID.String(), "", alice); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: got status %d", rec.Code)
+	}
+	if rec := serve(h, "GET", "/api/chirps/"+c.ID.String(), "", alice); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: got status %d, want 404", rec.Code)
+	}
+	if len(store.chirps) != 1 {
+		t.Fatalf("got %d stored chirps, want the deleted one kept", len(store.chirps))
+	}
+
+	rec := serve(h, "GET", "/api/users/me/deleted-chirps", "", alice)
+	var bin []deletedChirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &bin); err != nil {
+		t.Fatalf("decoding recycle bin: %v", err)
+	}
+	if len(bin) != 1 || bin[0].ID != c.ID {
+		t.Fatalf("got recycle bin %v, want the deleted chirp", bin)
+	}
+	if got := bin[0].PurgeAt.Sub(bin[0].DeletedAt); got != chirpRecycleRetention {
+		t.Errorf("got purge_at %v after deleted_at, want %v", got, chirpRecycleRetention)
+	}
+	rec = serve(h, "GET", "/api/users/me/deleted-chirps", "", bob)
+	if rec.Body.String() != "[]" {
+		t.Errorf("another user's recycle bin: got %s, want []", rec.Body.String())
+	}
+
+	if rec := serve(h, "POST", "/api/chirps/"+c.ID.String()+"/restore", "", bob); rec.Code != http.StatusNotFound {
+		t.Errorf("restore by another user: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps/"+c.ID.String()+"/restore", "", alice); rec.Code != http.StatusOK {
+		t.Fatalf("restore: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(h, "GET", "/api/chirps/"+c.ID.String(), "", alice); rec.Code != http.StatusOK {
+		t.Errorf("get after restore: got status %d, want 200", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps/"+c.ID.String()+"/restore", "", alice); rec.Code != http.StatusNotFound {
+		t.Errorf("restoring a live chirp: got status %d, want 404", rec.Code)
+	}
+}
+
+func TestRestoreChirpAfterRetention(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	id := uuid.New()
+	store.chirps = []database.Chirp{{
+		ID:        id,
+		UserID:    alice.ID,
+		DeletedAt: sql.NullTime{Time: clock.t.Add(-chirpRecycleRetention - time.Minute), Valid: true},
+	}}
+
+	if rec := serve(h, "POST", "/api/chirps/"+id.String()+"/restore", "", token); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404 once the retention has passed", rec.Code)
+	}
+}
+
+func TestRunDeletedChirpPurger(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+
+	base := time.Now()
+	deletedAt := func(age time.Duration) sql.NullTime {
+		return sql.NullTime{Time: base.Add(-age), Valid: true}
+	}
+	expired := database.Chirp{ID: uuid.New(), DeletedAt: deletedAt(31 * 24 * time.Hour)}
+	recent := database.Chirp{ID: uuid.New(), DeletedAt: deletedAt(24 * time.Hour)}
+	live := database.Chirp{ID: uuid.New(), CreatedAt: deletedAt(365 * 24 * time.Hour)}
+	store.chirps = []database.Chirp{expired, recent, live}
+	store.chirpVersions = []database.ChirpVersion{{ChirpID: expired.ID}, {ChirpID: live.ID}}
+
+	tick := make(chan time.Time)
+	done := make(chan struct{})
+	go func() {
+		cfg.runDeletedChirpPurger(context.Background(), tick)
+		close(done)
+	}()
+	tick <- base
+	close(tick)
+	<-done
+
+	if len(store.chirps) != 2 || store.chirps[0].ID != recent.ID || store.chirps[1].ID != live.ID {
+		t.Errorf("got chirps %v, want only the expired one purged", store.chirps)
+	}
+	if len(store.chirpVersions) != 1 || store.chirpVersions[0].ChirpID != live.ID {
+		t.Errorf("got versions %v, want only the purged chirp's removed", store.chirpVersions)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "unicode/utf8"
+
+const (
+	sizeTierShort  = "short"
+	sizeTierMedium = "medium"
+	sizeTierLong   = "long"
+)
+
+// chirpSizeTier buckets a chirp by its length in characters, not bytes, so
+// multi-byte text such as emoji is not pushed into a larger tier.
+func chirpSizeTier(body string) string {
+	switch n := utf8.RuneCountInString(body); {
+	case n <= 40:
+		return sizeTierShort
+	case n <= 140:
+		return sizeTierMedium
+	default:
+		return sizeTierLong
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestChirpSizeTier(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "", sizeTierShort},
+		{"40 ascii", strings.Repeat("a", 40), sizeTierShort},
+		{"41 ascii", strings.Repeat("a", 41), sizeTierMedium},
+		{"140 ascii", strings.Repeat("a", 140), sizeTierMedium},
+		{"141 ascii", strings.Repeat("a", 141), sizeTierLong},
+		{"40 emoji is 160 bytes", strings.Repeat("🔥", 40), sizeTierShort},
+		{"41 emoji", strings.Repeat("🔥", 41), sizeTierMedium},
+		{"140 accented", strings.Repeat("é", 140), sizeTierMedium},
+		{"141 cjk", strings.Repeat("字", 141), sizeTierLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chirpSizeTier(tt.body); got != tt.want {
+				t.Errorf("chirpSizeTier(%d runes, %d bytes) = %q, want %q", len([]rune(tt.body)), len(tt.body), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetChirpsSizeTierFilter(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	u, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"short one"}`, token)
+	postChirp(t, h, `{"body":"`+strings.Repeat("m", 60)+`"}`, token)
+	store.chirps = append(store.chirps, database.Chirp{
+		ID:        uuid.New(),
+		UserID:    u.ID,
+		CreatedAt: nullNow(),
+		Body:      sql.NullString{String: strings.Repeat("l", 200), Valid: true},
+	})
+
+	for _, tier := range []string{sizeTierShort, sizeTierMedium, sizeTierLong} {
+		rec := serve(h, "GET", "/api/chirps?size_tier="+tier, "", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d", tier, rec.Code)
+		}
+		var chirps []chirpResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &chirps); err != nil {
+			t.Fatal(err)
+		}
+		if len(chirps) != 1 || chirps[0].SizeTier != tier {
+			t.Errorf("%s: got %+v, want exactly one %s chirp", tier, chirps, tier)
+		}
+	}
+	if rec := serve(h, "GET", "/api/chirps?size_tier=huge", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid tier: got status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	recentChirpBodies             = 5
+	defaultDuplicateChirpCooldown = 60 * time.Second
+)
+
+// chirpBodyRing holds a user's last recentChirpBodies chirp bodies,
+// normalized, overwriting the oldest once full.
+type chirpBodyRing struct {
+	bodies [recentChirpBodies]string
+	next   int
+}
+
+func (b *chirpBodyRing) add(body string) {
+	b.bodies[b.next] = body
+	b.next = (b.next + 1) % recentChirpBodies
+}
+
+func (b *chirpBodyRing) contains(body string) bool {
+	for _, prev := range b.bodies {
+		if prev != "" && prev == body {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeChirpBody lowercases body and collapses runs of whitespace, so
+// trivially edited copies compare equal.
+func normalizeChirpBody(body string) string {
+	return strings.Join(strings.Fields(strings.ToLower(body)), " ")
+}
+
+// inDuplicateCooldown reports whether userID is still barred from posting
+// for having repeated themselves.
+func (cfg *apiConfig) inDuplicateCooldown(userID uuid.UUID) bool {
+	v, ok := cfg.duplicateCooldowns.Load(userID)
+	if !ok {
+		return false
+	}
+	if cfg.timeNow().Before(v.(time.Time)) {
+		return true
+	}
+	cfg.duplicateCooldowns.CompareAndDelete(userID, v)
+	return false
+}
+
+// checkDuplicateBody reports whether body repeats one of userID's recent
+// chirps, starting a cfg.duplicateChirpCooldown for them if it does. A zero
+// cooldown turns the check off.
+func (cfg *apiConfig) checkDuplicateBody(userID uuid.UUID, body string) bool {
+	if cfg.duplicateChirpCooldown <= 0 {
+		return false
+	}
+	body = normalizeChirpBody(body)
+	cfg.recentBodiesMu.Lock()
+	ring := cfg.recentBodies[userID]
+	dup := ring != nil && ring.contains(body)
+	cfg.recentBodiesMu.Unlock()
+	if dup {
+		cfg.duplicateCooldowns.Store(userID, cfg.timeNow().Add(cfg.duplicateChirpCooldown))
+	}
+	return dup
+}
+
+// rememberChirpBody adds a newly posted body to userID's recent chirps.
+func (cfg *apiConfig) rememberChirpBody(userID uuid.UUID, body string) {
+	if cfg.duplicateChirpCooldown <= 0 {
+		return
+	}
+	cfg.recentBodiesMu.Lock()
+	defer cfg.recentBodiesMu.Unlock()
+	if cfg.recentBodies == nil {
+		cfg.recentBodies = make(map[uuid.UUID]*chirpBodyRing)
+	}
+	ring := cfg.recentBodies[userID]
+	if ring == nil {
+		ring = &chirpBodyRing{}
+		cfg.recentBodies[userID] = ring
+	}
+	ring.add(normalizeChirpBody(body))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newSpamTest(t *testing.T) (http.Handler, *memStore, *fakeClock, string) {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.duplicateChirpCooldown = defaultDuplicateChirpCooldown
+	clock := &fakeClock{t: time.Now()}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "spammer@example.com")
+	return h, store, clock, token
+}
+
+func TestNormalizeChirpBody(t *testing.T) {
+	if got := normalizeChirpBody("  Buy\tNOW \n  cheap  "); got != "buy now cheap" {
+		t.Errorf("got %q, want %q", got, "buy now cheap")
+	}
+}
+
+func TestDuplicateChirpBody(t *testing.T) {
+	tests := []struct {
+		name   string
+		repeat string
+	}{
+		{"exact duplicate", `{"body":"buy now cheap"}`},
+		{"whitespace and case", `{"body":"  Buy   NOW\tcheap "}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, store, _, token := newSpamTest(t)
+			postChirp(t, h, `{"body":"buy now cheap"}`, token)
+			// Take the retry window out of play so only the spam check applies.
+			store.fingerprints = nil
+			rec := serve(h, "POST", "/api/chirps", tt.repeat, token)
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("got status %d, want 429", rec.Code)
+			}
+			if rec.Body.String() != `{"error":"duplicate content detected"}` {
+				t.Errorf("got body %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestDuplicateChirpBodyOnlyLastFive(t *testing.T) {
+	h, store, _, token := newSpamTest(t)
+	for i := range recentChirpBodies + 1 {
+		postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+	store.fingerprints = nil
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"chirp 0"}`, token); rec.Code != http.StatusCreated {
+		t.Errorf("repeating the sixth most recent chirp: got status %d, want 201", rec.Code)
+	}
+}
+
+func TestDuplicateChirpCooldown(t *testing.T) {
+	h, store, clock, token := newSpamTest(t)
+	postChirp(t, h, `{"body":"spam"}`, token)
+	store.fingerprints = nil
+	serve(h, "POST", "/api/chirps", `{"body":"spam"}`, token)
+
+	clock.t = clock.t.Add(defaultDuplicateChirpCooldown - time.Second)
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"something new"}`, token); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("during the cool-down: got status %d, want 429", rec.Code)
+	}
+	clock.t = clock.t.Add(time.Second)
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"something new"}`, token); rec.Code != http.StatusCreated {
+		t.Errorf("after the cool-down: got status %d, want 201", rec.Code)
+	}
+}
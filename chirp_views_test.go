@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListFetchesCountImpressions(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	for i := range 5 {
+		postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+
+	if rec := serve(h, "GET", "/api/chirps", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("list: got status %d", rec.Code)
+	}
+	cfg.impressions.Wait()
+	for _, c := range store.chirps {
+		if c.ImpressionCount != 1 {
+			t.Errorf("chirp %q: got %d impressions after one list fetch, want 1", c.Body.String, c.ImpressionCount)
+		}
+	}
+
+	// The second feed fetch is served from the cache and still counts.
+	for range 2 {
+		if rec := serve(h, "GET", "/api/feed", "", token); rec.Code != http.StatusOK {
+			t.Fatalf("feed: got status %d", rec.Code)
+		}
+	}
+	cfg.impressions.Wait()
+	for _, c := range store.chirps {
+		if c.ImpressionCount != 3 {
+			t.Errorf("chirp %q: got %d impressions after two feed fetches, want 3", c.Body.String, c.ImpressionCount)
+		}
+	}
+
+	rec := serve(h, "GET", "/api/chirps", "", "")
+	if want := `"impression_count":3`; !strings.Contains(rec.Body.String(), want) {
+		t.Errorf("got body %s, want it to report %s", rec.Body.String(), want)
+	}
+	cfg.impressions.Wait()
+}
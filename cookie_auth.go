@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+const authCookieName = "chirpy_token"
+
+// handlerCookieLogin logs in like POST /api/login but hands the access token
+// to the browser as an HttpOnly cookie instead of in the body, for clients
+// that should not keep tokens in script-readable storage.
+func (cfg *apiConfig) handlerCookieLogin(w http.ResponseWriter, r *http.Request) {
+	cfg.login(w, r, cfg.respondWithCookieLogin)
+}
+
+func (cfg *apiConfig) respondWithCookieLogin(w http.ResponseWriter, r *http.Request, user database.User, expiresIn time.Duration) {
+	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, expiresIn)
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(500)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Value:    auth.SignCookieValue(token, cfg.cookieSigningKey),
+		Path:     "/",
+		MaxAge:   int(expiresIn / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	cfg.audit(withActor(r.Context(), user.ID), "user.login", "user", user.ID, nil)
+	respondWithJSON(w, http.StatusOK, userResp{
+		ID:          user.ID,
+		CreatedAt:   user.CreatedAt.Time,
+		UpdatedAt:   user.UpdatedAt.Time,
+		Email:       user.Email.String,
+		IsChirpyRed: user.IsChirpyRed,
+		IsVerified:  user.IsVerified,
+	})
+}
+
+// handlerCookieLogout tells the browser to drop the auth cookie. The token
+// inside stays valid until it expires, as a bearer token would.
+func (cfg *apiConfig) handlerCookieLogout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     authCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// middlewareCookieAuth lets handlers that read the Authorization header
+// accept the auth cookie too, by presenting a correctly signed cookie's
+// token as a bearer token. A request that already has an Authorization
+// header is passed through untouched, and a cookie that fails its signature
+// check is ignored.
+func (cfg *apiConfig) middlewareCookieAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(cfg.cookieSigningKey) == 0 || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cookie, err := r.Cookie(authCookieName)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, err := auth.VerifyCookieValue(cookie.Value, cfg.cookieSigningKey)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.Clone(r.Context())
+		r.Header.Set("Authorization", "Bearer "+token)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newCookieAuthTest(t *testing.T) http.Handler {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.cookieSigningKey = []byte("cookie-key")
+	h := newServer("0", cfg).Handler
+	serve(h, "POST", "/api/users", `{"email":"a@example.com","password":"secret"}`, "")
+	return h
+}
+
+func authCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == authCookieName {
+			return c
+		}
+	}
+	t.Fatalf("response set no %s cookie", authCookieName)
+	return nil
+}
+
+// withCookie serves a request to path carrying cookie and, if non-empty, an
+// Authorization header.
+func withCookie(h http.Handler, path string, cookie *http.Cookie, authorization string) int {
+	req := httptest.NewRequest("GET", path, nil)
+	req.AddCookie(cookie)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestCookieLogin(t *testing.T) {
+	h := newCookieAuthTest(t)
+	rec := serve(h, "POST", "/api/auth/cookie-login", `{"email":"a@example.com","password":"secret"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	c := authCookie(t, rec)
+	if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteStrictMode || c.MaxAge != 3600 {
+		t.Errorf("got cookie %+v, want HttpOnly, Secure, SameSite=Strict lasting an hour", c)
+	}
+	if strings.Contains(rec.Body.String(), `"token":"ey`) {
+		t.Errorf("cookie login leaked the token in the body: %s", rec.Body.String())
+	}
+
+	if code := withCookie(h, "/api/users/me/deleted-chirps", c, ""); code != http.StatusOK {
+		t.Errorf("request with cookie: got status %d, want 200", code)
+	}
+	if code := withCookie(h, "/api/users/me/deleted-chirps", c, "Bearer not-a-jwt"); code != http.StatusUnauthorized {
+		t.Errorf("header should take priority over the cookie: got status %d, want 401", code)
+	}
+
+	rec = serve(h, "POST", "/api/auth/cookie-login", `{"email":"a@example.com","password":"wrong"}`, "")
+	if rec.Code != http.StatusUnauthorized || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("wrong password: got status %d with cookies %v", rec.Code, rec.Result().Cookies())
+	}
+}
+
+func TestCookieAuthRejectsTampering(t *testing.T) {
+	h := newCookieAuthTest(t)
+	c := authCookie(t, serve(h, "POST", "/api/auth/cookie-login", `{"email":"a@example.com","password":"secret"}`, ""))
+
+	tampered := *c
+	i := strings.LastIndexByte(c.Value, '.')
+	flip := "A"
+	if c.Value[i-1] == 'A' {
+		flip = "B"
+	}
+	tampered.Value = c.Value[:i-1] + flip + c.Value[i:]
+	if code := withCookie(h, "/api/users/me/deleted-chirps", &tampered, ""); code != http.StatusUnauthorized {
+		t.Errorf("tampered cookie: got status %d, want 401", code)
+	}
+	unsigned := *c
+	unsigned.Value = c.Value[:i]
+	if code := withCookie(h, "/api/users/me/deleted-chirps", &unsigned, ""); code != http.StatusUnauthorized {
+		t.Errorf("cookie without a signature: got status %d, want 401", code)
+	}
+}
+
+func TestCookieLogout(t *testing.T) {
+	h := newCookieAuthTest(t)
+	rec := serve(h, "POST", "/api/auth/cookie-logout", "", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, want 204", rec.Code)
+	}
+	c := authCookie(t, rec)
+	if c.Value != "" || c.MaxAge >= 0 {
+		t.Errorf("got cookie %+v, want it cleared", c)
+	}
+}
+
+func TestCookieLoginDisabledWithoutKey(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	if rec := serve(h, "POST", "/api/auth/cookie-login", `{}`, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404 when no signing key is set", rec.Code)
+	}
+}
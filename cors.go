@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsMaxAge is how long browsers may cache a preflight response.
+const corsMaxAge = 10 * time.Minute
+
+const (
+	corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowedHeaders = "Authorization, Content-Type, Accept, Accept-Version, Date, Digest, Signature"
+)
+
+// corsPolicy is the parsed CORS_ALLOWED_ORIGINS setting.
+type corsPolicy struct {
+	// allowAll answers every origin with "*", which rules out credentials.
+	allowAll bool
+	origins  []string
+}
+
+// parseCORSOrigins reads a comma-separated list of origins such as
+// "https://app.example", or "*" for any origin.
+func parseCORSOrigins(s string) corsPolicy {
+	var p corsPolicy
+	for o := range strings.SplitSeq(s, ",") {
+		o = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(o)), "/")
+		if o == "*" {
+			return corsPolicy{allowAll: true}
+		}
+		if o != "" {
+			p.origins = append(p.origins, o)
+		}
+	}
+	return p
+}
+
+func (p corsPolicy) enabled() bool {
+	return p.allowAll || len(p.origins) > 0
+}
+
+// middlewareCORS adds CORS headers for the configured origins and answers
+// preflight requests itself, letting browsers cache them for corsMaxAge. An
+// allowlisted origin is echoed back with credentials allowed; with "*" the
+// wildcard is sent and credentials are not, as the spec requires. Whenever
+// the response depends on the Origin it carries Vary: Origin so a shared
+// cache never hands one origin's headers to another.
+func (cfg *apiConfig) middlewareCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := cfg.cors
+		if !p.enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		origin := r.Header.Get("Origin")
+		h := w.Header()
+		if !p.allowAll {
+			h.Add("Vary", "Origin")
+		}
+		allowed := origin != "" && (p.allowAll || slices.Contains(p.origins, strings.ToLower(origin)))
+		if allowed {
+			if p.allowAll {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if allowed {
+			h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(corsMaxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func corsRequest(h http.Handler, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/chirps", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCORSPreflightCache(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.cors = parseCORSOrigins("https://app.example, https://admin.example")
+	h := newServer("0", cfg).Handler
+
+	for i := range 3 {
+		rec := corsRequest(h, http.MethodOptions, "https://app.example")
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("preflight %d: got status %d, want 204", i, rec.Code)
+		}
+		got := rec.Header()
+		if v := got.Get("Access-Control-Max-Age"); v != "600" {
+			t.Errorf("preflight %d: got Access-Control-Max-Age %q, want 600", i, v)
+		}
+		if v := got.Get("Access-Control-Allow-Origin"); v != "https://app.example" {
+			t.Errorf("preflight %d: got Access-Control-Allow-Origin %q", i, v)
+		}
+		if v := got.Get("Access-Control-Allow-Credentials"); v != "true" {
+			t.Errorf("preflight %d: got Access-Control-Allow-Credentials %q, want true", i, v)
+		}
+		if !slices.Contains(got.Values("Vary"), "Origin") {
+			t.Errorf("preflight %d: got Vary %q, want Origin", i, got.Values("Vary"))
+		}
+	}
+
+	// A disallowed origin still varies on Origin, so a cache cannot replay
+	// its headers to an allowed one, or the other way round.
+	rec := corsRequest(h, http.MethodOptions, "https://evil.example")
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "" {
+		t.Errorf("got Access-Control-Allow-Origin %q for a disallowed origin", v)
+	}
+	if rec.Header().Get("Access-Control-Max-Age") != "" || !slices.Contains(rec.Header().Values("Vary"), "Origin") {
+		t.Errorf("got headers %v for a disallowed origin", rec.Header())
+	}
+
+	rec = corsRequest(h, http.MethodGet, "https://admin.example")
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://admin.example" {
+		t.Errorf("got status %d and origin %q on a simple request", rec.Code, rec.Header().Get("Access-Control-Allow-Origin"))
+	}
+}
+
+func TestCORSWildcardOmitsCredentials(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.cors = parseCORSOrigins("*")
+	h := newServer("0", cfg).Handler
+
+	rec := corsRequest(h, http.MethodOptions, "https://anywhere.example")
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "*" {
+		t.Errorf("got Access-Control-Allow-Origin %q, want *", v)
+	}
+	if v := rec.Header().Get("Access-Control-Allow-Credentials"); v != "" {
+		t.Errorf("got Access-Control-Allow-Credentials %q with a wildcard, want none", v)
+	}
+	if v := rec.Header().Get("Access-Control-Max-Age"); v != "600" {
+		t.Errorf("got Access-Control-Max-Age %q, want 600", v)
+	}
+}
+
+func TestCORSDisabled(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	rec := corsRequest(h, http.MethodGet, "https://app.example")
+	if v := rec.Header().Get("Access-Control-Allow-Origin"); v != "" {
+		t.Errorf("got Access-Control-Allow-Origin %q with CORS off", v)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "hourly", "* * * *", "60 * * * *", "* 24 * * *", "0 0 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCron(expr); err == nil {
+			t.Errorf("parseCron(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"weekly", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2026, 3, 4, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2026, 3, 5, 10, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17/4 * * 1-5", time.Date(2026, 3, 4, 13, 0, 0, 0, time.UTC)},
+		{"0 8 1,15 * *", time.Date(2026, 3, 15, 8, 0, 0, 0, time.UTC)},
+		{"0 0 1 1 *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Restricting both day fields fires on either: the 10th or a Friday.
+		{"0 0 10 * 5", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := parseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", tt.expr, err)
+		}
+		if got := s.next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: next(%v) = %v, want %v", tt.expr, from, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	dbErrorWindow = 60 * time.Second
+	maxRetryAfter = 30 * time.Second
+)
+
+type dbErrorKind int
+
+const (
+	dbErrorOther dbErrorKind = iota
+	dbErrorUnavailable
+	dbErrorTimeout
+)
+
+func classifyDBError(err error) dbErrorKind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return dbErrorTimeout
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "57014": // query_canceled, raised by statement_timeout
+			return dbErrorTimeout
+		case strings.HasPrefix(string(pqErr.Code), "08"), // connection_exception
+			strings.HasPrefix(string(pqErr.Code), "53"), // insufficient_resources
+			strings.HasPrefix(string(pqErr.Code), "57"): // operator_intervention
+			return dbErrorUnavailable
+		}
+		return dbErrorOther
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return dbErrorTimeout
+		}
+		return dbErrorUnavailable
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return dbErrorUnavailable
+	}
+	return dbErrorOther
+}
+
+// recordDBFailure bumps the consecutive failure count, starting over when the
+// previous failure is older than dbErrorWindow, and returns the Retry-After
+// delay: 1s doubling per failure, capped at maxRetryAfter.
+func (cfg *apiConfig) recordDBFailure() time.Duration {
+	now := time.Now().UnixNano()
+	if last := cfg.lastDBError.Swap(now); now-last > int64(dbErrorWindow) {
+		cfg.consecutiveDBErrors.Store(0)
+	}
+	n := cfg.consecutiveDBErrors.Add(1)
+	if n > 6 {
+		return maxRetryAfter
+	}
+	return min(time.Second<<(n-1), maxRetryAfter)
+}
+
+// respondWithDBError maps a failed query to a response: 503 when the database
+// is unreachable, 504 when it timed out, both with a Retry-After hint, and a
+// plain 500 for everything else.
+func (cfg *apiConfig) respondWithDBError(w http.ResponseWriter, err error) {
+	fmt.Println(err)
+	status := http.StatusInternalServerError
+	switch classifyDBError(err) {
+	case dbErrorUnavailable:
+		status = http.StatusServiceUnavailable
+	case dbErrorTimeout:
+		status = http.StatusGatewayTimeout
+	}
+	if status != http.StatusInternalServerError {
+		delay := cfg.recordDBFailure()
+		w.Header().Set("Retry-After", strconv.Itoa(int(delay/time.Second)))
+	}
+	w.WriteHeader(status)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Push forwards to the underlying writer, which a type assertion on the
+// wrapper would otherwise hide.
+func (r *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	if p, ok := r.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// flush a streamed response.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// middlewareDBErrors clears the consecutive database failure count once a
+// request completes without a server error.
+func (cfg *apiConfig) middlewareDBErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status < 500 && cfg.consecutiveDBErrors.Load() != 0 {
+			cfg.consecutiveDBErrors.Store(0)
+		}
+	})
+}
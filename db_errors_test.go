@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/lib/pq"
+)
+
+// failingStore fails GetChirps with err until err is cleared.
+type failingStore struct {
+	*memStore
+	err error
+}
+
+func (s *failingStore) GetChirps(ctx context.Context, arg database.GetChirpsParams) ([]database.GetChirpsRow, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.memStore.GetChirps(ctx, arg)
+}
+
+func TestDBErrorStatuses(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantRetry  string
+	}{
+		{"connection failure", &pq.Error{Code: "08006"}, http.StatusServiceUnavailable, "1"},
+		{"server shutting down", &pq.Error{Code: "57P01"}, http.StatusServiceUnavailable, "1"},
+		{"bad connection", driver.ErrBadConn, http.StatusServiceUnavailable, "1"},
+		{"context timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, "1"},
+		{"statement timeout", &pq.Error{Code: "57014"}, http.StatusGatewayTimeout, "1"},
+		{"constraint violation", &pq.Error{Code: "23505"}, http.StatusInternalServerError, ""},
+		{"generic error", errors.New("boom"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(newMemStore())
+			cfg.db = &failingStore{memStore: newMemStore(), err: tt.err}
+			rec := serve(newServer("0", cfg).Handler, "GET", "/api/chirps", "", "")
+			if rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("got Retry-After %q, want %q", got, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestRetryAfterBackoff(t *testing.T) {
+	store := &failingStore{memStore: newMemStore(), err: &pq.Error{Code: "08006"}}
+	cfg := newTestConfig(store.memStore)
+	cfg.db = store
+	handler := newServer("0", cfg).Handler
+	retryAfter := func() string {
+		return serve(handler, "GET", "/api/chirps", "", "").Header().Get("Retry-After")
+	}
+
+	for _, want := range []string{"1", "2", "4", "8", "16", "30", "30"} {
+		if got := retryAfter(); got != want {
+			t.Errorf("got Retry-After %q, want %q", got, want)
+		}
+	}
+
+	store.err = nil
+	if rec := serve(handler, "GET", "/api/chirps", "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	store.err = &pq.Error{Code: "08006"}
+	if got := retryAfter(); got != "1" {
+		t.Errorf("after a success got Retry-After %q, want 1", got)
+	}
+
+	// Failures older than the window no longer count.
+	retryAfter()
+	cfg.lastDBError.Store(time.Now().Add(-2 * dbErrorWindow).UnixNano())
+	if got := retryAfter(); got != "1" {
+		t.Errorf("after the window got Retry-After %q, want 1", got)
+	}
+}
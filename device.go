@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// DeviceInfo is what a request's User-Agent says about the client. Platform
+// is ios, android or web, matching the push platforms, or empty when the
+// client is a bot or cannot be placed.
+type DeviceInfo struct {
+	Platform string
+	Browser  string
+	IsBot    bool
+}
+
+type deviceInfoKey struct{}
+
+// uaBotMarkers are substrings, lowercased, that mark a crawler.
+var uaBotMarkers = []string{"bot", "crawl", "spider", "slurp", "facebookexternalhit"}
+
+// uaBrowsers maps User-Agent tokens to browsers, most specific first: Edge
+// and Opera also claim to be Chrome, and Chrome claims to be Safari.
+var uaBrowsers = []struct{ token, name string }{
+	{"edg/", "Edge"},
+	{"edgios/", "Edge"},
+	{"edga/", "Edge"},
+	{"opr/", "Opera"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"chrome/", "Chrome"},
+	{"crios/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+// parseUserAgent places a client from its User-Agent by plain substring
+// matching. It is meant for analytics, not for deciding what a client may
+// do: anyone can send any User-Agent.
+func parseUserAgent(ua string) DeviceInfo {
+	ua = strings.ToLower(ua)
+	var info DeviceInfo
+	for _, b := range uaBrowsers {
+		if strings.Contains(ua, b.token) {
+			info.Browser = b.name
+			break
+		}
+	}
+	for _, m := range uaBotMarkers {
+		if strings.Contains(ua, m) {
+			info.IsBot = true
+			return info
+		}
+	}
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"), strings.Contains(ua, "cfnetwork"):
+		info.Platform = "ios"
+	case strings.Contains(ua, "android"), strings.Contains(ua, "okhttp"):
+		info.Platform = "android"
+	case info.Browser != "":
+		info.Platform = "web"
+	}
+	return info
+}
+
+// deviceInfoFromContext returns the DeviceInfo middlewareDevice stored, or
+// the zero value outside it.
+func deviceInfoFromContext(ctx context.Context) DeviceInfo {
+	info, _ := ctx.Value(deviceInfoKey{}).(DeviceInfo)
+	return info
+}
+
+// middlewareDevice parses each request's User-Agent into its context.
+func middlewareDevice(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := parseUserAgent(r.UserAgent())
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), deviceInfoKey{}, info)))
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want DeviceInfo
+	}{
+		{"chrome on windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", DeviceInfo{Platform: "web", Browser: "Chrome"}},
+		{"edge on windows", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.2592.87", DeviceInfo{Platform: "web", Browser: "Edge"}},
+		{"firefox on linux", "Mozilla/5.0 (X11; Linux x86_64; rv:127.0) Gecko/20100101 Firefox/127.0", DeviceInfo{Platform: "web", Browser: "Firefox"}},
+		{"safari on macos", "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15", DeviceInfo{Platform: "web", Browser: "Safari"}},
+		{"safari on iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", DeviceInfo{Platform: "ios", Browser: "Safari"}},
+		{"chrome on android", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36", DeviceInfo{Platform: "android", Browser: "Chrome"}},
+		{"ios app", "Chirpy/2.3 CFNetwork/1496.0.7 Darwin/23.5.0", DeviceInfo{Platform: "ios"}},
+		{"android app", "okhttp/4.12.0", DeviceInfo{Platform: "android"}},
+		{"googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", DeviceInfo{IsBot: true}},
+		{"mobile googlebot", "Mozilla/5.0 (Linux; Android 6.0.1; Nexus 5X Build/MMB29P) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", DeviceInfo{Browser: "Chrome", IsBot: true}},
+		{"curl", "curl/8.7.1", DeviceInfo{}},
+		{"empty", "", DeviceInfo{}},
+	}
+	for _, tt := range tests {
+		if got := parseUserAgent(tt.ua); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccessLogIncludesDevicePlatform(t *testing.T) {
+	logs := captureLog(t)
+	cfg := newTestConfig(newMemStore())
+	cfg.platform = "prod"
+	h := newServer("0", cfg).Handler
+
+	req := httptest.NewRequest("POST", "/api/login", strings.NewReader(`{"email":"a@example.com"}`))
+	req.Header.Set("User-Agent", "okhttp/4.12.0")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if want := fmt.Sprintf("POST /api/login %d device_platform=android", rec.Code); !strings.Contains(logs.String(), want) {
+		t.Errorf("access log missing %q:\n%s", want, logs.String())
+	}
+}
+
+func TestRegisterPushTokenPlatformFromUserAgent(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+
+	register := func(ua, body string) int {
+		req := httptest.NewRequest("POST", "/api/users/me/push-tokens", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", ua)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if got := register("Chirpy/2.3 CFNetwork/1496.0.7 Darwin/23.5.0", `{"device_token":"phone"}`); got != http.StatusCreated {
+		t.Fatalf("got status %d, want 201", got)
+	}
+	// An explicit platform wins over the User-Agent.
+	if got := register("okhttp/4.12.0", `{"device_token":"laptop","platform":"web"}`); got != http.StatusCreated {
+		t.Fatalf("got status %d, want 201", got)
+	}
+	if got := register("curl/8.7.1", `{"device_token":"unknown"}`); got != http.StatusBadRequest {
+		t.Errorf("unknown platform: got status %d, want 400", got)
+	}
+
+	tokens, _ := store.GetPushTokens(t.Context(), alice.ID)
+	platforms := map[string]string{}
+	for _, tok := range tokens {
+		platforms[tok.DeviceToken] = string(tok.Platform)
+	}
+	if platforms["phone"] != "ios" || platforms["laptop"] != "web" || len(platforms) != 2 {
+		t.Errorf("got platforms %v, want phone on ios and laptop on web", platforms)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+const (
+	defaultDigestLookback = 24 * time.Hour
+	digestSubject         = "Your Chirpy digest"
+)
+
+// digestNouns names each notification type in a digest line.
+var digestNouns = map[database.NotificationType][2]string{
+	database.NotificationTypeLike:    {"like", "likes"},
+	database.NotificationTypeReply:   {"reply", "replies"},
+	database.NotificationTypeMention: {"mention", "mentions"},
+	database.NotificationTypeFollow:  {"new follower", "new followers"},
+	database.NotificationTypeRepost:  {"repost", "reposts"},
+}
+
+// digestBody summarises a user's unread notifications, one line per type.
+func digestBody(rows []database.GetDigestNotificationCountsRow) string {
+	var total int64
+	var lines strings.Builder
+	for _, row := range rows {
+		total += row.Count
+		noun := digestNouns[row.Type]
+		if row.Count == 1 {
+			fmt.Fprintf(&lines, "- 1 %s\n", noun[0])
+		} else {
+			fmt.Fprintf(&lines, "- %d %s\n", row.Count, noun[1])
+		}
+	}
+	plural := "s"
+	if total == 1 {
+		plural = ""
+	}
+	return fmt.Sprintf("You have %d unread notification%s on Chirpy:\n\n%s", total, plural, lines.String())
+}
+
+// sendDigests emails every user who has unread notifications created since
+// since and has email notifications turned on. A failed send is logged and
+// skipped so one bad address does not hold up the rest. It returns how many
+// digests were sent.
+func (cfg *apiConfig) sendDigests(ctx context.Context, since time.Time) (int, error) {
+	rows, err := cfg.db.GetDigestNotificationCounts(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	// Rows arrive ordered by user, so each run of one user_id is a digest.
+	for start := 0; start < len(rows); {
+		end := start
+		for end < len(rows) && rows[end].UserID == rows[start].UserID {
+			end++
+		}
+		to := rows[start].Email.String
+		if err := cfg.mailer.Send(to, digestSubject, digestBody(rows[start:end])); err != nil {
+			log.Printf("Error sending digest to user %s: %s", rows[start].UserID, err)
+		} else {
+			sent++
+		}
+		start = end
+	}
+	return sent, nil
+}
+
+// runDigestJob sends digests each time schedule fires, covering
+// notifications from the preceding lookback, until ctx is cancelled.
+// Schedules are evaluated in UTC.
+func (cfg *apiConfig) runDigestJob(ctx context.Context, schedule *cronSchedule, lookback time.Duration) {
+	for {
+		now := cfg.timeNow().UTC()
+		next := schedule.next(now)
+		if next.IsZero() {
+			log.Printf("Digest schedule never fires; stopping digest job")
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		n, err := cfg.sendDigests(ctx, next.Add(-lookback))
+		if err != nil {
+			log.Printf("Error sending digests: %s", err)
+			continue
+		}
+		log.Printf("Sent %d digest emails", n)
+	}
+}
+
+type setEmailNotificationsParams struct {
+	Enabled bool `json:"enabled"`
+}
+
+type notificationSettingsResp struct {
+	EmailNotifications bool `json:"email_notifications"`
+}
+
+// handlerSetEmailNotifications turns digest emails on or off for the caller.
+func (cfg *apiConfig) handlerSetEmailNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	params := setEmailNotificationsParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "enabled must be true or false")
+		return
+	}
+	user, err := cfg.db.SetUserEmailNotifications(r.Context(), database.SetUserEmailNotificationsParams{
+		ID:                 userId,
+		EmailNotifications: params.Enabled,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.evictUser(user)
+	cfg.audit(withActor(r.Context(), userId), "user.email_notifications_updated", "user", user.ID, map[string]bool{
+		"enabled": user.EmailNotifications,
+	})
+	respondWithJSON(w, http.StatusOK, notificationSettingsResp{EmailNotifications: user.EmailNotifications})
+}
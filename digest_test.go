@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendDigests(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	mailer := &MockMailer{}
+	cfg.mailer = mailer
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	_, carolToken := seedUser(t, cfg, store, "carol@example.com")
+
+	chirp := postChirp(t, h, `{"body":"hello"}`, aliceToken)
+	serve(h, "POST", "/api/chirps/"+chirp.ID.String()+"/like", "", bobToken)
+	serve(h, "POST", "/api/chirps/"+chirp.ID.String()+"/like", "", carolToken)
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	bobChirp := postChirp(t, h, `{"body":"hi"}`, bobToken)
+	serve(h, "POST", "/api/chirps/"+bobChirp.ID.String()+"/like", "", aliceToken)
+	runJobs(t, cfg)
+
+	sent, err := cfg.sendDigests(t.Context(), time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 2 || len(mailer.Sent) != 2 {
+		t.Fatalf("sent %d digests with %d mailer calls, want 2", sent, len(mailer.Sent))
+	}
+	var toAlice sentMail
+	for _, m := range mailer.Sent {
+		if m.To == "alice@example.com" {
+			toAlice = m
+		}
+	}
+	if toAlice.Subject != digestSubject || !strings.Contains(toAlice.Body, "3 unread notifications") ||
+		!strings.Contains(toAlice.Body, "- 2 likes\n") || !strings.Contains(toAlice.Body, "- 1 new follower\n") {
+		t.Errorf("got digest %+v", toAlice)
+	}
+
+	// Read notifications and ones older than the window are left out.
+	mailer.Sent = nil
+	serve(h, "POST", "/api/notifications/read-all", "", aliceToken)
+	if sent, _ := cfg.sendDigests(t.Context(), time.Now().Add(-time.Hour)); sent != 1 {
+		t.Errorf("sent %d digests after alice read hers, want 1", sent)
+	}
+	if sent, _ := cfg.sendDigests(t.Context(), time.Now().Add(time.Hour)); sent != 0 {
+		t.Errorf("sent %d digests with nothing in the window, want 0", sent)
+	}
+}
+
+func TestDigestsRespectEmailNotifications(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	mailer := &MockMailer{}
+	cfg.mailer = mailer
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	runJobs(t, cfg)
+
+	rec := serve(h, "PUT", "/api/users/me/email-notifications", `{"enabled":false}`, aliceToken)
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"email_notifications":false`) {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if sent, _ := cfg.sendDigests(t.Context(), time.Now().Add(-time.Hour)); sent != 0 || len(mailer.Sent) != 0 {
+		t.Errorf("sent %d digests to a user who turned them off, want 0", sent)
+	}
+
+	serve(h, "PUT", "/api/users/me/email-notifications", `{"enabled":true}`, aliceToken)
+	if sent, _ := cfg.sendDigests(t.Context(), time.Now().Add(-time.Hour)); sent != 1 {
+		t.Errorf("sent %d digests after turning them back on, want 1", sent)
+	}
+	if rec := serve(h, "PUT", "/api/users/me/email-notifications", `{"enabled":true}`, ""); rec.Code != 401 {
+		t.Errorf("got status %d without a token, want 401", rec.Code)
+	}
+}
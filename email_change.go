@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// emailChangeTTL is how long the new address has to confirm a change.
+const emailChangeTTL = 24 * time.Hour
+
+type confirmEmailChangeParams struct {
+	Token string `json:"token"`
+}
+
+// emailTaken reports whether another user in user's namespace already
+// signs in with email.
+func (cfg *apiConfig) emailTaken(ctx context.Context, email string, user database.User) (bool, error) {
+	other, err := cfg.db.GetUserByEmail(ctx, database.GetUserByEmailParams{
+		Email:     sql.NullString{String: email, Valid: true},
+		Namespace: user.Namespace,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return other.ID != user.ID, nil
+}
+
+// startEmailChange records email as user's pending address and mails it a
+// confirmation token, telling the current address about the request. Mail
+// that cannot be sent is logged; the token still works.
+func (cfg *apiConfig) startEmailChange(ctx context.Context, user database.User, email string) (database.User, error) {
+	token := auth.MakeRefreshToken()
+	user, err := cfg.db.SetPendingEmail(ctx, database.SetPendingEmailParams{
+		ID:                    user.ID,
+		PendingEmail:          sql.NullString{String: email, Valid: true},
+		PendingEmailToken:     sql.NullString{String: token, Valid: true},
+		PendingEmailExpiresAt: sql.NullTime{Time: cfg.timeNow().Add(emailChangeTTL).UTC(), Valid: true},
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	if err := cfg.mailer.Send(email, "Confirm your new Chirpy email",
+		"Confirm this address for your Chirpy account by sending this token to POST /api/auth/confirm-email-change within 24 hours:\n\n"+token+"\n"); err != nil {
+		log.Printf("Error mailing email change confirmation to user %s: %s", user.ID, err)
+	}
+	if user.Email.Valid {
+		if err := cfg.mailer.Send(user.Email.String, "Your Chirpy email is changing",
+			"Someone asked to change your Chirpy account's email to "+email+". It stays "+user.Email.String+" until the new address confirms. If this wasn't you, change your password.\n"); err != nil {
+			log.Printf("Error notifying user %s of an email change: %s", user.ID, err)
+		}
+	}
+	return user, nil
+}
+
+// handlerConfirmEmailChange makes a pending email the user's address once
+// the token mailed to it comes back.
+func (cfg *apiConfig) handlerConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var params confirmEmailChangeParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	token := sql.NullString{String: params.Token, Valid: true}
+	user, err := cfg.db.GetUserByPendingEmailToken(r.Context(), token)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !cfg.timeNow().Before(user.PendingEmailExpiresAt.Time) {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	taken, err := cfg.emailTaken(r.Context(), user.PendingEmail.String, user)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if taken {
+		respondWithError(w, http.StatusConflict, "email already in use")
+		return
+	}
+	user, err = cfg.db.ConfirmPendingEmail(r.Context(), database.ConfirmPendingEmailParams{ID: user.ID, PendingEmailToken: token})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another confirmation or change got there first.
+		respondWithError(w, http.StatusBadRequest, "invalid or expired token")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.evictUser(user)
+	cfg.audit(withActor(r.Context(), user.ID), "user.email_changed", "user", user.ID, nil)
+	respondWithJSON(w, http.StatusOK, newUserResp(user))
+}
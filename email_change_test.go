@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newEmailChangeTest signs up old@example.com with password pw and returns
+// its access token.
+func newEmailChangeTest(t *testing.T) (http.Handler, *apiConfig, *MockMailer, *fakeClock, string) {
+	t.Helper()
+	cfg := newTestConfig(newMemStore())
+	mailer := &MockMailer{}
+	cfg.mailer = mailer
+	clock := &fakeClock{t: time.Now()}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	if rec := serve(h, "POST", "/api/users", `{"email":"old@example.com","password":"pw"}`, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("sign up: got status %d", rec.Code)
+	}
+	return h, cfg, mailer, clock, loginAs(t, h, "old@example.com")
+}
+
+func loginAs(t *testing.T, h http.Handler, email string) string {
+	t.Helper()
+	rec := serve(h, "POST", "/api/login", `{"email":"`+email+`","password":"pw"}`, "")
+	if rec.Code != http.StatusOK {
+		return ""
+	}
+	var resp userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Token
+}
+
+// confirmationToken is the token in the last mail sent to email.
+func confirmationToken(t *testing.T, mailer *MockMailer, email string) string {
+	t.Helper()
+	for i := len(mailer.Sent) - 1; i >= 0; i-- {
+		if m := mailer.Sent[i]; m.To == email {
+			lines := strings.Split(strings.TrimSpace(m.Body), "\n")
+			return lines[len(lines)-1]
+		}
+	}
+	t.Fatalf("no mail sent to %s", email)
+	return ""
+}
+
+func TestEmailChangeNeedsConfirmation(t *testing.T) {
+	h, _, mailer, _, token := newEmailChangeTest(t)
+
+	rec := serve(h, "PUT", "/api/users", `{"email":"new@example.com","password":"pw"}`, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: got status %d", rec.Code)
+	}
+	var updated userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Email != "old@example.com" || updated.PendingEmail != "new@example.com" {
+		t.Errorf("got email %q pending %q, want old@example.com pending new@example.com", updated.Email, updated.PendingEmail)
+	}
+	notified := false
+	for _, m := range mailer.Sent {
+		notified = notified || (m.To == "old@example.com" && strings.Contains(m.Body, "new@example.com"))
+	}
+	if !notified {
+		t.Error("the old address was not told about the change")
+	}
+	if loginAs(t, h, "old@example.com") == "" {
+		t.Error("login with the old email failed before confirmation")
+	}
+	if loginAs(t, h, "new@example.com") != "" {
+		t.Error("login with the new email worked before confirmation")
+	}
+
+	confirm := confirmationToken(t, mailer, "new@example.com")
+	rec = serve(h, "POST", "/api/auth/confirm-email-change", `{"token":"`+confirm+`"}`, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"email":"new@example.com"`) {
+		t.Fatalf("confirm: got %d %s", rec.Code, rec.Body.String())
+	}
+	if loginAs(t, h, "new@example.com") == "" {
+		t.Error("login with the new email failed after confirmation")
+	}
+	if loginAs(t, h, "old@example.com") != "" {
+		t.Error("login with the old email worked after confirmation")
+	}
+	if rec := serve(h, "POST", "/api/auth/confirm-email-change", `{"token":"`+confirm+`"}`, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("reused token: got status %d, want 400", rec.Code)
+	}
+}
+
+func TestEmailChangeTokenExpires(t *testing.T) {
+	h, _, mailer, clock, token := newEmailChangeTest(t)
+	serve(h, "PUT", "/api/users", `{"email":"new@example.com","password":"pw"}`, token)
+	confirm := confirmationToken(t, mailer, "new@example.com")
+
+	clock.t = clock.t.Add(emailChangeTTL)
+	rec := serve(h, "POST", "/api/auth/confirm-email-change", `{"token":"`+confirm+`"}`, "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expired token: got status %d, want 400", rec.Code)
+	}
+	if loginAs(t, h, "old@example.com") == "" {
+		t.Error("an expired confirmation changed the email")
+	}
+	if rec := serve(h, "POST", "/api/auth/confirm-email-change", `{"token":"nope"}`, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown token: got status %d, want 400", rec.Code)
+	}
+}
+
+func TestEmailChangeToTakenAddress(t *testing.T) {
+	h, _, _, _, token := newEmailChangeTest(t)
+	serve(h, "POST", "/api/users", `{"email":"taken@example.com","password":"pw"}`, "")
+	if rec := serve(h, "PUT", "/api/users", `{"email":"taken@example.com","password":"pw"}`, token); rec.Code != http.StatusConflict {
+		t.Errorf("got status %d, want 409", rec.Code)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+)
+
+type mfaChallenge struct {
+	MfaSessionToken string `json:"mfa_session_token"`
+	Otp             string `json:"otp"`
+}
+
+const mfaLoginBody = `{"email":"a@example.com","password":"secret"}`
+
+// newEmailMFATest registers a user with email MFA enabled and returns a
+// handler whose clock the test controls.
+func newEmailMFATest(t *testing.T) (http.Handler, *memStore, *fakeClock) {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Now()}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	if rec := serve(h, "POST", "/api/users", mfaLoginBody, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("create user: got status %d", rec.Code)
+	}
+	token, err := auth.MakeJWT(store.users[0].ID, cfg.tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(h, "PUT", "/api/users/me/email-mfa", `{"enabled":true}`, token); rec.Code != http.StatusOK {
+		t.Fatalf("enable email MFA: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	return h, store, clock
+}
+
+func startMFALogin(t *testing.T, h http.Handler) mfaChallenge {
+	t.Helper()
+	rec := serve(h, "POST", "/api/login", mfaLoginBody, "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("login: got status %d, want 202", rec.Code)
+	}
+	var c mfaChallenge
+	if err := json.Unmarshal(rec.Body.Bytes(), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.MfaSessionToken == "" || len(c.Otp) != 6 {
+		t.Fatalf("got challenge %+v, want a session token and a six-digit otp", c)
+	}
+	return c
+}
+
+func verifyOTP(h http.Handler, c mfaChallenge, otp string) int {
+	body, _ := json.Marshal(map[string]string{"session_token": c.MfaSessionToken, "otp": otp})
+	return serve(h, "POST", "/api/auth/email-otp", string(body), "").Code
+}
+
+func TestEmailOTPLogin(t *testing.T) {
+	h, store, _ := newEmailMFATest(t)
+	c := startMFALogin(t, h)
+	if store.otpSessions[0].OtpHash == c.Otp {
+		t.Errorf("stored the otp in plain text")
+	}
+
+	wrong := "000000"
+	if c.Otp == wrong {
+		wrong = "111111"
+	}
+	if code := verifyOTP(h, c, wrong); code != http.StatusUnauthorized {
+		t.Errorf("wrong otp: got status %d, want 401", code)
+	}
+	if code := verifyOTP(h, mfaChallenge{MfaSessionToken: "nope"}, c.Otp); code != http.StatusUnauthorized {
+		t.Errorf("unknown session: got status %d, want 401", code)
+	}
+
+	body, _ := json.Marshal(map[string]string{"session_token": c.MfaSessionToken, "otp": c.Otp})
+	rec := serve(h, "POST", "/api/auth/email-otp", string(body), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("correct otp: got status %d, want 200", rec.Code)
+	}
+	var login userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &login); err != nil {
+		t.Fatal(err)
+	}
+	if login.Token == "" || login.RefreshToken == "" {
+		t.Errorf("got login %s, want tokens", rec.Body.String())
+	}
+
+	if code := verifyOTP(h, c, c.Otp); code != http.StatusUnauthorized {
+		t.Errorf("reused otp: got status %d, want 401", code)
+	}
+}
+
+func TestEmailOTPExpired(t *testing.T) {
+	h, _, clock := newEmailMFATest(t)
+	c := startMFALogin(t, h)
+	clock.t = clock.t.Add(emailOTPTTL)
+	if code := verifyOTP(h, c, c.Otp); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401 after the otp expired", code)
+	}
+}
+
+func TestEmailOTPTooManyAttempts(t *testing.T) {
+	h, store, _ := newEmailMFATest(t)
+	c := startMFALogin(t, h)
+	store.otpSessions[0].FailedAttempts = maxEmailOTPAttempts
+	if code := verifyOTP(h, c, c.Otp); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401 once attempts are used up", code)
+	}
+}
+
+func TestLoginWithoutEmailMFA(t *testing.T) {
+	store := newMemStore()
+	h := newServer("0", newTestConfig(store)).Handler
+	serve(h, "POST", "/api/users", mfaLoginBody, "")
+	if rec := serve(h, "POST", "/api/login", mfaLoginBody, ""); rec.Code != http.StatusOK {
+		t.Errorf("got status %d, want 200 without email MFA", rec.Code)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// embeddingDimensions matches the vector(384) column in chirp_embeddings.
+const embeddingDimensions = 384
+
+var errEmbeddingNotConfigured = errors.New("embeddings are not configured")
+
+// Embedder turns text into a vector for similarity search.
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// NoopEmbedder is used when no EMBEDDING_PROVIDER is configured.
+type NoopEmbedder struct{}
+
+func (NoopEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, errEmbeddingNotConfigured
+}
+
+// mockEmbeddingModel is the model_version recorded for MockEmbedder.
+const mockEmbeddingModel = "mock-bag-of-words"
+
+// MockEmbedder hashes each word of the text into one of
+// embeddingDimensions buckets and normalises the counts, for tests and
+// local development. Texts sharing words come out similar. Calls counts
+// how many embeddings it has computed.
+type MockEmbedder struct {
+	Calls int
+}
+
+func (m *MockEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	m.Calls++
+	vec := make([]float32, embeddingDimensions)
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		h := fnv.New32a()
+		h.Write([]byte(strings.Trim(word, ".,!?;:\"'")))
+		vec[h.Sum32()%embeddingDimensions]++
+	}
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v * v)
+	}
+	if norm == 0 {
+		return vec, nil
+	}
+	for i := range vec {
+		vec[i] /= float32(math.Sqrt(norm))
+	}
+	return vec, nil
+}
+
+// newEmbedder returns the embedder for provider along with the
+// model_version its embeddings are stored under.
+func newEmbedder(provider string) (Embedder, string) {
+	if provider == "mock" {
+		return &MockEmbedder{}, mockEmbeddingModel
+	}
+	return NoopEmbedder{}, ""
+}
+
+// formatVector renders vec in pgvector's text form, [1,2,3].
+func formatVector(vec []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vec {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// eventDrainTimeout bounds how long shutdown waits for in-flight requests
+// and the job in hand on each worker.
+const eventDrainTimeout = 5 * time.Second
+
+const eventChirpCreated = "chirp.created"
+
+// chirpCreatedPayload is queued once a chirp is stored. ParentAuthorID is
+// uuid.Nil unless the chirp is a reply.
+type chirpCreatedPayload struct {
+	Chirp          database.Chirp
+	ParentAuthorID uuid.UUID
+}
+
+func (chirpCreatedPayload) JobType() string { return eventChirpCreated }
+
+// registerJobHandlers wires the application's side effects to their jobs.
+func (cfg *apiConfig) registerJobHandlers() {
+	cfg.RegisterJobHandler(eventChirpCreated, jobHandlerFor(cfg.handleChirpCreated))
+	cfg.RegisterJobHandler(eventNotificationCreated, jobHandlerFor(cfg.pushNotification))
+}
+
+// handleChirpCreated runs the side effects of a new chirp. Failures are
+// logged rather than retried, since a retry would repeat the notifications
+// already sent.
+func (cfg *apiConfig) handleChirpCreated(ctx context.Context, p chirpCreatedPayload) error {
+	cfg.notifyChirpCreated(ctx, p)
+	if cfg.webhooks != nil {
+		cfg.deliverChirpCreated(ctx, p)
+	}
+	return nil
+}
+
+// notifyChirpCreated sends the reply and mention notifications for a new
+// chirp.
+func (cfg *apiConfig) notifyChirpCreated(ctx context.Context, p chirpCreatedPayload) {
+	if p.ParentAuthorID != uuid.Nil {
+		cfg.notify(ctx, p.ParentAuthorID, p.Chirp.UserID, database.NotificationTypeReply, p.Chirp.ID)
+	}
+	cfg.notifyMentions(withNamespace(ctx, database.Namespace{Name: p.Chirp.Namespace}), p.Chirp.UserID, p.Chirp.ID, p.Chirp.Body.String)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Experiment is an A/B test read from EXPERIMENTS_PATH. When
+// BucketByUserID is false the experiment is switched off and everyone gets
+// the first variant.
+type Experiment struct {
+	Name           string   `json:"name"`
+	Variants       []string `json:"variants"`
+	BucketByUserID bool     `json:"bucket_by_user_id"`
+}
+
+// experimentRegistry holds the experiments loaded from a JSON file along
+// with how many assignments each variant has handed out since the last
+// load.
+type experimentRegistry struct {
+	path string
+
+	mu          sync.RWMutex
+	experiments []Experiment
+	assignments map[string]map[string]int64
+}
+
+func newExperimentRegistry(path string) *experimentRegistry {
+	return &experimentRegistry{path: path}
+}
+
+// load replaces the experiments with the contents of the file, keeping the
+// old ones if it cannot be read. An empty path means no experiments.
+func (e *experimentRegistry) load() error {
+	var experiments []Experiment
+	if e.path != "" {
+		data, err := os.ReadFile(e.path)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &experiments); err != nil {
+			return fmt.Errorf("parsing %s: %w", e.path, err)
+		}
+	}
+	seen := map[string]bool{}
+	for _, exp := range experiments {
+		if exp.Name == "" || seen[exp.Name] {
+			return fmt.Errorf("experiment names must be unique and non-empty, got %q", exp.Name)
+		}
+		if len(exp.Variants) == 0 {
+			return fmt.Errorf("experiment %q has no variants", exp.Name)
+		}
+		seen[exp.Name] = true
+	}
+	e.mu.Lock()
+	e.experiments = experiments
+	e.assignments = map[string]map[string]int64{}
+	e.mu.Unlock()
+	return nil
+}
+
+// GetVariant returns the variant of experimentName userID is in, or "" if
+// there is no such experiment. A user stays in the same variant for as long
+// as the experiment's name and variants are unchanged.
+func (e *experimentRegistry) GetVariant(userID uuid.UUID, experimentName string) string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, exp := range e.experiments {
+		if exp.Name == experimentName {
+			return e.assign(exp, userID)
+		}
+	}
+	return ""
+}
+
+// variants assigns userID to every experiment, keyed by name.
+func (e *experimentRegistry) variants(userID uuid.UUID) map[string]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.experiments) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(e.experiments))
+	for _, exp := range e.experiments {
+		out[exp.Name] = e.assign(exp, userID)
+	}
+	return out
+}
+
+// assign buckets userID into exp and records the assignment. e.mu must be
+// held.
+func (e *experimentRegistry) assign(exp Experiment, userID uuid.UUID) string {
+	variant := exp.Variants[0]
+	if exp.BucketByUserID {
+		variant = exp.Variants[crc32.ChecksumIEEE([]byte(userID.String()+exp.Name))%uint32(len(exp.Variants))]
+	}
+	if e.assignments[exp.Name] == nil {
+		e.assignments[exp.Name] = map[string]int64{}
+	}
+	e.assignments[exp.Name][variant]++
+	log.Printf("experiment %s: user %s assigned %s", exp.Name, userID, variant)
+	return variant
+}
+
+// runExperimentReloader reloads the experiments each time a signal arrives
+// on sig, which main wires to SIGHUP, until ctx is cancelled.
+func (e *experimentRegistry) runExperimentReloader(ctx context.Context, sig <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sig:
+			if err := e.load(); err != nil {
+				log.Printf("Error reloading experiments: %s", err)
+				continue
+			}
+			log.Printf("Reloaded experiments from %s", e.path)
+		}
+	}
+}
+
+type experimentResp struct {
+	Experiment
+	// Assignments counts the times each variant has been handed out since
+	// the experiments were last loaded.
+	Assignments map[string]int64 `json:"assignments"`
+}
+
+func (cfg *apiConfig) handlerGetExperiments(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	e := cfg.experiments
+	e.mu.RLock()
+	resp := make([]experimentResp, 0, len(e.experiments))
+	for _, exp := range e.experiments {
+		counts := make(map[string]int64, len(exp.Variants))
+		for _, v := range exp.Variants {
+			counts[v] = e.assignments[exp.Name][v]
+		}
+		resp = append(resp, experimentResp{Experiment: exp, Assignments: counts})
+	}
+	e.mu.RUnlock()
+	respondWithJSON(w, http.StatusOK, resp)
+}
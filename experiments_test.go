@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func writeExperiments(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExperimentBucketing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	writeExperiments(t, path, `[
+		{"name":"new_feed_algo","variants":["control","ranked"],"bucket_by_user_id":true},
+		{"name":"dark_mode","variants":["off","on"]}
+	]`)
+	e := newExperimentRegistry(path)
+	if err := e.load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	seen := map[string]int{}
+	for range 200 {
+		user := uuid.New()
+		first := e.GetVariant(user, "new_feed_algo")
+		for range 3 {
+			if got := e.GetVariant(user, "new_feed_algo"); got != first {
+				t.Fatalf("user %s got %q then %q", user, first, got)
+			}
+		}
+		seen[first]++
+		if got := e.GetVariant(user, "dark_mode"); got != "off" {
+			t.Errorf("got %q for an experiment not bucketed by user, want the first variant", got)
+		}
+	}
+	if seen["control"] == 0 || seen["ranked"] == 0 {
+		t.Errorf("got distribution %v, want both variants used", seen)
+	}
+	if got := e.GetVariant(uuid.New(), "missing"); got != "" {
+		t.Errorf("got %q for an unknown experiment, want none", got)
+	}
+}
+
+func TestExperimentHotReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	writeExperiments(t, path, `[{"name":"new_feed_algo","variants":["control"],"bucket_by_user_id":true}]`)
+	e := newExperimentRegistry(path)
+	if err := e.load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	user := uuid.New()
+	if got := e.GetVariant(user, "new_feed_algo"); got != "control" {
+		t.Fatalf("got %q, want control", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sig := make(chan os.Signal)
+	done := make(chan struct{})
+	go func() {
+		e.runExperimentReloader(ctx, sig)
+		close(done)
+	}()
+	reload := func() {
+		sig <- syscall.SIGHUP
+		// A second signal is only received once the first reload is done.
+		sig <- syscall.SIGHUP
+	}
+
+	writeExperiments(t, path, `[{"name":"new_feed_algo","variants":["treatment"],"bucket_by_user_id":true}]`)
+	reload()
+	if got := e.GetVariant(user, "new_feed_algo"); got != "treatment" {
+		t.Errorf("got %q after reloading, want treatment", got)
+	}
+	writeExperiments(t, path, `not json`)
+	reload()
+	if got := e.GetVariant(user, "new_feed_algo"); got != "treatment" {
+		t.Errorf("got %q after a bad reload, want the previous experiments kept", got)
+	}
+	cancel()
+	<-done
+}
+
+func TestGetMeExperiments(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	path := filepath.Join(t.TempDir(), "experiments.json")
+	writeExperiments(t, path, `[{"name":"new_feed_algo","variants":["control","ranked"],"bucket_by_user_id":true}]`)
+	cfg.experiments = newExperimentRegistry(path)
+	if err := cfg.experiments.load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+
+	if rec := serve(h, "GET", "/api/users/me", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d without a token, want 401", rec.Code)
+	}
+	rec := serve(h, "GET", "/api/users/me", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var me userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &me); err != nil {
+		t.Fatal(err)
+	}
+	want := cfg.experiments.GetVariant(alice.ID, "new_feed_algo")
+	if me.ID != alice.ID || me.Experiments["new_feed_algo"] != want {
+		t.Errorf("got %s in %v, want alice in %q", me.ID, me.Experiments, want)
+	}
+
+	rec = serve(h, "GET", "/admin/experiments", "", adminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var exps []experimentResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &exps); err != nil {
+		t.Fatal(err)
+	}
+	if len(exps) != 1 || exps[0].Assignments[want] != 2 {
+		t.Errorf("got %+v, want the 2 assignments to %q counted", exps, want)
+	}
+	if rec := serve(h, "GET", "/admin/experiments", "", token); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d for a non-admin, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const feedCacheTTL = 5 * time.Second
+
+// feedCacheKey identifies one page of a user's home feed. page holds the
+// cursor and limit, normalised so equivalent queries share an entry.
+type feedCacheKey struct {
+	userID uuid.UUID
+	page   string
+}
+
+type feedCacheEntry struct {
+	body []byte
+	// chirpIDs are the chirps on the page, so a cache hit still counts
+	// their impressions.
+	chirpIDs  []uuid.UUID
+	expiresAt time.Time
+}
+
+func newFeedCacheKey(userID uuid.UUID, r *http.Request) feedCacheKey {
+	q := r.URL.Query()
+	page := url.Values{"cursor": {q.Get("cursor")}, "limit": {q.Get("limit")}, "namespace": {namespaceOf(r.Context())}}
+	return feedCacheKey{userID: userID, page: page.Encode()}
+}
+
+// cachedFeed returns the page stored under key unless the entry has
+// expired or the client sent Cache-Control: no-cache.
+func (cfg *apiConfig) cachedFeed(r *http.Request, key feedCacheKey) (*feedCacheEntry, bool) {
+	if r.Header.Get("Cache-Control") == "no-cache" {
+		return nil, false
+	}
+	v, ok := cfg.feedCache.Load(key)
+	if !ok {
+		return nil, false
+	}
+	entry := v.(*feedCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		cfg.feedCache.CompareAndDelete(key, v)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (cfg *apiConfig) storeFeed(key feedCacheKey, body []byte, chirpIDs []uuid.UUID) {
+	cfg.feedCache.Store(key, &feedCacheEntry{body: body, chirpIDs: chirpIDs, expiresAt: time.Now().Add(feedCacheTTL)})
+}
+
+// invalidateFeeds drops every cached page belonging to userIDs. Expired
+// entries for other users are swept along the way, since nothing else
+// removes pages that are never requested again.
+func (cfg *apiConfig) invalidateFeeds(userIDs ...uuid.UUID) {
+	drop := make(map[uuid.UUID]bool, len(userIDs))
+	for _, id := range userIDs {
+		drop[id] = true
+	}
+	now := time.Now()
+	cfg.feedCache.Range(func(k, v any) bool {
+		if drop[k.(feedCacheKey).userID] || !now.Before(v.(*feedCacheEntry).expiresAt) {
+			cfg.feedCache.Delete(k)
+		}
+		return true
+	})
+}
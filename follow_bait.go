@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// followBaitPatterns are checked in order; the first match names the flag.
+var followBaitPatterns = []struct {
+	re     *regexp.Regexp
+	reason string
+}{
+	{regexp.MustCompile(`(?i)\bfollow\s*(me|back)\b`), "asks for follows"},
+	{regexp.MustCompile(`(?i)\b(follow\s*(4|for)\s*follow|f4f)\b`), "follow-for-follow"},
+	{regexp.MustCompile(`(?i)\b(rt|retweet|repost)\s+(for|if)\b`), "asks for reposts"},
+	{regexp.MustCompile(`(?i)\b(like\s*(4|for)\s*like|l4l)\b`), "like-for-like"},
+}
+
+// detectFollowBait returns why body looks like follow-bait, or "" when it
+// does not. Flagged chirps are still posted; the flag is only shown to the
+// author and to admins.
+func detectFollowBait(body string) string {
+	for _, p := range followBaitPatterns {
+		if p.re.MatchString(body) {
+			return p.reason
+		}
+	}
+	return ""
+}
+
+// flaggedFor reports whether viewer should see c as flagged, which only its
+// author does.
+func flaggedFor(c database.Chirp, viewer uuid.UUID) bool {
+	return c.FlaggedReason.Valid && c.UserID == viewer
+}
+
+type flaggedChirpResp struct {
+	chirpResp
+	FlaggedReason string `json:"flagged_reason"`
+}
+
+func (cfg *apiConfig) handlerGetFlaggedChirps(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	chirps, err := cfg.db.GetFlaggedChirps(r.Context())
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]flaggedChirpResp, 0, len(chirps))
+	for _, c := range chirps {
+		cr := newChirpResp(c)
+		cr.Flagged = true
+		resp = append(resp, flaggedChirpResp{chirpResp: cr, FlaggedReason: c.FlaggedReason.String})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestDetectFollowBait(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{"just had a great lunch", ""},
+		{"Follow me for more!", "asks for follows"},
+		{"I always FOLLOW BACK", "asks for follows"},
+		{"follow4follow anyone?", "follow-for-follow"},
+		{"#f4f", "follow-for-follow"},
+		{"RT for a shoutout", "asks for reposts"},
+		{"retweet if you agree", "asks for reposts"},
+		{"like for like", "like-for-like"},
+		{"I follow the news closely", ""},
+		{"the art for sale", ""},
+		{"unfollow meetings are the worst", ""},
+	}
+	for _, tt := range tests {
+		if got := detectFollowBait(tt.body); got != tt.want {
+			t.Errorf("detectFollowBait(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestFlaggedChirpVisibleOnlyToAuthor(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, author := seedUser(t, cfg, store, "author@example.com")
+	_, other := seedUser(t, cfg, store, "other@example.com")
+
+	created := postChirp(t, h, `{"body":"follow me please"}`, author)
+	if !created.Flagged {
+		t.Error("author should see the flag on the created chirp")
+	}
+	if store.chirps[0].FlaggedReason.String != "asks for follows" {
+		t.Errorf("got flagged_reason %q", store.chirps[0].FlaggedReason.String)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{"author", author, true},
+		{"other user", other, false},
+		{"anonymous", "", false},
+	} {
+		rec := serve(h, "GET", "/api/chirps/"+created.ID.String(), "", tt.token)
+		var got map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got["flagged"]; ok != tt.want {
+			t.Errorf("%s: flagged present = %v, want %v", tt.name, ok, tt.want)
+		}
+	}
+}
+
+func TestAdminFlaggedChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, author := seedUser(t, cfg, store, "author@example.com")
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	postChirp(t, h, `{"body":"nothing to see"}`, author)
+	postChirp(t, h, `{"body":"RT for luck"}`, author)
+
+	if rec := serve(h, "GET", "/admin/flagged-chirps", "", author); rec.Code != http.StatusForbidden {
+		t.Errorf("non-admin: got status %d, want 403", rec.Code)
+	}
+	rec := serve(h, "GET", "/admin/flagged-chirps", "", admin)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	var got []flaggedChirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Body != "RT for luck" || got[0].FlaggedReason != "asks for reposts" {
+		t.Errorf("got %+v", got)
+	}
+}
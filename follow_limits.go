@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const defaultMaxFollowsPerUser = 5000
+
+// followLimitReached reports whether followerID following followee would
+// push either of them past cfg.maxFollowsPerUser or
+// cfg.maxFollowersPerUser; zero disables a limit. Re-following someone
+// already followed is a no-op, so it is never limited.
+func (cfg *apiConfig) followLimitReached(ctx context.Context, followerID uuid.UUID, followee database.GetUserByIdRow) (bool, string, error) {
+	msg := ""
+	if cfg.maxFollowersPerUser > 0 && followee.FollowersCount >= int64(cfg.maxFollowersPerUser) {
+		msg = "follower limit reached"
+	}
+	if msg == "" && cfg.maxFollowsPerUser > 0 {
+		follower, err := cfg.db.GetUserById(ctx, database.GetUserByIdParams{ID: followerID, Namespace: namespaceOf(ctx)})
+		if err != nil {
+			return false, "", err
+		}
+		if follower.FollowingCount >= int64(cfg.maxFollowsPerUser) {
+			msg = "follow limit reached"
+		}
+	}
+	if msg == "" {
+		return false, "", nil
+	}
+	rel, err := cfg.db.GetFollowRelationship(ctx, database.GetFollowRelationshipParams{
+		CallerID: followerID,
+		TargetID: followee.User.ID,
+	})
+	if err != nil {
+		return false, "", err
+	}
+	return !rel.Following, msg, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func follow(h http.Handler, target database.User, token string) int {
+	return serve(h, "POST", "/api/users/"+target.ID.String()+"/follow", "", token).Code
+}
+
+func TestMaxFollowsPerUser(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxFollowsPerUser = 3
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "follower@example.com")
+	var targets []database.User
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+		u, _ := seedUser(t, cfg, store, email)
+		targets = append(targets, u)
+	}
+
+	for i, target := range targets[:3] {
+		if code := follow(h, target, token); code != http.StatusNoContent {
+			t.Fatalf("follow %d of 3: got status %d, want 204", i+1, code)
+		}
+	}
+	rec := serve(h, "POST", "/api/users/"+targets[3].ID.String()+"/follow", "", token)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("follow over the limit: got status %d, want 429", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"error":"follow limit reached"`) {
+		t.Errorf("got body %s", rec.Body.String())
+	}
+	if code := follow(h, targets[0], token); code != http.StatusNoContent {
+		t.Errorf("re-following at the limit: got status %d, want 204", code)
+	}
+
+	serve(h, "DELETE", "/api/users/"+targets[0].ID.String()+"/follow", "", token)
+	if code := follow(h, targets[3], token); code != http.StatusNoContent {
+		t.Errorf("follow after unfollowing: got status %d, want 204", code)
+	}
+}
+
+func TestMaxFollowersPerUser(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxFollowersPerUser = 2
+	h := newServer("0", cfg).Handler
+	celebrity, _ := seedUser(t, cfg, store, "celebrity@example.com")
+	var tokens []string
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, token := seedUser(t, cfg, store, email)
+		tokens = append(tokens, token)
+	}
+
+	for i, token := range tokens[:2] {
+		if code := follow(h, celebrity, token); code != http.StatusNoContent {
+			t.Fatalf("follower %d of 2: got status %d, want 204", i+1, code)
+		}
+	}
+	rec := serve(h, "POST", "/api/users/"+celebrity.ID.String()+"/follow", "", tokens[2])
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "follower limit reached") {
+		t.Errorf("follower over the limit: got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFollowLimitsDisabled(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "follower@example.com")
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		u, _ := seedUser(t, cfg, store, email)
+		if code := follow(h, u, token); code != http.StatusNoContent {
+			t.Errorf("follow %s with no limit: got status %d, want 204", email, code)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/chirpypb"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultGRPCPort = "9090"
+
+// chirpService implements chirpypb.ChirpService on top of the same cfg
+// methods as the HTTP handlers.
+type chirpService struct {
+	chirpypb.UnimplementedChirpServiceServer
+	cfg *apiConfig
+}
+
+func newGRPCServer(cfg *apiConfig) *grpc.Server {
+	s := grpc.NewServer()
+	chirpypb.RegisterChirpServiceServer(s, &chirpService{cfg: cfg})
+	return s
+}
+
+// grpcAuthHeader lifts the authorization metadata into an http.Header so
+// the HTTP API's bearer token parsing applies unchanged.
+func grpcAuthHeader(ctx context.Context) http.Header {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return http.Header{"Authorization": md.Get("authorization")}
+}
+
+// callerID returns the user whose JWT ctx carries, for calls that write.
+// Tokens whose scope only allows reads are refused as they would be for a
+// POST over HTTP.
+func (s *chirpService) callerID(ctx context.Context) (uuid.UUID, error) {
+	claims, err := s.callerClaims(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !scopeAllows(claims.Scope, http.MethodPost) {
+		return uuid.Nil, status.Error(codes.PermissionDenied, errInsufficientScope.Error())
+	}
+	return claims.UserID, nil
+}
+
+// optionalCallerID is callerID for read-only calls that also serve
+// anonymous callers, who get uuid.Nil.
+func (s *chirpService) optionalCallerID(ctx context.Context) (uuid.UUID, error) {
+	if len(grpcAuthHeader(ctx)["Authorization"]) == 0 {
+		return uuid.Nil, nil
+	}
+	claims, err := s.callerClaims(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// callerClaims validates the JWT ctx carries.
+func (s *chirpService) callerClaims(ctx context.Context) (auth.Claims, error) {
+	bearerToken, err := auth.GetBearerToken(grpcAuthHeader(ctx))
+	if err != nil {
+		return auth.Claims{}, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	claims, err := s.cfg.parseAccessToken(ctx, bearerToken)
+	if err != nil {
+		return auth.Claims{}, status.Error(codes.Unauthenticated, "invalid bearer token")
+	}
+	return claims, nil
+}
+
+// grpcError maps an error from the shared cfg methods to a gRPC status,
+// following respondWithDBError for database failures.
+func grpcError(err error) error {
+	var rejected *chirpRejection
+	switch {
+	case errors.As(err, &rejected):
+		code := codes.InvalidArgument
+		switch rejected.status {
+		case http.StatusNotFound:
+			code = codes.NotFound
+		case http.StatusTooManyRequests:
+			code = codes.ResourceExhausted
+		}
+		return status.Error(code, rejected.msg)
+	case errors.Is(err, errEmailDomainBlocked):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, "not found")
+	}
+	log.Printf("gRPC call failed: %s", err)
+	switch classifyDBError(err) {
+	case dbErrorUnavailable:
+		return status.Error(codes.Unavailable, "database unavailable")
+	case dbErrorTimeout:
+		return status.Error(codes.DeadlineExceeded, "database timed out")
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func newChirpProto(c chirpResp) *chirpypb.Chirp {
+	pb := &chirpypb.Chirp{
+		Id:         c.ID.String(),
+		CreatedAt:  timestamppb.New(c.CreatedAt),
+		UpdatedAt:  timestamppb.New(c.UpdatedAt),
+		Body:       c.Body,
+		UserId:     c.UserId,
+		LikeCount:  c.LikeCount,
+		ReplyCount: c.ReplyCount,
+		IsNsfw:     c.IsNsfw,
+		Visibility: string(c.Visibility),
+	}
+	if c.ParentId != nil {
+		pb.ParentId = c.ParentId.String()
+	}
+	return pb
+}
+
+func newUserProto(u database.User) *chirpypb.User {
+	return &chirpypb.User{
+		Id:          u.ID.String(),
+		CreatedAt:   timestamppb.New(u.CreatedAt.Time),
+		UpdatedAt:   timestamppb.New(u.UpdatedAt.Time),
+		Email:       u.Email.String,
+		IsChirpyRed: u.IsChirpyRed,
+		IsVerified:  u.IsVerified,
+	}
+}
+
+func (s *chirpService) CreateChirp(ctx context.Context, req *chirpypb.CreateChirpRequest) (*chirpypb.Chirp, error) {
+	userId, err := s.callerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params := createChirpParams{
+		Body:       req.GetBody(),
+		IsNsfw:     req.GetIsNsfw(),
+		Visibility: req.GetVisibility(),
+	}
+	if req.GetParentId() != "" {
+		parentId, err := uuid.Parse(req.GetParentId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid parent_id")
+		}
+		params.ParentId = &parentId
+	}
+	chirp, _, err := s.cfg.createChirp(ctx, userId, params)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return newChirpProto(chirp), nil
+}
+
+// GetChirp applies the same hidden and visibility checks as
+// handlerGetChirpByID. Hidden chirps are never returned, since there is no
+// admin override over gRPC.
+func (s *chirpService) GetChirp(ctx context.Context, req *chirpypb.GetChirpRequest) (*chirpypb.Chirp, error) {
+	chirpId, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+	viewer, err := s.optionalCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chirp, err := s.cfg.getChirpCoalesced(ctx, chirpId)
+	if err == nil && chirp.Chirp.IsHidden {
+		err = sql.ErrNoRows
+	}
+	if err == nil {
+		var visible bool
+		if visible, err = s.cfg.canViewChirp(ctx, chirp.Chirp, viewer); err == nil && !visible {
+			err = sql.ErrNoRows
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "chirp not found")
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	s.cfg.recordChirpRead(viewer, chirpId)
+	resp := newChirpResp(chirp.Chirp)
+	resp.LikeCount, resp.ReplyCount = chirp.LikeCount, chirp.ReplyCount
+	return newChirpProto(resp), nil
+}
+
+func (s *chirpService) ListChirps(ctx context.Context, req *chirpypb.ListChirpsRequest) (*chirpypb.ListChirpsResponse, error) {
+	viewer, err := s.optionalCallerID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var chirps []chirpResp
+	if req.GetAuthorId() != "" {
+		authorId, err := uuid.Parse(req.GetAuthorId())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid author_id")
+		}
+		rows, err := s.cfg.db.GetChirpsByUserId(ctx, database.GetChirpsByUserIdParams{
+			UserID:    authorId,
+			ViewerID:  viewer,
+			Namespace: namespaceOf(ctx),
+		})
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		for _, c := range rows {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			chirps = append(chirps, cr)
+		}
+	} else {
+		rows, err := s.cfg.db.GetChirps(ctx, database.GetChirpsParams{ViewerID: viewer, Namespace: namespaceOf(ctx)})
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		for _, c := range rows {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			chirps = append(chirps, cr)
+		}
+	}
+	s.cfg.recordImpressions(ctx, chirpIDs(chirps))
+	resp := &chirpypb.ListChirpsResponse{Chirps: make([]*chirpypb.Chirp, 0, len(chirps))}
+	for _, c := range chirps {
+		resp.Chirps = append(resp.Chirps, newChirpProto(c))
+	}
+	return resp, nil
+}
+
+func (s *chirpService) CreateUser(ctx context.Context, req *chirpypb.CreateUserRequest) (*chirpypb.User, error) {
+	user, err := s.cfg.createUser(ctx, createUserParams{Email: req.GetEmail(), Password: req.GetPassword()})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return newUserProto(user), nil
+}
+
+// Login issues tokens like POST /api/login. Users with email MFA enabled
+// are refused, as the OTP step is only offered over HTTP.
+func (s *chirpService) Login(ctx context.Context, req *chirpypb.LoginRequest) (*chirpypb.LoginResponse, error) {
+	user, err := s.cfg.db.GetUserByEmail(ctx, database.GetUserByEmailParams{
+		Email:     sql.NullString{String: req.GetEmail(), Valid: req.GetEmail() != ""},
+		Namespace: namespaceOf(ctx),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, grpcError(err)
+	}
+	match, _ := auth.CheckHashedPassword(req.GetPassword(), user.HashedPassword)
+	if err != nil || !match {
+		s.cfg.audit(ctx, "user.login_failed", "user", user.ID, map[string]string{
+			"email": req.GetEmail(),
+		})
+		return nil, status.Error(codes.Unauthenticated, "incorrect email or password")
+	}
+	if user.EmailMfaEnabled {
+		return nil, status.Error(codes.FailedPrecondition, "email OTP required; log in over HTTP")
+	}
+	expiresIn := time.Hour
+	if req.GetExpiresInSeconds() > 0 {
+		expiresIn = time.Duration(req.GetExpiresInSeconds()) * time.Second
+	}
+	token, refreshToken, err := s.cfg.issueTokens(ctx, user, expiresIn, "")
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &chirpypb.LoginResponse{User: newUserProto(user), Token: token, RefreshToken: refreshToken}, nil
+}
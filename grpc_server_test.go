@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/chirpypb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves cfg's ChirpService over an in-memory listener and returns
+// a client connected to it.
+func dialGRPC(t *testing.T, cfg *apiConfig) chirpypb.ChirpServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := newGRPCServer(cfg)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return chirpypb.NewChirpServiceClient(conn)
+}
+
+func TestGRPCChirpService(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	client := dialGRPC(t, cfg)
+	ctx := context.Background()
+
+	user, err := client.CreateUser(ctx, &chirpypb.CreateUserRequest{Email: "alice@example.com", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	_, err = client.Login(ctx, &chirpypb.LoginRequest{Email: "alice@example.com", Password: "wrong"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Login with a bad password: got %v, want Unauthenticated", err)
+	}
+	login, err := client.Login(ctx, &chirpypb.LoginRequest{Email: "alice@example.com", Password: "hunter2"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if login.GetUser().GetId() != user.GetId() || login.GetToken() == "" || login.GetRefreshToken() == "" {
+		t.Fatalf("got login %+v, want tokens for %s", login, user.GetId())
+	}
+
+	_, err = client.CreateChirp(ctx, &chirpypb.CreateChirpRequest{Body: "no token"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("CreateChirp without a token: got %v, want Unauthenticated", err)
+	}
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+login.GetToken())
+	_, err = client.CreateChirp(authed, &chirpypb.CreateChirpRequest{Body: "hi", Visibility: "secret"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateChirp with a bad visibility: got %v, want InvalidArgument", err)
+	}
+	chirp, err := client.CreateChirp(authed, &chirpypb.CreateChirpRequest{Body: "hello over grpc"})
+	if err != nil {
+		t.Fatalf("CreateChirp: %v", err)
+	}
+	if chirp.GetUserId() != user.GetId() || chirp.GetBody() != "hello over grpc" {
+		t.Errorf("got chirp %+v, want alice's chirp", chirp)
+	}
+
+	got, err := client.GetChirp(ctx, &chirpypb.GetChirpRequest{Id: chirp.GetId()})
+	if err != nil {
+		t.Fatalf("GetChirp: %v", err)
+	}
+	if got.GetId() != chirp.GetId() || got.GetBody() != chirp.GetBody() {
+		t.Errorf("got %+v, want %+v", got, chirp)
+	}
+	_, err = client.GetChirp(ctx, &chirpypb.GetChirpRequest{Id: user.GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetChirp of an unknown id: got %v, want NotFound", err)
+	}
+
+	list, err := client.ListChirps(ctx, &chirpypb.ListChirpsRequest{AuthorId: user.GetId()})
+	if err != nil {
+		t.Fatalf("ListChirps: %v", err)
+	}
+	if len(list.GetChirps()) != 1 || list.GetChirps()[0].GetId() != chirp.GetId() {
+		t.Errorf("got %d chirps, want just %s", len(list.GetChirps()), chirp.GetId())
+	}
+	cfg.impressions.Wait()
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
 	//w.Write([]byte(fmt.Sprintf("Hits: %d", cfg.fileserverHits.Load())))
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "<html><body><h1>Welcome, Chirpy Admin</h1><p>Chirpy has been visited %d times!</p></body></html>", cfg.fileserverHits.Load())
+	rt := &cfg.revokedTokens
+	fmt.Fprintf(w, "<html><body><h1>Welcome, Chirpy Admin</h1><p>Chirpy has been visited %d times!</p>"+
+		"<p>Revocation filter: %d hits (%d false positives), %d misses</p></body></html>",
+		cfg.fileserverHits.Load(), rt.hits.Load(), rt.falsePositives.Load(), rt.misses.Load())
 }
 func (cfg *apiConfig) handlerReset(w http.ResponseWriter, r *http.Request) {
 	if cfg.platform != "dev" {
@@ -19,8 +27,45 @@ func (cfg *apiConfig) handlerReset(w http.ResponseWriter, r *http.Request) {
 	cfg.resetMetrics()
 	err := cfg.db.DeleteUsers(r.Context())
 	if err != nil {
-		w.WriteHeader(500)
+		cfg.respondWithDBError(w, err)
 		return
 	}
+	cfg.chirpCounts.Clear()
 	w.Write([]byte("Metrics reset\n"))
 }
+
+// callerIsAdmin reports whether the request carries a valid token for a user
+// with is_admin set. Any failure along the way counts as not an admin.
+func (cfg *apiConfig) callerIsAdmin(r *http.Request) bool {
+	userId, err := cfg.optionalUserID(r)
+	if err != nil || userId == uuid.Nil {
+		return false
+	}
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	return err == nil && user.User.IsAdmin
+}
+
+// requireAdmin authenticates the caller as an admin user, writing 401 or 403
+// and returning false when they are not.
+func (cfg *apiConfig) requireAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	userId, err := cfg.authenticateRequest(r, bearerToken)
+	if errors.Is(err, errInsufficientScope) {
+		w.WriteHeader(http.StatusForbidden)
+		return uuid.Nil, false
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return uuid.Nil, false
+	}
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if err != nil || !user.User.IsAdmin {
+		w.WriteHeader(http.StatusForbidden)
+		return uuid.Nil, false
+	}
+	return userId, true
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func exportCSV(t *testing.T, h http.Handler, path, token string) [][]string {
+	t.Helper()
+	rec := serve(h, "GET", path, "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: got status %d: %s", path, rec.Code, rec.Body.String())
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parsing CSV: %v", err)
+	}
+	return records
+}
+
+func TestExportChirpsCSV(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"apples, pears and \"plums\""}`, token)
+
+	rec := serve(h, "GET", "/admin/export/chirps", "", admin)
+	if got := rec.Header().Get("Content-Type"); got != "text/csv" {
+		t.Errorf("got Content-Type %q", got)
+	}
+	wantDisposition := fmt.Sprintf(`attachment; filename="chirps-export-%s.csv"`, time.Now().UTC().Format(time.DateOnly))
+	if got := rec.Header().Get("Content-Disposition"); got != wantDisposition {
+		t.Errorf("got Content-Disposition %q, want %q", got, wantDisposition)
+	}
+	if !strings.Contains(rec.Body.String(), `"apples, pears and ""plums"""`) {
+		t.Errorf("body not quoted in %s", rec.Body.String())
+	}
+
+	records := exportCSV(t, h, "/admin/export/chirps", admin)
+	wantHeader := "id,user_id,body,created_at,likes_count,replies_count,is_hidden,language"
+	if len(records) != 2 || strings.Join(records[0], ",") != wantHeader {
+		t.Fatalf("got %q", records)
+	}
+	if row := records[1]; row[0] != chirp.ID.String() || row[2] != `apples, pears and "plums"` || row[6] != "false" {
+		t.Errorf("got row %q", row)
+	}
+}
+
+func TestExportChirpsBatchesAndRange(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 250 {
+		store.chirps = append(store.chirps, database.Chirp{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			CreatedAt: sql.NullTime{Time: start.Add(time.Duration(i) * time.Hour), Valid: true},
+			Body:      sql.NullString{String: fmt.Sprintf("chirp %d", i), Valid: true},
+		})
+	}
+
+	records := exportCSV(t, h, "/admin/export/chirps", admin)
+	if len(records) != 251 {
+		t.Errorf("got %d rows, want header plus 250", len(records))
+	}
+	if store.exportBatches != 3 {
+		t.Errorf("got %d batches, want 3", store.exportBatches)
+	}
+
+	from := start.Add(10 * time.Hour).Format(time.RFC3339)
+	to := start.Add(20 * time.Hour).Format(time.RFC3339)
+	records = exportCSV(t, h, "/admin/export/chirps?from="+from+"&to="+to, admin)
+	if len(records) != 11 || records[1][2] != "chirp 10" || records[10][2] != "chirp 19" {
+		t.Errorf("got %d rows from %s to %s: %q", len(records)-1, from, to, records[1:])
+	}
+
+	if rec := serve(h, "GET", "/admin/export/chirps?from=yesterday", "", admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad from: got status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerVerifyUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserVerified(w, r, true)
+}
+
+func (cfg *apiConfig) handlerUnverifyUser(w http.ResponseWriter, r *http.Request) {
+	cfg.setUserVerified(w, r, false)
+}
+
+func (cfg *apiConfig) setUserVerified(w http.ResponseWriter, r *http.Request, verified bool) {
+	if cfg.platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	user, err := cfg.db.SetUserVerified(r.Context(), database.SetUserVerifiedParams{
+		ID:         userId,
+		IsVerified: verified,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.evictUser(user)
+	action := "user.verified"
+	if !verified {
+		action = "user.unverified"
+	}
+	cfg.audit(r.Context(), action, "user", user.ID, nil)
+	respondWithJSON(w, http.StatusOK, newUserResp(user))
+}
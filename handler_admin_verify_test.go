@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestVerifiedFlagPersistsInLogin(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	credentials := `{"email":"verified@example.com","password":"hunter2"}`
+
+	rec := serve(handler, "POST", "/api/users", credentials, "")
+	var created userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decoding user: %v", err)
+	}
+	rec = serve(handler, "POST", "/admin/users/"+created.ID.String()+"/verify", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify: got status %d, want 200", rec.Code)
+	}
+
+	rec = serve(handler, "POST", "/api/login", credentials, "")
+	var login userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &login); err != nil {
+		t.Fatalf("decoding login: %v", err)
+	}
+	if !login.IsVerified {
+		t.Error("login response is_verified = false, want true")
+	}
+
+	serve(handler, "DELETE", "/admin/users/"+created.ID.String()+"/verify", "", "")
+	rec = serve(handler, "POST", "/api/login", credentials, "")
+	login = userResp{}
+	json.Unmarshal(rec.Body.Bytes(), &login)
+	if login.IsVerified {
+		t.Error("login response is_verified = true after revoke, want false")
+	}
+}
+
+func TestVerifyUserRequiresDevPlatform(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.platform = "prod"
+	user, _ := seedUser(t, cfg, store, "someone@example.com")
+	rec := serve(newServer("0", cfg).Handler, "POST", "/admin/users/"+user.ID.String()+"/verify", "", "")
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want 403", rec.Code)
+	}
+}
+
+func TestGetChirpsVerifiedOnly(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	verified, verifiedToken := seedUser(t, cfg, store, "blue@example.com")
+	_, otherToken := seedUser(t, cfg, store, "plain@example.com")
+	serve(handler, "POST", "/admin/users/"+verified.ID.String()+"/verify", "", "")
+	serve(handler, "POST", "/api/chirps", `{"body":"from a verified user"}`, verifiedToken)
+	serve(handler, "POST", "/api/chirps", `{"body":"from anyone"}`, otherToken)
+
+	rec := serve(handler, "GET", "/api/chirps?verified_only=true", "", "")
+	var got []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding chirps: %v", err)
+	}
+	if len(got) != 1 || got[0].UserId != verified.ID.String() {
+		t.Fatalf("got %+v, want only the verified user's chirp", got)
+	}
+
+	rec = serve(handler, "GET", "/api/chirps", "", "")
+	got = nil
+	json.Unmarshal(rec.Body.Bytes(), &got)
+	if len(got) != 2 {
+		t.Errorf("got %d chirps without filter, want 2", len(got))
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type webhookResp struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type webhookDeliveryResp struct {
+	ID            uuid.UUID `json:"id"`
+	ChirpID       uuid.UUID `json:"chirp_id"`
+	AttemptNumber int32     `json:"attempt_number"`
+	StatusCode    *int32    `json:"status_code"`
+	Error         *string   `json:"error"`
+	DeliveredAt   time.Time `json:"delivered_at"`
+}
+
+type createWebhookParams struct {
+	URL string `json:"url"`
+}
+
+func (cfg *apiConfig) handlerCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	params := createWebhookParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	u, err := url.Parse(params.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		respondWithError(w, http.StatusBadRequest, "url must be an absolute http or https URL")
+		return
+	}
+	hook, err := cfg.db.CreateWebhook(r.Context(), u.String())
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), adminId), "webhook.created", "webhook", hook.ID, map[string]string{"url": hook.Url})
+	respondWithJSON(w, http.StatusCreated, webhookResp{ID: hook.ID, URL: hook.Url, CreatedAt: hook.CreatedAt})
+}
+
+// handlerGetWebhookDeliveries lists every delivery attempt for a webhook,
+// newest first.
+func (cfg *apiConfig) handlerGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	webhookId, err := uuid.Parse(r.PathValue("webhookId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+	_, err = cfg.db.GetWebhookByID(r.Context(), webhookId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	deliveries, err := cfg.db.GetWebhookDeliveries(r.Context(), webhookId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]webhookDeliveryResp, 0, len(deliveries))
+	for _, d := range deliveries {
+		dr := webhookDeliveryResp{
+			ID:            d.ID,
+			ChirpID:       d.ChirpID,
+			AttemptNumber: d.AttemptNumber,
+			DeliveredAt:   d.DeliveredAt,
+		}
+		if d.StatusCode.Valid {
+			dr.StatusCode = &d.StatusCode.Int32
+		}
+		if d.Error.Valid {
+			dr.Error = &d.Error.String
+		}
+		resp = append(resp, dr)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+type auditLogResp struct {
+	ID         uuid.UUID       `json:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   *uuid.UUID      `json:"entity_id"`
+	Details    json.RawMessage `json:"details"`
+	IPAddress  string          `json:"ip_address"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerGetAuditLog(w http.ResponseWriter, r *http.Request) {
+	if cfg.platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	// Both bounds are optional RFC3339 timestamps; an open range returns the
+	// whole log.
+	since := time.Time{}
+	until := time.Now().Add(time.Minute)
+	var err error
+	if from := r.URL.Query().Get("from"); from != "" {
+		since, err = time.Parse(time.RFC3339, from)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		until, err = time.Parse(time.RFC3339, to)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries, err := cfg.db.GetAuditLogs(r.Context(), database.GetAuditLogsParams{
+		Since: sql.NullTime{Time: since, Valid: true},
+		Until: sql.NullTime{Time: until, Valid: true},
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+
+	resp := make([]auditLogResp, 0, len(entries))
+	for _, e := range entries {
+		entry := auditLogResp{
+			ID:         e.ID,
+			Action:     e.Action,
+			EntityType: e.EntityType,
+			Details:    e.Details,
+			IPAddress:  e.IpAddress.String,
+			CreatedAt:  e.CreatedAt.Time,
+		}
+		if e.ActorID.Valid {
+			entry.ActorID = &e.ActorID.UUID
+		}
+		if e.EntityID.Valid {
+			entry.EntityID = &e.EntityID.UUID
+		}
+		resp = append(resp, entry)
+	}
+	stop := startWriterPhase(w, "marshal")
+	dat, _ := json.Marshal(resp)
+	stop()
+	w.WriteHeader(200)
+	w.Write(dat)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type blockedDomainParams struct {
+	Domain string `json:"domain"`
+}
+
+func (cfg *apiConfig) handlerAddBlockedDomain(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	params := blockedDomainParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	domain := strings.ToLower(strings.TrimSpace(params.Domain))
+	if domain == "" || strings.Contains(domain, "@") {
+		respondWithError(w, http.StatusBadRequest, "invalid domain")
+		return
+	}
+	if err := cfg.db.AddBlockedEmailDomain(r.Context(), domain); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.blockedDomains.invalidate()
+	cfg.audit(withActor(r.Context(), adminId), "blocked_domain.added", "blocked_domain", uuid.Nil, map[string]string{"domain": domain})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerDeleteBlockedDomain(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	domain := strings.ToLower(r.PathValue("domain"))
+	n, err := cfg.db.DeleteBlockedEmailDomain(r.Context(), domain)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if n == 0 {
+		respondWithError(w, http.StatusNotFound, "domain not blocked")
+		return
+	}
+	cfg.blockedDomains.invalidate()
+	cfg.audit(withActor(r.Context(), adminId), "blocked_domain.removed", "blocked_domain", uuid.Nil, map[string]string{"domain": domain})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistrationBlockedDomains(t *testing.T) {
+	store := newMemStore()
+	store.blocked = []string{"mailinator.com"}
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+
+	register := func(email string) int {
+		return serve(handler, "POST", "/api/users", `{"email":"`+email+`","password":"pw"}`, "").Code
+	}
+
+	rec := serve(handler, "POST", "/api/users", `{"email":"spam@Mailinator.com","password":"pw"}`, "")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("blocked domain: got status %d, want 422", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "email domain not allowed") {
+		t.Errorf("got body %s", rec.Body.String())
+	}
+	if code := register("someone@example.org"); code != http.StatusCreated {
+		t.Errorf("allowed domain: got status %d, want 201", code)
+	}
+
+	if rec := serve(handler, "POST", "/admin/blocked-domains", `{"domain":"Spam.test"}`, adminToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("adding domain: got status %d, want 204", rec.Code)
+	}
+	if code := register("bot@spam.test"); code != http.StatusUnprocessableEntity {
+		t.Errorf("newly blocked domain: got status %d, want 422", code)
+	}
+
+	if rec := serve(handler, "DELETE", "/admin/blocked-domains/spam.test", "", adminToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("removing domain: got status %d, want 204", rec.Code)
+	}
+	if code := register("human@spam.test"); code != http.StatusCreated {
+		t.Errorf("unblocked domain: got status %d, want 201", code)
+	}
+	if rec := serve(handler, "DELETE", "/admin/blocked-domains/spam.test", "", adminToken); rec.Code != http.StatusNotFound {
+		t.Errorf("removing unknown domain: got status %d, want 404", rec.Code)
+	}
+}
+
+func TestBlockedDomainsCacheTTL(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	if blocked, _ := cfg.isEmailDomainBlocked(t.Context(), "a@late.test"); blocked {
+		t.Fatal("domain blocked before it was added")
+	}
+	// Rows added behind the cache's back are picked up only after the TTL.
+	store.blocked = append(store.blocked, "late.test")
+	if blocked, _ := cfg.isEmailDomainBlocked(t.Context(), "a@late.test"); blocked {
+		t.Error("cache refreshed before the TTL expired")
+	}
+	cfg.blockedDomains.loadedAt = time.Now().Add(-blockedDomainsTTL)
+	if blocked, _ := cfg.isEmailDomainBlocked(t.Context(), "a@late.test"); !blocked {
+		t.Error("cache not refreshed after the TTL expired")
+	}
+}
+
+func TestBlockedDomainAdminOnly(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	_, token := seedUser(t, cfg, store, "user@example.com")
+	rec := serve(newServer("0", cfg).Handler, "POST", "/admin/blocked-domains", `{"domain":"x.test"}`, token)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+type archivedChirpResp struct {
+	chirpResp
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+func (cfg *apiConfig) handlerGetArchivedChirps(w http.ResponseWriter, r *http.Request) {
+	if cfg.platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	chirps, err := cfg.db.GetArchivedChirps(r.Context())
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]archivedChirpResp, 0, len(chirps))
+	for _, c := range chirps {
+		resp = append(resp, archivedChirpResp{
+			chirpResp: newChirpResp(database.Chirp{
+				ID:        c.ID,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+				Body:      c.Body,
+				UserID:    c.UserID,
+				ParentID:  c.ParentID,
+				IsNsfw:    c.IsNsfw,
+				IsHidden:  c.IsHidden,
+
+				WordCount:          c.WordCount,
+				ReadingTimeSeconds: c.ReadingTimeSeconds,
+				Visibility:         c.Visibility,
+				FlaggedReason:      c.FlaggedReason,
+				ImpressionCount:    c.ImpressionCount,
+				ThreadDepth:        c.ThreadDepth,
+				ContentWarning:     c.ContentWarning,
+				AdminEdited:        c.AdminEdited,
+				ImportanceScore:    c.ImportanceScore,
+			}),
+			ArchivedAt: c.ArchivedAt.Time,
+		})
+	}
+	stop := startWriterPhase(w, "marshal")
+	dat, _ := json.Marshal(resp)
+	stop()
+	w.WriteHeader(200)
+	w.Write(dat)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// slowChirpStore holds every GetChirpByID call until release is closed.
+type slowChirpStore struct {
+	*memStore
+	calls   atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *slowChirpStore) GetChirpByID(ctx context.Context, arg database.GetChirpByIDParams) (database.GetChirpByIDRow, error) {
+	if s.calls.Add(1) == 1 {
+		close(s.entered)
+	}
+	<-s.release
+	return s.memStore.GetChirpByID(ctx, arg)
+}
+
+func TestGetChirpCoalescesConcurrentRequests(t *testing.T) {
+	mem := newMemStore()
+	cfg := newTestConfig(mem)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, mem, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"popular"}`, token)
+
+	store := &slowChirpStore{memStore: mem, entered: make(chan struct{}), release: make(chan struct{})}
+	cfg.db = store
+	const requests = 50
+	codes := make(chan int, requests)
+	var started, done sync.WaitGroup
+	started.Add(requests)
+	for range requests {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			started.Done()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/chirps/"+chirp.ID.String(), nil))
+			codes <- rec.Code
+		}()
+	}
+	started.Wait()
+	<-store.entered
+	time.Sleep(50 * time.Millisecond) // let the rest pile up behind the first query
+	close(store.release)
+	done.Wait()
+	close(codes)
+
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("got status %d, want 200", code)
+		}
+	}
+	if n := store.calls.Load(); n != 1 {
+		t.Errorf("got %d GetChirpByID calls, want 1", n)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultContextWindow = 5
+	maxContextWindow     = 50
+)
+
+type chirpContextResp struct {
+	Before []chirpResp `json:"before"`
+	Target chirpResp   `json:"target"`
+	After  []chirpResp `json:"after"`
+}
+
+// handlerGetChirpContext returns a chirp with up to window of its author's
+// chirps on either side, each list in chronological order. Chirps the
+// caller cannot see are skipped.
+func (cfg *apiConfig) handlerGetChirpContext(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	window := defaultContextWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		if window, err = strconv.Atoi(v); err != nil || window < 0 || window > maxContextWindow {
+			respondWithError(w, http.StatusBadRequest, "window must be between 0 and "+strconv.Itoa(maxContextWindow))
+			return
+		}
+	}
+	viewer, err := cfg.optionalUserID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	target, visible, err := cfg.visibleChirp(r, chirpUUId, viewer)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	before, err := cfg.db.GetChirpsBefore(r.Context(), database.GetChirpsBeforeParams{
+		UserID:    target.Chirp.UserID,
+		CreatedAt: target.Chirp.CreatedAt,
+		Namespace: namespaceOf(r.Context()),
+		ViewerID:  viewer,
+		MaxChirps: int32(window),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	after, err := cfg.db.GetChirpsAfter(r.Context(), database.GetChirpsAfterParams{
+		UserID:    target.Chirp.UserID,
+		CreatedAt: target.Chirp.CreatedAt,
+		Namespace: namespaceOf(r.Context()),
+		ViewerID:  viewer,
+		MaxChirps: int32(window),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+
+	// before comes back nearest first.
+	slices.Reverse(before)
+	chirps := make([]chirpResp, 0, len(before)+1+len(after))
+	add := func(c database.Chirp, likes, replies int64) {
+		cr := newChirpResp(c)
+		cr.LikeCount, cr.ReplyCount = likes, replies
+		cr.Flagged = flaggedFor(c, viewer)
+		chirps = append(chirps, cr)
+	}
+	for _, c := range before {
+		add(c.Chirp, c.LikeCount, c.ReplyCount)
+	}
+	add(target.Chirp, target.LikeCount, target.ReplyCount)
+	for _, c := range after {
+		add(c.Chirp, c.LikeCount, c.ReplyCount)
+	}
+	if err := cfg.attachMedia(r.Context(), chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if wantsRenderedBody(r) {
+		renderBodies(chirps)
+	}
+	respondWithJSON(w, http.StatusOK, chirpContextResp{
+		Before: chirps[:len(before)],
+		Target: chirps[len(before)],
+		After:  chirps[len(before)+1:],
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func getContext(t *testing.T, h http.Handler, path, token string) chirpContextResp {
+	t.Helper()
+	rec := serve(h, "GET", path, "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp chirpContextResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func bodies(chirps []chirpResp) string {
+	var out []string
+	for _, c := range chirps {
+		out = append(out, c.Body)
+	}
+	return fmt.Sprint(out)
+}
+
+func TestChirpContext(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+
+	var ids []uuid.UUID
+	for i := range 9 {
+		body := fmt.Sprintf(`{"body":"c%d"}`, i)
+		if i == 4 {
+			body = `{"body":"c4","visibility":"mutual"}`
+		}
+		ids = append(ids, postChirp(t, h, body, alice).ID)
+	}
+	postChirp(t, h, `{"body":"bob's"}`, bob)
+	// Spread the chirps out so their order does not rest on the clock.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range store.chirps {
+		store.chirps[i].CreatedAt.Time = start.Add(time.Duration(i) * time.Minute)
+	}
+
+	got := getContext(t, h, "/api/chirps/"+ids[2].String()+"/context", "")
+	if got.Target.ID != ids[2] {
+		t.Errorf("got target %s, want c2", got.Target.Body)
+	}
+	if s := bodies(got.Before); s != "[c0 c1]" {
+		t.Errorf("got before %s, want the 2 earlier chirps", s)
+	}
+	if s := bodies(got.After); s != "[c3 c5 c6 c7 c8]" {
+		t.Errorf("got after %s, want 5 later public chirps", s)
+	}
+
+	got = getContext(t, h, "/api/chirps/"+ids[6].String()+"/context?window=2", alice)
+	if s := bodies(got.Before); s != "[c4 c5]" {
+		t.Errorf("got before %s, want alice to see her own mutual chirp", s)
+	}
+	if s := bodies(got.After); s != "[c7 c8]" {
+		t.Errorf("got after %s, want [c7 c8] without bob's chirp", s)
+	}
+
+	got = getContext(t, h, "/api/chirps/"+ids[8].String()+"/context?window=0", "")
+	if len(got.Before) != 0 || len(got.After) != 0 {
+		t.Errorf("got %d before and %d after with window=0, want none", len(got.Before), len(got.After))
+	}
+	if rec := serve(h, "GET", "/api/chirps/"+ids[4].String()+"/context", "", bob); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for a chirp bob cannot see, want 404", rec.Code)
+	}
+	if rec := serve(h, "GET", "/api/chirps/"+ids[0].String()+"/context?window=x", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for a bad window, want 400", rec.Code)
+	}
+}
@@ -1,80 +1,251 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
-	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/analytics"
 	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
-func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
-	type parameters struct {
-		Body string `json:"body"`
-	}
-	type errResp struct {
-		Error string `json:"error"`
-	}
+// defaultMaxReplyDepth is MAX_THREAD_DEPTH when it is unset.
+const defaultMaxReplyDepth = 25
 
-	bearerToken, err := auth.GetBearerToken(r.Header)
+type createChirpParams struct {
+	Body       string     `json:"body"`
+	ParentId   *uuid.UUID `json:"parent_id"`
+	IsNsfw     bool       `json:"is_nsfw"`
+	Visibility string     `json:"visibility"`
+	Media      []struct {
+		URL      string `json:"url"`
+		MimeType string `json:"mime_type"`
+		AltText  string `json:"alt_text"`
+	} `json:"media"`
+}
 
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
+// altTextHints lists the images in params that have no alt text. They are
+// advice for the client, not a reason to refuse the chirp.
+func altTextHints(params createChirpParams) []string {
+	var hints []string
+	for i, m := range params.Media {
+		if strings.HasPrefix(strings.ToLower(m.MimeType), "image/") && strings.TrimSpace(m.AltText) == "" {
+			hints = append(hints, fmt.Sprintf("media item %d: alt_text is empty", i))
+		}
 	}
+	return hints
+}
 
-	userId, err := auth.ValidateJWT(bearerToken, cfg.tokenSecret)
+// chirpRejection is a chirp createChirp refused to post. status is the HTTP
+// status it maps to; limit is set when a quota was hit.
+type chirpRejection struct {
+	status int
+	msg    string
+	limit  int
+}
 
-	if err != nil {
-		w.WriteHeader(401)
+func (e *chirpRejection) Error() string { return e.msg }
+
+func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, r *http.Request) {
+	type quotaResp struct {
+		Error string `json:"error"`
+		Limit int    `json:"limit"`
+	}
+
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
+	userId := user.ID
 
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
-	err = decoder.Decode(&params)
+	params := createChirpParams{}
+	err := decoder.Decode(&params)
 	w.Header().Set("Content-Type", "application/json")
 	if err != nil {
-		dat, _ := json.Marshal(errResp{
-			Error: "Something went wrong",
-		})
 		log.Printf("Error decoding parameters: %s", err)
-		w.WriteHeader(500)
-		w.Write(dat)
+		respondWithError(w, 500, "Something went wrong")
 		return
 	}
-	if len(params.Body) > 140 {
-		dat, _ := json.Marshal(errResp{
-			Error: "Chirp is too long",
-		})
-		w.WriteHeader(400)
-		w.Write(dat)
+	resp, created, err := cfg.createChirp(r.Context(), userId, params)
+	var rejected *chirpRejection
+	if errors.As(err, &rejected) {
+		if rejected.limit > 0 {
+			respondWithJSON(w, rejected.status, quotaResp{Error: rejected.msg, Limit: rejected.limit})
+			return
+		}
+		respondWithError(w, rejected.status, rejected.msg)
 		return
 	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp.Warnings = altTextHints(params)
+	if !created {
+		// A retry of a chirp we already stored: hand back the original.
+		respondWithJSON(w, 200, resp)
+		return
+	}
+	respondWithJSON(w, 201, resp)
+}
+
+// createChirp posts a chirp by userId, shared by the HTTP and gRPC APIs. A
+// request the service refuses returns a *chirpRejection; any other error is
+// from the database. created is false when params repeat a chirp userId
+// just posted, in which case the original is returned.
+func (cfg *apiConfig) createChirp(ctx context.Context, userId uuid.UUID, params createChirpParams) (chirpResp, bool, error) {
+	longURLs := cfg.longURLs(params.Body)
+	if cfg.shortenedLen(params.Body, longURLs) > 140 {
+		return chirpResp{}, false, &chirpRejection{status: 400, msg: "Chirp is too long"}
+	}
+	for _, m := range params.Media {
+		if m.URL == "" || m.MimeType == "" {
+			return chirpResp{}, false, &chirpRejection{status: 400, msg: "Media requires url and mime_type"}
+		}
+		if !cfg.mediaOriginAllowed(m.URL) {
+			return chirpResp{}, false, &chirpRejection{status: http.StatusUnprocessableEntity, msg: "media URL origin not allowed"}
+		}
+	}
+	visibility := database.ChirpVisibility(params.Visibility)
+	if visibility == "" {
+		visibility = database.ChirpVisibilityPublic
+	}
+	if visibility != database.ChirpVisibilityPublic && visibility != database.ChirpVisibilityMutual {
+		return chirpResp{}, false, &chirpRejection{status: 400, msg: "Visibility must be public or mutual"}
+	}
+	fingerprint := chirpFingerprint(userId, params.ParentId, params.Body)
+	dup, isDup, err := cfg.findDuplicateChirp(ctx, fingerprint)
+	if err != nil {
+		return chirpResp{}, false, err
+	}
+	if isDup {
+		resp := newChirpResp(dup.Chirp)
+		resp.LikeCount, resp.ReplyCount = dup.LikeCount, dup.ReplyCount
+		resp.Flagged = flaggedFor(dup.Chirp, userId)
+		withMedia := []chirpResp{resp}
+		if err := cfg.attachMedia(ctx, withMedia); err != nil {
+			return chirpResp{}, false, err
+		}
+		return withMedia[0], false, nil
+	}
+	if cfg.inDuplicateCooldown(userId) || cfg.checkDuplicateBody(userId, params.Body) {
+		return chirpResp{}, false, &chirpRejection{status: http.StatusTooManyRequests, msg: "duplicate content detected"}
+	}
+	reached, limit, err := cfg.chirpQuotaReached(ctx, userId)
+	if err != nil {
+		return chirpResp{}, false, err
+	}
+	if reached {
+		return chirpResp{}, false, &chirpRejection{status: http.StatusTooManyRequests, msg: "chirp quota reached", limit: limit}
+	}
+	if cfg.moderation != nil {
+		// Fail open: an unreachable moderator must not block posting.
+		verdict, err := cfg.moderation.Moderate(ctx, params.Body)
+		if err != nil {
+			log.Printf("Error moderating chirp, allowing it through: %s", err)
+		} else if verdict.rejected() {
+			return chirpResp{}, false, &chirpRejection{status: 422, msg: verdict.Reason}
+		}
+	}
+	body, shortCodes, err := cfg.shortenURLs(ctx, userId, params.Body, longURLs)
+	if err != nil {
+		return chirpResp{}, false, err
+	}
+	body = sanitize(body)
+	wordCount, readingTime := chirpMetrics(body)
+	flagReason := detectFollowBait(body)
 	chirpParam := database.CreateChirpParams{
 		Body: sql.NullString{
-			String: sanitize(params.Body),
+			String: body,
 			Valid:  true,
 		},
-		UserID: userId,
+		UserID:             userId,
+		IsNsfw:             params.IsNsfw,
+		WordCount:          int32(wordCount),
+		ReadingTimeSeconds: int32(readingTime),
+		Visibility:         visibility,
+		FlaggedReason: sql.NullString{
+			String: flagReason,
+			Valid:  flagReason != "",
+		},
+		SentimentScore: analytics.SentimentScore(body),
 	}
-	chirp, err := cfg.db.CreateChirp(r.Context(), chirpParam)
+	for _, m := range params.Media {
+		chirpParam.MediaUrls = append(chirpParam.MediaUrls, m.URL)
+		chirpParam.MediaMimeTypes = append(chirpParam.MediaMimeTypes, m.MimeType)
+		chirpParam.MediaAltTexts = append(chirpParam.MediaAltTexts, m.AltText)
+	}
+	var parent database.GetChirpByIDRow
+	if params.ParentId != nil {
+		parent, err = cfg.db.GetChirpByID(ctx, database.GetChirpByIDParams{ID: *params.ParentId, Namespace: namespaceOf(ctx)})
+		visible := err == nil && !parent.Chirp.IsHidden
+		if visible {
+			if visible, err = cfg.canViewChirp(ctx, parent.Chirp, userId); err != nil {
+				return chirpResp{}, false, err
+			}
+		}
+		if !visible {
+			return chirpResp{}, false, &chirpRejection{status: 404, msg: "Parent chirp not found"}
+		}
+		// A chirp's depth never changes once it is posted, so the parent
+		// read above is as good as one taken in the insert's transaction.
+		if cfg.maxReplyDepth > 0 && parent.Chirp.ThreadDepth >= cfg.maxReplyDepth {
+			return chirpResp{}, false, &chirpRejection{status: 400, msg: "maximum thread depth reached"}
+		}
+		chirpParam.ParentID = uuid.NullUUID{UUID: *params.ParentId, Valid: true}
+	}
+	chirp, err := cfg.db.CreateChirp(ctx, chirpParam)
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
-		return
+		return chirpResp{}, false, err
 	}
-
-	dat, _ := json.Marshal(chirpResp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt.Time,
-		UpdatedAt: chirp.UpdatedAt.Time,
-		Body:      chirp.Body.String,
-		UserId:    chirp.UserID.String(),
+	if len(shortCodes) > 0 {
+		err := cfg.db.AttachShortLinksToChirp(ctx, database.AttachShortLinksToChirpParams{
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+			Codes:   shortCodes,
+		})
+		if err != nil {
+			return chirpResp{}, false, err
+		}
+	}
+	cfg.adjustChirpCount(userId, 1)
+	cfg.rememberChirpBody(userId, params.Body)
+	for _, topic := range chirpTopics(chirp.Body.String) {
+		err := cfg.db.AddChirpTopic(ctx, database.AddChirpTopicParams{
+			ChirpID: chirp.ID,
+			Topic:   topic,
+		})
+		if err != nil {
+			return chirpResp{}, false, err
+		}
+	}
+	err = cfg.db.SaveRequestFingerprint(ctx, database.SaveRequestFingerprintParams{
+		Fingerprint: fingerprint,
+		ChirpID:     chirp.ID,
 	})
-	w.WriteHeader(201)
-	w.Write(dat)
+	if err != nil {
+		log.Printf("Error saving fingerprint for chirp %s: %s", chirp.ID, err)
+	}
+	cfg.audit(withActor(ctx, userId), "chirp.created", "chirp", chirp.ID, nil)
+	cfg.invalidateFollowerFeeds(ctx, userId)
+	payload := chirpCreatedPayload{Chirp: chirp}
+	if params.ParentId != nil {
+		payload.ParentAuthorID = parent.Chirp.UserID
+	}
+	cfg.enqueueJob(ctx, payload)
+
+	created := newChirpResp(chirp)
+	created.Flagged = flaggedFor(chirp, userId)
+	resp := []chirpResp{created}
+	if err := cfg.attachMedia(ctx, resp); err != nil {
+		return chirpResp{}, false, err
+	}
+	return resp[0], true, nil
 }
@@ -1,12 +1,18 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 
-	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
 	"github.com/google/uuid"
 )
 
+const maxBulkDeleteChirps = 50
+
+// handlerDeleteChirp moves one of the caller's chirps to their recycle bin,
+// from which handlerRestoreChirp can bring it back.
 func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	chirpId := r.PathValue("chirpId")
@@ -15,37 +21,97 @@ func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	bearerToken, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		w.WriteHeader(401)
-		return
-	}
-
-	userId, err := auth.ValidateJWT(bearerToken, cfg.tokenSecret)
-
-	if err != nil {
-		w.WriteHeader(403)
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
+	userId := user.ID
 
-	chirp, err := cfg.db.GetChirpByID(r.Context(), chirpUUId)
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpUUId, Namespace: namespaceOf(r.Context())})
 	if err != nil {
 		w.WriteHeader(404)
 		w.Write([]byte(err.Error()))
 		return
 	}
 
-	if userId != chirp.UserID {
+	if userId != chirp.Chirp.UserID {
 		w.WriteHeader(403)
 		return
 	}
 
-	err = cfg.db.DeleteChirpById(r.Context(), chirpUUId)
+	err = cfg.db.SoftDeleteChirp(r.Context(), chirpUUId)
 
 	if err != nil {
 		w.WriteHeader(401)
 		w.Write([]byte(err.Error()))
 		return
 	}
+	cfg.adjustChirpCount(userId, -1)
+	cfg.audit(withActor(r.Context(), userId), "chirp.deleted", "chirp", chirpUUId, nil)
 	w.WriteHeader(204)
 }
+
+type deleteChirpsParams struct {
+	Ids []uuid.UUID `json:"ids"`
+}
+
+type deleteChirpsResp struct {
+	Deleted int `json:"deleted"`
+}
+
+// handlerDeleteChirps moves up to maxBulkDeleteChirps of the caller's chirps
+// to their recycle bin at once. If any listed chirp belongs to someone else
+// nothing is deleted; IDs that no longer exist are skipped and not counted.
+func (cfg *apiConfig) handlerDeleteChirps(w http.ResponseWriter, r *http.Request) {
+	type forbiddenResp struct {
+		Error string      `json:"error"`
+		Ids   []uuid.UUID `json:"ids"`
+	}
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	params := deleteChirpsParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "ids must be a list of chirp ids")
+		return
+	}
+	if len(params.Ids) == 0 || len(params.Ids) > maxBulkDeleteChirps {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("ids must list between 1 and %d chirps", maxBulkDeleteChirps))
+		return
+	}
+	owners, err := cfg.db.GetChirpOwners(r.Context(), params.Ids)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	var notOwned []uuid.UUID
+	for _, o := range owners {
+		if o.UserID != userId {
+			notOwned = append(notOwned, o.ID)
+		}
+	}
+	if len(notOwned) > 0 {
+		respondWithJSON(w, http.StatusForbidden, forbiddenResp{
+			Error: "chirps belong to another user",
+			Ids:   notOwned,
+		})
+		return
+	}
+	deleted, err := cfg.db.SoftDeleteChirpsByIds(r.Context(), database.SoftDeleteChirpsByIdsParams{
+		Ids:    params.Ids,
+		UserID: userId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.adjustChirpCount(userId, -int64(len(deleted)))
+	for _, id := range deleted {
+		cfg.audit(withActor(r.Context(), userId), "chirp.deleted", "chirp", id, nil)
+	}
+	respondWithJSON(w, http.StatusOK, deleteChirpsResp{Deleted: len(deleted)})
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func idsBody(ids ...uuid.UUID) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = `"` + id.String() + `"`
+	}
+	return `{"ids":[` + strings.Join(quoted, ",") + `]}`
+}
+
+func TestBulkDeleteChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	var ids []uuid.UUID
+	for i := range 3 {
+		ids = append(ids, postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), alice).ID)
+	}
+
+	// A missing ID is skipped, and a repeated one is only counted once.
+	rec := serve(h, "DELETE", "/api/chirps", idsBody(ids[0], ids[1], ids[1], uuid.New()), alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != `{"deleted":2}` {
+		t.Errorf("got body %s, want deleted 2", rec.Body.String())
+	}
+	for i, c := range store.chirps {
+		if c.DeletedAt.Valid != (i < 2) {
+			t.Errorf("chirp %d: got deleted %v", i, c.DeletedAt.Valid)
+		}
+	}
+}
+
+func TestBulkDeleteChirpsOwnership(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+	mine := postChirp(t, h, `{"body":"mine"}`, alice).ID
+	theirs := postChirp(t, h, `{"body":"theirs"}`, bob).ID
+
+	rec := serve(h, "DELETE", "/api/chirps", idsBody(mine, theirs), alice)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("got status %d, want 403", rec.Code)
+	}
+	var got struct {
+		Ids []uuid.UUID `json:"ids"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Ids) != 1 || got.Ids[0] != theirs {
+		t.Errorf("got offending ids %v, want [%s]", got.Ids, theirs)
+	}
+	if len(store.chirps) != 2 {
+		t.Errorf("got %d chirps, want nothing deleted", len(store.chirps))
+	}
+}
+
+func TestBulkDeleteChirpsValidation(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	tooMany := make([]uuid.UUID, maxBulkDeleteChirps+1)
+	for i := range tooMany {
+		tooMany[i] = uuid.New()
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		token string
+		want  int
+	}{
+		{"no token", idsBody(uuid.New()), "", http.StatusUnauthorized},
+		{"empty list", `{"ids":[]}`, token, http.StatusBadRequest},
+		{"too many", idsBody(tooMany...), token, http.StatusBadRequest},
+		{"bad id", `{"ids":["not-a-uuid"]}`, token, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := serve(h, "DELETE", "/api/chirps", tt.body, tt.token); rec.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/analytics"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type adminEditChirpParams struct {
+	Body string `json:"body"`
+}
+
+// handlerAdminEditChirp lets an admin correct a chirp's body, such as to
+// strip illegal content, without deleting it. The body it replaces is kept
+// in chirp_versions.
+func (cfg *apiConfig) handlerAdminEditChirp(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	var params adminEditChirpParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if strings.TrimSpace(params.Body) == "" {
+		respondWithError(w, http.StatusBadRequest, "body is required")
+		return
+	}
+	if len(params.Body) > 140 {
+		respondWithError(w, http.StatusBadRequest, "Chirp is too long")
+		return
+	}
+
+	existing, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpId, Namespace: namespaceOf(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	body := sanitize(params.Body)
+	wordCount, readingTime := chirpMetrics(body)
+	chirp, err := cfg.db.AdminUpdateChirp(r.Context(), database.AdminUpdateChirpParams{
+		ID:                 chirpId,
+		EditedBy:           adminId,
+		Body:               sql.NullString{String: body, Valid: true},
+		WordCount:          int32(wordCount),
+		ReadingTimeSeconds: int32(readingTime),
+		SentimentScore:     analytics.SentimentScore(body),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), adminId), "chirp.admin_edited", "chirp", chirp.ID, nil)
+
+	resp := newChirpResp(chirp)
+	resp.LikeCount, resp.ReplyCount = existing.LikeCount, existing.ReplyCount
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAdminEditChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	admin, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"something illegal here"}`, alice)
+	path := "/api/chirps/" + chirp.ID.String()
+
+	rec := serve(h, "PATCH", path, `{"body":"removed by a kerfuffle admin"}`, adminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Body != "removed by a **** admin" || !resp.AdminEdited {
+		t.Errorf("got body %q, admin_edited %v", resp.Body, resp.AdminEdited)
+	}
+	if len(store.chirpVersions) != 1 || store.chirpVersions[0].Body.String != "something illegal here" || store.chirpVersions[0].EditedBy != admin.ID {
+		t.Errorf("got versions %+v, want the original body", store.chirpVersions)
+	}
+	if got := store.auditLogs[len(store.auditLogs)-1].Action; got != "chirp.admin_edited" {
+		t.Errorf("got audit action %q", got)
+	}
+
+	rec = serve(h, "PATCH", path, `{"body":"`+strings.Repeat("x", 141)+`"}`, adminToken)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("too long: got status %d, want 400", rec.Code)
+	}
+}
+
+func TestAdminEditChirpRejectsNonAdmins(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"my own words"}`, alice)
+
+	for _, token := range []string{alice, ""} {
+		rec := serve(h, "PATCH", "/api/chirps/"+chirp.ID.String(), `{"body":"rewritten"}`, token)
+		if token != "" && rec.Code != http.StatusForbidden {
+			t.Errorf("author: got status %d, want 403", rec.Code)
+		}
+		if token == "" && rec.Code != http.StatusUnauthorized {
+			t.Errorf("anonymous: got status %d, want 401", rec.Code)
+		}
+	}
+	if store.chirps[0].Body.String != "my own words" || store.chirps[0].AdminEdited || len(store.chirpVersions) != 0 {
+		t.Error("a rejected edit changed the chirp")
+	}
+}
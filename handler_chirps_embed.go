@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const nsfwPlaceholder = "This chirp may contain sensitive content."
+
+var embedTemplate = template.Must(template.New("embed").Parse(`<meta property="og:title" content="Chirp by {{.Author}}">
+<meta property="og:description" content="{{.Body}}">
+<meta property="og:url" content="{{.URL}}">
+<blockquote class="chirpy-embed">
+<p>{{.Body}}</p>
+&mdash; {{.Author}} <a href="{{.URL}}"><time datetime="{{.CreatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.CreatedAt.Format "Jan 2, 2006"}}</time></a>
+</blockquote>
+<img src="{{.PixelURL}}" width="1" height="1" alt="">
+`))
+
+// embedData fills embedTemplate. Embeds are published on other sites, so
+// Author is the author's user ID rather than anything personal.
+type embedData struct {
+	Author    string
+	Body      string
+	URL       string
+	PixelURL  string
+	CreatedAt time.Time
+}
+
+func (cfg *apiConfig) handlerGetChirpEmbed(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	chirp, err := cfg.anonymousChirp(r, chirpId)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	data := embedData{
+		Author:    chirp.Chirp.UserID.String(),
+		Body:      chirp.Chirp.Body.String,
+		URL:       requestBaseURL(r, cfg) + "/share/chirps/" + chirp.Chirp.ID.String(),
+		PixelURL:  requestBaseURL(r, cfg) + "/pixel/chirps/" + chirp.Chirp.ID.String(),
+		CreatedAt: chirp.Chirp.CreatedAt.Time,
+	}
+	if chirp.Chirp.IsNsfw {
+		data.Body = nsfwPlaceholder
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := embedTemplate.Execute(w, data); err != nil {
+		fmt.Println(err)
+	}
+}
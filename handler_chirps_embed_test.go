@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func postChirp(t *testing.T, h http.Handler, body, token string) chirpResp {
+	t.Helper()
+	rec := serve(h, "POST", "/api/chirps", body, token)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating chirp: got status %d, want 201", rec.Code)
+	}
+	var c chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &c); err != nil {
+		t.Fatalf("decoding chirp: %v", err)
+	}
+	return c
+}
+
+func TestChirpEmbedEscapesBody(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	author, token := seedUser(t, cfg, store, "embed@example.com")
+	chirp := postChirp(t, handler, `{"body":"<script>alert(1)</script>"}`, token)
+
+	rec := serve(handler, "GET", "/api/chirps/"+chirp.ID.String()+"/embed", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("got Content-Type %q", ct)
+	}
+	html := rec.Body.String()
+	if strings.Contains(html, "<script>") {
+		t.Errorf("embed contains unescaped script tag:\n%s", html)
+	}
+	if !strings.Contains(html, "&lt;script&gt;") {
+		t.Errorf("embed missing escaped body:\n%s", html)
+	}
+	for _, tag := range []string{`property="og:title"`, `property="og:description"`, `property="og:url"`, "<blockquote"} {
+		if !strings.Contains(html, tag) {
+			t.Errorf("embed missing %s", tag)
+		}
+	}
+	if strings.Contains(html, "embed@example.com") || !strings.Contains(html, "Chirp by "+author.ID.String()) {
+		t.Errorf("embed should credit the author by ID, not email:\n%s", html)
+	}
+}
+
+func TestChirpEmbedHidesNSFWBody(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "nsfw@example.com")
+	chirp := postChirp(t, handler, `{"body":"something spicy","is_nsfw":true}`, token)
+
+	rec := serve(handler, "GET", "/api/chirps/"+chirp.ID.String()+"/embed", "", "")
+	html := rec.Body.String()
+	if strings.Contains(html, "something spicy") {
+		t.Errorf("embed leaked NSFW body:\n%s", html)
+	}
+	if !strings.Contains(html, nsfwPlaceholder) {
+		t.Errorf("embed missing NSFW placeholder:\n%s", html)
+	}
+}
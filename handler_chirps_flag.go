@@ -0,0 +1,98 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	flagTypeNsfw           = "nsfw"
+	flagTypeContentWarning = "content_warning"
+
+	maxContentWarningLen = 100
+)
+
+type selfFlagParams struct {
+	FlagType string `json:"flag_type"`
+	// Note is the warning shown for content_warning, and is only recorded
+	// in the audit log for nsfw.
+	Note string `json:"note"`
+}
+
+// handlerSelfFlagChirp lets an author mark their own chirp as sensitive
+// after posting it, either as nsfw or behind a content warning. It is
+// separate from admins hiding chirps.
+func (cfg *apiConfig) handlerSelfFlagChirp(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	var params selfFlagParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	params.Note = strings.TrimSpace(params.Note)
+	switch params.FlagType {
+	case flagTypeNsfw:
+	case flagTypeContentWarning:
+		if params.Note == "" || len(params.Note) > maxContentWarningLen {
+			respondWithError(w, http.StatusBadRequest, "a content warning needs a note of at most 100 characters")
+			return
+		}
+	default:
+		respondWithError(w, http.StatusBadRequest, "flag_type must be nsfw or content_warning")
+		return
+	}
+
+	existing, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpId, Namespace: namespaceOf(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if existing.Chirp.UserID != userId {
+		respondWithError(w, http.StatusForbidden, "you can only flag your own chirps")
+		return
+	}
+
+	var chirp database.Chirp
+	if params.FlagType == flagTypeNsfw {
+		chirp, err = cfg.db.SetChirpNsfw(r.Context(), chirpId)
+	} else {
+		chirp, err = cfg.db.SetChirpContentWarning(r.Context(), database.SetChirpContentWarningParams{
+			ID:             chirpId,
+			ContentWarning: sql.NullString{String: params.Note, Valid: true},
+		})
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	details := map[string]string{"flag_type": params.FlagType}
+	if params.Note != "" {
+		details["note"] = params.Note
+	}
+	cfg.audit(withActor(r.Context(), userId), "chirp.self_flagged", "chirp", chirp.ID, details)
+
+	resp := newChirpResp(chirp)
+	resp.LikeCount, resp.ReplyCount = existing.LikeCount, existing.ReplyCount
+	resp.Flagged = flaggedFor(chirp, userId)
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestSelfFlagChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"beach photos"}`, alice)
+	path := "/api/chirps/" + chirp.ID.String() + "/flag"
+
+	rec := serve(h, "POST", path, `{"flag_type":"nsfw","note":"forgot to mark it"}`, alice)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("nsfw: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !resp.IsNsfw || !store.chirps[0].IsNsfw {
+		t.Error("nsfw flag did not set is_nsfw")
+	}
+	last := store.auditLogs[len(store.auditLogs)-1]
+	if last.Action != "chirp.self_flagged" || last.EntityID.UUID != chirp.ID || string(last.Details) != `{"flag_type":"nsfw","note":"forgot to mark it"}` {
+		t.Errorf("got audit entry %s %s %s", last.Action, last.EntityID.UUID, last.Details)
+	}
+
+	rec = serve(h, "POST", path, `{"flag_type":"content_warning","note":"spiders"}`, alice)
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || resp.ContentWarning != "spiders" || !resp.IsNsfw {
+		t.Errorf("content_warning: got %d %+v", rec.Code, resp)
+	}
+	if got := store.auditLogs[len(store.auditLogs)-1].Action; got != "chirp.self_flagged" {
+		t.Errorf("got audit action %q", got)
+	}
+}
+
+func TestSelfFlagChirpRejections(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+	chirp := postChirp(t, h, `{"body":"alice's chirp"}`, alice)
+	path := "/api/chirps/" + chirp.ID.String() + "/flag"
+	entries := len(store.auditLogs)
+
+	cases := []struct {
+		name, path, body, token string
+		want                    int
+	}{
+		{"no token", path, `{"flag_type":"nsfw"}`, "", http.StatusUnauthorized},
+		{"not the author", path, `{"flag_type":"nsfw"}`, bob, http.StatusForbidden},
+		{"unknown type", path, `{"flag_type":"spoiler"}`, alice, http.StatusBadRequest},
+		{"warning without note", path, `{"flag_type":"content_warning"}`, alice, http.StatusBadRequest},
+		{"unknown chirp", "/api/chirps/00000000-0000-0000-0000-000000000000/flag", `{"flag_type":"nsfw"}`, alice, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		if rec := serve(h, "POST", c.path, c.body, c.token); rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+	}
+	if store.chirps[0].IsNsfw || store.chirps[0].ContentWarning.Valid {
+		t.Error("a rejected flag changed the chirp")
+	}
+	if len(store.auditLogs) != entries {
+		t.Errorf("rejected flags wrote %d audit entries", len(store.auditLogs)-entries)
+	}
+}
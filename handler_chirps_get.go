@@ -1,10 +1,14 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"slices"
+	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/azs06/Chirpy/internal/database"
 	"github.com/google/uuid"
@@ -14,10 +18,26 @@ func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	author_id := r.URL.Query().Get("author_id")
 	sort := r.URL.Query().Get("sort")
-	var chirps []database.Chirp
+	byImportance := sort == "importance"
+	verifiedOnly := r.URL.Query().Get("verified_only") == "true"
+	sizeTier := r.URL.Query().Get("size_tier")
+	if sizeTier != "" && sizeTier != sizeTierShort && sizeTier != sizeTierMedium && sizeTier != sizeTierLong {
+		respondWithError(w, http.StatusBadRequest, "size_tier must be short, medium or long")
+		return
+	}
+	minSentiment := -1.0
+	if v := r.URL.Query().Get("min_sentiment"); v != "" {
+		var err error
+		if minSentiment, err = strconv.ParseFloat(v, 64); err != nil || minSentiment < -1 || minSentiment > 1 {
+			respondWithError(w, http.StatusBadRequest, "min_sentiment must be between -1 and 1")
+			return
+		}
+	}
+	includeHidden := cfg.callerIsAdmin(r)
+	viewer, _ := cfg.optionalUserID(r)
+	var resp []chirpResp
 	var err error
 	var author_uuid uuid.UUID
-	resp := make([]chirpResp, 0, len(chirps))
 
 	if author_id != "" {
 		author_uuid, err = uuid.Parse(author_id)
@@ -25,35 +45,96 @@ func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(400)
 			return
 		}
-		chirps, err = cfg.db.GetChirpsByUserId(r.Context(), author_uuid)
+	}
+
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" {
+		prefix := r.URL.Query().Get("prefix") == "true"
+		if prefix {
+			if q = prefixTSQuery(q); q == "" {
+				respondWithJSON(w, http.StatusOK, []chirpResp{})
+				return
+			}
+		}
+		var chirps []database.SearchChirpsRow
+		chirps, err = cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+			Prefix:        prefix,
+			Query:         q,
+			AuthorID:      author_uuid,
+			IncludeHidden: includeHidden,
+			MinSentiment:  minSentiment,
+			VerifiedOnly:  verifiedOnly,
+			ViewerID:      viewer,
+			Namespace:     namespaceOf(r.Context()),
+		})
+		resp = make([]chirpResp, 0, len(chirps))
+		for _, c := range chirps {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			cr.Flagged = flaggedFor(c.Chirp, viewer)
+			cr.SearchRank = &c.Rank
+			resp = append(resp, cr)
+		}
+		// Results are ordered by rank, best first.
+		sort = ""
+	} else if author_id != "" {
+		var chirps []database.GetChirpsByUserIdRow
+		chirps, err = cfg.db.GetChirpsByUserId(r.Context(), database.GetChirpsByUserIdParams{
+			UserID:        author_uuid,
+			ByImportance:  byImportance,
+			IncludeHidden: includeHidden,
+			MinSentiment:  minSentiment,
+			VerifiedOnly:  verifiedOnly,
+			ViewerID:      viewer,
+			Namespace:     namespaceOf(r.Context()),
+		})
+		resp = make([]chirpResp, 0, len(chirps))
+		for _, c := range chirps {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			cr.Flagged = flaggedFor(c.Chirp, viewer)
+			resp = append(resp, cr)
+		}
 	} else {
-		chirps, err = cfg.db.GetChirps(r.Context())
+		var chirps []database.GetChirpsRow
+		chirps, err = cfg.db.GetChirps(r.Context(), database.GetChirpsParams{
+			ByImportance:  byImportance,
+			IncludeHidden: includeHidden,
+			MinSentiment:  minSentiment,
+			VerifiedOnly:  verifiedOnly,
+			ViewerID:      viewer,
+			Namespace:     namespaceOf(r.Context()),
+		})
+		resp = make([]chirpResp, 0, len(chirps))
+		for _, c := range chirps {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			cr.Flagged = flaggedFor(c.Chirp, viewer)
+			resp = append(resp, cr)
+		}
+	}
+	if sizeTier != "" {
+		// The tier is computed rather than stored, so it can't be filtered in SQL.
+		resp = slices.DeleteFunc(resp, func(c chirpResp) bool { return c.SizeTier != sizeTier })
+	}
+	if err == nil {
+		err = cfg.attachMedia(r.Context(), resp)
 	}
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+		cfg.respondWithDBError(w, err)
 		return
 	}
 	if sort == "desc" {
-		slices.Reverse(chirps)
+		slices.Reverse(resp)
 	}
-
-	for _, c := range chirps {
-		resp = append(resp, chirpResp{
-			ID:        c.ID,
-			CreatedAt: c.CreatedAt.Time,
-			UpdatedAt: c.UpdatedAt.Time,
-			Body:      c.Body.String,
-			UserId:    c.UserID.String(),
-		})
+	if wantsRenderedBody(r) {
+		renderBodies(resp)
 	}
-	dat, err := json.Marshal(resp)
-	if err != nil {
-		w.WriteHeader(400)
+	cfg.recordImpressions(r.Context(), chirpIDs(resp))
+	if acceptsNDJSON(r) {
+		writeNDJSON(w, resp)
 		return
 	}
-	w.WriteHeader(200)
-	w.Write(dat)
+	respondWithJSON(w, http.StatusOK, resp)
 }
 
 func (cfg *apiConfig) handlerGetChirpByID(w http.ResponseWriter, r *http.Request) {
@@ -67,20 +148,62 @@ func (cfg *apiConfig) handlerGetChirpByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	chirp, err := cfg.db.GetChirpByID(r.Context(), chirpUUId)
+	chirp, err := cfg.getChirpCoalesced(r.Context(), chirpUUId)
+	if err == nil && chirp.Chirp.IsHidden && !cfg.callerIsAdmin(r) {
+		err = sql.ErrNoRows
+	}
+	viewer, _ := cfg.optionalUserID(r)
+	if err == nil {
+		var visible bool
+		if visible, err = cfg.canViewChirp(r.Context(), chirp.Chirp, viewer); err == nil && !visible {
+			err = sql.ErrNoRows
+		}
+	}
 	if err != nil {
 		fmt.Println(err)
 		w.WriteHeader(404)
 		w.Write([]byte(err.Error()))
 		return
 	}
-	dat, _ := json.Marshal(chirpResp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt.Time,
-		UpdatedAt: chirp.UpdatedAt.Time,
-		Body:      chirp.Body.String,
-		UserId:    chirp.UserID.String(),
+	cfg.recordChirpView(r, chirpUUId)
+	cfg.recordChirpRead(viewer, chirpUUId)
+	resp := newChirpResp(chirp.Chirp)
+	resp.LikeCount, resp.ReplyCount = chirp.LikeCount, chirp.ReplyCount
+	resp.Flagged = flaggedFor(chirp.Chirp, viewer)
+	withMedia := []chirpResp{resp}
+	if err := cfg.attachMedia(r.Context(), withMedia); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if wantsRenderedBody(r) {
+		renderBodies(withMedia)
+	}
+	respondWithJSON(w, http.StatusOK, withMedia[0])
+}
+
+// getChirpCoalesced loads a chirp, sharing one query among concurrent
+// requests for the same ID. The row is identical for every viewer; hidden
+// and visibility checks still run per request.
+func (cfg *apiConfig) getChirpCoalesced(ctx context.Context, id uuid.UUID) (database.GetChirpByIDRow, error) {
+	v, err, _ := cfg.sfGroup.Do(namespaceOf(ctx)+"/"+id.String(), func() (any, error) {
+		// Detached from ctx so one caller hanging up does not fail the rest.
+		return cfg.db.GetChirpByID(context.WithoutCancel(ctx), database.GetChirpByIDParams{ID: id, Namespace: namespaceOf(ctx)})
 	})
-	w.WriteHeader(200)
-	w.Write(dat)
+	if err != nil {
+		return database.GetChirpByIDRow{}, err
+	}
+	return v.(database.GetChirpByIDRow), nil
+}
+
+// prefixTSQuery turns a search box's contents into a to_tsquery expression
+// that matches every word, the last one as a prefix, so "go gen" finds
+// "go generics". Anything but letters and digits is dropped, since the
+// tsquery operators would otherwise reach to_tsquery. It returns "" when no
+// words are left.
+func prefixTSQuery(q string) string {
+	words := strings.FieldsFunc(q, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.Join(words, " & ") + ":*"
 }
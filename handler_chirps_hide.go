@@ -0,0 +1,48 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerHideChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpHidden(w, r, true)
+}
+
+func (cfg *apiConfig) handlerUnhideChirp(w http.ResponseWriter, r *http.Request) {
+	cfg.setChirpHidden(w, r, false)
+}
+
+func (cfg *apiConfig) setChirpHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	chirp, err := cfg.db.SetChirpHidden(r.Context(), database.SetChirpHiddenParams{
+		ID:       chirpId,
+		IsHidden: hidden,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	action := "chirp.hidden"
+	if !hidden {
+		action = "chirp.unhidden"
+	}
+	cfg.audit(withActor(r.Context(), adminId), action, "chirp", chirp.ID, nil)
+	respondWithJSON(w, http.StatusOK, newChirpResp(chirp))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func seedAdmin(t *testing.T, cfg *apiConfig, store *memStore, email string) (database.User, string) {
+	t.Helper()
+	u, token := seedUser(t, cfg, store, email)
+	for i := range store.users {
+		if store.users[i].ID == u.ID {
+			store.users[i].IsAdmin = true
+			u = store.users[i]
+		}
+	}
+	return u, token
+}
+
+func TestHiddenChirpViews(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	_, userToken := seedUser(t, cfg, store, "user@example.com")
+	chirp := postChirp(t, handler, `{"body":"needs review"}`, userToken)
+	postChirp(t, handler, `{"body":"fine"}`, userToken)
+
+	rec := serve(handler, "POST", "/admin/chirps/"+chirp.ID.String()+"/hide", "", adminToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("hide: got status %d, want 200", rec.Code)
+	}
+	if len(store.auditLogs) == 0 || store.auditLogs[len(store.auditLogs)-1].Action != "chirp.hidden" {
+		t.Error("hide did not record a chirp.hidden audit entry")
+	}
+
+	t.Run("regular user", func(t *testing.T) {
+		if rec := serve(handler, "GET", "/api/chirps/"+chirp.ID.String(), "", userToken); rec.Code != http.StatusNotFound {
+			t.Errorf("got status %d, want 404", rec.Code)
+		}
+		var list []chirpResp
+		json.Unmarshal(serve(handler, "GET", "/api/chirps", "", "").Body.Bytes(), &list)
+		if len(list) != 1 || list[0].Body != "fine" {
+			t.Errorf("got %+v, want only the visible chirp", list)
+		}
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		rec := serve(handler, "GET", "/api/chirps/"+chirp.ID.String(), "", adminToken)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200", rec.Code)
+		}
+		var got chirpResp
+		json.Unmarshal(rec.Body.Bytes(), &got)
+		if !got.IsHidden {
+			t.Error("got is_hidden=false, want true")
+		}
+		var list []chirpResp
+		json.Unmarshal(serve(handler, "GET", "/api/chirps", "", adminToken).Body.Bytes(), &list)
+		if len(list) != 2 {
+			t.Errorf("got %d chirps, want 2", len(list))
+		}
+	})
+
+	serve(handler, "DELETE", "/admin/chirps/"+chirp.ID.String()+"/hide", "", adminToken)
+	if rec := serve(handler, "GET", "/api/chirps/"+chirp.ID.String(), "", userToken); rec.Code != http.StatusOK {
+		t.Errorf("after unhide got status %d, want 200", rec.Code)
+	}
+}
+
+func TestHideChirpRequiresAdmin(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "user@example.com")
+	chirp := postChirp(t, handler, `{"body":"mine"}`, token)
+	if rec := serve(handler, "POST", "/admin/chirps/"+chirp.ID.String()+"/hide", "", token); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want 403", rec.Code)
+	}
+	if rec := serve(handler, "POST", "/admin/chirps/"+chirp.ID.String()+"/hide", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("without a token got status %d, want 401", rec.Code)
+	}
+}
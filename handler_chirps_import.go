@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/analytics"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxImportLines     = 10000
+	importBatchSize    = 100
+	maxImportFileBytes = 32 << 20
+)
+
+// importChirpLine is one NDJSON line of an import file. Fields are decoded
+// as strings so each can be reported on separately.
+type importChirpLine struct {
+	ID        string `json:"id"`
+	Body      string `json:"body"`
+	UserID    string `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+type importError struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+type importChirpsResp struct {
+	Imported int           `json:"imported"`
+	Skipped  int           `json:"skipped"`
+	Errors   []importError `json:"errors"`
+}
+
+// pendingImport is a validated chirp waiting for its batch to be inserted.
+type pendingImport struct {
+	line      int
+	id        uuid.UUID
+	userID    uuid.UUID
+	body      string
+	createdAt time.Time
+}
+
+// handlerImportChirps loads chirps from an NDJSON file uploaded in the
+// "file" form field, for migrating data from another platform. Invalid
+// lines are skipped and reported rather than failing the import.
+func (cfg *apiConfig) handlerImportChirps(w http.ResponseWriter, r *http.Request) {
+	if cfg.platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "expected a multipart file field named file")
+		return
+	}
+	defer file.Close()
+
+	resp := importChirpsResp{Errors: []importError{}}
+	skip := func(line int, reason string) {
+		resp.Skipped++
+		resp.Errors = append(resp.Errors, importError{Line: line, Reason: reason})
+	}
+	var batch []pendingImport
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		params := database.ImportChirpsParams{}
+		for _, c := range batch {
+			words, readingTime := chirpMetrics(c.body)
+			params.Ids = append(params.Ids, c.id)
+			params.CreatedAts = append(params.CreatedAts, c.createdAt)
+			params.Bodies = append(params.Bodies, c.body)
+			params.UserIds = append(params.UserIds, c.userID)
+			params.WordCounts = append(params.WordCounts, int32(words))
+			params.ReadingTimes = append(params.ReadingTimes, int32(readingTime))
+			params.SentimentScores = append(params.SentimentScores, analytics.SentimentScore(c.body))
+		}
+		inserted, err := cfg.db.ImportChirps(r.Context(), params)
+		if err != nil {
+			return err
+		}
+		ok := make(map[uuid.UUID]bool, len(inserted))
+		for _, id := range inserted {
+			ok[id] = true
+		}
+		for _, c := range batch {
+			if ok[c.id] {
+				resp.Imported++
+			} else {
+				skip(c.line, "unknown user_id or id already exists")
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line > maxImportLines {
+			respondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("import files are limited to %d lines", maxImportLines))
+			return
+		}
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		c, reason := cfg.parseImportLine(text)
+		if reason == "" && seen[c.id] {
+			reason = "duplicate id in file"
+		}
+		if reason != "" {
+			skip(line, reason)
+			continue
+		}
+		seen[c.id] = true
+		c.line = line
+		batch = append(batch, c)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				cfg.respondWithDBError(w, err)
+				return
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "reading import file: "+err.Error())
+		return
+	}
+	if err := flush(); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// parseImportLine validates one line, returning the reason it was rejected
+// or "" if it can be imported.
+func (cfg *apiConfig) parseImportLine(text string) (pendingImport, string) {
+	var in importChirpLine
+	if err := json.Unmarshal([]byte(text), &in); err != nil {
+		return pendingImport{}, "invalid JSON"
+	}
+	var c pendingImport
+	var err error
+	if c.id, err = uuid.Parse(in.ID); err != nil {
+		return c, "invalid id"
+	}
+	if c.userID, err = uuid.Parse(in.UserID); err != nil {
+		return c, "invalid user_id"
+	}
+	if in.Body == "" {
+		return c, "body is required"
+	}
+	if len(in.Body) > 140 {
+		return c, "Chirp is too long"
+	}
+	if c.createdAt, err = time.Parse(time.RFC3339, in.CreatedAt); err != nil {
+		return c, "created_at must be an RFC 3339 timestamp"
+	}
+	if !c.createdAt.Before(cfg.timeNow()) {
+		return c, "created_at must be in the past"
+	}
+	c.createdAt = c.createdAt.UTC()
+	c.body = sanitize(in.Body)
+	return c, ""
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func importChirps(t *testing.T, h http.Handler, ndjson string) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "chirps.ndjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(ndjson))
+	mw.Close()
+	req := httptest.NewRequest("POST", "/admin/import/chirps", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func importLine(id, userID uuid.UUID, body string, createdAt time.Time) string {
+	dat, _ := json.Marshal(map[string]string{
+		"id":         id.String(),
+		"user_id":    userID.String(),
+		"body":       body,
+		"created_at": createdAt.Format(time.RFC3339),
+	})
+	return string(dat)
+}
+
+func decodeImport(t *testing.T, rec *httptest.ResponseRecorder) importChirpsResp {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp importChirpsResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestImportChirpsValidRecords(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, _ := seedUser(t, cfg, store, "alice@example.com")
+	past := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var lines []string
+	for i := range 250 {
+		lines = append(lines, importLine(uuid.New(), alice.ID, fmt.Sprintf("imported %d", i), past.Add(time.Duration(i)*time.Minute)))
+	}
+	resp := decodeImport(t, importChirps(t, h, strings.Join(lines, "\n")+"\n"))
+	if resp.Imported != 250 || resp.Skipped != 0 || len(resp.Errors) != 0 {
+		t.Fatalf("got %+v, want 250 imported", resp)
+	}
+	if len(store.chirps) != 250 || !store.chirps[0].CreatedAt.Time.Equal(past) || store.chirps[0].UserID != alice.ID {
+		t.Errorf("got %d chirps, first %+v; want them stored with their original timestamps", len(store.chirps), store.chirps[0])
+	}
+
+	again := decodeImport(t, importChirps(t, h, lines[0]))
+	if again.Imported != 0 || again.Skipped != 1 || again.Errors[0].Line != 1 {
+		t.Errorf("got %+v re-importing an existing id, want it skipped", again)
+	}
+}
+
+func TestImportChirpsInvalidRecords(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, _ := seedUser(t, cfg, store, "alice@example.com")
+	past := time.Now().Add(-time.Hour)
+	dupID := uuid.New()
+
+	file := strings.Join([]string{
+		importLine(uuid.New(), alice.ID, "fine", past),
+		`{"body":`,
+		`{"id":"nope","user_id":"` + alice.ID.String() + `","body":"x","created_at":"2020-01-01T00:00:00Z"}`,
+		importLine(uuid.New(), alice.ID, "from the future", time.Now().Add(time.Hour)),
+		importLine(uuid.New(), alice.ID, strings.Repeat("a", 141), past),
+		"",
+		importLine(uuid.New(), uuid.New(), "who wrote this", past),
+		importLine(dupID, alice.ID, "first", past),
+		importLine(dupID, alice.ID, "second", past),
+		`{"id":"` + uuid.NewString() + `","user_id":"` + alice.ID.String() + `","body":"x","created_at":"yesterday"}`,
+	}, "\n")
+	resp := decodeImport(t, importChirps(t, h, file))
+	if resp.Imported != 2 || resp.Skipped != 7 {
+		t.Errorf("got imported %d, skipped %d; want 2 and 7", resp.Imported, resp.Skipped)
+	}
+	want := map[int]string{
+		2:  "invalid JSON",
+		3:  "invalid id",
+		4:  "created_at must be in the past",
+		5:  "Chirp is too long",
+		7:  "unknown user_id or id already exists",
+		9:  "duplicate id in file",
+		10: "created_at must be an RFC 3339 timestamp",
+	}
+	got := map[int]string{}
+	for _, e := range resp.Errors {
+		got[e.Line] = e.Reason
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got errors %v, want %v", got, want)
+	}
+}
+
+func TestImportChirpsRequiresDev(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.platform = "prod"
+	h := newServer("0", cfg).Handler
+	if rec := importChirps(t, h, ""); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d outside dev, want 403", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerLikeChirp(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpUUId, Namespace: namespaceOf(r.Context())})
+	if err != nil || chirp.Chirp.IsHidden {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	visible, err := cfg.canViewChirp(r.Context(), chirp.Chirp, userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	n, err := cfg.db.CreateChirpLike(r.Context(), database.CreateChirpLikeParams{
+		ChirpID: chirpUUId,
+		UserID:  userId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if n > 0 {
+		cfg.notify(r.Context(), chirp.Chirp.UserID, userId, database.NotificationTypeLike, chirpUUId)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnlikeChirp(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+
+	err = cfg.db.DeleteChirpLike(r.Context(), database.DeleteChirpLikeParams{
+		ChirpID: chirpUUId,
+		UserID:  userId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestChirpEngagementCounts(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, authorToken := seedUser(t, cfg, store, "author@example.com")
+
+	rec := serve(handler, "POST", "/api/chirps", `{"body":"popular"}`, authorToken)
+	var chirp chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+		t.Fatalf("decoding chirp: %v", err)
+	}
+	for i := range 3 {
+		_, token := seedUser(t, cfg, store, fmt.Sprintf("fan%d@example.com", i))
+		if rec := serve(handler, "POST", "/api/chirps/"+chirp.ID.String()+"/like", "", token); rec.Code != 204 {
+			t.Fatalf("like: got status %d", rec.Code)
+		}
+		// Liking twice must not double count.
+		serve(handler, "POST", "/api/chirps/"+chirp.ID.String()+"/like", "", token)
+	}
+	for _, reply := range []string{"first reply", "second reply"} {
+		body := `{"body":"` + reply + `","parent_id":"` + chirp.ID.String() + `"}`
+		if rec := serve(handler, "POST", "/api/chirps", body, authorToken); rec.Code != 201 {
+			t.Fatalf("reply: got status %d", rec.Code)
+		}
+	}
+
+	rec = serve(handler, "GET", "/api/chirps/"+chirp.ID.String(), "", "")
+	var got chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding chirp: %v", err)
+	}
+	if got.LikeCount != 3 || got.ReplyCount != 2 {
+		t.Errorf("got like_count=%d reply_count=%d, want 3 and 2", got.LikeCount, got.ReplyCount)
+	}
+
+	rec = serve(handler, "GET", "/api/chirps", "", "")
+	var list []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decoding chirps: %v", err)
+	}
+	if len(list) != 3 || list[0].LikeCount != 3 || list[0].ReplyCount != 2 {
+		t.Errorf("got %+v, want the liked chirp first with counts 3 and 2", list)
+	}
+	if list[1].ParentId == nil || *list[1].ParentId != chirp.ID {
+		t.Errorf("got parent_id=%v, want %v", list[1].ParentId, chirp.ID)
+	}
+}
+
+func TestReplyToUnknownChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	_, token := seedUser(t, cfg, store, "author@example.com")
+	body := `{"body":"reply","parent_id":"00000000-0000-0000-0000-000000000001"}`
+	if rec := serve(newServer("0", cfg).Handler, "POST", "/api/chirps", body, token); rec.Code != 404 {
+		t.Errorf("got status %d, want 404", rec.Code)
+	}
+}
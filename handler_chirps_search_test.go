@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func searchChirps(t *testing.T, h http.Handler, query string) []chirpResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/chirps?"+query, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestSearchChirpsOrdersByRank(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	once := postChirp(t, h, `{"body":"I tried golang once"}`, token)
+	thrice := postChirp(t, h, `{"body":"golang golang golang all day"}`, token)
+	twice := postChirp(t, h, `{"body":"golang is fun, golang is fast"}`, token)
+	postChirp(t, h, `{"body":"nothing to see here"}`, token)
+
+	got := searchChirps(t, h, "q=golang&sort=asc")
+	if len(got) != 3 {
+		t.Fatalf("got %d results, want the 3 chirps mentioning golang", len(got))
+	}
+	for i, want := range []chirpResp{thrice, twice, once} {
+		if got[i].ID != want.ID {
+			t.Errorf("result %d: got %q, want %q", i, got[i].Body, want.Body)
+		}
+		if got[i].SearchRank == nil {
+			t.Errorf("result %d has no search_rank", i)
+		}
+	}
+	if *got[0].SearchRank <= *got[1].SearchRank || *got[1].SearchRank <= *got[2].SearchRank {
+		t.Errorf("got ranks %v, %v, %v, want strictly decreasing", *got[0].SearchRank, *got[1].SearchRank, *got[2].SearchRank)
+	}
+
+	if got := searchChirps(t, h, ""); len(got) != 4 || got[0].SearchRank != nil {
+		t.Errorf("got %d chirps without q, want all 4 with no search_rank", len(got))
+	}
+}
+
+func TestSearchChirpsPrefix(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"go generics are here"}`, token)
+	postChirp(t, h, `{"body":"go general strike"}`, token)
+	postChirp(t, h, `{"body":"generics in java"}`, token)
+
+	if got := searchChirps(t, h, "q="+url.QueryEscape("go gen")+"&prefix=true"); len(got) != 2 {
+		t.Errorf("got %d results for the prefix go gen, want 2", len(got))
+	}
+	if got := searchChirps(t, h, "q=gen"); len(got) != 0 {
+		t.Errorf("got %d results for gen without prefix, want none", len(got))
+	}
+	if got := searchChirps(t, h, "q="+url.QueryEscape("!&|")+"&prefix=true"); len(got) != 0 {
+		t.Errorf("got %d results for operators alone, want none", len(got))
+	}
+}
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"go gen":           "go & gen:*",
+		"  hello  ":        "hello:*",
+		"foo & bar | !baz": "foo & bar & baz:*",
+		"it's":             "it & s:*",
+		"!&|":              "",
+	}
+	for in, want := range tests {
+		if got := prefixTSQuery(in); got != want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
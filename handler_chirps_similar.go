@@ -0,0 +1,153 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSimilarChirps = 10
+	maxSimilarChirps     = 50
+)
+
+type chirpEmbeddingResp struct {
+	ChirpID      uuid.UUID `json:"chirp_id"`
+	ModelVersion string    `json:"model_version"`
+	Dimensions   int       `json:"dimensions"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// handlerEmbedChirp computes and stores a chirp's embedding, replacing any
+// earlier one.
+func (cfg *apiConfig) handlerEmbedChirp(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpId, Namespace: namespaceOf(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	vec, err := cfg.embedder.Embed(r.Context(), chirp.Chirp.Body.String)
+	if errors.Is(err, errEmbeddingNotConfigured) {
+		respondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if err == nil && len(vec) != embeddingDimensions {
+		err = errors.New("embedder returned " + strconv.Itoa(len(vec)) + " dimensions")
+	}
+	if err != nil {
+		log.Printf("Error embedding chirp %s: %s", chirpId, err)
+		respondWithError(w, http.StatusBadGateway, "embedding failed")
+		return
+	}
+	stored, err := cfg.db.UpsertChirpEmbedding(r.Context(), database.UpsertChirpEmbeddingParams{
+		ChirpID:      chirpId,
+		Embedding:    formatVector(vec),
+		ModelVersion: cfg.embeddingModel,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, chirpEmbeddingResp{
+		ChirpID:      stored.ChirpID,
+		ModelVersion: stored.ModelVersion,
+		Dimensions:   len(vec),
+		CreatedAt:    stored.CreatedAt,
+	})
+}
+
+// handlerGetSimilarChirps returns the chirps whose embeddings are closest
+// to this one's, most similar first. Without pgvector (USE_PGVECTOR unset)
+// it returns a random sample instead, with no similarity scores.
+func (cfg *apiConfig) handlerGetSimilarChirps(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	limit := defaultSimilarChirps
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 || limit > maxSimilarChirps {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxSimilarChirps))
+			return
+		}
+	}
+	viewer, err := cfg.optionalUserID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if _, visible, err := cfg.visibleChirp(r, chirpId, viewer); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	} else if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	var resp []chirpResp
+	if cfg.usePgvector {
+		rows, err := cfg.db.GetSimilarChirps(r.Context(), database.GetSimilarChirpsParams{
+			ChirpID:   chirpId,
+			Namespace: namespaceOf(r.Context()),
+			ViewerID:  viewer,
+			MaxChirps: int32(limit),
+		})
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		resp = make([]chirpResp, 0, len(rows))
+		for _, c := range rows {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			cr.Flagged = flaggedFor(c.Chirp, viewer)
+			cr.Similarity = &c.Similarity
+			resp = append(resp, cr)
+		}
+	} else {
+		rows, err := cfg.db.GetRandomChirps(r.Context(), database.GetRandomChirpsParams{
+			ExcludeID: chirpId,
+			Namespace: namespaceOf(r.Context()),
+			ViewerID:  viewer,
+			MaxChirps: int32(limit),
+		})
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		resp = make([]chirpResp, 0, len(rows))
+		for _, c := range rows {
+			cr := newChirpResp(c.Chirp)
+			cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+			cr.Flagged = flaggedFor(c.Chirp, viewer)
+			resp = append(resp, cr)
+		}
+	}
+	if err := cfg.attachMedia(r.Context(), resp); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if wantsRenderedBody(r) {
+		renderBodies(resp)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
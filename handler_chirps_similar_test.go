@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockEmbedder(t *testing.T) {
+	e := &MockEmbedder{}
+	a, _ := e.Embed(t.Context(), "Cats are great!")
+	b, _ := e.Embed(t.Context(), "cats are GREAT")
+	if len(a) != embeddingDimensions {
+		t.Fatalf("got %d dimensions, want %d", len(a), embeddingDimensions)
+	}
+	var norm float64
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("embeddings differ at %d for the same words", i)
+		}
+		norm += float64(a[i] * a[i])
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Errorf("got squared norm %v, want 1", norm)
+	}
+	if e.Calls != 2 {
+		t.Errorf("got %d calls, want 2", e.Calls)
+	}
+}
+
+func getSimilar(t *testing.T, h http.Handler, id string) []chirpResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/chirps/"+id+"/similar", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var got []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestSimilarChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	embedder := &MockEmbedder{}
+	cfg.embedder, cfg.embeddingModel, cfg.usePgvector = embedder, mockEmbeddingModel, true
+	h := newServer("0", cfg).Handler
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	cats := postChirp(t, h, `{"body":"cats are great pets"}`, alice)
+	moreCats := postChirp(t, h, `{"body":"cats make great pets too"}`, alice)
+	stocks := postChirp(t, h, `{"body":"stock market news today"}`, alice)
+	postChirp(t, h, `{"body":"never embedded"}`, alice)
+
+	if rec := serve(h, "POST", "/api/chirps/"+cats.ID.String()+"/embed", "", alice); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d embedding as a non-admin, want 403", rec.Code)
+	}
+	for _, c := range []chirpResp{cats, moreCats, stocks} {
+		rec := serve(h, "POST", "/api/chirps/"+c.ID.String()+"/embed", "", admin)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp chirpEmbeddingResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.ModelVersion != mockEmbeddingModel || resp.Dimensions != embeddingDimensions {
+			t.Errorf("got %+v", resp)
+		}
+	}
+	if embedder.Calls != 3 {
+		t.Errorf("got %d embed calls, want 3", embedder.Calls)
+	}
+	if got := strings.Count(store.embeddings[cats.ID], ",") + 1; got != embeddingDimensions {
+		t.Errorf("stored %d dimensions, want %d", got, embeddingDimensions)
+	}
+
+	got := getSimilar(t, h, cats.ID.String())
+	if len(got) != 2 || got[0].ID != moreCats.ID || got[1].ID != stocks.ID {
+		t.Fatalf("got %d similar chirps: %+v", len(got), got)
+	}
+	if got[0].Similarity == nil || got[1].Similarity == nil || *got[0].Similarity <= *got[1].Similarity {
+		t.Errorf("got similarities %v, %v, want the first higher", got[0].Similarity, got[1].Similarity)
+	}
+}
+
+func TestSimilarChirpsFallback(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	cats := postChirp(t, h, `{"body":"cats are great pets"}`, alice)
+	postChirp(t, h, `{"body":"dogs are great pets"}`, alice)
+	postChirp(t, h, `{"body":"birds are great pets"}`, alice)
+
+	if rec := serve(h, "POST", "/api/chirps/"+cats.ID.String()+"/embed", "", admin); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d without an embedder, want 503", rec.Code)
+	}
+	got := getSimilar(t, h, cats.ID.String())
+	if len(got) != 2 {
+		t.Fatalf("got %d chirps, want the 2 others", len(got))
+	}
+	for _, c := range got {
+		if c.ID == cats.ID || c.Similarity != nil {
+			t.Errorf("got %+v in the random sample", c)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type chirpStatsResp struct {
+	Likes     int64 `json:"likes"`
+	Reposts   int64 `json:"reposts"`
+	Replies   int64 `json:"replies"`
+	Bookmarks int64 `json:"bookmarks"`
+	Views     int64 `json:"views"`
+}
+
+// handlerGetChirpStats reports engagement counts for a chirp. Views lag by up
+// to chirpViewFlushInterval because they are buffered in memory. Chirpy has
+// no reposts or bookmarks yet, so those are always zero.
+func (cfg *apiConfig) handlerGetChirpStats(w http.ResponseWriter, r *http.Request) {
+	chirpID, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpID, Namespace: namespaceOf(r.Context())})
+	if err != nil || (chirp.Chirp.IsHidden && !cfg.callerIsAdmin(r)) {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	viewer, _ := cfg.optionalUserID(r)
+	visible, err := cfg.canViewChirp(r.Context(), chirp.Chirp, viewer)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	views, err := cfg.db.GetChirpViewCount(r.Context(), chirpID)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, chirpStatsResp{
+		Likes:   chirp.LikeCount,
+		Replies: chirp.ReplyCount,
+		Views:   views,
+	})
+}
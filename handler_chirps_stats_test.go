@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// flakyViewStore fails RecordChirpViews with err until err is cleared.
+type flakyViewStore struct {
+	*memStore
+	err error
+}
+
+func (s *flakyViewStore) RecordChirpViews(ctx context.Context, arg database.RecordChirpViewsParams) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.memStore.RecordChirpViews(ctx, arg)
+}
+
+func viewChirp(h http.Handler, id, token, remoteAddr string) {
+	req := httptest.NewRequest("GET", "/api/chirps/"+id, nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func getStats(t *testing.T, h http.Handler, id string) chirpStatsResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/chirps/"+id+"/stats", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var stats chirpStatsResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	return stats
+}
+
+func TestChirpViewsFlush(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+	c := postChirp(t, h, `{"body":"hello"}`, alice)
+	id := c.ID.String()
+	serve(h, "POST", "/api/chirps/"+id+"/like", "", bob)
+
+	viewChirp(h, id, bob, "192.0.2.1:1000")
+	viewChirp(h, id, bob, "192.0.2.2:1000")
+	viewChirp(h, id, "", "198.51.100.7:1000")
+	viewChirp(h, id, "", "198.51.100.7:2000")
+	viewChirp(h, id, "", "198.51.100.8:1000")
+
+	if got := getStats(t, h, id).Views; got != 0 {
+		t.Errorf("views before flush: got %d, want 0", got)
+	}
+	n, err := cfg.flushChirpViews(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bob once, plus two distinct anonymous addresses.
+	if n != 3 {
+		t.Errorf("flushed %d views, want 3", n)
+	}
+	stats := getStats(t, h, id)
+	if stats.Views != 3 || stats.Likes != 1 || stats.Replies != 0 {
+		t.Errorf("got stats %+v, want 3 views and 1 like", stats)
+	}
+
+	if n, _ := cfg.flushChirpViews(t.Context()); n != 0 {
+		t.Errorf("second flush wrote %d views, want 0", n)
+	}
+	viewChirp(h, id, bob, "192.0.2.1:1000")
+	cfg.flushChirpViews(t.Context())
+	if got := getStats(t, h, id).Views; got != 3 {
+		t.Errorf("repeat view counted again: got %d views, want 3", got)
+	}
+	for _, v := range store.views {
+		if v.ViewerKey == "ip:198.51.100.7" {
+			t.Error("raw client IP stored as viewer key")
+		}
+	}
+}
+
+func TestChirpViewsFlushRetriesOnError(t *testing.T) {
+	store := &flakyViewStore{memStore: newMemStore(), err: errors.New("boom")}
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store.memStore, "alice@example.com")
+	c := postChirp(t, h, `{"body":"hello"}`, alice)
+	viewChirp(h, c.ID.String(), alice, "192.0.2.1:1000")
+
+	if _, err := cfg.flushChirpViews(t.Context()); err == nil {
+		t.Fatal("expected flush to fail")
+	}
+	store.err = nil
+	n, err := cfg.flushChirpViews(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("retried flush wrote %d views, want 1", n)
+	}
+}
+
+func TestChirpStatsHidden(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"hello"}`, alice)
+	for i := range store.chirps {
+		store.chirps[i].IsHidden = true
+	}
+	if rec := serve(h, "GET", "/api/chirps/"+c.ID.String()+"/stats", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("hidden chirp: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "GET", "/api/chirps/not-a-uuid/stats", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad id: got status %d, want 400", rec.Code)
+	}
+}
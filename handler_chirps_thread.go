@@ -0,0 +1,170 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxThreadDepth       = 10
+	maxThreadDescendants = 100
+)
+
+// threadChirpResp is a chirp placed in a thread relative to the requested
+// chirp: 0 for the chirp itself, negative for ancestors and positive for
+// replies.
+type threadChirpResp struct {
+	chirpResp
+	Depth int `json:"depth"`
+}
+
+type threadResp struct {
+	Root        threadChirpResp   `json:"root"`
+	Ancestors   []threadChirpResp `json:"ancestors"`
+	Descendants []threadChirpResp `json:"descendants"`
+}
+
+// handlerGetChirpThread returns a chirp with the chain of chirps it replies
+// to, outermost first, and its replies breadth first down to maxThreadDepth
+// levels. Chirps the caller cannot see are left out, along with the rest of
+// the thread beyond them.
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	chirpUUId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	viewer, err := cfg.optionalUserID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	root, visible, err := cfg.visibleChirp(r, chirpUUId, viewer)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	members, err := cfg.db.GetThreadChirps(r.Context(), database.GetThreadChirpsParams{
+		RootID:    root.Chirp.RootID,
+		ViewerID:  viewer,
+		Namespace: namespaceOf(r.Context()),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	ancestors, descendants := threadAround(root.Chirp, members)
+
+	// ancestors runs from the parent up; the response lists them outermost
+	// first, with the parent at depth -1.
+	slices.Reverse(ancestors)
+	chirps := make([]chirpResp, 0, 1+len(ancestors)+len(descendants))
+	add := func(c database.Chirp, likes, replies int64) {
+		cr := newChirpResp(c)
+		cr.LikeCount, cr.ReplyCount = likes, replies
+		cr.Flagged = flaggedFor(c, viewer)
+		chirps = append(chirps, cr)
+	}
+	add(root.Chirp, root.LikeCount, root.ReplyCount)
+	for _, c := range ancestors {
+		add(c.Chirp, c.LikeCount, c.ReplyCount)
+	}
+	for _, c := range descendants {
+		add(c.Chirp, c.LikeCount, c.ReplyCount)
+	}
+	if err := cfg.attachMedia(r.Context(), chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+
+	resp := threadResp{
+		Root:        threadChirpResp{chirpResp: chirps[0]},
+		Ancestors:   make([]threadChirpResp, 0, len(ancestors)),
+		Descendants: make([]threadChirpResp, 0, len(descendants)),
+	}
+	for i, cr := range chirps[1 : 1+len(ancestors)] {
+		resp.Ancestors = append(resp.Ancestors, threadChirpResp{chirpResp: cr, Depth: i - len(ancestors)})
+	}
+	for i, cr := range chirps[1+len(ancestors):] {
+		resp.Descendants = append(resp.Descendants, threadChirpResp{chirpResp: cr, Depth: descendants[i].depth})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// visibleChirp loads a chirp and reports whether viewer may see it, treating
+// missing and hidden chirps as not visible.
+func (cfg *apiConfig) visibleChirp(r *http.Request, id, viewer uuid.UUID) (database.GetChirpByIDRow, bool, error) {
+	c, err := cfg.getChirpCoalesced(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, false, nil
+	}
+	if err != nil {
+		return c, false, err
+	}
+	if c.Chirp.IsHidden {
+		return c, false, nil
+	}
+	visible, err := cfg.canViewChirp(r.Context(), c.Chirp, viewer)
+	return c, visible, err
+}
+
+// threadDescendant is a reply below the requested chirp, depth levels down.
+type threadDescendant struct {
+	database.GetThreadChirpsRow
+	depth int
+}
+
+// threadAround places target within the visible members of its thread. It
+// returns target's ancestors from its parent up, stopping at the first one
+// missing from members, and its replies breadth first in creation order,
+// leaving out any whose parent is missing. Replies stop after
+// maxThreadDepth levels or maxThreadDescendants chirps.
+func threadAround(target database.Chirp, members []database.GetThreadChirpsRow) ([]database.GetThreadChirpsRow, []threadDescendant) {
+	byID := make(map[uuid.UUID]database.GetThreadChirpsRow, len(members))
+	children := map[uuid.UUID][]database.GetThreadChirpsRow{}
+	for _, m := range members {
+		byID[m.Chirp.ID] = m
+		if m.Chirp.ParentID.Valid {
+			children[m.Chirp.ParentID.UUID] = append(children[m.Chirp.ParentID.UUID], m)
+		}
+	}
+
+	var ancestors []database.GetThreadChirpsRow
+	seen := map[uuid.UUID]bool{target.ID: true}
+	for parent := target.ParentID; parent.Valid && !seen[parent.UUID]; {
+		seen[parent.UUID] = true
+		c, ok := byID[parent.UUID]
+		if !ok {
+			break
+		}
+		ancestors = append(ancestors, c)
+		parent = c.Chirp.ParentID
+	}
+
+	var descendants []threadDescendant
+	level := []uuid.UUID{target.ID}
+	for depth := 1; depth <= maxThreadDepth && len(level) > 0; depth++ {
+		var next []uuid.UUID
+		for _, id := range level {
+			for _, c := range children[id] {
+				if len(descendants) == maxThreadDescendants {
+					return ancestors, descendants
+				}
+				descendants = append(descendants, threadDescendant{GetThreadChirpsRow: c, depth: depth})
+				next = append(next, c.Chirp.ID)
+			}
+		}
+		level = next
+	}
+	return ancestors, descendants
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func getThread(t *testing.T, h http.Handler, id, token string) threadResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/chirps/"+id+"/thread", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp threadResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func threadSummary(chirps []threadChirpResp) []string {
+	var out []string
+	for _, c := range chirps {
+		out = append(out, fmt.Sprintf("%s@%d", c.Body, c.Depth))
+	}
+	return out
+}
+
+func TestChirpThread(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+	reply := func(parent chirpResp, body, token string) chirpResp {
+		return postChirp(t, h, fmt.Sprintf(`{"body":%q,"parent_id":%q}`, body, parent.ID), token)
+	}
+
+	top := postChirp(t, h, `{"body":"top"}`, alice)
+	mid := reply(top, "mid", bob)
+	leaf := reply(mid, "leaf", alice)
+	reply(leaf, "deepest", bob)
+	reply(top, "sibling", bob)
+	secret := postChirp(t, h, fmt.Sprintf(`{"body":"secret","parent_id":%q,"visibility":"mutual"}`, mid.ID), alice)
+	reply(secret, "under secret", alice)
+
+	got := getThread(t, h, mid.ID.String(), "")
+	if got.Root.ID != mid.ID || got.Root.Depth != 0 {
+		t.Errorf("got root %+v, want mid at depth 0", got.Root)
+	}
+	if s := fmt.Sprint(threadSummary(got.Ancestors)); s != "[top@-1]" {
+		t.Errorf("got ancestors %s, want [top@-1]", s)
+	}
+	if s := fmt.Sprint(threadSummary(got.Descendants)); s != "[leaf@1 deepest@2]" {
+		t.Errorf("got descendants %s, want the secret branch left out", s)
+	}
+
+	got = getThread(t, h, top.ID.String(), alice)
+	if len(got.Ancestors) != 0 {
+		t.Errorf("got ancestors %v for a top-level chirp, want none", threadSummary(got.Ancestors))
+	}
+	want := "[mid@1 sibling@1 leaf@2 secret@2 deepest@3 under secret@3]"
+	if s := fmt.Sprint(threadSummary(got.Descendants)); s != want {
+		t.Errorf("got descendants %s, want %s", s, want)
+	}
+
+	got = getThread(t, h, leaf.ID.String(), "")
+	if s := fmt.Sprint(threadSummary(got.Ancestors)); s != "[top@-2 mid@-1]" {
+		t.Errorf("got ancestors %s, want [top@-2 mid@-1]", s)
+	}
+
+	if rec := serve(h, "GET", "/api/chirps/"+secret.ID.String()+"/thread", "", bob); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for a thread the caller cannot see, want 404", rec.Code)
+	}
+}
+
+func TestDeepThreadSharesRootID(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	top := postChirp(t, h, `{"body":"level 0"}`, alice)
+	chain := []chirpResp{top}
+	for i := 1; i <= 6; i++ {
+		parent := chain[len(chain)-1]
+		chain = append(chain, postChirp(t, h, fmt.Sprintf(`{"body":"level %d","parent_id":%q}`, i, parent.ID), alice))
+	}
+	postChirp(t, h, `{"body":"another thread"}`, alice)
+
+	for _, c := range store.chirps {
+		inThread := c.Body.String != "another thread"
+		if got := c.RootID == top.ID; got != inThread {
+			t.Errorf("chirp %q: root_id == top is %v, want %v", c.Body.String, got, inThread)
+		}
+	}
+
+	got := getThread(t, h, chain[3].ID.String(), "")
+	if s := fmt.Sprint(threadSummary(got.Ancestors)); s != "[level 0@-3 level 1@-2 level 2@-1]" {
+		t.Errorf("got ancestors %s", s)
+	}
+	if s := fmt.Sprint(threadSummary(got.Descendants)); s != "[level 4@1 level 5@2 level 6@3]" {
+		t.Errorf("got descendants %s", s)
+	}
+}
+
+func TestReplyDepthLimit(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.maxReplyDepth = 3
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	chain := []chirpResp{postChirp(t, h, `{"body":"depth 0"}`, alice)}
+	for i := 1; i <= 3; i++ {
+		parent := chain[len(chain)-1]
+		chain = append(chain, postChirp(t, h, fmt.Sprintf(`{"body":"depth %d","parent_id":%q}`, i, parent.ID), alice))
+	}
+	for i, c := range store.chirps {
+		if c.ThreadDepth != int32(i) {
+			t.Errorf("chirp %q: got thread_depth %d, want %d", c.Body.String, c.ThreadDepth, i)
+		}
+	}
+
+	rec := serve(h, "POST", "/api/chirps", fmt.Sprintf(`{"body":"depth 4","parent_id":%q}`, chain[3].ID), alice)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"error":"maximum thread depth reached"`) {
+		t.Errorf("reply to depth 3: got %d %s, want 400", rec.Code, rec.Body.String())
+	}
+	postChirp(t, h, fmt.Sprintf(`{"body":"another depth 3","parent_id":%q}`, chain[2].ID), alice)
+
+	cfg.maxReplyDepth = 0
+	postChirp(t, h, fmt.Sprintf(`{"body":"unlimited depth 4","parent_id":%q}`, chain[3].ID), alice)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type translationResp struct {
+	Original       string `json:"original"`
+	Translated     string `json:"translated"`
+	TargetLanguage string `json:"target_language"`
+}
+
+type translateChirpParams struct {
+	TargetLanguage string `json:"target_language"`
+}
+
+// handlerTranslateChirp returns a chirp translated into target_language.
+// Translations are stored in chirp_translations so each chirp is only sent
+// to the translator once per language.
+func (cfg *apiConfig) handlerTranslateChirp(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	var params translateChirpParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	lang := strings.ToLower(params.TargetLanguage)
+	if !isISO6391(lang) {
+		respondWithError(w, http.StatusBadRequest, "target_language must be an ISO 639-1 code")
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpId, Namespace: namespaceOf(r.Context())})
+	if err != nil || chirp.Chirp.IsHidden {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	visible, err := cfg.canViewChirp(r.Context(), chirp.Chirp, userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+	resp := translationResp{Original: chirp.Chirp.Body.String, TargetLanguage: lang}
+
+	resp.Translated, err = cfg.db.GetChirpTranslation(r.Context(), database.GetChirpTranslationParams{
+		ChirpID:  chirpId,
+		Language: lang,
+	})
+	if err == nil {
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+
+	resp.Translated, err = cfg.translator.Translate(r.Context(), resp.Original, lang)
+	if errors.Is(err, errTranslationNotImplemented) {
+		respondWithError(w, http.StatusNotImplemented, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Error translating chirp %s: %s", chirpId, err)
+		respondWithError(w, http.StatusBadGateway, "translation failed")
+		return
+	}
+	err = cfg.db.CreateChirpTranslation(r.Context(), database.CreateChirpTranslationParams{
+		ChirpID:        chirpId,
+		Language:       lang,
+		TranslatedBody: resp.Translated,
+	})
+	if err != nil {
+		log.Printf("Error caching translation of chirp %s: %s", chirpId, err)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTranslateChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	translator := &MockTranslator{}
+	cfg.translator = translator
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"hello world"}`, token)
+	path := "/api/chirps/" + c.ID.String() + "/translate"
+
+	for range 2 {
+		rec := serve(h, "POST", path, `{"target_language":"ES"}`, token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200", rec.Code)
+		}
+		var resp translationResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		want := translationResp{Original: "hello world", Translated: "[es] hello world", TargetLanguage: "es"}
+		if resp != want {
+			t.Errorf("got %+v, want %+v", resp, want)
+		}
+	}
+	if translator.Calls != 1 {
+		t.Errorf("translator called %d times, want 1 (second request should hit the cache)", translator.Calls)
+	}
+}
+
+func TestTranslateChirpErrors(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.translator = &MockTranslator{}
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"hello"}`, token)
+	path := "/api/chirps/" + c.ID.String() + "/translate"
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		token      string
+		wantStatus int
+	}{
+		{"no auth", path, `{"target_language":"es"}`, "", http.StatusUnauthorized},
+		{"unknown language", path, `{"target_language":"xx"}`, token, http.StatusBadRequest},
+		{"three letter code", path, `{"target_language":"spa"}`, token, http.StatusBadRequest},
+		{"missing language", path, `{}`, token, http.StatusBadRequest},
+		{"bad chirp id", "/api/chirps/nope/translate", `{"target_language":"es"}`, token, http.StatusBadRequest},
+		{"unknown chirp", "/api/chirps/00000000-0000-0000-0000-000000000001/translate", `{"target_language":"es"}`, token, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := serve(h, "POST", tt.path, tt.body, tt.token); rec.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTranslateChirpNotConfigured(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.translator = newTranslator("")
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"hello"}`, token)
+	rec := serve(h, "POST", "/api/chirps/"+c.ID.String()+"/translate", `{"target_language":"fr"}`, token)
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("got status %d, want 501", rec.Code)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "net/http"
+
+// echoedHeaders are the request headers GET /api/debug/echo reports,
+// verbatim unless redacted.
+var echoedHeaders = []string{"Authorization", "X-Request-Id", "Accept", "User-Agent"}
+
+// redactedHeaders are echoed with their value hidden.
+var redactedHeaders = map[string]bool{"Authorization": true}
+
+const redactedValue = "[REDACTED]"
+
+type debugEchoResp struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+}
+
+// echoHeaders returns the echoedHeaders present in h, with secrets
+// redacted. Every other header is left out.
+func echoHeaders(h http.Header) map[string]string {
+	out := map[string]string{}
+	for _, name := range echoedHeaders {
+		v := h.Get(name)
+		if v == "" {
+			continue
+		}
+		if redactedHeaders[name] {
+			v = redactedValue
+		}
+		out[name] = v
+	}
+	return out
+}
+
+// handlerDebugEcho shows a client which of its headers reached the server.
+// It is only registered on the dev platform.
+func (cfg *apiConfig) handlerDebugEcho(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, debugEchoResp{
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Headers: echoHeaders(r.Header),
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEchoHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret-token")
+	h.Set("X-Request-Id", "req-1")
+	h.Set("Accept", "application/json")
+	h.Set("User-Agent", "chirpy-cli/1.0")
+	h.Set("Cookie", "chirpy_auth=secret")
+	h.Set("X-Api-Key", "secret")
+
+	want := map[string]string{
+		"Authorization": "[REDACTED]",
+		"X-Request-Id":  "req-1",
+		"Accept":        "application/json",
+		"User-Agent":    "chirpy-cli/1.0",
+	}
+	if got := echoHeaders(h); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	h = http.Header{}
+	h.Set("Authorization", "ApiKey whatever")
+	if got := echoHeaders(h)["Authorization"]; got != "[REDACTED]" {
+		t.Errorf("got Authorization %q, want it redacted whatever the scheme", got)
+	}
+}
+
+func TestDebugEchoDevOnly(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	req := httptest.NewRequest("GET", "/api/debug/echo", nil)
+	req.Header.Set("User-Agent", "chirpy-cli/1.0")
+	rec := httptest.NewRecorder()
+	newServer("0", cfg).Handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d on dev, want 200", rec.Code)
+	}
+	var resp debugEchoResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Method != "GET" || resp.Path != "/api/debug/echo" || resp.Headers["User-Agent"] != "chirpy-cli/1.0" {
+		t.Errorf("got %+v", resp)
+	}
+
+	cfg.platform = "prod"
+	if rec := serve(newServer("0", cfg).Handler, "GET", "/api/debug/echo", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d off dev, want 404", rec.Code)
+	}
+}
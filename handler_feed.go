@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type homeFeedResp struct {
+	Chirps     []chirpResp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// handlerGetHomeFeed returns the caller's chirps and those of everyone they
+// follow, newest first. Pages are cached for feedCacheTTL.
+func (cfg *apiConfig) handlerGetHomeFeed(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.GetHomeFeedParams{
+		UserID:          userId,
+		CursorCreatedAt: farFuture,
+		CursorID:        uuid.Max,
+		PageSize:        pageSize + 1,
+		Namespace:       namespaceOf(r.Context()),
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		params.CursorCreatedAt, params.CursorID, err = decodeCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	key := newFeedCacheKey(userId, r)
+	w.Header().Set("Content-Type", "application/json")
+	if entry, ok := cfg.cachedFeed(r, key); ok {
+		cfg.recordImpressions(r.Context(), entry.chirpIDs)
+		w.WriteHeader(http.StatusOK)
+		w.Write(entry.body)
+		return
+	}
+
+	chirps, err := cfg.db.GetHomeFeed(r.Context(), params)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := homeFeedResp{Chirps: make([]chirpResp, 0, len(chirps))}
+	if len(chirps) > int(pageSize) {
+		chirps = chirps[:pageSize]
+		last := chirps[len(chirps)-1].Chirp
+		resp.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	for _, c := range chirps {
+		cr := newChirpResp(c.Chirp)
+		cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+		cr.Flagged = flaggedFor(c.Chirp, userId)
+		resp.Chirps = append(resp.Chirps, cr)
+	}
+	if err := cfg.attachMedia(r.Context(), resp.Chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	stop := startWriterPhase(w, "marshal")
+	dat, err := json.Marshal(resp)
+	stop()
+	if err != nil {
+		log.Printf("Error marshalling JSON: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	ids := chirpIDs(resp.Chirps)
+	cfg.storeFeed(key, dat, ids)
+	cfg.recordImpressions(r.Context(), ids)
+	w.WriteHeader(http.StatusOK)
+	w.Write(dat)
+}
+
+// invalidateFollowerFeeds drops the cached home feeds that a new chirp by
+// authorID would appear in. A failed lookup only costs staleness up to
+// feedCacheTTL, so it is logged rather than returned.
+func (cfg *apiConfig) invalidateFollowerFeeds(ctx context.Context, authorID uuid.UUID) {
+	followers, err := cfg.db.GetFollowers(ctx, database.GetFollowersParams{FolloweeID: authorID, Namespace: namespaceOf(ctx)})
+	if err != nil {
+		log.Printf("Error loading followers of %s for feed invalidation: %s", authorID, err)
+	}
+	ids := make([]uuid.UUID, 0, len(followers)+1)
+	ids = append(ids, authorID)
+	for _, f := range followers {
+		ids = append(ids, f.ID)
+	}
+	cfg.invalidateFeeds(ids...)
+}
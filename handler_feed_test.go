@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func getHomeFeed(t *testing.T, h http.Handler, token string, noCache bool) homeFeedResp {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/api/feed", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if noCache {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var resp homeFeedResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestHomeFeedCache(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	postChirp(t, h, `{"body":"first"}`, aliceToken)
+
+	if got := len(getHomeFeed(t, h, bobToken, false).Chirps); got != 1 {
+		t.Fatalf("got %d chirps, want 1", got)
+	}
+
+	// A chirp written behind the handler's back is invisible until the
+	// cached page expires or is bypassed.
+	store.chirps = append(store.chirps, database.Chirp{ID: uuid.New(), UserID: alice.ID, CreatedAt: nullNow()})
+	if got := len(getHomeFeed(t, h, bobToken, false).Chirps); got != 1 {
+		t.Errorf("cache hit: got %d chirps, want 1", got)
+	}
+	if got := len(getHomeFeed(t, h, bobToken, true).Chirps); got != 2 {
+		t.Errorf("no-cache: got %d chirps, want 2", got)
+	}
+
+	key := feedCacheKey{userID: uuid.Nil}
+	cfg.feedCache.Range(func(k, _ any) bool {
+		key = k.(feedCacheKey)
+		return false
+	})
+	cfg.feedCache.Store(key, &feedCacheEntry{body: []byte(`{"chirps":[]}`), expiresAt: time.Now().Add(-time.Second)})
+	if got := len(getHomeFeed(t, h, bobToken, false).Chirps); got != 2 {
+		t.Errorf("expired entry: got %d chirps, want 2", got)
+	}
+}
+
+func TestHomeFeedCacheInvalidatedOnChirp(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	carol, carolToken := seedUser(t, cfg, store, "carol@example.com")
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+
+	getHomeFeed(t, h, bobToken, false)
+	getHomeFeed(t, h, carolToken, false)
+	getHomeFeed(t, h, aliceToken, false)
+	postChirp(t, h, `{"body":"hello followers"}`, aliceToken)
+
+	if got := len(getHomeFeed(t, h, bobToken, false).Chirps); got != 1 {
+		t.Errorf("follower feed: got %d chirps, want 1", got)
+	}
+	if got := len(getHomeFeed(t, h, aliceToken, false).Chirps); got != 1 {
+		t.Errorf("author feed: got %d chirps, want 1", got)
+	}
+
+	carolCached := false
+	cfg.feedCache.Range(func(k, _ any) bool {
+		carolCached = carolCached || k.(feedCacheKey).userID == carol.ID
+		return true
+	})
+	if !carolCached {
+		t.Error("feed of a non-follower was invalidated")
+	}
+}
+
+func TestHomeFeedRequiresAuth(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	if rec := serve(newServer("0", cfg).Handler, "GET", "/api/feed", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", rec.Code)
+	}
+}
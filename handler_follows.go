@@ -0,0 +1,298 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// followUserResp is a user in a public follow list. Email is left empty so
+// the lists cannot be used to look up addresses.
+type followUserResp struct {
+	userResp
+	IsMutual bool `json:"is_mutual"`
+}
+
+type followUsersResp struct {
+	Users      []followUserResp `json:"users"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	followerId := user.ID
+	followeeId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if followeeId == followerId {
+		respondWithError(w, http.StatusBadRequest, "cannot follow yourself")
+		return
+	}
+	followee, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: followeeId, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	limited, msg, err := cfg.followLimitReached(r.Context(), followerId, followee)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if limited {
+		respondWithError(w, http.StatusTooManyRequests, msg)
+		return
+	}
+
+	n, err := cfg.db.CreateFollow(r.Context(), database.CreateFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), followerId), "user.followed", "user", followeeId, nil)
+	if n > 0 {
+		cfg.notify(r.Context(), followeeId, followerId, database.NotificationTypeFollow, uuid.Nil)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	followerId := user.ID
+	followeeId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	err = cfg.db.DeleteFollow(r.Context(), database.DeleteFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), followerId), "user.unfollowed", "user", followeeId, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// followPage is a parsed request for one page of a user's followers or
+// follows, starting after the cursor.
+type followPage struct {
+	user            database.GetUserByIdRow
+	cursorCreatedAt time.Time
+	cursorID        uuid.UUID
+	pageSize        int32
+}
+
+func (cfg *apiConfig) parseFollowPage(w http.ResponseWriter, r *http.Request) (followPage, bool) {
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return followPage{}, false
+	}
+	var page followPage
+	page.pageSize, err = parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return followPage{}, false
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		page.cursorCreatedAt, page.cursorID, err = decodeCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return followPage{}, false
+		}
+	}
+	page.user, err = cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return followPage{}, false
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return followPage{}, false
+	}
+	return page, true
+}
+
+// writeFollowPageHeaders sets the Link and X-Total-Count headers for
+// clients that page through headers.
+func writeFollowPageHeaders(w http.ResponseWriter, r *http.Request, total int64, nextCursor string) {
+	baseURL := r.URL.Path
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		baseURL += "?limit=" + url.QueryEscape(limit)
+	}
+	writePaginationLinks(w, baseURL, nextCursor)
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+}
+
+// respondWithFollowPage writes one page of users along with its paging
+// headers.
+func respondWithFollowPage(w http.ResponseWriter, r *http.Request, total int64, users []followUserResp, nextCursor string) {
+	writeFollowPageHeaders(w, r, total, nextCursor)
+	respondWithJSON(w, http.StatusOK, followUsersResp{Users: users, NextCursor: nextCursor})
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	page, ok := cfg.parseFollowPage(w, r)
+	if !ok {
+		return
+	}
+	rows, err := cfg.db.GetFollowersPage(r.Context(), database.GetFollowersPageParams{
+		FolloweeID:      page.user.User.ID,
+		Namespace:       namespaceOf(r.Context()),
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageSize:        page.pageSize + 1,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	var nextCursor string
+	if len(rows) > int(page.pageSize) {
+		rows = rows[:page.pageSize]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(last.FollowedAt.Time, last.ID)
+	}
+	resp := make([]followUserResp, 0, len(rows))
+	for _, u := range rows {
+		resp = append(resp, followUserResp{
+			userResp: userResp{
+				ID:          u.ID,
+				CreatedAt:   u.CreatedAt.Time,
+				UpdatedAt:   u.UpdatedAt.Time,
+				IsChirpyRed: u.IsChirpyRed,
+				IsVerified:  u.IsVerified,
+			},
+			IsMutual: u.IsMutual,
+		})
+	}
+	respondWithFollowPage(w, r, page.user.FollowersCount, resp, nextCursor)
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	page, ok := cfg.parseFollowPage(w, r)
+	if !ok {
+		return
+	}
+	rows, err := cfg.db.GetFollowingPage(r.Context(), database.GetFollowingPageParams{
+		FollowerID:      page.user.User.ID,
+		Namespace:       namespaceOf(r.Context()),
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageSize:        page.pageSize + 1,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	var nextCursor string
+	if len(rows) > int(page.pageSize) {
+		rows = rows[:page.pageSize]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(last.FollowedAt.Time, last.ID)
+	}
+	resp := make([]followUserResp, 0, len(rows))
+	for _, u := range rows {
+		resp = append(resp, followUserResp{
+			userResp: userResp{
+				ID:          u.ID,
+				CreatedAt:   u.CreatedAt.Time,
+				UpdatedAt:   u.UpdatedAt.Time,
+				IsChirpyRed: u.IsChirpyRed,
+				IsVerified:  u.IsVerified,
+			},
+			IsMutual: u.IsMutual,
+		})
+	}
+	respondWithFollowPage(w, r, page.user.FollowingCount, resp, nextCursor)
+}
+
+// mutualFollowsResp is a page of mutual follows; like followUserResp, its
+// users carry no email.
+type mutualFollowsResp struct {
+	Users            []userResp `json:"users"`
+	TotalMutualCount int64      `json:"total_mutual_count"`
+	NextCursor       string     `json:"next_cursor,omitempty"`
+}
+
+// handlerGetMutualFollows lists the users who follow a user and are followed
+// back. Verified users' mutuals are public; anyone else's are only shown to
+// themselves.
+func (cfg *apiConfig) handlerGetMutualFollows(w http.ResponseWriter, r *http.Request) {
+	page, ok := cfg.parseFollowPage(w, r)
+	if !ok {
+		return
+	}
+	userId := page.user.User.ID
+	if !page.user.User.IsVerified {
+		callerId, err := cfg.optionalUserID(r)
+		if err != nil || callerId == uuid.Nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if callerId != userId {
+			respondWithError(w, http.StatusForbidden, "only verified users' mutual follows are public")
+			return
+		}
+	}
+
+	total, err := cfg.db.CountMutualFollows(r.Context(), database.CountMutualFollowsParams{
+		FollowerID: userId,
+		Namespace:  namespaceOf(r.Context()),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	rows, err := cfg.db.GetMutualFollowsPage(r.Context(), database.GetMutualFollowsPageParams{
+		UserID:          userId,
+		Namespace:       namespaceOf(r.Context()),
+		CursorCreatedAt: page.cursorCreatedAt,
+		CursorID:        page.cursorID,
+		PageSize:        page.pageSize + 1,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	var nextCursor string
+	if len(rows) > int(page.pageSize) {
+		rows = rows[:page.pageSize]
+		last := rows[len(rows)-1]
+		nextCursor = encodeCursor(last.FollowedAt.Time, last.ID)
+	}
+	users := make([]userResp, 0, len(rows))
+	for _, u := range rows {
+		users = append(users, userResp{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt.Time,
+			UpdatedAt:   u.UpdatedAt.Time,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+		})
+	}
+	writeFollowPageHeaders(w, r, total, nextCursor)
+	respondWithJSON(w, http.StatusOK, mutualFollowsResp{Users: users, TotalMutualCount: total, NextCursor: nextCursor})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var nextLinkRE = regexp.MustCompile(`^<([^>]+)>; rel="next"$`)
+
+func TestFollowersPagination(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	seen := map[string]bool{}
+	for i := range 3 {
+		_, token := seedUser(t, cfg, store, fmt.Sprintf("fan%d@example.com", i))
+		serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", token)
+	}
+
+	base := "/api/users/" + alice.ID.String() + "/followers"
+	rec := serve(h, "GET", base+"?limit=2", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("got X-Total-Count %q, want 3", got)
+	}
+	if strings.Contains(rec.Body.String(), "@example.com") {
+		t.Errorf("public follower list exposes emails: %s", rec.Body.String())
+	}
+	var page followUsersResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Users) != 2 || page.NextCursor == "" {
+		t.Fatalf("got first page %+v, want two users and a cursor", page)
+	}
+	link := rec.Header().Get("Link")
+	m := nextLinkRE.FindStringSubmatch(link)
+	if m == nil || m[1] != base+"?limit=2&cursor="+page.NextCursor {
+		t.Fatalf("got Link %q, want the next page of %s", link, base)
+	}
+	for _, u := range page.Users {
+		seen[u.ID.String()] = true
+	}
+
+	rec = serve(h, "GET", m[1], "", "")
+	page = followUsersResp{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Users) != 1 || seen[page.Users[0].ID.String()] || page.NextCursor != "" {
+		t.Errorf("got last page %+v, want the remaining follower and no cursor", page)
+	}
+	if link := rec.Header().Get("Link"); link != "" {
+		t.Errorf("got Link %q on the last page, want none", link)
+	}
+
+	rec = serve(h, "GET", "/api/users/"+alice.ID.String()+"/following", "", aliceToken)
+	if got := rec.Header().Get("X-Total-Count"); got != "0" {
+		t.Errorf("got following X-Total-Count %q, want 0", got)
+	}
+}
+
+func TestFollowListsUnknownUser(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	for _, path := range []string{"followers", "following", "followers/mutual"} {
+		rec := serve(h, "GET", "/api/users/00000000-0000-0000-0000-000000000001/"+path, "", "")
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got status %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestMutualFollows(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	carol, carolToken := seedUser(t, cfg, store, "carol@example.com")
+	follow := func(token string, followee string) {
+		if rec := serve(h, "POST", "/api/users/"+followee+"/follow", "", token); rec.Code != http.StatusNoContent {
+			t.Fatalf("follow: got status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	// Alice and Bob follow each other; Alice and Bob both follow Carol, who
+	// follows nobody back.
+	follow(aliceToken, bob.ID.String())
+	follow(bobToken, alice.ID.String())
+	follow(aliceToken, carol.ID.String())
+	follow(bobToken, carol.ID.String())
+
+	mutuals := func(user, token string) mutualFollowsResp {
+		t.Helper()
+		rec := serve(h, "GET", "/api/users/"+user+"/followers/mutual", "", token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), "@example.com") {
+			t.Errorf("mutual follows expose emails: %s", rec.Body.String())
+		}
+		var resp mutualFollowsResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if got := mutuals(alice.ID.String(), aliceToken); len(got.Users) != 1 || got.Users[0].ID != bob.ID || got.TotalMutualCount != 1 {
+		t.Errorf("alice's mutuals: got %+v, want only bob", got)
+	}
+	if got := mutuals(bob.ID.String(), bobToken); len(got.Users) != 1 || got.Users[0].ID != alice.ID || got.TotalMutualCount != 1 {
+		t.Errorf("bob's mutuals: got %+v, want only alice", got)
+	}
+	if got := mutuals(carol.ID.String(), carolToken); len(got.Users) != 0 || got.TotalMutualCount != 0 {
+		t.Errorf("carol's mutuals: got %+v, want none", got)
+	}
+
+	path := "/api/users/" + alice.ID.String() + "/followers/mutual"
+	if rec := serve(h, "GET", path, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous, unverified user: got status %d, want 401", rec.Code)
+	}
+	if rec := serve(h, "GET", path, "", carolToken); rec.Code != http.StatusForbidden {
+		t.Errorf("someone else, unverified user: got status %d, want 403", rec.Code)
+	}
+	store.users[0].IsVerified = true
+	if got := mutuals(alice.ID.String(), ""); len(got.Users) != 1 || got.Users[0].ID != bob.ID {
+		t.Errorf("anonymous, verified user: got %+v, want only bob", got)
+	}
+}
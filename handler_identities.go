@@ -0,0 +1,225 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// linkStateTTL is how long a user has to approve a link at the provider.
+const linkStateTTL = 10 * time.Minute
+
+// unsetPassword is the hashed_password of users who never set one.
+const unsetPassword = "unset"
+
+type linkStartResp struct {
+	AuthorizeURL string `json:"authorize_url"`
+}
+
+type identityResp struct {
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newIdentityResp(i database.OauthIdentity) identityResp {
+	return identityResp{Provider: i.Provider, ProviderUserID: i.ProviderUserID, CreatedAt: i.CreatedAt}
+}
+
+// linkCallbackURL is where providers send the user back to after they
+// approve a link.
+func (cfg *apiConfig) linkCallbackURL(r *http.Request, provider string) string {
+	return requestBaseURL(r, cfg) + "/api/auth/link/" + provider + "/callback"
+}
+
+// makeLinkState signs the user and provider a link was started for. The
+// callback arrives from the provider's site without the user's token, so
+// the state is what says whose account to link.
+func (cfg *apiConfig) makeLinkState(userID uuid.UUID, provider string) string {
+	value := fmt.Sprintf("%s|%s|%d", userID, provider, cfg.timeNow().Add(linkStateTTL).Unix())
+	return auth.SignCookieValue(value, []byte(cfg.tokenSecret))
+}
+
+// parseLinkState returns the user a state from makeLinkState was made for,
+// if it is genuine, unexpired and for provider.
+func (cfg *apiConfig) parseLinkState(state, provider string) (uuid.UUID, error) {
+	value, err := auth.VerifyCookieValue(state, []byte(cfg.tokenSecret))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	parts := strings.Split(value, "|")
+	if len(parts) != 3 || parts[1] != provider {
+		return uuid.Nil, errors.New("state is for another provider")
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || cfg.timeNow().Unix() > expires {
+		return uuid.Nil, errors.New("state expired")
+	}
+	return uuid.Parse(parts[0])
+}
+
+// handlerStartLink begins linking a provider account to the caller's. The
+// client sends the user to the returned authorize_url.
+func (cfg *apiConfig) handlerStartLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	name := r.PathValue("provider")
+	provider, ok := cfg.identityProviders[name]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "unknown provider")
+		return
+	}
+	identities, err := cfg.db.GetOAuthIdentities(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	for _, i := range identities {
+		if i.Provider == name {
+			respondWithError(w, http.StatusConflict, "a "+name+" account is already linked")
+			return
+		}
+	}
+	respondWithJSON(w, http.StatusOK, linkStartResp{
+		AuthorizeURL: provider.AuthCodeURL(cfg.makeLinkState(userId, name), cfg.linkCallbackURL(r, name)),
+	})
+}
+
+// handlerLinkCallback finishes a link once the provider sends the user
+// back. The provider account is attached to the user who started the link;
+// no new Chirpy account is ever created.
+func (cfg *apiConfig) handlerLinkCallback(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	provider, ok := cfg.identityProviders[name]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "unknown provider")
+		return
+	}
+	q := r.URL.Query()
+	userId, err := cfg.parseLinkState(q.Get("state"), name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid or expired state")
+		return
+	}
+	if e := q.Get("error"); e != "" {
+		respondWithError(w, http.StatusBadRequest, name+" did not approve the link: "+e)
+		return
+	}
+	identity, err := provider.Exchange(r.Context(), q.Get("code"), cfg.linkCallbackURL(r, name))
+	if err != nil {
+		respondWithError(w, http.StatusBadGateway, "could not reach "+name)
+		return
+	}
+
+	existing, err := cfg.db.GetOAuthIdentityByProviderUser(r.Context(), database.GetOAuthIdentityByProviderUserParams{
+		Provider:       name,
+		ProviderUserID: identity.UserID,
+	})
+	if err == nil {
+		msg := "this " + name + " account is linked to another user"
+		if existing.UserID == userId {
+			msg = "this " + name + " account is already linked"
+		}
+		respondWithError(w, http.StatusConflict, msg)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	identities, err := cfg.db.GetOAuthIdentities(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	for _, i := range identities {
+		if i.Provider == name {
+			respondWithError(w, http.StatusConflict, "a "+name+" account is already linked")
+			return
+		}
+	}
+
+	sealed, err := auth.EncryptSecret([]byte(identity.AccessToken), cfg.identityTokenKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	linked, err := cfg.db.CreateOAuthIdentity(r.Context(), database.CreateOAuthIdentityParams{
+		UserID:               userId,
+		Provider:             name,
+		ProviderUserID:       identity.UserID,
+		AccessTokenEncrypted: sealed,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), userId), "identity.linked", "user", userId, map[string]string{"provider": name})
+	respondWithJSON(w, http.StatusCreated, newIdentityResp(linked))
+}
+
+func (cfg *apiConfig) handlerGetIdentities(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	identities, err := cfg.db.GetOAuthIdentities(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]identityResp, 0, len(identities))
+	for _, i := range identities {
+		resp = append(resp, newIdentityResp(i))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerUnlink removes a linked provider account, unless the user has no
+// password and it is the only way left to sign in.
+func (cfg *apiConfig) handlerUnlink(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	name := r.PathValue("provider")
+	identities, err := cfg.db.GetOAuthIdentities(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	linked := false
+	for _, i := range identities {
+		linked = linked || i.Provider == name
+	}
+	if !linked {
+		respondWithError(w, http.StatusNotFound, "no "+name+" account is linked")
+		return
+	}
+	if len(identities) == 1 && (user.HashedPassword == "" || user.HashedPassword == unsetPassword) {
+		respondWithError(w, http.StatusConflict, "set a password or link another account before unlinking your only sign-in method")
+		return
+	}
+	if _, err := cfg.db.DeleteOAuthIdentity(r.Context(), database.DeleteOAuthIdentityParams{UserID: userId, Provider: name}); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), userId), "identity.unlinked", "user", userId, map[string]string{"provider": name})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubGitHub serves GitHub's token and user endpoints. Code "code-<id>"
+// exchanges for a token belonging to GitHub user <id>.
+func stubGitHub(t *testing.T) *githubProvider {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := strings.CutPrefix(r.FormValue("code"), "code-")
+		if !ok || r.FormValue("client_secret") != "shh" {
+			json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "gho_" + id})
+	})
+	mux.HandleFunc("GET /user", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer gho_")
+		w.Write([]byte(`{"id":` + id + `}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	gh := newGitHubProvider("client", "shh")
+	gh.authURL, gh.tokenURL, gh.userURL = "https://github.example/authorize", srv.URL+"/token", srv.URL+"/user"
+	return gh
+}
+
+type linkTest struct {
+	h     http.Handler
+	cfg   *apiConfig
+	store *memStore
+}
+
+func newLinkTest(t *testing.T) linkTest {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.identityProviders = map[string]IdentityProvider{"github": stubGitHub(t)}
+	cfg.identityTokenKey = []byte(strings.Repeat("k", 32))
+	return linkTest{h: newServer("0", cfg).Handler, cfg: cfg, store: store}
+}
+
+// link starts a link as token and returns the callback's response once
+// GitHub hands back code.
+func (lt linkTest) link(t *testing.T, token, code string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := serve(lt.h, "POST", "/api/users/me/link/github", "", token)
+	if rec.Code != http.StatusOK {
+		return rec
+	}
+	var start linkStartResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &start); err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(start.AuthorizeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := u.Query().Get("redirect_uri"); got != "http://example.com/api/auth/link/github/callback" {
+		t.Errorf("got redirect_uri %q", got)
+	}
+	q := url.Values{"code": {code}, "state": {u.Query().Get("state")}}
+	return serve(lt.h, "GET", "/api/auth/link/github/callback?"+q.Encode(), "", "")
+}
+
+func TestLinkIdentity(t *testing.T) {
+	lt := newLinkTest(t)
+	alice, aliceToken := seedUser(t, lt.cfg, lt.store, "alice@example.com")
+
+	rec := lt.link(t, aliceToken, "code-42")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(lt.store.users) != 1 {
+		t.Errorf("got %d users, want linking to leave it at 1", len(lt.store.users))
+	}
+	if got := lt.store.identities[0]; got.UserID != alice.ID || got.ProviderUserID != "42" || strings.Contains(string(got.AccessTokenEncrypted), "gho_42") {
+		t.Errorf("got identity %+v", got)
+	}
+
+	rec = serve(lt.h, "GET", "/api/users/me/identities", "", aliceToken)
+	var identities []identityResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &identities); err != nil {
+		t.Fatal(err)
+	}
+	if len(identities) != 1 || identities[0].Provider != "github" || identities[0].ProviderUserID != "42" {
+		t.Errorf("got identities %+v", identities)
+	}
+
+	if rec := serve(lt.h, "POST", "/api/users/me/link/gitlab", "", aliceToken); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for an unknown provider, want 404", rec.Code)
+	}
+	q := url.Values{"code": {"code-7"}, "state": {"forged.c2ln"}}
+	if rec := serve(lt.h, "GET", "/api/auth/link/github/callback?"+q.Encode(), "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for a forged state, want 400", rec.Code)
+	}
+}
+
+func TestLinkIdentityDuplicates(t *testing.T) {
+	lt := newLinkTest(t)
+	_, alice := seedUser(t, lt.cfg, lt.store, "alice@example.com")
+	_, bob := seedUser(t, lt.cfg, lt.store, "bob@example.com")
+	if rec := lt.link(t, alice, "code-42"); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := lt.link(t, alice, "code-43"); rec.Code != http.StatusConflict {
+		t.Errorf("got status %d linking a second github account, want 409", rec.Code)
+	}
+	rec := lt.link(t, bob, "code-42")
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "another user") {
+		t.Errorf("got status %d linking alice's github account to bob: %s", rec.Code, rec.Body.String())
+	}
+	if len(lt.store.identities) != 1 {
+		t.Errorf("got %d identities, want 1", len(lt.store.identities))
+	}
+}
+
+func TestLinkStateExpires(t *testing.T) {
+	lt := newLinkTest(t)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	lt.cfg.now = clock.Now
+	_, alice := seedUser(t, lt.cfg, lt.store, "alice@example.com")
+
+	rec := serve(lt.h, "POST", "/api/users/me/link/github", "", alice)
+	var start linkStartResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &start); err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(start.AuthorizeURL)
+	clock.t = clock.t.Add(linkStateTTL + time.Second)
+	q := url.Values{"code": {"code-42"}, "state": {u.Query().Get("state")}}
+	if rec := serve(lt.h, "GET", "/api/auth/link/github/callback?"+q.Encode(), "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an expired state, want 400", rec.Code)
+	}
+}
+
+func TestUnlinkIdentity(t *testing.T) {
+	lt := newLinkTest(t)
+	_, alice := seedUser(t, lt.cfg, lt.store, "alice@example.com")
+	if rec := lt.link(t, alice, "code-42"); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// alice has no password, so github is her only way in.
+	rec := serve(lt.h, "DELETE", "/api/users/me/link/github", "", alice)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("got status %d unlinking the only sign-in method, want 409", rec.Code)
+	}
+
+	lt.store.users[0].HashedPassword = "$argon2id$hash"
+	if rec := serve(lt.h, "DELETE", "/api/users/me/link/github", "", alice); rec.Code != http.StatusNoContent {
+		t.Errorf("got status %d unlinking with a password set, want 204", rec.Code)
+	}
+	if len(lt.store.identities) != 0 {
+		t.Errorf("got %d identities after unlinking", len(lt.store.identities))
+	}
+	if rec := serve(lt.h, "DELETE", "/api/users/me/link/github", "", alice); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d unlinking twice, want 404", rec.Code)
+	}
+}
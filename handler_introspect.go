@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// introspectScope is reported for access tokens issued without a scope,
+// which have full access to their user's account.
+const introspectScope = "chirpy"
+
+type introspectParams struct {
+	Token string `json:"token"`
+}
+
+// introspectResp follows RFC 7662: an inactive token reports nothing but
+// "active": false.
+type introspectResp struct {
+	Active bool   `json:"active"`
+	Sub    string `json:"sub,omitempty"`
+	Exp    int64  `json:"exp,omitempty"`
+	Iat    int64  `json:"iat,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+}
+
+// handlerIntrospect reports whether an access token is valid and who it
+// belongs to. Admins may introspect any token; other callers only tokens
+// issued to themselves.
+func (cfg *apiConfig) handlerIntrospect(w http.ResponseWriter, r *http.Request) {
+	caller, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	params := introspectParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil || params.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	claims, err := cfg.parseAccessToken(r.Context(), params.Token)
+	if err != nil {
+		respondWithJSON(w, http.StatusOK, introspectResp{Active: false})
+		return
+	}
+	if claims.UserID != caller.ID && !caller.IsAdmin {
+		respondWithError(w, http.StatusForbidden, "only admins can introspect other users' tokens")
+		return
+	}
+	scope := claims.Scope
+	if scope == "" {
+		scope = introspectScope
+	}
+	respondWithJSON(w, http.StatusOK, introspectResp{
+		Active: true,
+		Sub:    claims.UserID.String(),
+		Exp:    claims.ExpiresAt.Unix(),
+		Iat:    claims.IssuedAt.Unix(),
+		Scope:  scope,
+	})
+}
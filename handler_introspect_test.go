@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+)
+
+func introspect(t *testing.T, h http.Handler, token, caller string) (int, introspectResp) {
+	t.Helper()
+	body, _ := json.Marshal(introspectParams{Token: token})
+	rec := serve(h, "POST", "/api/auth/introspect", string(body), caller)
+	var resp introspectResp
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decoding %s: %v", rec.Body.String(), err)
+		}
+	}
+	return rec.Code, resp
+}
+
+func TestIntrospect(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+
+	code, resp := introspect(t, h, aliceToken, adminToken)
+	if code != http.StatusOK || !resp.Active {
+		t.Fatalf("got %d %+v for a valid token, want active", code, resp)
+	}
+	if resp.Sub != alice.ID.String() || resp.Scope != "chirpy" || resp.Exp-resp.Iat != int64(time.Hour.Seconds()) {
+		t.Errorf("got %+v, want alice's claims with an hour's lifetime", resp)
+	}
+
+	expired, err := auth.MakeJWT(alice.ID, cfg.tokenSecret, -time.Minute)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+	parts := strings.Split(aliceToken, ".")
+	parts[1] = strings.Repeat("A", len(parts[1]))
+	tampered := strings.Join(parts, ".")
+	for name, token := range map[string]string{"expired": expired, "tampered": tampered} {
+		code, resp := introspect(t, h, token, adminToken)
+		if code != http.StatusOK || resp != (introspectResp{}) {
+			t.Errorf("%s token: got %d %+v, want only active=false", name, code, resp)
+		}
+	}
+}
+
+func TestIntrospectAuthorization(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+
+	if code, _ := introspect(t, h, aliceToken, ""); code != http.StatusUnauthorized {
+		t.Errorf("got status %d without a bearer token, want 401", code)
+	}
+	if code, resp := introspect(t, h, aliceToken, aliceToken); code != http.StatusOK || !resp.Active {
+		t.Errorf("got %d %+v introspecting own token, want active", code, resp)
+	}
+	if code, _ := introspect(t, h, bobToken, aliceToken); code != http.StatusForbidden {
+		t.Errorf("got status %d introspecting another user's token, want 403", code)
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type listResp struct {
+	ID          uuid.UUID `json:"id"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPublic    bool      `json:"is_public"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newListResp(l database.List) listResp {
+	return listResp{
+		ID:          l.ID,
+		OwnerID:     l.OwnerID,
+		Name:        l.Name,
+		Description: l.Description,
+		IsPublic:    l.IsPublic,
+		CreatedAt:   l.CreatedAt.Time,
+		UpdatedAt:   l.UpdatedAt.Time,
+	}
+}
+
+type listFeedResp struct {
+	Chirps     []chirpResp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// optionalUserID returns the caller for endpoints that also serve anonymous
+// requests. A missing token yields uuid.Nil; an invalid one is an error.
+func (cfg *apiConfig) optionalUserID(r *http.Request) (uuid.UUID, error) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.Nil, nil
+	}
+	bearerToken, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return cfg.authenticateRequest(r, bearerToken)
+}
+
+// loadOwnedList fetches the list in the listId path value and checks that
+// callerId owns it, writing the error response when it does not.
+func (cfg *apiConfig) loadOwnedList(w http.ResponseWriter, r *http.Request, callerId uuid.UUID) (database.List, bool) {
+	listId, err := uuid.Parse(r.PathValue("listId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid list id")
+		return database.List{}, false
+	}
+	list, err := cfg.db.GetList(r.Context(), listId)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "list not found")
+		return database.List{}, false
+	}
+	if list.OwnerID != callerId {
+		respondWithError(w, http.StatusForbidden, "not the list owner")
+		return database.List{}, false
+	}
+	return list, true
+}
+
+type createListParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPublic    *bool  `json:"is_public"`
+}
+
+func (cfg *apiConfig) handlerCreateList(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	params := createListParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	isPublic := true
+	if params.IsPublic != nil {
+		isPublic = *params.IsPublic
+	}
+
+	list, err := cfg.db.CreateList(r.Context(), database.CreateListParams{
+		OwnerID:     userId,
+		Name:        params.Name,
+		Description: params.Description,
+		IsPublic:    isPublic,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), userId), "list.created", "list", list.ID, nil)
+	respondWithJSON(w, http.StatusCreated, newListResp(list))
+}
+
+type addListMemberParams struct {
+	UserId uuid.UUID `json:"user_id"`
+}
+
+func (cfg *apiConfig) handlerAddListMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	callerId := user.ID
+	list, ok := cfg.loadOwnedList(w, r, callerId)
+	if !ok {
+		return
+	}
+	params := addListMemberParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if _, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: params.UserId, Namespace: namespaceOf(r.Context())}); err != nil {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	err := cfg.db.AddListMember(r.Context(), database.AddListMemberParams{
+		ListID: list.ID,
+		UserID: params.UserId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), callerId), "list.member_added", "list", list.ID, map[string]string{"user_id": params.UserId.String()})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerRemoveListMember(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	callerId := user.ID
+	list, ok := cfg.loadOwnedList(w, r, callerId)
+	if !ok {
+		return
+	}
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+
+	err = cfg.db.RemoveListMember(r.Context(), database.RemoveListMemberParams{
+		ListID: list.ID,
+		UserID: userId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), callerId), "list.member_removed", "list", list.ID, map[string]string{"user_id": userId.String()})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetListFeed(w http.ResponseWriter, r *http.Request) {
+	callerId, err := cfg.optionalUserID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	listId, err := uuid.Parse(r.PathValue("listId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid list id")
+		return
+	}
+	list, err := cfg.db.GetList(r.Context(), listId)
+	// Private lists are indistinguishable from missing ones to non-owners.
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !list.IsPublic && list.OwnerID != callerId) {
+		respondWithError(w, http.StatusNotFound, "list not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.GetListFeedParams{
+		ListID:          list.ID,
+		ViewerID:        callerId,
+		CursorCreatedAt: farFuture,
+		CursorID:        uuid.Max,
+		PageSize:        pageSize + 1,
+		Namespace:       namespaceOf(r.Context()),
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		params.CursorCreatedAt, params.CursorID, err = decodeCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	chirps, err := cfg.db.GetListFeed(r.Context(), params)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := listFeedResp{Chirps: make([]chirpResp, 0, len(chirps))}
+	if len(chirps) > int(pageSize) {
+		chirps = chirps[:pageSize]
+		last := chirps[len(chirps)-1].Chirp
+		resp.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	for _, c := range chirps {
+		cr := newChirpResp(c.Chirp)
+		cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+		cr.Flagged = flaggedFor(c.Chirp, callerId)
+		resp.Chirps = append(resp.Chirps, cr)
+	}
+	if err := cfg.attachMedia(r.Context(), resp.Chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerGetUserLists(w http.ResponseWriter, r *http.Request) {
+	callerId, err := cfg.optionalUserID(r)
+	if err != nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	lists, err := cfg.db.GetListsByOwner(r.Context(), database.GetListsByOwnerParams{
+		OwnerID:        userId,
+		IncludePrivate: callerId == userId,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := make([]listResp, 0, len(lists))
+	for _, l := range lists {
+		resp = append(resp, newListResp(l))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func createList(t *testing.T, h http.Handler, body, token string) listResp {
+	t.Helper()
+	rec := serve(h, "POST", "/api/lists", body, token)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating list: got status %d, want 201", rec.Code)
+	}
+	var l listResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &l); err != nil {
+		t.Fatalf("decoding list: %v", err)
+	}
+	return l
+}
+
+func TestListFeedWithMultipleMembers(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, ownerToken := seedUser(t, cfg, store, "owner@example.com")
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	_, carolToken := seedUser(t, cfg, store, "carol@example.com")
+	list := createList(t, handler, `{"name":"friends"}`, ownerToken)
+	for _, id := range []string{alice.ID.String(), bob.ID.String()} {
+		rec := serve(handler, "POST", "/api/lists/"+list.ID.String()+"/members", `{"user_id":"`+id+`"}`, ownerToken)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("adding member: got status %d, want 204", rec.Code)
+		}
+	}
+	postChirp(t, handler, `{"body":"alice 1"}`, aliceToken)
+	postChirp(t, handler, `{"body":"bob 1"}`, bobToken)
+	postChirp(t, handler, `{"body":"carol 1"}`, carolToken)
+	postChirp(t, handler, `{"body":"alice 2"}`, aliceToken)
+
+	var got []string
+	cursor := ""
+	for {
+		rec := serve(handler, "GET", "/api/lists/"+list.ID.String()+"/feed?limit=2&cursor="+cursor, "", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200", rec.Code)
+		}
+		var page listFeedResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("decoding page: %v", err)
+		}
+		for _, c := range page.Chirps {
+			got = append(got, c.Body)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	want := []string{"alice 2", "bob 1", "alice 1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	serve(handler, "DELETE", "/api/lists/"+list.ID.String()+"/members/"+alice.ID.String(), "", ownerToken)
+	rec := serve(handler, "GET", "/api/lists/"+list.ID.String()+"/feed", "", "")
+	var page listFeedResp
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	if len(page.Chirps) != 1 || page.Chirps[0].Body != "bob 1" {
+		t.Errorf("after removing alice got %+v, want only bob's chirp", page.Chirps)
+	}
+}
+
+func TestListMembersOwnerOnly(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, ownerToken := seedUser(t, cfg, store, "owner@example.com")
+	other, otherToken := seedUser(t, cfg, store, "other@example.com")
+	list := createList(t, handler, `{"name":"mine"}`, ownerToken)
+	rec := serve(handler, "POST", "/api/lists/"+list.ID.String()+"/members", `{"user_id":"`+other.ID.String()+`"}`, otherToken)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d, want 403", rec.Code)
+	}
+}
+
+func TestPrivateListsHiddenFromOthers(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	owner, ownerToken := seedUser(t, cfg, store, "owner@example.com")
+	_, otherToken := seedUser(t, cfg, store, "other@example.com")
+	createList(t, handler, `{"name":"public"}`, ownerToken)
+	private := createList(t, handler, `{"name":"secret","is_public":false}`, ownerToken)
+
+	listNames := func(token string) []string {
+		rec := serve(handler, "GET", "/api/users/"+owner.ID.String()+"/lists", "", token)
+		var lists []listResp
+		json.Unmarshal(rec.Body.Bytes(), &lists)
+		var names []string
+		for _, l := range lists {
+			names = append(names, l.Name)
+		}
+		return names
+	}
+	if got := listNames(otherToken); !slices.Equal(got, []string{"public"}) {
+		t.Errorf("other user sees %v, want [public]", got)
+	}
+	if got := listNames(ownerToken); !slices.Equal(got, []string{"public", "secret"}) {
+		t.Errorf("owner sees %v, want [public secret]", got)
+	}
+
+	if rec := serve(handler, "GET", "/api/lists/"+private.ID.String()+"/feed", "", otherToken); rec.Code != http.StatusNotFound {
+		t.Errorf("private feed for other user: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(handler, "GET", "/api/lists/"+private.ID.String()+"/feed", "", ownerToken); rec.Code != http.StatusOK {
+		t.Errorf("private feed for owner: got status %d, want 200", rec.Code)
+	}
+}
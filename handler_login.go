@@ -1,71 +1,140 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/azs06/Chirpy/internal/auth"
 	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
-	type parameters struct {
-		Email            string `json:"email"`
-		Password         string `json:"password"`
-		ExpiresInSeconds int    `json:"expires_in_seconds"`
-	}
+	cfg.login(w, r, cfg.respondWithLogin)
+}
+
+type loginParams struct {
+	Email            string `json:"email"`
+	Password         string `json:"password"`
+	ExpiresInSeconds int    `json:"expires_in_seconds"`
+}
+
+// login checks the email and password in r and hands the user to complete,
+// unless the user must first pass an email OTP challenge.
+func (cfg *apiConfig) login(w http.ResponseWriter, r *http.Request, complete func(http.ResponseWriter, *http.Request, database.User, time.Duration)) {
 
 	defaultExpiresInSeconds := time.Hour
 
 	w.Header().Set("Content-Type", "application/json")
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
+	params := loginParams{}
 	err := decoder.Decode(&params)
 
 	if err != nil {
 		fmt.Println(err)
+		cfg.auditLoginFailed(r.Context(), uuid.Nil, "", "invalid_body")
 		w.WriteHeader(500)
 		return
 	}
 
-	user, err := cfg.db.GetUserByEmail(r.Context(), sql.NullString{
-		String: params.Email,
-		Valid:  params.Email != "",
+	user, err := cfg.db.GetUserByEmail(r.Context(), database.GetUserByEmailParams{
+		Email:     sql.NullString{String: params.Email, Valid: params.Email != ""},
+		Namespace: namespaceOf(r.Context()),
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		cfg.auditLoginFailed(r.Context(), uuid.Nil, params.Email, "unknown_email")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		cfg.auditLoginFailed(r.Context(), uuid.Nil, params.Email, "lookup_failed")
+		cfg.respondWithDBError(w, err)
+		return
+	}
 
-	match, err := auth.CheckHashedPassword(params.Password, user.HashedPassword)
+	match, _ := auth.CheckHashedPassword(params.Password, user.HashedPassword)
 	if !match {
+		cfg.auditLoginFailed(r.Context(), user.ID, params.Email, "wrong_password")
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
+	if user.EmailMfaEnabled {
+		cfg.startEmailOTP(w, r, user)
+		return
+	}
 	if params.ExpiresInSeconds > 0 {
 		defaultExpiresInSeconds = time.Duration(params.ExpiresInSeconds) * time.Second
 	}
-	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, defaultExpiresInSeconds)
-	refresh_token := auth.MakeRefreshToken()
-	refresh_token_expiry := time.Now().Add(60 * 24 * time.Hour)
-	tokenParams := database.CreateRefreshTokenParams{
-		Token:  refresh_token,
-		UserID: user.ID,
-		ExpiresAt: sql.NullTime{
-			Time:  refresh_token_expiry,
-			Valid: true,
-		},
-		RevokedAt: sql.NullTime{},
+	complete(w, r, user, defaultExpiresInSeconds)
+}
+
+// respondWithLogin issues an access token lasting expiresIn and a new
+// refresh token for user, completing a login.
+func (cfg *apiConfig) respondWithLogin(w http.ResponseWriter, r *http.Request, user database.User, expiresIn time.Duration) {
+	w.Header().Set("Content-Type", "application/json")
+	token, refreshToken, err := cfg.issueTokens(r.Context(), user, expiresIn, "")
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
 	}
-	tokenData, err := cfg.db.CreateRefreshToken(r.Context(), tokenParams)
+	stop := startWriterPhase(w, "marshal")
 	dat, _ := json.Marshal(userResp{
 		ID:           user.ID,
 		CreatedAt:    user.CreatedAt.Time,
 		UpdatedAt:    user.UpdatedAt.Time,
 		Email:        user.Email.String,
 		Token:        token,
-		RefreshToken: tokenData.Token,
+		RefreshToken: refreshToken,
 		IsChirpyRed:  user.IsChirpyRed,
+		IsVerified:   user.IsVerified,
 	})
+	stop()
 	w.Write(dat)
 	w.WriteHeader(http.StatusOK)
 }
+
+// auditLoginFailed records a login attempt that did not end in tokens.
+// userID is uuid.Nil and email empty when the attempt never got that far.
+func (cfg *apiConfig) auditLoginFailed(ctx context.Context, userID uuid.UUID, email, reason string) {
+	details := map[string]string{"reason": reason}
+	if email != "" {
+		details["email"] = email
+	}
+	cfg.audit(ctx, "user.login_failed", "user", userID, details)
+}
+
+// issueTokens creates an access token lasting expiresIn and a stored
+// refresh token for user, both limited to scope unless it is empty. The
+// login is recorded only once both exist, and a failure to create them is
+// recorded as a failed login.
+func (cfg *apiConfig) issueTokens(ctx context.Context, user database.User, expiresIn time.Duration, scope string) (string, string, error) {
+	token, err := auth.MakeScopedJWT(user.ID, cfg.tokenSecret, expiresIn, scope)
+	if err != nil {
+		cfg.auditLoginFailed(ctx, user.ID, user.Email.String, "token_error")
+		return "", "", err
+	}
+	refresh_token := auth.MakeRefreshToken()
+	refresh_token_expiry := time.Now().Add(60 * 24 * time.Hour)
+	tokenParams := database.CreateRefreshTokenParams{
+		Token:  refresh_token,
+		UserID: user.ID,
+		ExpiresAt: sql.NullTime{
+			Time:  refresh_token_expiry,
+			Valid: true,
+		},
+		RevokedAt: sql.NullTime{},
+		Scope:     scope,
+	}
+	tokenData, err := cfg.db.CreateRefreshToken(ctx, tokenParams)
+	if err != nil {
+		cfg.auditLoginFailed(ctx, user.ID, user.Email.String, "token_error")
+		return "", "", err
+	}
+	cfg.audit(withActor(ctx, user.ID), "user.login", "user", user.ID, nil)
+	return token, tokenData.Token, nil
+}
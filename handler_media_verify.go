@@ -0,0 +1,24 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/media"
+)
+
+func (cfg *apiConfig) handlerVerifyMedia(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	err := media.VerifySignedURL(q.Get("url"), q.Get("expires"), q.Get("sig"), cfg.mediaSigningKey)
+	if errors.Is(err, media.ErrExpired) || errors.Is(err, media.ErrInvalidSignature) {
+		respondWithError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		fmt.Println(err)
+		w.WriteHeader(500)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
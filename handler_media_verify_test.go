@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestChirpMediaURLsAreSigned(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "media@example.com")
+	rawURL := "https://cdn.example.com/cat.png"
+	chirp := postChirp(t, handler, `{"body":"look","media":[{"url":"`+rawURL+`","mime_type":"image/png"}]}`, token)
+	if len(chirp.Media) != 1 {
+		t.Fatalf("got %d media items, want 1", len(chirp.Media))
+	}
+	signed, err := url.Parse(chirp.Media[0].URL)
+	if err != nil {
+		t.Fatalf("parsing signed url: %v", err)
+	}
+	q := signed.Query()
+	if q.Get("sig") == "" || q.Get("expires") == "" {
+		t.Fatalf("media url %q is not signed", chirp.Media[0].URL)
+	}
+
+	verify := func(mediaURL string) int {
+		v := url.Values{"url": {mediaURL}, "expires": {q.Get("expires")}, "sig": {q.Get("sig")}}
+		return serve(handler, "GET", "/api/media/verify?"+v.Encode(), "", "").Code
+	}
+	if code := verify(rawURL); code != http.StatusOK {
+		t.Errorf("valid signature: got status %d, want 200", code)
+	}
+	if code := verify(strings.Replace(rawURL, "cat", "dog", 1)); code != http.StatusForbidden {
+		t.Errorf("tampered url: got status %d, want 403", code)
+	}
+}
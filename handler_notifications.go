@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type notificationResp struct {
+	ID        uuid.UUID                 `json:"id"`
+	ActorID   uuid.UUID                 `json:"actor_id"`
+	Type      database.NotificationType `json:"type"`
+	ChirpID   *uuid.UUID                `json:"chirp_id,omitempty"`
+	ReadAt    *time.Time                `json:"read_at"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+type notificationsResp struct {
+	Notifications []notificationResp `json:"notifications"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
+}
+
+// Notifications are ordered unread first, so their cursor also records
+// which half of the feed it points into.
+func encodeNotificationCursor(n database.Notification) string {
+	prefix := "u."
+	if n.ReadAt.Valid {
+		prefix = "r."
+	}
+	return prefix + encodeCursor(n.CreatedAt.Time, n.ID)
+}
+
+func decodeNotificationCursor(cursor string) (bool, time.Time, uuid.UUID, error) {
+	prefix, rest, ok := strings.Cut(cursor, ".")
+	if !ok || (prefix != "u" && prefix != "r") {
+		return false, time.Time{}, uuid.Nil, errors.New("invalid cursor")
+	}
+	createdAt, id, err := decodeCursor(rest)
+	return prefix == "r", createdAt, id, err
+}
+
+func (cfg *apiConfig) handlerGetNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params := database.GetNotificationsParams{
+		RecipientID:     userId,
+		CursorCreatedAt: farFuture,
+		CursorID:        uuid.Max,
+		PageSize:        pageSize + 1,
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		params.CursorRead, params.CursorCreatedAt, params.CursorID, err = decodeNotificationCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	rows, err := cfg.db.GetNotifications(r.Context(), params)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := notificationsResp{Notifications: make([]notificationResp, 0, len(rows))}
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		resp.NextCursor = encodeNotificationCursor(rows[len(rows)-1])
+	}
+	for _, n := range rows {
+		nr := notificationResp{
+			ID:        n.ID,
+			ActorID:   n.ActorID,
+			Type:      n.Type,
+			CreatedAt: n.CreatedAt.Time,
+		}
+		if n.ChirpID.Valid {
+			nr.ChirpID = &n.ChirpID.UUID
+		}
+		if n.ReadAt.Valid {
+			nr.ReadAt = &n.ReadAt.Time
+		}
+		resp.Notifications = append(resp.Notifications, nr)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerReadAllNotifications(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	if err := cfg.db.MarkAllNotificationsRead(r.Context(), userId); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestActionsCreateNotifications(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	author, authorToken := seedUser(t, cfg, store, "author@example.com")
+	actor, actorToken := seedUser(t, cfg, store, "actor@example.com")
+	chirp := postChirp(t, handler, `{"body":"hello"}`, authorToken)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		wantType     database.NotificationType
+		wantHasChirp bool
+	}{
+		{"like", "POST", "/api/chirps/" + chirp.ID.String() + "/like", "", database.NotificationTypeLike, true},
+		{"reply", "POST", "/api/chirps", `{"body":"hi back","parent_id":"` + chirp.ID.String() + `"}`, database.NotificationTypeReply, true},
+		{"mention", "POST", "/api/chirps", `{"body":"cc @author@example.com!"}`, database.NotificationTypeMention, true},
+		{"follow", "POST", "/api/users/" + author.ID.String() + "/follow", "", database.NotificationTypeFollow, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.notifications = nil
+			serve(handler, tt.method, tt.path, tt.body, actorToken)
+			runJobs(t, cfg)
+			if len(store.notifications) != 1 {
+				t.Fatalf("got %d notifications, want 1", len(store.notifications))
+			}
+			n := store.notifications[0]
+			if n.Type != tt.wantType || n.RecipientID != author.ID || n.ActorID != actor.ID {
+				t.Errorf("got %s for %v from %v, want %s for %v from %v",
+					n.Type, n.RecipientID, n.ActorID, tt.wantType, author.ID, actor.ID)
+			}
+			if n.ChirpID.Valid != tt.wantHasChirp {
+				t.Errorf("got chirp_id valid=%v, want %v", n.ChirpID.Valid, tt.wantHasChirp)
+			}
+		})
+	}
+}
+
+func TestNoSelfNotifications(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "me@example.com")
+	chirp := postChirp(t, handler, `{"body":"talking to @me@example.com"}`, token)
+	serve(handler, "POST", "/api/chirps/"+chirp.ID.String()+"/like", "", token)
+	runJobs(t, cfg)
+	if len(store.notifications) != 0 {
+		t.Errorf("got %d notifications for own actions, want 0", len(store.notifications))
+	}
+}
+
+func TestGetNotificationsUnreadFirst(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, authorToken := seedUser(t, cfg, store, "author@example.com")
+	_, fanToken := seedUser(t, cfg, store, "fan@example.com")
+	var chirps []chirpResp
+	for _, body := range []string{"one", "two", "three"} {
+		chirps = append(chirps, postChirp(t, handler, `{"body":"`+body+`"}`, authorToken))
+	}
+	serve(handler, "POST", "/api/chirps/"+chirps[0].ID.String()+"/like", "", fanToken)
+	serve(handler, "POST", "/api/chirps/"+chirps[1].ID.String()+"/like", "", fanToken)
+	if rec := serve(handler, "POST", "/api/notifications/read-all", "", authorToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("read-all: got status %d, want 204", rec.Code)
+	}
+	serve(handler, "POST", "/api/chirps/"+chirps[2].ID.String()+"/like", "", fanToken)
+
+	var got []uuid.UUID
+	var readFlags []bool
+	cursor := ""
+	for {
+		rec := serve(handler, "GET", "/api/notifications?limit=1&cursor="+cursor, "", authorToken)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200", rec.Code)
+		}
+		var page notificationsResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("decoding page: %v", err)
+		}
+		for _, n := range page.Notifications {
+			got = append(got, *n.ChirpID)
+			readFlags = append(readFlags, n.ReadAt != nil)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	want := []uuid.UUID{chirps[2].ID, chirps[1].ID, chirps[0].ID}
+	if !slices.Equal(got, want) {
+		t.Errorf("got chirp order %v, want %v", got, want)
+	}
+	if !slices.Equal(readFlags, []bool{false, true, true}) {
+		t.Errorf("got read flags %v, want [false true true]", readFlags)
+	}
+}
+
+func TestMentionedEmails(t *testing.T) {
+	got := mentionedEmails("hey @a@example.com, and @b@example.org. not@c@example.com or @nobody")
+	want := []string{"a@example.com", "b@example.org"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -6,13 +6,15 @@ import (
 	"time"
 
 	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
 )
 
+type refreshResp struct {
+	Token string `json:"token"`
+}
+
 func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	type respParams struct {
-		Token string `json:"token"`
-	}
 	bearerToken, err := auth.GetBearerToken(r.Header)
 
 	if err != nil {
@@ -27,20 +29,22 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := cfg.db.GetUserById(r.Context(), refresh_token.UserID)
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: refresh_token.UserID, Namespace: namespaceOf(r.Context())})
 
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
-	token, err := auth.MakeJWT(user.ID, cfg.tokenSecret, time.Hour)
+	token, err := auth.MakeScopedJWT(user.User.ID, cfg.tokenSecret, time.Hour, refresh_token.Scope)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
-	data, err := json.Marshal(respParams{
+	stop := startWriterPhase(w, "marshal")
+	data, err := json.Marshal(refreshResp{
 		Token: token,
 	})
+	stop()
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -56,6 +60,20 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	// An access token is revoked by its jti; anything else is taken to be
+	// a refresh token.
+	if claims, err := auth.ParseJWT(bearerToken, cfg.tokenSecret); err == nil {
+		if claims.ID == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := cfg.revokeToken(r.Context(), claims); err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
 	refresh_token, err := cfg.db.GetRefreshToken(r.Context(), bearerToken)
 	if err != nil {
 		w.WriteHeader(http.StatusUnauthorized)
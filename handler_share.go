@@ -0,0 +1,93 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const shareDescriptionLen = 160
+
+var shareTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta name="twitter:card" content="summary">
+<meta http-equiv="refresh" content="0; url={{.AppURL}}">
+</head>
+<body>
+<a href="{{.AppURL}}">Open in Chirpy</a>
+</body>
+</html>
+`))
+
+type shareData struct {
+	Title       string
+	Description string
+	URL         string
+	AppURL      string
+}
+
+// truncateRunes shortens s to at most n runes so multi-byte characters are
+// never cut in half.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// anonymousChirp looks up a chirp for a page shown to anyone, such as an
+// embed or a share page. Those viewers are anonymous, so hidden and
+// mutual-only chirps are reported as sql.ErrNoRows.
+func (cfg *apiConfig) anonymousChirp(r *http.Request, chirpId uuid.UUID) (database.GetChirpByIDRow, error) {
+	chirp, err := cfg.db.GetChirpByID(r.Context(), database.GetChirpByIDParams{ID: chirpId, Namespace: namespaceOf(r.Context())})
+	if err == nil && (chirp.Chirp.IsHidden || chirp.Chirp.Visibility != database.ChirpVisibilityPublic) {
+		return database.GetChirpByIDRow{}, sql.ErrNoRows
+	}
+	return chirp, err
+}
+
+// handlerShareChirp serves a chirp's share page: preview metadata for
+// crawlers, which is public, so the author appears by user ID.
+func (cfg *apiConfig) handlerShareChirp(w http.ResponseWriter, r *http.Request) {
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	chirp, err := cfg.anonymousChirp(r, chirpId)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	description := truncateRunes(sanitize(chirp.Chirp.Body.String), shareDescriptionLen)
+	if chirp.Chirp.IsNsfw {
+		description = nsfwPlaceholder
+	}
+	data := shareData{
+		Title:       "Chirp by " + chirp.Chirp.UserID.String(),
+		Description: description,
+		URL:         requestBaseURL(r, cfg) + "/share/chirps/" + chirp.Chirp.ID.String(),
+		AppURL:      "/app/?chirp=" + chirp.Chirp.ID.String(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := shareTemplate.Execute(w, data); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShareChirpMetaTags(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	author, token := seedUser(t, cfg, store, "share@example.com")
+	chirp := postChirp(t, handler, `{"body":"say \"hi\" <b>loudly</b>"}`, token)
+
+	rec := serve(handler, "GET", "/share/chirps/"+chirp.ID.String(), "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	html := rec.Body.String()
+	for _, want := range []string{
+		`<meta property="og:title" content="Chirp by ` + author.ID.String() + `">`,
+		`<meta property="og:description" content="say &#34;hi&#34; &lt;b&gt;loudly&lt;/b&gt;">`,
+		`<meta name="twitter:card" content="summary">`,
+		`<meta http-equiv="refresh" content="0; url=/app/?chirp=` + chirp.ID.String() + `">`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("page missing %s\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<b>") {
+		t.Errorf("page contains unescaped body:\n%s", html)
+	}
+}
+
+func TestShareChirpTruncatesDescription(t *testing.T) {
+	if got := truncateRunes(strings.Repeat("é", 200), shareDescriptionLen); len([]rune(got)) != shareDescriptionLen {
+		t.Errorf("got %d runes, want %d", len([]rune(got)), shareDescriptionLen)
+	}
+	if got := truncateRunes("short", shareDescriptionLen); got != "short" {
+		t.Errorf("got %q, want %q", got, "short")
+	}
+}
+
+func TestShareChirpNotFound(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	rec := serve(newServer("0", cfg).Handler, "GET", "/share/chirps/"+uuid.NewString(), "", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// Topics are the hashtags in a chirp body, lowercased without the #.
+var (
+	hashtagPattern = regexp.MustCompile(`(?:^|\s)#([A-Za-z0-9_]+)`)
+	topicPattern   = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+func chirpTopics(body string) []string {
+	var topics []string
+	seen := make(map[string]bool)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		topic := strings.ToLower(m[1])
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+type topicFeedChirpResp struct {
+	chirpResp
+	MatchedTopics []string `json:"matched_topics"`
+}
+
+type topicFeedResp struct {
+	Chirps     []topicFeedChirpResp `json:"chirps"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerSubscribeTopic(w http.ResponseWriter, r *http.Request) {
+	cfg.setTopicSubscription(w, r, true)
+}
+
+func (cfg *apiConfig) handlerUnsubscribeTopic(w http.ResponseWriter, r *http.Request) {
+	cfg.setTopicSubscription(w, r, false)
+}
+
+func (cfg *apiConfig) setTopicSubscription(w http.ResponseWriter, r *http.Request, subscribe bool) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	topic := strings.ToLower(strings.TrimPrefix(r.PathValue("topic"), "#"))
+	if !topicPattern.MatchString(topic) {
+		respondWithError(w, http.StatusBadRequest, "invalid topic")
+		return
+	}
+
+	var err error
+	if subscribe {
+		err = cfg.db.SubscribeTopic(r.Context(), database.SubscribeTopicParams{UserID: userId, Topic: topic})
+	} else {
+		err = cfg.db.UnsubscribeTopic(r.Context(), database.UnsubscribeTopicParams{UserID: userId, Topic: topic})
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerGetTopicFeed returns chirps tagged with any of the caller's
+// subscribed topics. Each chirp appears once, ranked by the number of
+// subscribed topics it matches divided by (1 + its age in hours).
+func (cfg *apiConfig) handlerGetTopicFeed(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var offset int32
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		offset, err = decodeOffsetCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	rows, err := cfg.db.GetTopicFeed(r.Context(), database.GetTopicFeedParams{
+		UserID:     userId,
+		PageSize:   pageSize + 1,
+		PageOffset: offset,
+		Namespace:  namespaceOf(r.Context()),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := topicFeedResp{Chirps: make([]topicFeedChirpResp, 0, len(rows))}
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		resp.NextCursor = encodeOffsetCursor(offset + pageSize)
+	}
+	chirps := make([]chirpResp, 0, len(rows))
+	for _, row := range rows {
+		cr := newChirpResp(row.Chirp)
+		cr.LikeCount, cr.ReplyCount = row.LikeCount, row.ReplyCount
+		cr.Flagged = flaggedFor(row.Chirp, userId)
+		chirps = append(chirps, cr)
+	}
+	if err := cfg.attachMedia(r.Context(), chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	for i, row := range rows {
+		resp.Chirps = append(resp.Chirps, topicFeedChirpResp{
+			chirpResp:     chirps[i],
+			MatchedTopics: row.MatchedTopics,
+		})
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
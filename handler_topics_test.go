@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func getTopicFeed(t *testing.T, h http.Handler, token string) []topicFeedChirpResp {
+	t.Helper()
+	var all []topicFeedChirpResp
+	cursor := ""
+	for {
+		rec := serve(h, "GET", "/api/feed/topics?limit=1&cursor="+cursor, "", token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got status %d, want 200", rec.Code)
+		}
+		var page topicFeedResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatalf("decoding feed: %v", err)
+		}
+		all = append(all, page.Chirps...)
+		if page.NextCursor == "" {
+			return all
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func TestTopicFeedRanking(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	_, readerToken := seedUser(t, cfg, store, "reader@example.com")
+	_, authorToken := seedUser(t, cfg, store, "author@example.com")
+	for _, topic := range []string{"go", "Rust"} {
+		if rec := serve(handler, "POST", "/api/topics/"+topic+"/subscribe", "", readerToken); rec.Code != http.StatusNoContent {
+			t.Fatalf("subscribing to %s: got status %d, want 204", topic, rec.Code)
+		}
+	}
+	one := postChirp(t, handler, `{"body":"learning #go"}`, authorToken)
+	both := postChirp(t, handler, `{"body":"#Go vs #rust #go"}`, authorToken)
+	postChirp(t, handler, `{"body":"just #python"}`, authorToken)
+	postChirp(t, handler, `{"body":"no tags at all"}`, authorToken)
+
+	got := getTopicFeed(t, handler, readerToken)
+	if len(got) != 2 {
+		t.Fatalf("got %d chirps, want 2 (deduplicated, untagged excluded)", len(got))
+	}
+	if got[0].ID != both.ID || got[1].ID != one.ID {
+		t.Errorf("got order %v, %v; want the two-topic chirp first", got[0].Body, got[1].Body)
+	}
+	if !slices.Equal(got[0].MatchedTopics, []string{"go", "rust"}) {
+		t.Errorf("got matched_topics %v, want [go rust]", got[0].MatchedTopics)
+	}
+
+	// An old chirp matching both topics should rank below a fresh one
+	// matching a single topic.
+	for i := range store.chirps {
+		if store.chirps[i].ID == both.ID {
+			store.chirps[i].CreatedAt.Time = time.Now().Add(-5 * time.Hour)
+		}
+	}
+	got = getTopicFeed(t, handler, readerToken)
+	if len(got) != 2 || got[0].ID != one.ID {
+		t.Errorf("got first chirp %q, want the recent single-topic chirp", got[0].Body)
+	}
+}
+
+func TestChirpTopics(t *testing.T) {
+	got := chirpTopics("#Go is fun, #go again and #rust_lang but not a#tag")
+	want := []string{"go", "rust_lang"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type activityResp struct {
+	ChirpsToday          int64 `json:"chirps_today"`
+	ChirpsThisWeek       int64 `json:"chirps_this_week"`
+	LikesReceivedToday   int64 `json:"likes_received_today"`
+	NewFollowersToday    int64 `json:"new_followers_today"`
+	RepliesReceivedToday int64 `json:"replies_received_today"`
+}
+
+// activityWindow returns the start of now's UTC day and of its week, which
+// begins on Monday.
+func activityWindow(now time.Time) (dayStart, weekStart time.Time) {
+	now = now.UTC()
+	dayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	return dayStart, dayStart.AddDate(0, 0, -daysSinceMonday)
+}
+
+func (cfg *apiConfig) handlerGetMyActivity(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	dayStart, weekStart := activityWindow(time.Now())
+	activity, err := cfg.db.GetUserActivity(r.Context(), database.GetUserActivityParams{
+		UserID:    userId,
+		DayStart:  dayStart,
+		WeekStart: weekStart,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, activityResp(activity))
+}
+
+// activityChartTTL is how long a user's activity chart is served from
+// memory, so new chirps can take this long to show up in it.
+const activityChartTTL = 30 * time.Minute
+
+type activityChartPoint struct {
+	Hour  time.Time `json:"hour"`
+	Count int64     `json:"count"`
+}
+
+type activityChartEntry struct {
+	points    []activityChartPoint
+	expiresAt time.Time
+}
+
+// handlerGetActivityChart returns how many public chirps a user posted in
+// each of the last 168 hours. Verified users' charts are public; anyone
+// else's is only shown to themselves. Hidden and mutual-only chirps are
+// never counted, even for the user, because the cached chart is the same
+// for every viewer.
+func (cfg *apiConfig) handlerGetActivityChart(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !user.User.IsVerified {
+		callerId, err := cfg.optionalUserID(r)
+		if err != nil || callerId == uuid.Nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if callerId != userId {
+			respondWithError(w, http.StatusForbidden, "only verified users' activity is public")
+			return
+		}
+	}
+
+	if v, ok := cfg.activityCharts.Load(userId); ok {
+		entry := v.(*activityChartEntry)
+		if cfg.timeNow().Before(entry.expiresAt) {
+			respondWithJSON(w, http.StatusOK, entry.points)
+			return
+		}
+		cfg.activityCharts.CompareAndDelete(userId, v)
+	}
+	rows, err := cfg.db.GetUserHourlyChirpCounts(r.Context(), userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	points := make([]activityChartPoint, 0, len(rows))
+	for _, row := range rows {
+		points = append(points, activityChartPoint{Hour: row.Hour.UTC(), Count: row.Count})
+	}
+	cfg.activityCharts.Store(userId, &activityChartEntry{points: points, expiresAt: cfg.timeNow().Add(activityChartTTL)})
+	respondWithJSON(w, http.StatusOK, points)
+}
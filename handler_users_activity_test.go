@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func getActivity(t *testing.T, h http.Handler, token string) activityResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/users/me/activity", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var resp activityResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestActivityWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantDay  time.Time
+		wantWeek time.Time
+	}{
+		{"wednesday", time.Date(2026, 3, 11, 15, 4, 5, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"monday", time.Date(2026, 3, 9, 0, 0, 1, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"sunday", time.Date(2026, 3, 15, 23, 59, 0, 0, time.UTC), time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+		{"non-utc input", time.Date(2026, 3, 11, 1, 0, 0, 0, time.FixedZone("UTC+3", 3*3600)), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, week := activityWindow(tt.now)
+			if !day.Equal(tt.wantDay) || !week.Equal(tt.wantWeek) {
+				t.Errorf("got %s, %s; want %s, %s", day, week, tt.wantDay, tt.wantWeek)
+			}
+		})
+	}
+}
+
+func TestMyActivityQueryParams(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	u, token := seedUser(t, cfg, store, "alice@example.com")
+	getActivity(t, newServer("0", cfg).Handler, token)
+
+	wantDay, wantWeek := activityWindow(time.Now())
+	got := store.activityParams
+	if got.UserID != u.ID {
+		t.Errorf("got user %s, want %s", got.UserID, u.ID)
+	}
+	if !got.DayStart.Equal(wantDay) || !got.WeekStart.Equal(wantWeek) {
+		t.Errorf("got window %s..%s, want %s..%s", got.WeekStart, got.DayStart, wantWeek, wantDay)
+	}
+}
+
+func TestMyActivityCounts(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+
+	if got := getActivity(t, h, aliceToken); got != (activityResp{}) {
+		t.Fatalf("new user: got %+v, want all zero", got)
+	}
+	c := postChirp(t, h, `{"body":"first"}`, aliceToken)
+	if got := getActivity(t, h, aliceToken).ChirpsToday; got != 1 {
+		t.Errorf("after one chirp: chirps_today = %d, want 1", got)
+	}
+
+	serve(h, "POST", "/api/chirps/"+c.ID.String()+"/like", "", bobToken)
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	postChirp(t, h, `{"body":"reply","parent_id":"`+c.ID.String()+`"}`, bobToken)
+	postChirp(t, h, `{"body":"self reply","parent_id":"`+c.ID.String()+`"}`, aliceToken)
+
+	want := activityResp{
+		ChirpsToday:          2,
+		ChirpsThisWeek:       2,
+		LikesReceivedToday:   1,
+		NewFollowersToday:    1,
+		RepliesReceivedToday: 1,
+	}
+	if got := getActivity(t, h, aliceToken); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMyActivityRequiresAuth(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	if rec := serve(newServer("0", cfg).Handler, "GET", "/api/users/me/activity", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", rec.Code)
+	}
+}
+
+func TestActivityChartFillsEmptyHours(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	store.users[0].IsVerified = true
+	postChirp(t, h, `{"body":"now"}`, token)
+	postChirp(t, h, `{"body":"also now"}`, token)
+	postChirp(t, h, `{"body":"yesterday"}`, token)
+	postChirp(t, h, `{"body":"last month"}`, token)
+	postChirp(t, h, `{"body":"hidden"}`, token)
+	postChirp(t, h, `{"body":"for mutuals","visibility":"mutual"}`, token)
+	store.chirps[2].CreatedAt.Time = time.Now().Add(-24 * time.Hour)
+	store.chirps[3].CreatedAt.Time = time.Now().Add(-30 * 24 * time.Hour)
+	store.chirps[4].IsHidden = true
+
+	rec := serve(h, "GET", "/api/users/"+alice.ID.String()+"/activity-chart", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"count":0`) {
+		t.Errorf("empty hours are missing a count of 0: %s", rec.Body.String())
+	}
+	var points []activityChartPoint
+	if err := json.Unmarshal(rec.Body.Bytes(), &points); err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 168 {
+		t.Fatalf("got %d points, want 168", len(points))
+	}
+	var total int64
+	for i, p := range points {
+		total += p.Count
+		if i > 0 && !p.Hour.Equal(points[i-1].Hour.Add(time.Hour)) {
+			t.Fatalf("point %d at %s does not follow %s", i, p.Hour, points[i-1].Hour)
+		}
+	}
+	if points[167].Count != 2 || points[143].Count != 1 || total != 3 {
+		t.Errorf("got %d this hour, %d a day ago, %d in all; want 2, 1, 3", points[167].Count, points[143].Count, total)
+	}
+}
+
+func TestActivityChartAccess(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	path := "/api/users/" + alice.ID.String() + "/activity-chart"
+
+	for _, c := range []struct {
+		name, token string
+		want        int
+	}{
+		{"anonymous", "", http.StatusUnauthorized},
+		{"another user", bobToken, http.StatusForbidden},
+		{"the user", aliceToken, http.StatusOK},
+	} {
+		if rec := serve(h, "GET", path, "", c.token); rec.Code != c.want {
+			t.Errorf("%s on an unverified user: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+	}
+	store.users[0].IsVerified = true
+	if rec := serve(h, "GET", path, "", ""); rec.Code != http.StatusOK {
+		t.Errorf("anonymous on a verified user: got status %d, want 200", rec.Code)
+	}
+}
+
+func TestActivityChartCached(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Now()}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	path := "/api/users/" + alice.ID.String() + "/activity-chart"
+
+	count := func() int64 {
+		t.Helper()
+		var points []activityChartPoint
+		json.Unmarshal(serve(h, "GET", path, "", token).Body.Bytes(), &points)
+		var total int64
+		for _, p := range points {
+			total += p.Count
+		}
+		return total
+	}
+	if n := count(); n != 0 {
+		t.Fatalf("got %d chirps before posting any", n)
+	}
+	postChirp(t, h, `{"body":"fresh"}`, token)
+	if n := count(); n != 0 {
+		t.Errorf("got %d chirps within the cache TTL, want the cached 0", n)
+	}
+	clock.t = clock.t.Add(activityChartTTL)
+	if n := count(); n != 1 {
+		t.Errorf("got %d chirps once the cache expired, want 1", n)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type userChirpsResp struct {
+	User       userResp    `json:"user"`
+	Chirps     []chirpResp `json:"chirps"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// farFuture bounds descending scans that start without a cursor.
+var farFuture = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func (cfg *apiConfig) handlerGetUserChirps(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	pageSize, err := parsePageSize(r)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	desc := r.URL.Query().Get("sort") == "desc"
+	viewer, _ := cfg.optionalUserID(r)
+
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	cursorCreatedAt, cursorID := time.Time{}, uuid.Nil
+	if desc {
+		cursorCreatedAt, cursorID = farFuture, uuid.Max
+	}
+	if cursor := r.URL.Query().Get("cursor"); cursor != "" {
+		cursorCreatedAt, cursorID, err = decodeCursor(cursor)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	// Fetch one extra row to learn whether another page follows.
+	var chirps []database.GetUserChirpsAscRow
+	if desc {
+		var rows []database.GetUserChirpsDescRow
+		rows, err = cfg.db.GetUserChirpsDesc(r.Context(), database.GetUserChirpsDescParams{
+			UserID:          userId,
+			ViewerID:        viewer,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			PageSize:        pageSize + 1,
+			Namespace:       namespaceOf(r.Context()),
+		})
+		for _, row := range rows {
+			chirps = append(chirps, database.GetUserChirpsAscRow(row))
+		}
+	} else {
+		chirps, err = cfg.db.GetUserChirpsAsc(r.Context(), database.GetUserChirpsAscParams{
+			UserID:          userId,
+			ViewerID:        viewer,
+			CursorCreatedAt: cursorCreatedAt,
+			CursorID:        cursorID,
+			PageSize:        pageSize + 1,
+			Namespace:       namespaceOf(r.Context()),
+		})
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+
+	resp := userChirpsResp{
+		User:   newPublicProfileResp(user),
+		Chirps: make([]chirpResp, 0, len(chirps)),
+	}
+	if len(chirps) > int(pageSize) {
+		chirps = chirps[:pageSize]
+		last := chirps[len(chirps)-1].Chirp
+		resp.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	for _, c := range chirps {
+		cr := newChirpResp(c.Chirp)
+		cr.LikeCount, cr.ReplyCount = c.LikeCount, c.ReplyCount
+		cr.Flagged = flaggedFor(c.Chirp, viewer)
+		resp.Chirps = append(resp.Chirps, cr)
+	}
+	if err := cfg.attachMedia(r.Context(), resp.Chirps); err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetUserChirpsPagination(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	author, token := seedUser(t, cfg, store, "author@example.com")
+	_, otherToken := seedUser(t, cfg, store, "other@example.com")
+	var want []string
+	for _, body := range []string{"one", "two", "three", "four", "five"} {
+		serve(handler, "POST", "/api/chirps", `{"body":"`+body+`"}`, token)
+		want = append(want, body)
+	}
+	serve(handler, "POST", "/api/chirps", `{"body":"not mine"}`, otherToken)
+
+	for _, sort := range []string{"asc", "desc"} {
+		t.Run(sort, func(t *testing.T) {
+			var got []string
+			cursor := ""
+			pages := 0
+			for {
+				rec := serve(handler, "GET", "/api/users/"+author.ID.String()+"/chirps?limit=2&sort="+sort+"&cursor="+cursor, "", "")
+				if rec.Code != 200 {
+					t.Fatalf("got status %d, want 200", rec.Code)
+				}
+				var page userChirpsResp
+				if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+					t.Fatalf("decoding page: %v", err)
+				}
+				if page.User.ID != author.ID {
+					t.Errorf("got user %v, want %v", page.User.ID, author.ID)
+				}
+				for _, c := range page.Chirps {
+					got = append(got, c.Body)
+				}
+				pages++
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+			expected := append([]string(nil), want...)
+			if sort == "desc" {
+				for i, j := 0, len(expected)-1; i < j; i, j = i+1, j-1 {
+					expected[i], expected[j] = expected[j], expected[i]
+				}
+			}
+			if pages != 3 {
+				t.Errorf("got %d pages, want 3", pages)
+			}
+			if len(got) != len(expected) {
+				t.Fatalf("got %v, want %v", got, expected)
+			}
+			for i := range got {
+				if got[i] != expected[i] {
+					t.Errorf("got %v, want %v", got, expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestGetUserChirpsUnknownUser(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	rec := serve(newServer("0", cfg).Handler, "GET", "/api/users/"+uuid.NewString()+"/chirps", "", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
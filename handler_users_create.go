@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -10,51 +12,74 @@ import (
 	"github.com/azs06/Chirpy/internal/database"
 )
 
+type createUserParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 func (cfg *apiConfig) handlerCreateUser(w http.ResponseWriter, r *http.Request) {
-	type parameters struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
 	type errResp struct {
 		Error string `json:"error"`
 	}
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
+	params := createUserParams{}
 	err := decoder.Decode(&params)
 	if err != nil {
 		fmt.Println(err)
 		w.WriteHeader(500)
 		return
 	}
-	hPassword, err := auth.HashPassword(params.Password)
-	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+	user, err := cfg.createUser(r.Context(), params)
+	if errors.Is(err, errEmailDomainBlocked) {
+		respondWithError(w, http.StatusUnprocessableEntity, err.Error())
 		return
 	}
-	userData := database.CreateUserParams{
-		Email: sql.NullString{
-			String: params.Email,
-			Valid:  params.Email != "",
-		},
-		HashedPassword: hPassword,
-	}
-	user, err := cfg.db.CreateUser(r.Context(), userData)
-
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+		cfg.respondWithDBError(w, err)
 		return
 	}
-
+	stop := startWriterPhase(w, "marshal")
 	dat, _ := json.Marshal(userResp{
 		ID:          user.ID,
 		CreatedAt:   user.CreatedAt.Time,
 		UpdatedAt:   user.UpdatedAt.Time,
 		Email:       user.Email.String,
 		IsChirpyRed: user.IsChirpyRed,
+		IsVerified:  user.IsVerified,
 	})
+	stop()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(201)
 	w.Write(dat)
 }
+
+var errEmailDomainBlocked = errors.New("email domain not allowed")
+
+// createUser signs up a user, shared by the HTTP and gRPC APIs. It fails
+// with errEmailDomainBlocked for a blocked email domain.
+func (cfg *apiConfig) createUser(ctx context.Context, params createUserParams) (database.User, error) {
+	blocked, err := cfg.isEmailDomainBlocked(ctx, params.Email)
+	if err != nil {
+		return database.User{}, err
+	}
+	if blocked {
+		return database.User{}, errEmailDomainBlocked
+	}
+	hPassword, err := auth.HashPassword(params.Password)
+	if err != nil {
+		return database.User{}, err
+	}
+	user, err := cfg.db.CreateUser(ctx, database.CreateUserParams{
+		Email: sql.NullString{
+			String: params.Email,
+			Valid:  params.Email != "",
+		},
+		HashedPassword: hPassword,
+		Namespace:      namespaceOf(ctx),
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	cfg.audit(ctx, "user.created", "user", user.ID, nil)
+	return user, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// newPublicProfileResp is newUserProfileResp for anyone but the user
+// themselves, who alone see their email.
+func newPublicProfileResp(u database.GetUserByIdRow) userResp {
+	resp := newUserProfileResp(u)
+	resp.Email = ""
+	return resp
+}
+
+func newUserProfileResp(u database.GetUserByIdRow) userResp {
+	resp := newUserResp(u.User)
+	resp.FollowersCount = u.FollowersCount
+	resp.FollowingCount = u.FollowingCount
+	resp.ChirpsCount = u.ChirpsCount
+	return resp
+}
+
+func (cfg *apiConfig) handlerGetUser(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	bust := r.URL.Query().Get("cache_bust") == "true" && cfg.callerIsAdmin(r)
+	if resp, ok := cfg.cachedUser(namespaceOf(r.Context()), userId); ok && !bust {
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	resp := newPublicProfileResp(user)
+	cfg.storeUser(namespaceOf(r.Context()), resp)
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handlerGetMe returns the caller's profile along with the variant of each
+// experiment they are in.
+func (cfg *apiConfig) handlerGetMe(w http.ResponseWriter, r *http.Request) {
+	caller, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	// The profile carries follower and chirp counts, which the context's
+	// user does not.
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: caller.ID, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	resp := newUserProfileResp(user)
+	resp.PendingEmail = user.User.PendingEmail.String
+	resp.Experiments = cfg.experiments.variants(caller.ID)
+	respondWithJSON(w, http.StatusOK, resp)
+}
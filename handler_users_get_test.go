@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetUserProfileCounts(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	user, token := seedUser(t, cfg, store, "profile@example.com")
+	for i := range 3 {
+		serve(handler, "POST", "/api/chirps", fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+	for i := range 2 {
+		_, followerToken := seedUser(t, cfg, store, fmt.Sprintf("follower%d@example.com", i))
+		serve(handler, "POST", "/api/users/"+user.ID.String()+"/follow", "", followerToken)
+	}
+
+	rec := serve(handler, "GET", "/api/users/"+user.ID.String(), "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var got userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decoding profile: %v", err)
+	}
+	if got.ChirpsCount != 3 || got.FollowersCount != 2 || got.FollowingCount != 0 {
+		t.Errorf("got chirps=%d followers=%d following=%d, want 3, 2, 0",
+			got.ChirpsCount, got.FollowersCount, got.FollowingCount)
+	}
+}
+
+func TestGetUserProfileNotFound(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	rec := serve(newServer("0", cfg).Handler, "GET", "/api/users/"+uuid.NewString(), "", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want 404", rec.Code)
+	}
+}
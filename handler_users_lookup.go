@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxUserLookupIDs = 100
+	// userLookupsPerMinute is separate from the chirp rate because one
+	// lookup can stand in for a hundred profile loads.
+	userLookupsPerMinute = 20
+)
+
+type userLookupParams struct {
+	IDs []uuid.UUID `json:"ids"`
+}
+
+// handlerLookupUsers resolves many user IDs in one round trip, for clients
+// rendering lists of chirps. The response is keyed by ID; IDs with no user
+// in the request's namespace are left out, and no email is returned.
+func (cfg *apiConfig) handlerLookupUsers(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authUserFromContext(r.Context()); !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var params userLookupParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "ids must be a list of user ids")
+		return
+	}
+	if len(params.IDs) > maxUserLookupIDs {
+		respondWithError(w, http.StatusBadRequest, "at most 100 ids may be looked up at once")
+		return
+	}
+	resp := make(map[uuid.UUID]userResp, len(params.IDs))
+	if len(params.IDs) == 0 {
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+	users, err := cfg.db.GetUsersByIds(r.Context(), database.GetUsersByIdsParams{
+		Ids:       params.IDs,
+		Namespace: namespaceOf(r.Context()),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	for _, u := range users {
+		resp[u.User.ID] = newPublicProfileResp(database.GetUserByIdRow(u))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestLookupUsersPartialResults(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, _ := seedUser(t, cfg, store, "bob@example.com")
+	carol, _ := seedUser(t, cfg, store, "carol@example.com")
+	store.users[2].Namespace = "team"
+	missing := uuid.New()
+
+	body := `{"ids":["` + alice.ID.String() + `","` + missing.String() + `","` + bob.ID.String() + `","` + carol.ID.String() + `"]}`
+	rec := serve(h, "POST", "/api/users/lookup", body, aliceToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp map[uuid.UUID]userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 2 {
+		t.Fatalf("got %d users, want 2: %v", len(resp), resp)
+	}
+	if resp[alice.ID].ID != alice.ID || resp[bob.ID].ID != bob.ID {
+		t.Errorf("got %+v", resp)
+	}
+	if strings.Contains(rec.Body.String(), "@example.com") {
+		t.Errorf("lookup leaks an email: %s", rec.Body.String())
+	}
+	if _, ok := resp[missing]; ok {
+		t.Errorf("unknown id %s is in the response", missing)
+	}
+	if _, ok := resp[carol.ID]; ok {
+		t.Errorf("user %s from another namespace is in the response", carol.ID)
+	}
+}
+
+func TestLookupUsersValidation(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	ids := make([]string, maxUserLookupIDs+1)
+	for i := range ids {
+		ids[i] = `"` + uuid.NewString() + `"`
+	}
+	cases := []struct {
+		name, body, token string
+		want              int
+	}{
+		{"no token", `{"ids":[]}`, "", http.StatusUnauthorized},
+		{"bad id", `{"ids":["nope"]}`, token, http.StatusBadRequest},
+		{"too many", `{"ids":[` + strings.Join(ids, ",") + `]}`, token, http.StatusBadRequest},
+		{"empty", `{"ids":[]}`, token, http.StatusOK},
+	}
+	for _, c := range cases {
+		if rec := serve(h, "POST", "/api/users/lookup", c.body, c.token); rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.name, rec.Code, c.want)
+		}
+	}
+}
+
+func TestLookupUsersRateLimit(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 1
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	body := `{"ids":["` + alice.ID.String() + `"]}`
+
+	for i := range userLookupsPerMinute {
+		if rec := serve(h, "POST", "/api/users/lookup", body, aliceToken); rec.Code != http.StatusOK {
+			t.Fatalf("lookup %d: got status %d, want 200", i+1, rec.Code)
+		}
+	}
+	rec := serve(h, "POST", "/api/users/lookup", body, aliceToken)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the limit: got status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("X-RateLimit-Limit"); got != "20" {
+		t.Errorf("got X-RateLimit-Limit %q, want 20", got)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"lookups do not use my chirp budget"}`, aliceToken); rec.Code != http.StatusCreated {
+		t.Errorf("chirp after lookups: got status %d, want 201", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/users/lookup", body, bobToken); rec.Code != http.StatusOK {
+		t.Errorf("another user: got status %d, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Relationship describes how the caller relates to another user. Blocking and
+// muting are not modelled yet and are always reported as false.
+type Relationship struct {
+	Following  bool `json:"following"`
+	FollowedBy bool `json:"followed_by"`
+	Mutual     bool `json:"mutual"`
+	Blocking   bool `json:"blocking"`
+	Muting     bool `json:"muting"`
+}
+
+func (cfg *apiConfig) getUserRelationship(ctx context.Context, callerID, targetID uuid.UUID) (Relationship, error) {
+	row, err := cfg.db.GetFollowRelationship(ctx, database.GetFollowRelationshipParams{
+		CallerID: callerID,
+		TargetID: targetID,
+	})
+	if err != nil {
+		return Relationship{}, err
+	}
+	return Relationship{
+		Following:  row.Following,
+		FollowedBy: row.FollowedBy,
+		Mutual:     row.Following && row.FollowedBy,
+	}, nil
+}
+
+func (cfg *apiConfig) handlerGetRelationship(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	callerId := user.ID
+	targetId, err := uuid.Parse(r.PathValue("userId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	if _, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: targetId, Namespace: namespaceOf(r.Context())}); err != nil {
+		respondWithError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	rel, err := cfg.getUserRelationship(r.Context(), callerId, targetId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, rel)
+}
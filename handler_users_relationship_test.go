@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGetRelationship(t *testing.T) {
+	tests := []struct {
+		name         string
+		callerFollow bool
+		targetFollow bool
+		want         Relationship
+	}{
+		{"none", false, false, Relationship{}},
+		{"following", true, false, Relationship{Following: true}},
+		{"followed by", false, true, Relationship{FollowedBy: true}},
+		{"mutual", true, true, Relationship{Following: true, FollowedBy: true, Mutual: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			cfg := newTestConfig(store)
+			handler := newServer("0", cfg).Handler
+			caller, callerToken := seedUser(t, cfg, store, "caller@example.com")
+			target, targetToken := seedUser(t, cfg, store, "target@example.com")
+			if tt.callerFollow {
+				serve(handler, "POST", "/api/users/"+target.ID.String()+"/follow", "", callerToken)
+			}
+			if tt.targetFollow {
+				serve(handler, "POST", "/api/users/"+caller.ID.String()+"/follow", "", targetToken)
+			}
+
+			rec := serve(handler, "GET", "/api/users/"+target.ID.String()+"/relationship", "", callerToken)
+			if rec.Code != 200 {
+				t.Fatalf("got status %d, want 200", rec.Code)
+			}
+			var got Relationship
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding relationship: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetRelationshipRequiresAuth(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	target, _ := seedUser(t, cfg, store, "target@example.com")
+	rec := serve(newServer("0", cfg).Handler, "GET", "/api/users/"+target.ID.String()+"/relationship", "", "")
+	if rec.Code != 401 {
+		t.Errorf("got status %d, want 401", rec.Code)
+	}
+}
+
+func TestFollowListsIncludeIsMutual(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	handler := newServer("0", cfg).Handler
+	a, aToken := seedUser(t, cfg, store, "a@example.com")
+	b, bToken := seedUser(t, cfg, store, "b@example.com")
+	c, cToken := seedUser(t, cfg, store, "c@example.com")
+	serve(handler, "POST", "/api/users/"+b.ID.String()+"/follow", "", aToken)
+	serve(handler, "POST", "/api/users/"+a.ID.String()+"/follow", "", bToken)
+	serve(handler, "POST", "/api/users/"+a.ID.String()+"/follow", "", cToken)
+
+	rec := serve(handler, "GET", "/api/users/"+a.ID.String()+"/followers", "", "")
+	var followersPage followUsersResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &followersPage); err != nil {
+		t.Fatalf("decoding followers: %v", err)
+	}
+	followers := followersPage.Users
+	want := map[string]bool{b.ID.String(): true, c.ID.String(): false}
+	if len(followers) != len(want) {
+		t.Fatalf("got %d followers, want %d", len(followers), len(want))
+	}
+	for _, f := range followers {
+		if f.IsMutual != want[f.ID.String()] {
+			t.Errorf("follower %s: got is_mutual=%v, want=%v", f.Email, f.IsMutual, want[f.ID.String()])
+		}
+	}
+
+	rec = serve(handler, "GET", "/api/users/"+c.ID.String()+"/following", "", "")
+	var followingPage followUsersResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &followingPage); err != nil {
+		t.Fatalf("decoding following: %v", err)
+	}
+	following := followingPage.Users
+	if len(following) != 1 || following[0].ID != a.ID || following[0].IsMutual {
+		t.Errorf("got following=%+v, want only a, not mutual", following)
+	}
+}
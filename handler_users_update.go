@@ -1,7 +1,6 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,64 +9,90 @@ import (
 	"github.com/azs06/Chirpy/internal/database"
 )
 
+type updateUserParams struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 func (cfg *apiConfig) handlerUpdateUser(w http.ResponseWriter, r *http.Request) {
-	type parameters struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
-	}
 	type errResp struct {
 		Error string `json:"error"`
 	}
-	bearerToken, err := auth.GetBearerToken(r.Header)
-
-	if err != nil {
+	current, ok := authUserFromContext(r.Context())
+	if !ok {
 		w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
-
-	userId, err := auth.ValidateJWT(bearerToken, cfg.tokenSecret)
-
-	if err != nil {
-		w.WriteHeader(401)
-		return
-	}
+	userId := current.ID
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
-	err = decoder.Decode(&params)
+	params := updateUserParams{}
+	err := decoder.Decode(&params)
 	if err != nil {
 		fmt.Println(err)
 		w.WriteHeader(500)
 		return
 	}
+	changingEmail := params.Email != "" && params.Email != current.Email.String
+	if changingEmail {
+		blocked, err := cfg.isEmailDomainBlocked(r.Context(), params.Email)
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusUnprocessableEntity, "email domain not allowed")
+			return
+		}
+		taken, err := cfg.emailTaken(r.Context(), params.Email, current)
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		if taken {
+			respondWithError(w, http.StatusConflict, "email already in use")
+			return
+		}
+	}
 	hPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		fmt.Println(err)
 		w.WriteHeader(500)
 		return
 	}
+	// The email only changes once the new address confirms it; see
+	// handlerConfirmEmailChange.
 	userData := database.UpdateUserParams{
-		ID: userId,
-		Email: sql.NullString{
-			String: params.Email,
-			Valid:  params.Email != "",
-		},
+		ID:             userId,
+		Email:          current.Email,
 		HashedPassword: hPassword,
 	}
 	user, err := cfg.db.UpdateUser(r.Context(), userData)
 
 	if err != nil {
-		fmt.Println(err)
-		w.WriteHeader(500)
+		cfg.respondWithDBError(w, err)
 		return
 	}
+	if changingEmail {
+		if user, err = cfg.startEmailChange(r.Context(), user, params.Email); err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+	}
+	cfg.evictUser(user)
+	cfg.audit(withActor(r.Context(), userId), "user.updated", "user", user.ID, nil)
 
+	stop := startWriterPhase(w, "marshal")
 	dat, _ := json.Marshal(userResp{
 		ID:          user.ID,
 		CreatedAt:   user.CreatedAt.Time,
 		UpdatedAt:   user.UpdatedAt.Time,
 		Email:       user.Email.String,
 		IsChirpyRed: user.IsChirpyRed,
+		IsVerified:  user.IsVerified,
+
+		PendingEmail: user.PendingEmail.String,
 	})
+	stop()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(200)
 	w.Write(dat)
@@ -9,16 +9,17 @@ import (
 	"github.com/google/uuid"
 )
 
+type polkaWebhookData struct {
+	UserId uuid.UUID `json:"user_id"`
+}
+
+type polkaWebhookParams struct {
+	Event string           `json:"event"`
+	Data  polkaWebhookData `json:"data"`
+}
+
 func (cfg *apiConfig) handlerWebhook(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	type data struct {
-		UserId uuid.UUID `json:"user_id"`
-	}
-
-	type parameters struct {
-		Event string `json:"event"`
-		Data  data   `json:"data"`
-	}
 
 	apiKey, err := auth.GetAPIKey(r.Header)
 	if err != nil || apiKey != cfg.polkaKey {
@@ -27,7 +28,7 @@ func (cfg *apiConfig) handlerWebhook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	decoder := json.NewDecoder(r.Body)
-	params := parameters{}
+	params := polkaWebhookParams{}
 	err = decoder.Decode(&params)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
@@ -43,10 +44,11 @@ func (cfg *apiConfig) handlerWebhook(w http.ResponseWriter, r *http.Request) {
 		ID:          params.Data.UserId,
 		IsChirpyRed: true,
 	}
-	_, err = cfg.db.ToggleChirpRed(r.Context(), payload)
+	user, err := cfg.db.ToggleChirpRed(r.Context(), payload)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
+	cfg.evictUser(user)
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHealthHistorySize = 100
+	healthCheckInterval      = 30 * time.Second
+	healthCheckTimeout       = 5 * time.Second
+)
+
+type healthRecord struct {
+	Status    string    `json:"status"`
+	DBOK      bool      `json:"db_ok"`
+	Timestamp time.Time `json:"timestamp"`
+	LatencyMs int64     `json:"latency_ms"`
+}
+
+// healthHistory is a fixed-size ring buffer of health checks. Once full,
+// each new record overwrites the oldest.
+type healthHistory struct {
+	mu      sync.Mutex
+	records []healthRecord
+	head    int // index the next record is written to
+	full    bool
+}
+
+func newHealthHistory(size int) *healthHistory {
+	return &healthHistory{records: make([]healthRecord, size)}
+}
+
+func (h *healthHistory) add(rec healthRecord) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.head] = rec
+	h.head = (h.head + 1) % len(h.records)
+	if h.head == 0 {
+		h.full = true
+	}
+}
+
+// snapshot returns the buffered records newest first.
+func (h *healthHistory) snapshot() []healthRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := h.head
+	if h.full {
+		n = len(h.records)
+	}
+	out := make([]healthRecord, 0, n)
+	for i := 1; i <= n; i++ {
+		out = append(out, h.records[(h.head-i+len(h.records))%len(h.records)])
+	}
+	return out
+}
+
+// checkHealth pings the database and reports the result stamped with now.
+func (cfg *apiConfig) checkHealth(ctx context.Context, now time.Time) healthRecord {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	start := time.Now()
+	err := cfg.db.PingDatabase(ctx)
+	rec := healthRecord{
+		Status:    "ok",
+		DBOK:      err == nil,
+		Timestamp: now.UTC(),
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		rec.Status = "degraded"
+	}
+	return rec
+}
+
+// runHealthCollector records a health check into cfg.healthHistory each
+// time tick fires. It returns when tick is closed or ctx is cancelled.
+func (cfg *apiConfig) runHealthCollector(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-tick:
+			if !ok {
+				return
+			}
+			cfg.healthHistory.add(cfg.checkHealth(ctx, t))
+		}
+	}
+}
+
+func (cfg *apiConfig) handlerHealthHistory(w http.ResponseWriter, r *http.Request) {
+	records := []healthRecord{}
+	if cfg.healthHistory != nil {
+		records = cfg.healthHistory.snapshot()
+	}
+	respondWithJSON(w, http.StatusOK, records)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// downStore fails every health check ping.
+type downStore struct {
+	*memStore
+}
+
+func (s *downStore) PingDatabase(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+var healthEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestHealthHistoryOverflow(t *testing.T) {
+	h := newHealthHistory(3)
+	for i := range 5 {
+		h.add(healthRecord{Timestamp: healthEpoch.Add(time.Duration(i) * time.Second)})
+	}
+	got := h.snapshot()
+	if len(got) != 3 {
+		t.Fatalf("got %d records, want 3", len(got))
+	}
+	for i, want := range []int{4, 3, 2} {
+		if !got[i].Timestamp.Equal(healthEpoch.Add(time.Duration(want) * time.Second)) {
+			t.Errorf("record %d: got %s, want t+%ds", i, got[i].Timestamp, want)
+		}
+	}
+}
+
+func TestHealthHistoryEndpointOrder(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.healthHistory = newHealthHistory(defaultHealthHistorySize)
+	cfg.healthHistory.add(healthRecord{Status: "ok", DBOK: true, Timestamp: healthEpoch})
+	cfg.healthHistory.add(healthRecord{Status: "degraded", Timestamp: healthEpoch.Add(30 * time.Second)})
+
+	rec := serve(newServer("0", cfg).Handler, "GET", "/admin/health-history", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", rec.Code)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0]["status"] != "degraded" || got[1]["status"] != "ok" {
+		t.Fatalf("got %v, want newest first", got)
+	}
+	for _, field := range []string{"status", "db_ok", "timestamp", "latency_ms"} {
+		if _, ok := got[0][field]; !ok {
+			t.Errorf("record is missing %q", field)
+		}
+	}
+}
+
+func TestHealthHistoryEndpointEmpty(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	rec := serve(newServer("0", cfg).Handler, "GET", "/admin/health-history", "", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "[]" {
+		t.Errorf("got %d %s, want 200 []", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHealthCollector(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        *apiConfig
+		wantStatus string
+		wantDBOK   bool
+	}{
+		{"database up", newTestConfig(newMemStore()), "ok", true},
+		{"database down", newTestConfig(&downStore{newMemStore()}), "degraded", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.healthHistory = newHealthHistory(defaultHealthHistorySize)
+			tick := make(chan time.Time)
+			done := make(chan struct{})
+			go func() {
+				cfg.runHealthCollector(context.Background(), tick)
+				close(done)
+			}()
+			tick <- healthEpoch
+			tick <- healthEpoch.Add(healthCheckInterval)
+			close(tick)
+			<-done
+
+			got := cfg.healthHistory.snapshot()
+			if len(got) != 2 {
+				t.Fatalf("got %d records, want 2", len(got))
+			}
+			if !got[0].Timestamp.Equal(healthEpoch.Add(healthCheckInterval)) || !got[1].Timestamp.Equal(healthEpoch) {
+				t.Errorf("records not stamped with tick times: %+v", got)
+			}
+			if got[0].Status != tt.wantStatus || got[0].DBOK != tt.wantDBOK {
+				t.Errorf("got %+v, want status %q db_ok %v", got[0], tt.wantStatus, tt.wantDBOK)
+			}
+		})
+	}
+}
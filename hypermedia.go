@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	jsonAPIContentType = "application/vnd.api+json"
+	halContentType     = "application/hal+json"
+)
+
+// negotiateMediaType picks the response format for an Accept header: one
+// of jsonAPIContentType, halContentType or plain application/json, which
+// also covers wildcards and NDJSON since handlers that stream decide that
+// themselves, and text/html, which asks chirp endpoints for rendered_body.
+// It reports false when nothing acceptable is listed.
+func negotiateMediaType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return "application/json", true
+	}
+	best, bestQ := "", 0.0
+	for _, v := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(s, 64); err != nil {
+				continue
+			}
+		}
+		var format string
+		switch mt {
+		case jsonAPIContentType, halContentType:
+			format = mt
+		case "application/json", ndjsonContentType, "text/html", "application/*", "*/*":
+			format = "application/json"
+		default:
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best, best != ""
+}
+
+// hypermediaWriter marks a response that respondWithJSON should render as
+// JSON:API or HAL rather than plain JSON.
+type hypermediaWriter struct {
+	http.ResponseWriter
+	format string
+	// self is the request URI, linked from HAL collections.
+	self string
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *hypermediaWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// middlewareMediaType negotiates the format of /api/ responses from the
+// Accept header, answering 406 when the client accepts none of them.
+func middlewareMediaType(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		format, ok := negotiateMediaType(r.Header.Get("Accept"))
+		if !ok {
+			respondWithError(w, http.StatusNotAcceptable, "acceptable formats are application/json, "+jsonAPIContentType+" and "+halContentType)
+			return
+		}
+		if format != "application/json" {
+			w = &hypermediaWriter{ResponseWriter: w, format: format, self: r.URL.RequestURI()}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// render converts payload to w's format. Only chirps have a hypermedia
+// representation; anything else, errors included, stays plain JSON.
+func (w *hypermediaWriter) render(payload any) (any, string, error) {
+	switch p := payload.(type) {
+	case chirpResp:
+		if w.format == jsonAPIContentType {
+			res, err := jsonAPIChirp(p)
+			return jsonAPIDocument{Data: res}, w.format, err
+		}
+		res, err := halChirp(p)
+		return res, w.format, err
+	case []chirpResp:
+		if w.format == jsonAPIContentType {
+			data := make([]jsonAPIResource, 0, len(p))
+			for _, c := range p {
+				res, err := jsonAPIChirp(c)
+				if err != nil {
+					return nil, "", err
+				}
+				data = append(data, res)
+			}
+			return jsonAPIDocument{Data: data}, w.format, nil
+		}
+		chirps := make([]map[string]any, 0, len(p))
+		for _, c := range p {
+			res, err := halChirp(c)
+			if err != nil {
+				return nil, "", err
+			}
+			chirps = append(chirps, res)
+		}
+		return map[string]any{
+			"_links":    map[string]halLink{"self": {Href: w.self}},
+			"_embedded": map[string]any{"chirps": chirps},
+		}, w.format, nil
+	}
+	return payload, "application/json", nil
+}
+
+type jsonAPIDocument struct {
+	Data any `json:"data"`
+}
+
+type jsonAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id"`
+	Attributes    map[string]json.RawMessage     `json:"attributes"`
+	Relationships map[string]jsonAPIRelationship `json:"relationships"`
+}
+
+type jsonAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type jsonAPIRelationship struct {
+	Data jsonAPIIdentifier `json:"data"`
+}
+
+type halLink struct {
+	Href string `json:"href"`
+}
+
+// chirpFields returns the JSON members of c, keyed by name.
+func chirpFields(c chirpResp) (map[string]json.RawMessage, error) {
+	dat, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(dat, &fields)
+	return fields, err
+}
+
+// jsonAPIChirp moves the chirp's ID, author and parent out of its
+// attributes and into the resource identifier and relationships.
+func jsonAPIChirp(c chirpResp) (jsonAPIResource, error) {
+	attrs, err := chirpFields(c)
+	if err != nil {
+		return jsonAPIResource{}, err
+	}
+	delete(attrs, "id")
+	delete(attrs, "user_id")
+	delete(attrs, "parent_id")
+	res := jsonAPIResource{
+		Type:       "chirps",
+		ID:         c.ID.String(),
+		Attributes: attrs,
+		Relationships: map[string]jsonAPIRelationship{
+			"author": {Data: jsonAPIIdentifier{Type: "users", ID: c.UserId}},
+		},
+	}
+	if c.ParentId != nil {
+		res.Relationships["parent"] = jsonAPIRelationship{Data: jsonAPIIdentifier{Type: "chirps", ID: c.ParentId.String()}}
+	}
+	return res, nil
+}
+
+// halChirp adds _links to the chirp's fields and moves its media under
+// _embedded.
+func halChirp(c chirpResp) (map[string]any, error) {
+	fields, err := chirpFields(c)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "media")
+	res := make(map[string]any, len(fields)+2)
+	for k, v := range fields {
+		res[k] = v
+	}
+	links := map[string]halLink{
+		"self":   {Href: "/api/chirps/" + c.ID.String()},
+		"author": {Href: "/api/users/" + c.UserId},
+	}
+	if c.ParentId != nil {
+		links["parent"] = halLink{Href: "/api/chirps/" + c.ParentId.String()}
+	}
+	res["_links"] = links
+	if len(c.Media) > 0 {
+		res["_embedded"] = map[string]any{"media": c.Media}
+	}
+	return res, nil
+}
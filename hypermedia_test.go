@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func getWithAccept(h http.Handler, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestChirpResponseFormats(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	c := postChirp(t, h, `{"body":"formats"}`, token)
+	path := "/api/chirps/" + c.ID.String()
+
+	t.Run("json", func(t *testing.T) {
+		rec := getWithAccept(h, path, "application/json")
+		var got chirpResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.ID != c.ID || got.Body != "formats" {
+			t.Errorf("got %s (%v), want the plain chirp", rec.Body.String(), err)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got Content-Type %q", ct)
+		}
+	})
+
+	t.Run("json:api", func(t *testing.T) {
+		rec := getWithAccept(h, path, jsonAPIContentType)
+		if ct := rec.Header().Get("Content-Type"); ct != jsonAPIContentType {
+			t.Errorf("got Content-Type %q, want %q", ct, jsonAPIContentType)
+		}
+		var doc struct {
+			Data struct {
+				Type          string
+				ID            string
+				Attributes    map[string]any
+				Relationships map[string]jsonAPIRelationship
+			}
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		d := doc.Data
+		if d.Type != "chirps" || d.ID != c.ID.String() || d.Attributes["body"] != "formats" {
+			t.Errorf("got resource %+v", d)
+		}
+		if _, ok := d.Attributes["id"]; ok {
+			t.Errorf("attributes repeat the id: %v", d.Attributes)
+		}
+		if author := d.Relationships["author"].Data; author != (jsonAPIIdentifier{Type: "users", ID: alice.ID.String()}) {
+			t.Errorf("got author %+v, want alice", author)
+		}
+
+		rec = getWithAccept(h, "/api/chirps", jsonAPIContentType)
+		var list struct{ Data []jsonAPIResource }
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Data) != 1 || list.Data[0].ID != c.ID.String() {
+			t.Errorf("got list %s (%v), want one chirp resource", rec.Body.String(), err)
+		}
+	})
+
+	t.Run("hal", func(t *testing.T) {
+		rec := getWithAccept(h, path, "text/html;q=0.9, "+halContentType)
+		if ct := rec.Header().Get("Content-Type"); ct != halContentType {
+			t.Errorf("got Content-Type %q, want %q", ct, halContentType)
+		}
+		var doc struct {
+			Body  string             `json:"body"`
+			Links map[string]halLink `json:"_links"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.Body != "formats" || doc.Links["self"].Href != path || doc.Links["author"].Href != "/api/users/"+alice.ID.String() {
+			t.Errorf("got %s, want the chirp with self and author links", rec.Body.String())
+		}
+
+		rec = getWithAccept(h, "/api/chirps", halContentType)
+		var list struct {
+			Links    map[string]halLink `json:"_links"`
+			Embedded struct {
+				Chirps []map[string]any `json:"chirps"`
+			} `json:"_embedded"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Embedded.Chirps) != 1 || list.Links["self"].Href != "/api/chirps" {
+			t.Errorf("got list %s (%v), want one embedded chirp", rec.Body.String(), err)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		if rec := getWithAccept(h, path, "application/xml"); rec.Code != http.StatusNotAcceptable {
+			t.Errorf("got status %d, want 406", rec.Code)
+		}
+		if rec := getWithAccept(h, path, "application/xml, */*;q=0.1"); rec.Code != http.StatusOK {
+			t.Errorf("wildcard fallback: got status %d, want 200", rec.Code)
+		}
+	})
+}
+
+func TestNonChirpPayloadsStayPlainJSON(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	rec := getWithAccept(h, "/api/chirps/00000000-0000-0000-0000-000000000001/stats", halContentType)
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got Content-Type %q for an error, want application/json", ct)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const identityProviderTimeout = 10 * time.Second
+
+// IdentityProvider is a third-party OAuth provider users can link to their
+// Chirpy account.
+type IdentityProvider interface {
+	// AuthCodeURL is where to send the user to approve the link. The
+	// provider redirects back to redirectURI with a code and state.
+	AuthCodeURL(state, redirectURI string) string
+	// Exchange trades the code for the user's account at the provider.
+	Exchange(ctx context.Context, code, redirectURI string) (providerIdentity, error)
+}
+
+// providerIdentity is a user's account at an IdentityProvider.
+type providerIdentity struct {
+	UserID      string
+	AccessToken string
+}
+
+// githubProvider links GitHub accounts through a GitHub OAuth app.
+type githubProvider struct {
+	clientID     string
+	clientSecret string
+	client       *http.Client
+	// authURL, tokenURL and userURL are GitHub's endpoints, swapped for a
+	// stub server in tests.
+	authURL  string
+	tokenURL string
+	userURL  string
+}
+
+func newGitHubProvider(clientID, clientSecret string) *githubProvider {
+	return &githubProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		client:       &http.Client{Timeout: identityProviderTimeout},
+		authURL:      "https://github.com/login/oauth/authorize",
+		tokenURL:     "https://github.com/login/oauth/access_token",
+		userURL:      "https://api.github.com/user",
+	}
+}
+
+func (g *githubProvider) AuthCodeURL(state, redirectURI string) string {
+	q := url.Values{"client_id": {g.clientID}, "redirect_uri": {redirectURI}, "state": {state}}
+	return g.authURL + "?" + q.Encode()
+}
+
+func (g *githubProvider) Exchange(ctx context.Context, code, redirectURI string) (providerIdentity, error) {
+	form := url.Values{"client_id": {g.clientID}, "client_secret": {g.clientSecret}, "code": {code}, "redirect_uri": {redirectURI}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return providerIdentity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := g.do(req, &token); err != nil {
+		return providerIdentity{}, err
+	}
+	if token.AccessToken == "" {
+		return providerIdentity{}, fmt.Errorf("github token exchange failed: %s", token.Error)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, g.userURL, nil)
+	if err != nil {
+		return providerIdentity{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := g.do(req, &user); err != nil {
+		return providerIdentity{}, err
+	}
+	if user.ID == 0 {
+		return providerIdentity{}, errors.New("github returned no user id")
+	}
+	return providerIdentity{UserID: strconv.FormatInt(user.ID, 10), AccessToken: token.AccessToken}, nil
+}
+
+func (g *githubProvider) do(req *http.Request, out any) error {
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("github returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding github response: %w", err)
+	}
+	return nil
+}
+
+// newIdentityProviders returns the providers configured in the
+// environment, keyed by the name used in /api/users/me/link/{provider}.
+func newIdentityProviders(getenv func(string) string) map[string]IdentityProvider {
+	providers := map[string]IdentityProvider{}
+	if id, secret := getenv("GITHUB_CLIENT_ID"), getenv("GITHUB_CLIENT_SECRET"); id != "" && secret != "" {
+		providers["github"] = newGitHubProvider(id, secret)
+	}
+	return providers
+}
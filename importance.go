@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	importanceScoreInterval = 5 * time.Minute
+	// importanceScoreWindow is how old a chirp can get before its score
+	// stops being updated.
+	importanceScoreWindow = 72 * time.Hour
+)
+
+// importanceScore weighs engagement against age for ?sort=importance.
+// Chirpy has no reposts yet, so callers pass zero for them.
+func importanceScore(likes, replies, reposts, impressions int64, age time.Duration) float64 {
+	return float64(likes)*2 + float64(replies)*3 + float64(reposts)*4 + float64(impressions)*0.001 - age.Hours()*0.5
+}
+
+// runImportanceScorer rescores recent chirps each time tick fires. It
+// returns when tick is closed or ctx is cancelled.
+func (cfg *apiConfig) runImportanceScorer(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-tick:
+			if !ok {
+				return
+			}
+			if _, err := cfg.scoreChirps(ctx, t); err != nil {
+				log.Printf("Error updating importance scores: %s", err)
+			}
+		}
+	}
+}
+
+// scoreChirps recomputes the importance score of every chirp created in
+// the importanceScoreWindow before now, returning how many it scored.
+func (cfg *apiConfig) scoreChirps(ctx context.Context, now time.Time) (int, error) {
+	chirps, err := cfg.db.GetChirpsForScoring(ctx, now.Add(-importanceScoreWindow).UTC())
+	if err != nil || len(chirps) == 0 {
+		return 0, err
+	}
+	arg := database.SetImportanceScoresParams{
+		Ids:    make([]uuid.UUID, len(chirps)),
+		Scores: make([]float64, len(chirps)),
+	}
+	for i, c := range chirps {
+		arg.Ids[i] = c.ID
+		arg.Scores[i] = importanceScore(c.LikeCount, c.ReplyCount, 0, c.ImpressionCount, now.Sub(c.CreatedAt.Time))
+	}
+	return len(chirps), cfg.db.SetImportanceScores(ctx, arg)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestImportanceScore(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		likes, replies, reposts, impressions int64
+		age                                  time.Duration
+		want                                 float64
+	}{
+		{"new and untouched", 0, 0, 0, 0, 0, 0},
+		{"engagement", 3, 2, 1, 0, 0, 3*2 + 2*3 + 1*4},
+		{"impressions", 0, 0, 0, 5000, 0, 5},
+		{"age decays", 10, 0, 0, 0, 6 * time.Hour, 20 - 3},
+		{"stale goes negative", 0, 0, 0, 0, 72 * time.Hour, -36},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := importanceScore(tt.likes, tt.replies, tt.reposts, tt.impressions, tt.age); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortChirpsByImportance(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+
+	quiet := postChirp(t, h, `{"body":"nobody cares"}`, alice)
+	liked := postChirp(t, h, `{"body":"a liked one"}`, alice)
+	discussed := postChirp(t, h, `{"body":"a discussed one"}`, alice)
+	old := postChirp(t, h, `{"body":"an old favourite"}`, alice)
+	for _, id := range []string{liked.ID.String(), old.ID.String()} {
+		if rec := serve(h, "POST", "/api/chirps/"+id+"/like", "", bob); rec.Code >= 300 {
+			t.Fatalf("liking: got status %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	postChirp(t, h, fmt.Sprintf(`{"body":"first reply","parent_id":%q}`, discussed.ID), bob)
+
+	// old fell out of the scoring window with a score from back then.
+	now := time.Now()
+	for i := range store.chirps {
+		if store.chirps[i].ID == old.ID {
+			store.chirps[i].CreatedAt.Time = now.Add(-importanceScoreWindow - time.Hour)
+			store.chirps[i].ImportanceScore = 100
+		}
+	}
+	n, err := cfg.scoreChirps(t.Context(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("scored %d chirps, want the 4 inside the window", n)
+	}
+
+	rec := serve(h, "GET", "/api/chirps?author_id="+quiet.UserId+"&sort=importance", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var got []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var bodies []string
+	for _, c := range got {
+		bodies = append(bodies, c.Body)
+	}
+	want := "[an old favourite a discussed one a liked one nobody cares]"
+	if s := fmt.Sprint(bodies); s != want {
+		t.Errorf("got %s, want %s", s, want)
+	}
+
+	rec = serve(h, "GET", "/api/chirps?sort=importance", "", "")
+	var all []chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &all); err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 || all[0].ID != old.ID || all[len(all)-1].ID != quiet.ID {
+		t.Errorf("got %d chirps, first %s, last %s", len(all), all[0].Body, all[len(all)-1].Body)
+	}
+}
@@ -0,0 +1,55 @@
+// Package analytics derives signals from chirp text for ranking and
+// recommendations.
+package analytics
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+//go:embed lexicon.json
+var lexiconJSON []byte
+
+// lexicon maps lowercase words to weights between -1 and 1.
+var lexicon map[string]float64
+
+// negators flip the weight of the lexicon word that follows them.
+var negators = map[string]bool{"not": true, "no": true, "never": true, "don't": true, "isn't": true, "wasn't": true}
+
+func init() {
+	if err := json.Unmarshal(lexiconJSON, &lexicon); err != nil {
+		panic("analytics: parsing lexicon.json: " + err.Error())
+	}
+}
+
+// SentimentScore rates body from -1 (negative) to 1 (positive) as the mean
+// weight of its lexicon words, so the score reflects tone rather than
+// length. Text with no lexicon words, including an empty body, scores 0.
+func SentimentScore(body string) float64 {
+	words := strings.FieldsFunc(strings.ToLower(body), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	var sum float64
+	var matched int
+	negate := false
+	for _, w := range words {
+		if negators[w] {
+			negate = true
+			continue
+		}
+		if weight, ok := lexicon[w]; ok {
+			if negate {
+				weight = -weight
+			}
+			sum += weight
+			matched++
+		}
+		negate = false
+	}
+	if matched == 0 {
+		return 0
+	}
+	return max(-1, min(1, sum/float64(matched)))
+}
@@ -0,0 +1,46 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSentimentScore(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want float64
+	}{
+		{"empty", "", 0},
+		{"neutral", "The train leaves at noon.", 0},
+		{"positive", "What a wonderful day!", 0.9},
+		{"negative", "This is terrible.", -0.9},
+		{"mixed", "Good food, bad service", 0},
+		{"mean of matches", "love it, great stuff", 0.75},
+		{"case and punctuation", "AMAZING!!!", 0.9},
+		{"negated", "not happy about this", -0.7},
+		{"negation resets", "not today, happy though", 0.7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SentimentScore(tt.body)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("SentimentScore(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+			if got < -1 || got > 1 {
+				t.Errorf("SentimentScore(%q) = %v, outside [-1, 1]", tt.body, got)
+			}
+		})
+	}
+}
+
+func TestLexiconWeightsInRange(t *testing.T) {
+	if len(lexicon) < 50 {
+		t.Errorf("lexicon has %d words, want at least 50", len(lexicon))
+	}
+	for w, weight := range lexicon {
+		if weight < -1 || weight > 1 || weight == 0 {
+			t.Errorf("%q has weight %v, want a nonzero weight in [-1, 1]", w, weight)
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package auth
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/big"
 	"net/http"
 	"strings"
 	"time"
@@ -23,20 +29,59 @@ func CheckHashedPassword(password string, hash string) (bool, error) {
 }
 
 func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return MakeScopedJWT(userID, tokenSecret, expiresIn, "")
+}
+
+// MakeScopedJWT is MakeJWT for a token limited to scope. An empty scope
+// leaves the claim out, as MakeJWT does.
+func MakeScopedJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration, scope string) (string, error) {
 	signingKey := []byte(tokenSecret)
-	claims := &jwt.RegisteredClaims{
-		Issuer:    "chirpy-access",
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+	claims := &accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy-access",
+			Subject:   userID.String(),
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Scope: scope,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(signingKey)
 }
 
+// accessClaims are the claims of an access token as encoded in the JWT.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope,omitempty"`
+}
+
+// Claims are the parts of a validated access token callers need.
+type Claims struct {
+	UserID uuid.UUID
+	// ID is the token's jti, used to revoke it before it expires. Tokens
+	// issued before jti was added have none.
+	ID        string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	// Scope limits what the token may be used for. It is empty for
+	// tokens with full access to their user's account.
+	Scope string
+}
+
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	claims := &jwt.RegisteredClaims{}
+	claims, err := ParseJWT(tokenString, tokenSecret)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// ParseJWT validates an access token like ValidateJWT and returns its
+// claims.
+func ParseJWT(tokenString, tokenSecret string) (Claims, error) {
+	claims := &accessClaims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
 		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -45,17 +90,23 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 		return []byte(tokenSecret), nil
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return Claims{}, err
 	}
 	if !token.Valid {
-		return uuid.Nil, fmt.Errorf("invalid token")
+		return Claims{}, fmt.Errorf("invalid token")
 	}
 	userID, err := uuid.Parse(claims.Subject)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid user ID in token: %w", err)
+		return Claims{}, fmt.Errorf("invalid user ID in token: %w", err)
 	}
-
-	return userID, nil
+	out := Claims{UserID: userID, ID: claims.ID, Scope: claims.Scope}
+	if claims.IssuedAt != nil {
+		out.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		out.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return out, nil
 }
 
 func GetBearerToken(headers http.Header) (string, error) {
@@ -84,3 +135,79 @@ func MakeRefreshToken() string {
 	rand.Read(key)
 	return hex.EncodeToString(key)
 }
+
+// SignCookieValue appends to value an HMAC-SHA256 of it under key, so
+// VerifyCookieValue can tell whether the cookie was tampered with.
+func SignCookieValue(value string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCookieValue returns the value signed by SignCookieValue, or an error
+// if signed was not produced under key.
+func VerifyCookieValue(signed string, key []byte) (string, error) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", errors.New("unsigned cookie")
+	}
+	value := signed[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", errors.New("malformed cookie signature")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return "", errors.New("invalid cookie signature")
+	}
+	return value, nil
+}
+
+// MakeOTP returns a random six-digit one-time password, zero padded.
+func MakeOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// EncryptSecret seals plaintext with AES-256-GCM under key, which must be
+// 32 bytes, for secrets such as third-party access tokens that are stored
+// but must be readable again.
+func EncryptSecret(plaintext, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// DecryptSecret opens a value sealed by EncryptSecret under the same key.
+func DecryptSecret(sealed, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("sealed secret too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -49,3 +50,125 @@ func TestMakeAndValidateJWT(t *testing.T) {
 	}
 
 }
+
+func TestMakeOTP(t *testing.T) {
+	for range 100 {
+		otp, err := MakeOTP()
+		if err != nil {
+			t.Fatalf("MakeOTP failed: %v", err)
+		}
+		if len(otp) != 6 || strings.Trim(otp, "0123456789") != "" {
+			t.Fatalf("got otp %q, want six digits", otp)
+		}
+	}
+}
+
+func TestVerifyCookieValue(t *testing.T) {
+	key := []byte("cookie-key")
+	signed := SignCookieValue("header.payload.sig", key)
+	if got, err := VerifyCookieValue(signed, key); err != nil || got != "header.payload.sig" {
+		t.Errorf("got %q, %v; want the original value", got, err)
+	}
+
+	tests := []struct {
+		name   string
+		signed string
+	}{
+		{"wrong key", SignCookieValue("header.payload.sig", []byte("other-key"))},
+		{"tampered value", "header.payload.evil" + signed[len("header.payload.sig"):]},
+		{"unsigned", "nodots"},
+		{"bad encoding", "value.!!!"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VerifyCookieValue(tt.signed, key); err == nil {
+				t.Errorf("VerifyCookieValue(%q) succeeded, want an error", tt.signed)
+			}
+		})
+	}
+}
+
+func TestParseJWT(t *testing.T) {
+	userId := uuid.New()
+	token, err := MakeJWT(userId, "secret", time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+	claims, err := ParseJWT(token, "secret")
+	if err != nil {
+		t.Fatalf("ParseJWT failed: %v", err)
+	}
+	if claims.UserID != userId {
+		t.Errorf("got userID=%v, want=%v", claims.UserID, userId)
+	}
+	if got := claims.ExpiresAt.Sub(claims.IssuedAt); got != time.Hour {
+		t.Errorf("got lifetime %v, want 1h", got)
+	}
+	if _, err := ParseJWT(token, "other"); err == nil {
+		t.Error("ParseJWT accepted a token signed with another secret")
+	}
+}
+
+func TestMakeScopedJWT(t *testing.T) {
+	userId := uuid.New()
+	token, err := MakeScopedJWT(userId, "secret", time.Hour, "read")
+	if err != nil {
+		t.Fatalf("MakeScopedJWT failed: %v", err)
+	}
+	claims, err := ParseJWT(token, "secret")
+	if err != nil {
+		t.Fatalf("ParseJWT failed: %v", err)
+	}
+	if claims.Scope != "read" {
+		t.Errorf("got scope %q, want read", claims.Scope)
+	}
+	token, _ = MakeJWT(userId, "secret", time.Hour)
+	if claims, _ := ParseJWT(token, "secret"); claims.Scope != "" {
+		t.Errorf("got scope %q for an unscoped token, want none", claims.Scope)
+	}
+}
+
+func TestMakeJWTUniqueID(t *testing.T) {
+	userId := uuid.New()
+	ids := map[string]bool{}
+	for range 10 {
+		token, err := MakeJWT(userId, "secret", time.Hour)
+		if err != nil {
+			t.Fatalf("MakeJWT failed: %v", err)
+		}
+		claims, err := ParseJWT(token, "secret")
+		if err != nil {
+			t.Fatalf("ParseJWT failed: %v", err)
+		}
+		if claims.ID == "" || ids[claims.ID] {
+			t.Fatalf("got jti %q, want a fresh one per token", claims.ID)
+		}
+		ids[claims.ID] = true
+	}
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	sealed, err := EncryptSecret([]byte("gho_token"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "gho_token") {
+		t.Error("sealed secret contains the plaintext")
+	}
+	got, err := DecryptSecret(sealed, key)
+	if err != nil || string(got) != "gho_token" {
+		t.Errorf("got %q, %v", got, err)
+	}
+
+	if _, err := DecryptSecret(sealed, []byte(strings.Repeat("x", 32))); err == nil {
+		t.Error("decrypting under the wrong key succeeded")
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := DecryptSecret(sealed, key); err == nil {
+		t.Error("decrypting a tampered secret succeeded")
+	}
+	if _, err := EncryptSecret([]byte("x"), []byte("short")); err == nil {
+		t.Error("encrypting under a short key succeeded")
+	}
+}
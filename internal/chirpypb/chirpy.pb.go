@@ -0,0 +1,737 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: chirpy.proto
+
+package chirpypb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Chirp struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Body      string                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	UserId    string                 `protobuf:"bytes,5,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// Empty for a top-level chirp.
+	ParentId      string `protobuf:"bytes,6,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	LikeCount     int64  `protobuf:"varint,7,opt,name=like_count,json=likeCount,proto3" json:"like_count,omitempty"`
+	ReplyCount    int64  `protobuf:"varint,8,opt,name=reply_count,json=replyCount,proto3" json:"reply_count,omitempty"`
+	IsNsfw        bool   `protobuf:"varint,9,opt,name=is_nsfw,json=isNsfw,proto3" json:"is_nsfw,omitempty"`
+	Visibility    string `protobuf:"bytes,10,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chirp) Reset() {
+	*x = Chirp{}
+	mi := &file_chirpy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chirp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chirp) ProtoMessage() {}
+
+func (x *Chirp) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chirp.ProtoReflect.Descriptor instead.
+func (*Chirp) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Chirp) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Chirp) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Chirp) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Chirp) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Chirp) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Chirp) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Chirp) GetLikeCount() int64 {
+	if x != nil {
+		return x.LikeCount
+	}
+	return 0
+}
+
+func (x *Chirp) GetReplyCount() int64 {
+	if x != nil {
+		return x.ReplyCount
+	}
+	return 0
+}
+
+func (x *Chirp) GetIsNsfw() bool {
+	if x != nil {
+		return x.IsNsfw
+	}
+	return false
+}
+
+func (x *Chirp) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
+
+type CreateChirpRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Body     string                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	ParentId string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"`
+	IsNsfw   bool                   `protobuf:"varint,3,opt,name=is_nsfw,json=isNsfw,proto3" json:"is_nsfw,omitempty"`
+	// "public" (the default) or "mutual".
+	Visibility    string `protobuf:"bytes,4,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateChirpRequest) Reset() {
+	*x = CreateChirpRequest{}
+	mi := &file_chirpy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateChirpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateChirpRequest) ProtoMessage() {}
+
+func (x *CreateChirpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateChirpRequest.ProtoReflect.Descriptor instead.
+func (*CreateChirpRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateChirpRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *CreateChirpRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CreateChirpRequest) GetIsNsfw() bool {
+	if x != nil {
+		return x.IsNsfw
+	}
+	return false
+}
+
+func (x *CreateChirpRequest) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
+
+type GetChirpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChirpRequest) Reset() {
+	*x = GetChirpRequest{}
+	mi := &file_chirpy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChirpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChirpRequest) ProtoMessage() {}
+
+func (x *GetChirpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChirpRequest.ProtoReflect.Descriptor instead.
+func (*GetChirpRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetChirpRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListChirpsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Restricts the list to one author when set.
+	AuthorId      string `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChirpsRequest) Reset() {
+	*x = ListChirpsRequest{}
+	mi := &file_chirpy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChirpsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChirpsRequest) ProtoMessage() {}
+
+func (x *ListChirpsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChirpsRequest.ProtoReflect.Descriptor instead.
+func (*ListChirpsRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListChirpsRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+type ListChirpsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chirps        []*Chirp               `protobuf:"bytes,1,rep,name=chirps,proto3" json:"chirps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChirpsResponse) Reset() {
+	*x = ListChirpsResponse{}
+	mi := &file_chirpy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChirpsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChirpsResponse) ProtoMessage() {}
+
+func (x *ListChirpsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChirpsResponse.ProtoReflect.Descriptor instead.
+func (*ListChirpsResponse) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListChirpsResponse) GetChirps() []*Chirp {
+	if x != nil {
+		return x.Chirps
+	}
+	return nil
+}
+
+type User struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Email         string                 `protobuf:"bytes,4,opt,name=email,proto3" json:"email,omitempty"`
+	IsChirpyRed   bool                   `protobuf:"varint,5,opt,name=is_chirpy_red,json=isChirpyRed,proto3" json:"is_chirpy_red,omitempty"`
+	IsVerified    bool                   `protobuf:"varint,6,opt,name=is_verified,json=isVerified,proto3" json:"is_verified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *User) Reset() {
+	*x = User{}
+	mi := &file_chirpy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *User) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*User) ProtoMessage() {}
+
+func (x *User) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use User.ProtoReflect.Descriptor instead.
+func (*User) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *User) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *User) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *User) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *User) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *User) GetIsChirpyRed() bool {
+	if x != nil {
+		return x.IsChirpyRed
+	}
+	return false
+}
+
+func (x *User) GetIsVerified() bool {
+	if x != nil {
+		return x.IsVerified
+	}
+	return false
+}
+
+type CreateUserRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateUserRequest) Reset() {
+	*x = CreateUserRequest{}
+	mi := &file_chirpy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateUserRequest) ProtoMessage() {}
+
+func (x *CreateUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateUserRequest.ProtoReflect.Descriptor instead.
+func (*CreateUserRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateUserRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *CreateUserRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type LoginRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Email            string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	Password         string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	ExpiresInSeconds int64                  `protobuf:"varint,3,opt,name=expires_in_seconds,json=expiresInSeconds,proto3" json:"expires_in_seconds,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_chirpy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LoginRequest) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetExpiresInSeconds() int64 {
+	if x != nil {
+		return x.ExpiresInSeconds
+	}
+	return 0
+}
+
+type LoginResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *User                  `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	RefreshToken  string                 `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_chirpy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_chirpy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_chirpy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *LoginResponse) GetUser() *User {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+var File_chirpy_proto protoreflect.FileDescriptor
+
+const file_chirpy_proto_rawDesc = "" +
+	"\n" +
+	"\fchirpy.proto\x12\tchirpy.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd0\x02\n" +
+	"\x05Chirp\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\tR\x04body\x12\x17\n" +
+	"\auser_id\x18\x05 \x01(\tR\x06userId\x12\x1b\n" +
+	"\tparent_id\x18\x06 \x01(\tR\bparentId\x12\x1d\n" +
+	"\n" +
+	"like_count\x18\a \x01(\x03R\tlikeCount\x12\x1f\n" +
+	"\vreply_count\x18\b \x01(\x03R\n" +
+	"replyCount\x12\x17\n" +
+	"\ais_nsfw\x18\t \x01(\bR\x06isNsfw\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\n" +
+	" \x01(\tR\n" +
+	"visibility\"~\n" +
+	"\x12CreateChirpRequest\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\tR\x04body\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\x12\x17\n" +
+	"\ais_nsfw\x18\x03 \x01(\bR\x06isNsfw\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\x04 \x01(\tR\n" +
+	"visibility\"!\n" +
+	"\x0fGetChirpRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"0\n" +
+	"\x11ListChirpsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\">\n" +
+	"\x12ListChirpsResponse\x12(\n" +
+	"\x06chirps\x18\x01 \x03(\v2\x10.chirpy.v1.ChirpR\x06chirps\"\xe7\x01\n" +
+	"\x04User\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x129\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x14\n" +
+	"\x05email\x18\x04 \x01(\tR\x05email\x12\"\n" +
+	"\ris_chirpy_red\x18\x05 \x01(\bR\visChirpyRed\x12\x1f\n" +
+	"\vis_verified\x18\x06 \x01(\bR\n" +
+	"isVerified\"E\n" +
+	"\x11CreateUserRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"n\n" +
+	"\fLoginRequest\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12,\n" +
+	"\x12expires_in_seconds\x18\x03 \x01(\x03R\x10expiresInSeconds\"o\n" +
+	"\rLoginResponse\x12#\n" +
+	"\x04user\x18\x01 \x01(\v2\x0f.chirpy.v1.UserR\x04user\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12#\n" +
+	"\rrefresh_token\x18\x03 \x01(\tR\frefreshToken2\xcc\x02\n" +
+	"\fChirpService\x12>\n" +
+	"\vCreateChirp\x12\x1d.chirpy.v1.CreateChirpRequest\x1a\x10.chirpy.v1.Chirp\x128\n" +
+	"\bGetChirp\x12\x1a.chirpy.v1.GetChirpRequest\x1a\x10.chirpy.v1.Chirp\x12I\n" +
+	"\n" +
+	"ListChirps\x12\x1c.chirpy.v1.ListChirpsRequest\x1a\x1d.chirpy.v1.ListChirpsResponse\x12;\n" +
+	"\n" +
+	"CreateUser\x12\x1c.chirpy.v1.CreateUserRequest\x1a\x0f.chirpy.v1.User\x12:\n" +
+	"\x05Login\x12\x17.chirpy.v1.LoginRequest\x1a\x18.chirpy.v1.LoginResponseB+Z)github.com/azs06/Chirpy/internal/chirpypbb\x06proto3"
+
+var (
+	file_chirpy_proto_rawDescOnce sync.Once
+	file_chirpy_proto_rawDescData []byte
+)
+
+func file_chirpy_proto_rawDescGZIP() []byte {
+	file_chirpy_proto_rawDescOnce.Do(func() {
+		file_chirpy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_chirpy_proto_rawDesc), len(file_chirpy_proto_rawDesc)))
+	})
+	return file_chirpy_proto_rawDescData
+}
+
+var file_chirpy_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_chirpy_proto_goTypes = []any{
+	(*Chirp)(nil),                 // 0: chirpy.v1.Chirp
+	(*CreateChirpRequest)(nil),    // 1: chirpy.v1.CreateChirpRequest
+	(*GetChirpRequest)(nil),       // 2: chirpy.v1.GetChirpRequest
+	(*ListChirpsRequest)(nil),     // 3: chirpy.v1.ListChirpsRequest
+	(*ListChirpsResponse)(nil),    // 4: chirpy.v1.ListChirpsResponse
+	(*User)(nil),                  // 5: chirpy.v1.User
+	(*CreateUserRequest)(nil),     // 6: chirpy.v1.CreateUserRequest
+	(*LoginRequest)(nil),          // 7: chirpy.v1.LoginRequest
+	(*LoginResponse)(nil),         // 8: chirpy.v1.LoginResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_chirpy_proto_depIdxs = []int32{
+	9,  // 0: chirpy.v1.Chirp.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 1: chirpy.v1.Chirp.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: chirpy.v1.ListChirpsResponse.chirps:type_name -> chirpy.v1.Chirp
+	9,  // 3: chirpy.v1.User.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 4: chirpy.v1.User.updated_at:type_name -> google.protobuf.Timestamp
+	5,  // 5: chirpy.v1.LoginResponse.user:type_name -> chirpy.v1.User
+	1,  // 6: chirpy.v1.ChirpService.CreateChirp:input_type -> chirpy.v1.CreateChirpRequest
+	2,  // 7: chirpy.v1.ChirpService.GetChirp:input_type -> chirpy.v1.GetChirpRequest
+	3,  // 8: chirpy.v1.ChirpService.ListChirps:input_type -> chirpy.v1.ListChirpsRequest
+	6,  // 9: chirpy.v1.ChirpService.CreateUser:input_type -> chirpy.v1.CreateUserRequest
+	7,  // 10: chirpy.v1.ChirpService.Login:input_type -> chirpy.v1.LoginRequest
+	0,  // 11: chirpy.v1.ChirpService.CreateChirp:output_type -> chirpy.v1.Chirp
+	0,  // 12: chirpy.v1.ChirpService.GetChirp:output_type -> chirpy.v1.Chirp
+	4,  // 13: chirpy.v1.ChirpService.ListChirps:output_type -> chirpy.v1.ListChirpsResponse
+	5,  // 14: chirpy.v1.ChirpService.CreateUser:output_type -> chirpy.v1.User
+	8,  // 15: chirpy.v1.ChirpService.Login:output_type -> chirpy.v1.LoginResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_chirpy_proto_init() }
+func file_chirpy_proto_init() {
+	if File_chirpy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_chirpy_proto_rawDesc), len(file_chirpy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_chirpy_proto_goTypes,
+		DependencyIndexes: file_chirpy_proto_depIdxs,
+		MessageInfos:      file_chirpy_proto_msgTypes,
+	}.Build()
+	File_chirpy_proto = out.File
+	file_chirpy_proto_goTypes = nil
+	file_chirpy_proto_depIdxs = nil
+}
@@ -0,0 +1,281 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: chirpy.proto
+
+package chirpypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChirpService_CreateChirp_FullMethodName = "/chirpy.v1.ChirpService/CreateChirp"
+	ChirpService_GetChirp_FullMethodName    = "/chirpy.v1.ChirpService/GetChirp"
+	ChirpService_ListChirps_FullMethodName  = "/chirpy.v1.ChirpService/ListChirps"
+	ChirpService_CreateUser_FullMethodName  = "/chirpy.v1.ChirpService/CreateUser"
+	ChirpService_Login_FullMethodName       = "/chirpy.v1.ChirpService/Login"
+)
+
+// ChirpServiceClient is the client API for ChirpService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChirpService mirrors the core of the HTTP API. Calls that act as a user
+// carry the same JWT as the HTTP API in an "authorization: Bearer <token>"
+// metadata entry.
+type ChirpServiceClient interface {
+	CreateChirp(ctx context.Context, in *CreateChirpRequest, opts ...grpc.CallOption) (*Chirp, error)
+	GetChirp(ctx context.Context, in *GetChirpRequest, opts ...grpc.CallOption) (*Chirp, error)
+	ListChirps(ctx context.Context, in *ListChirpsRequest, opts ...grpc.CallOption) (*ListChirpsResponse, error)
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error)
+	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+}
+
+type chirpServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChirpServiceClient(cc grpc.ClientConnInterface) ChirpServiceClient {
+	return &chirpServiceClient{cc}
+}
+
+func (c *chirpServiceClient) CreateChirp(ctx context.Context, in *CreateChirpRequest, opts ...grpc.CallOption) (*Chirp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chirp)
+	err := c.cc.Invoke(ctx, ChirpService_CreateChirp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpServiceClient) GetChirp(ctx context.Context, in *GetChirpRequest, opts ...grpc.CallOption) (*Chirp, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chirp)
+	err := c.cc.Invoke(ctx, ChirpService_GetChirp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpServiceClient) ListChirps(ctx context.Context, in *ListChirpsRequest, opts ...grpc.CallOption) (*ListChirpsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChirpsResponse)
+	err := c.cc.Invoke(ctx, ChirpService_ListChirps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpServiceClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, ChirpService_CreateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *chirpServiceClient) Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponse)
+	err := c.cc.Invoke(ctx, ChirpService_Login_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChirpServiceServer is the server API for ChirpService service.
+// All implementations must embed UnimplementedChirpServiceServer
+// for forward compatibility.
+//
+// ChirpService mirrors the core of the HTTP API. Calls that act as a user
+// carry the same JWT as the HTTP API in an "authorization: Bearer <token>"
+// metadata entry.
+type ChirpServiceServer interface {
+	CreateChirp(context.Context, *CreateChirpRequest) (*Chirp, error)
+	GetChirp(context.Context, *GetChirpRequest) (*Chirp, error)
+	ListChirps(context.Context, *ListChirpsRequest) (*ListChirpsResponse, error)
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	mustEmbedUnimplementedChirpServiceServer()
+}
+
+// UnimplementedChirpServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChirpServiceServer struct{}
+
+func (UnimplementedChirpServiceServer) CreateChirp(context.Context, *CreateChirpRequest) (*Chirp, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateChirp not implemented")
+}
+func (UnimplementedChirpServiceServer) GetChirp(context.Context, *GetChirpRequest) (*Chirp, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetChirp not implemented")
+}
+func (UnimplementedChirpServiceServer) ListChirps(context.Context, *ListChirpsRequest) (*ListChirpsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListChirps not implemented")
+}
+func (UnimplementedChirpServiceServer) CreateUser(context.Context, *CreateUserRequest) (*User, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedChirpServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Login not implemented")
+}
+func (UnimplementedChirpServiceServer) mustEmbedUnimplementedChirpServiceServer() {}
+func (UnimplementedChirpServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeChirpServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChirpServiceServer will
+// result in compilation errors.
+type UnsafeChirpServiceServer interface {
+	mustEmbedUnimplementedChirpServiceServer()
+}
+
+func RegisterChirpServiceServer(s grpc.ServiceRegistrar, srv ChirpServiceServer) {
+	// If the following call panics, it indicates UnimplementedChirpServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChirpService_ServiceDesc, srv)
+}
+
+func _ChirpService_CreateChirp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateChirpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpServiceServer).CreateChirp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpService_CreateChirp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpServiceServer).CreateChirp(ctx, req.(*CreateChirpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpService_GetChirp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChirpRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpServiceServer).GetChirp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpService_GetChirp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpServiceServer).GetChirp(ctx, req.(*GetChirpRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpService_ListChirps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChirpsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpServiceServer).ListChirps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpService_ListChirps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpServiceServer).ListChirps(ctx, req.(*ListChirpsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChirpService_Login_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChirpServiceServer).Login(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChirpService_Login_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChirpServiceServer).Login(ctx, req.(*LoginRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChirpService_ServiceDesc is the grpc.ServiceDesc for ChirpService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChirpService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chirpy.v1.ChirpService",
+	HandlerType: (*ChirpServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateChirp",
+			Handler:    _ChirpService_CreateChirp_Handler,
+		},
+		{
+			MethodName: "GetChirp",
+			Handler:    _ChirpService_GetChirp_Handler,
+		},
+		{
+			MethodName: "ListChirps",
+			Handler:    _ChirpService_ListChirps_Handler,
+		},
+		{
+			MethodName: "CreateUser",
+			Handler:    _ChirpService_CreateUser_Handler,
+		},
+		{
+			MethodName: "Login",
+			Handler:    _ChirpService_Login_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "chirpy.proto",
+}
@@ -8,29 +8,67 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const confirmPendingEmail = `-- name: ConfirmPendingEmail :one
+UPDATE users
+SET email = pending_email, pending_email = NULL, pending_email_token = NULL, pending_email_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND pending_email_token = $2
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
+`
+
+type ConfirmPendingEmailParams struct {
+	ID                uuid.UUID
+	PendingEmailToken sql.NullString
+}
+
+func (q *Queries) ConfirmPendingEmail(ctx context.Context, arg ConfirmPendingEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, confirmPendingEmail, arg.ID, arg.PendingEmailToken)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
+	)
+	return i, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, namespace)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
 `
 
 type CreateUserParams struct {
 	Email          sql.NullString
 	HashedPassword string
+	Namespace      string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.HashedPassword, arg.Namespace)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -39,6 +77,14 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
 	)
 	return i, err
 }
@@ -52,12 +98,151 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 	return err
 }
 
+const getLeaderboard = `-- name: GetLeaderboard :many
+WITH points AS (
+    SELECT chirps.user_id, 1 AS points
+    FROM chirps
+    WHERE chirps.created_at >= $1::timestamp AND chirps.namespace = $2
+      AND chirps.deleted_at IS NULL AND NOT chirps.is_hidden
+    UNION ALL
+    SELECT chirps.user_id, 3
+    FROM chirp_likes
+    JOIN chirps ON chirps.id = chirp_likes.chirp_id
+    WHERE chirp_likes.created_at >= $1::timestamp AND chirps.namespace = $2
+      AND chirps.deleted_at IS NULL AND NOT chirps.is_hidden
+    UNION ALL
+    SELECT follows.followee_id, 5
+    FROM follows
+    JOIN users followee ON followee.id = follows.followee_id
+    WHERE follows.created_at >= $1::timestamp AND followee.namespace = $2
+), scores AS (
+    SELECT user_id, SUM(points) AS score
+    FROM points
+    GROUP BY user_id
+)
+SELECT users.id, users.created_at, users.updated_at, users.is_chirpy_red, users.is_verified,
+    scores.score::bigint AS score
+FROM scores
+JOIN users ON users.id = scores.user_id
+WHERE users.namespace = $2
+ORDER BY scores.score DESC, users.id
+LIMIT $3
+`
+
+type GetLeaderboardParams struct {
+	Since     time.Time
+	Namespace string
+	Size      int32
+}
+
+type GetLeaderboardRow struct {
+	ID          uuid.UUID
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+	IsChirpyRed bool
+	IsVerified  bool
+	Score       int64
+}
+
+func (q *Queries) GetLeaderboard(ctx context.Context, arg GetLeaderboardParams) ([]GetLeaderboardRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaderboard, arg.Since, arg.Namespace, arg.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLeaderboardRow
+	for rows.Next() {
+		var i GetLeaderboardRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.IsChirpyRed,
+			&i.IsVerified,
+			&i.Score,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserActivity = `-- name: GetUserActivity :one
+WITH events AS (
+    SELECT 'chirp' AS kind, chirps.created_at
+    FROM chirps
+    WHERE chirps.user_id = $1 AND chirps.deleted_at IS NULL
+    UNION ALL
+    SELECT 'like', chirp_likes.created_at
+    FROM chirp_likes
+    JOIN chirps ON chirps.id = chirp_likes.chirp_id
+    WHERE chirps.user_id = $1
+    UNION ALL
+    SELECT 'follower', follows.created_at
+    FROM follows
+    WHERE follows.followee_id = $1
+    UNION ALL
+    SELECT 'reply', replies.created_at
+    FROM chirps replies
+    JOIN chirps parent ON parent.id = replies.parent_id
+    WHERE parent.user_id = $1 AND replies.user_id <> $1
+      AND replies.deleted_at IS NULL
+)
+SELECT
+    COALESCE(SUM(CASE WHEN kind = 'chirp' AND created_at >= $2::timestamp THEN 1 ELSE 0 END), 0)::bigint AS chirps_today,
+    COALESCE(SUM(CASE WHEN kind = 'chirp' THEN 1 ELSE 0 END), 0)::bigint AS chirps_this_week,
+    COALESCE(SUM(CASE WHEN kind = 'like' AND created_at >= $2::timestamp THEN 1 ELSE 0 END), 0)::bigint AS likes_received_today,
+    COALESCE(SUM(CASE WHEN kind = 'follower' AND created_at >= $2::timestamp THEN 1 ELSE 0 END), 0)::bigint AS new_followers_today,
+    COALESCE(SUM(CASE WHEN kind = 'reply' AND created_at >= $2::timestamp THEN 1 ELSE 0 END), 0)::bigint AS replies_received_today
+FROM events
+WHERE created_at >= $3::timestamp
+`
+
+type GetUserActivityParams struct {
+	UserID    uuid.UUID
+	DayStart  time.Time
+	WeekStart time.Time
+}
+
+type GetUserActivityRow struct {
+	ChirpsToday          int64
+	ChirpsThisWeek       int64
+	LikesReceivedToday   int64
+	NewFollowersToday    int64
+	RepliesReceivedToday int64
+}
+
+func (q *Queries) GetUserActivity(ctx context.Context, arg GetUserActivityParams) (GetUserActivityRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserActivity, arg.UserID, arg.DayStart, arg.WeekStart)
+	var i GetUserActivityRow
+	err := row.Scan(
+		&i.ChirpsToday,
+		&i.ChirpsThisWeek,
+		&i.LikesReceivedToday,
+		&i.NewFollowersToday,
+		&i.RepliesReceivedToday,
+	)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at FROM users WHERE email = $1 AND namespace = $2
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+type GetUserByEmailParams struct {
+	Email     sql.NullString
+	Namespace string
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, arg.Email, arg.Namespace)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -66,16 +251,206 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email sql.NullString) (Use
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE id=$1
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified, users.is_admin, users.email_mfa_enabled, users.namespace, users.email_notifications, users.pending_email, users.pending_email_token, users.pending_email_expires_at,
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id) AS followers_count,
+    (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id AND chirps.deleted_at IS NULL) AS chirps_count
+FROM users
+WHERE users.id = $1 AND users.namespace = $2
 `
 
-func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserById, id)
+type GetUserByIdParams struct {
+	ID        uuid.UUID
+	Namespace string
+}
+
+type GetUserByIdRow struct {
+	User           User
+	FollowersCount int64
+	FollowingCount int64
+	ChirpsCount    int64
+}
+
+func (q *Queries) GetUserById(ctx context.Context, arg GetUserByIdParams) (GetUserByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, arg.ID, arg.Namespace)
+	var i GetUserByIdRow
+	err := row.Scan(
+		&i.User.ID,
+		&i.User.CreatedAt,
+		&i.User.UpdatedAt,
+		&i.User.Email,
+		&i.User.HashedPassword,
+		&i.User.IsChirpyRed,
+		&i.User.IsVerified,
+		&i.User.IsAdmin,
+		&i.User.EmailMfaEnabled,
+		&i.User.Namespace,
+		&i.User.EmailNotifications,
+		&i.User.PendingEmail,
+		&i.User.PendingEmailToken,
+		&i.User.PendingEmailExpiresAt,
+		&i.FollowersCount,
+		&i.FollowingCount,
+		&i.ChirpsCount,
+	)
+	return i, err
+}
+
+const getUserByPendingEmailToken = `-- name: GetUserByPendingEmailToken :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at FROM users WHERE pending_email_token = $1
+`
+
+func (q *Queries) GetUserByPendingEmailToken(ctx context.Context, pendingEmailToken sql.NullString) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByPendingEmailToken, pendingEmailToken)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
+	)
+	return i, err
+}
+
+const getUsersByIds = `-- name: GetUsersByIds :many
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified, users.is_admin, users.email_mfa_enabled, users.namespace, users.email_notifications, users.pending_email, users.pending_email_token, users.pending_email_expires_at,
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id) AS followers_count,
+    (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id AND chirps.deleted_at IS NULL) AS chirps_count
+FROM users
+WHERE users.id = ANY($1::uuid[]) AND users.namespace = $2
+`
+
+type GetUsersByIdsParams struct {
+	Ids       []uuid.UUID
+	Namespace string
+}
+
+type GetUsersByIdsRow struct {
+	User           User
+	FollowersCount int64
+	FollowingCount int64
+	ChirpsCount    int64
+}
+
+func (q *Queries) GetUsersByIds(ctx context.Context, arg GetUsersByIdsParams) ([]GetUsersByIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIds, pq.Array(arg.Ids), arg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUsersByIdsRow
+	for rows.Next() {
+		var i GetUsersByIdsRow
+		if err := rows.Scan(
+			&i.User.ID,
+			&i.User.CreatedAt,
+			&i.User.UpdatedAt,
+			&i.User.Email,
+			&i.User.HashedPassword,
+			&i.User.IsChirpyRed,
+			&i.User.IsVerified,
+			&i.User.IsAdmin,
+			&i.User.EmailMfaEnabled,
+			&i.User.Namespace,
+			&i.User.EmailNotifications,
+			&i.User.PendingEmail,
+			&i.User.PendingEmailToken,
+			&i.User.PendingEmailExpiresAt,
+			&i.FollowersCount,
+			&i.FollowingCount,
+			&i.ChirpsCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPendingEmail = `-- name: SetPendingEmail :one
+UPDATE users
+SET pending_email = $2, pending_email_token = $3, pending_email_expires_at = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
+`
+
+type SetPendingEmailParams struct {
+	ID                    uuid.UUID
+	PendingEmail          sql.NullString
+	PendingEmailToken     sql.NullString
+	PendingEmailExpiresAt sql.NullTime
+}
+
+func (q *Queries) SetPendingEmail(ctx context.Context, arg SetPendingEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setPendingEmail,
+		arg.ID,
+		arg.PendingEmail,
+		arg.PendingEmailToken,
+		arg.PendingEmailExpiresAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
+	)
+	return i, err
+}
+
+const setUserVerified = `-- name: SetUserVerified :one
+UPDATE users SET is_verified = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
+`
+
+type SetUserVerifiedParams struct {
+	ID         uuid.UUID
+	IsVerified bool
+}
+
+func (q *Queries) SetUserVerified(ctx context.Context, arg SetUserVerifiedParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserVerified, arg.ID, arg.IsVerified)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -84,6 +459,14 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
 	)
 	return i, err
 }
@@ -91,7 +474,7 @@ func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
 const toggleChirpRed = `-- name: ToggleChirpRed :one
 UPDATE users SET is_chirpy_red = $2, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
 `
 
 type ToggleChirpRedParams struct {
@@ -109,6 +492,14 @@ func (q *Queries) ToggleChirpRed(ctx context.Context, arg ToggleChirpRedParams)
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
 	)
 	return i, err
 }
@@ -117,7 +508,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $2, hashed_password = $3, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_verified, is_admin, email_mfa_enabled, namespace, email_notifications, pending_email, pending_email_token, pending_email_expires_at
 `
 
 type UpdateUserParams struct {
@@ -136,6 +527,14 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsVerified,
+		&i.IsAdmin,
+		&i.EmailMfaEnabled,
+		&i.Namespace,
+		&i.EmailNotifications,
+		&i.PendingEmail,
+		&i.PendingEmailToken,
+		&i.PendingEmailExpiresAt,
 	)
 	return i, err
 }
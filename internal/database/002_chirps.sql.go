@@ -8,29 +8,173 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const adminUpdateChirp = `-- name: AdminUpdateChirp :one
+-- Every statement in the query sees the chirp as it was before the update,
+-- so the old body is what lands in chirp_versions.
+WITH previous AS (
+    SELECT id, body FROM chirps WHERE id = $1 FOR UPDATE
+), saved AS (
+    INSERT INTO chirp_versions (chirp_id, body, edited_by)
+    SELECT previous.id, previous.body, $2 FROM previous
+)
+UPDATE chirps
+SET body = $3, word_count = $4, reading_time_seconds = $5,
+    sentiment_score = $6, admin_edited = true, updated_at = NOW()
+WHERE id = (SELECT previous.id FROM previous)
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
+`
+
+type AdminUpdateChirpParams struct {
+	ID                 uuid.UUID
+	EditedBy           uuid.UUID
+	Body               sql.NullString
+	WordCount          int32
+	ReadingTimeSeconds int32
+	SentimentScore     float64
+}
+
+func (q *Queries) AdminUpdateChirp(ctx context.Context, arg AdminUpdateChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, adminUpdateChirp,
+		arg.ID,
+		arg.EditedBy,
+		arg.Body,
+		arg.WordCount,
+		arg.ReadingTimeSeconds,
+		arg.SentimentScore,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
+	)
+	return i, err
+}
+
+const archiveChirpsBefore = `-- name: ArchiveChirpsBefore :execrows
+-- Every statement in the query sees chirp_media as it was before the
+-- cascade from the DELETE, so the media of archived chirps is still there
+-- to copy.
+WITH archived AS (
+    DELETE FROM chirps WHERE created_at < $1 AND deleted_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score
+), media AS (
+    INSERT INTO chirp_media_archive (id, chirp_id, url, mime_type, alt_text, position, created_at)
+    SELECT chirp_media.id, chirp_media.chirp_id, chirp_media.url, chirp_media.mime_type, chirp_media.alt_text, chirp_media.position, chirp_media.created_at
+    FROM chirp_media
+    WHERE chirp_media.chirp_id IN (SELECT archived.id FROM archived)
+)
+INSERT INTO chirps_archive (id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score, archived_at)
+SELECT id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score, NOW() FROM archived
+`
+
+func (q *Queries) ArchiveChirpsBefore(ctx context.Context, cutoff sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, archiveChirpsBefore, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countUserChirps = `-- name: CountUserChirps :one
+SELECT COUNT(*) FROM chirps WHERE user_id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) CountUserChirps(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserChirps, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+-- The chirp and its media go in together, so a failed media insert leaves
+-- no chirp behind. chirp_media's foreign key is checked at the end of the
+-- statement, after the chirp exists.
+WITH new AS (SELECT gen_random_uuid() AS id), media AS (
+    INSERT INTO chirp_media (id, chirp_id, url, mime_type, alt_text, position, created_at)
+    SELECT gen_random_uuid(), (SELECT new.id FROM new), m.url, m.mime_type, m.alt_text, m.position - 1, NOW()
+    FROM unnest(
+        $1::text[],
+        $2::text[],
+        $3::text[]
+    ) WITH ORDINALITY AS m(url, mime_type, alt_text, position)
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, is_nsfw, word_count, reading_time_seconds, visibility, flagged_reason, sentiment_score, namespace, root_id, thread_depth)
 VALUES (
-    gen_random_uuid(),
+    (SELECT new.id FROM new),
     NOW(),
     NOW(),
-    $1,
-    $2
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    $11,
+    $12,
+    (SELECT users.namespace FROM users WHERE users.id = $5),
+    COALESCE((SELECT parent.root_id FROM chirps parent WHERE parent.id = $6), (SELECT new.id FROM new)),
+    COALESCE((SELECT parent.thread_depth + 1 FROM chirps parent WHERE parent.id = $6), 0)
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
 `
 
 type CreateChirpParams struct {
-	Body   sql.NullString
-	UserID uuid.UUID
+	MediaUrls          []string
+	MediaMimeTypes     []string
+	MediaAltTexts      []string
+	Body               sql.NullString
+	UserID             uuid.UUID
+	ParentID           uuid.NullUUID
+	IsNsfw             bool
+	WordCount          int32
+	ReadingTimeSeconds int32
+	Visibility         ChirpVisibility
+	FlaggedReason      sql.NullString
+	SentimentScore     float64
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		pq.Array(arg.MediaUrls),
+		pq.Array(arg.MediaMimeTypes),
+		pq.Array(arg.MediaAltTexts),
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.IsNsfw,
+		arg.WordCount,
+		arg.ReadingTimeSeconds,
+		arg.Visibility,
+		arg.FlaggedReason,
+		arg.SentimentScore,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -38,19 +182,26 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
 	)
 	return i, err
 }
 
-const deleteChirpById = `-- name: DeleteChirpById :exec
-DELETE FROM chirps WHERE id = $1
-`
-
-func (q *Queries) DeleteChirpById(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirpById, id)
-	return err
-}
-
 const deleteChirps = `-- name: DeleteChirps :exec
 DELETE FROM chirps
 `
@@ -60,29 +211,608 @@ func (q *Queries) DeleteChirps(ctx context.Context) error {
 	return err
 }
 
+const getArchivedChirps = `-- name: GetArchivedChirps :many
+SELECT id, created_at, updated_at, body, user_id, archived_at, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score FROM chirps_archive ORDER BY created_at
+`
+
+func (q *Queries) GetArchivedChirps(ctx context.Context) ([]ChirpsArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getArchivedChirps)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChirpsArchive
+	for rows.Next() {
+		var i ChirpsArchive
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ArchivedAt,
+			&i.ParentID,
+			&i.IsNsfw,
+			&i.IsHidden,
+			&i.WordCount,
+			&i.ReadingTimeSeconds,
+			&i.Visibility,
+			&i.FlaggedReason,
+			&i.Namespace,
+			&i.SentimentScore,
+			&i.RootID,
+			&i.ImpressionCount,
+			&i.ThreadDepth,
+			&i.ContentWarning,
+			&i.AdminEdited,
+			&i.ImportanceScore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirpByID = `-- name: GetChirpByID :one
-  SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.id = $1 AND chirps.namespace = $2 AND chirps.deleted_at IS NULL
 `
 
-func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpByID, id)
-	var i Chirp
+type GetChirpByIDParams struct {
+	ID        uuid.UUID
+	Namespace string
+}
+
+type GetChirpByIDRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirpByID(ctx context.Context, arg GetChirpByIDParams) (GetChirpByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByID, arg.ID, arg.Namespace)
+	var i GetChirpByIDRow
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Body,
-		&i.UserID,
+		&i.Chirp.ID,
+		&i.Chirp.CreatedAt,
+		&i.Chirp.UpdatedAt,
+		&i.Chirp.Body,
+		&i.Chirp.UserID,
+		&i.Chirp.ParentID,
+		&i.Chirp.IsNsfw,
+		&i.Chirp.IsHidden,
+		&i.Chirp.WordCount,
+		&i.Chirp.ReadingTimeSeconds,
+		&i.Chirp.Visibility,
+		&i.Chirp.FlaggedReason,
+		&i.Chirp.DeletedAt,
+		&i.Chirp.ImpressionCount,
+		&i.Chirp.Namespace,
+		&i.Chirp.SentimentScore,
+		&i.Chirp.RootID,
+		&i.Chirp.ImportanceScore,
+		&i.Chirp.ThreadDepth,
+		&i.Chirp.ContentWarning,
+		&i.Chirp.AdminEdited,
+		&i.LikeCount,
+		&i.ReplyCount,
 	)
 	return i, err
 }
 
+const getChirpOwners = `-- name: GetChirpOwners :many
+SELECT id, user_id FROM chirps WHERE id = ANY($1::uuid[])
+`
+
+type GetChirpOwnersRow struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetChirpOwners(ctx context.Context, ids []uuid.UUID) ([]GetChirpOwnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpOwners, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpOwnersRow
+	for rows.Next() {
+		var i GetChirpOwnersRow
+		if err := rows.Scan(&i.ID, &i.UserID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirps = `-- name: GetChirps :many
- SELECT id, created_at, updated_at, body, user_id FROM chirps ORDER BY created_at
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND chirps.namespace = $1
+  AND ($2::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= $3::float8
+  AND (NOT $4::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = $5
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $5 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $5)))
+ORDER BY CASE WHEN $6::boolean THEN chirps.importance_score END DESC, chirps.created_at
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsParams struct {
+	Namespace     string
+	IncludeHidden bool
+	MinSentiment  float64
+	VerifiedOnly  bool
+	ViewerID      uuid.UUID
+	ByImportance  bool
+}
+
+type GetChirpsRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirps(ctx context.Context, arg GetChirpsParams) ([]GetChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirps,
+		arg.Namespace,
+		arg.IncludeHidden,
+		arg.MinSentiment,
+		arg.VerifiedOnly,
+		arg.ViewerID,
+		arg.ByImportance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsRow
+	for rows.Next() {
+		var i GetChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsAfter = `-- name: GetChirpsAfter :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = $1 AND chirps.created_at > $2
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $3
+  AND (chirps.visibility = 'public' OR chirps.user_id = $4
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $4 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $4)))
+ORDER BY chirps.created_at
+LIMIT $5
+`
+
+type GetChirpsAfterParams struct {
+	UserID    uuid.UUID
+	CreatedAt sql.NullTime
+	Namespace string
+	ViewerID  uuid.UUID
+	MaxChirps int32
+}
+
+type GetChirpsAfterRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirpsAfter(ctx context.Context, arg GetChirpsAfterParams) ([]GetChirpsAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAfter,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.Namespace,
+		arg.ViewerID,
+		arg.MaxChirps,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsAfterRow
+	for rows.Next() {
+		var i GetChirpsAfterRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsBefore = `-- name: GetChirpsBefore :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = $1 AND chirps.created_at < $2
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $3
+  AND (chirps.visibility = 'public' OR chirps.user_id = $4
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $4 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $4)))
+ORDER BY chirps.created_at DESC
+LIMIT $5
+`
+
+type GetChirpsBeforeParams struct {
+	UserID    uuid.UUID
+	CreatedAt sql.NullTime
+	Namespace string
+	ViewerID  uuid.UUID
+	MaxChirps int32
+}
+
+type GetChirpsBeforeRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirpsBefore(ctx context.Context, arg GetChirpsBeforeParams) ([]GetChirpsBeforeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsBefore,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.Namespace,
+		arg.ViewerID,
+		arg.MaxChirps,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsBeforeRow
+	for rows.Next() {
+		var i GetChirpsBeforeRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByUserId = `-- name: GetChirpsByUserId :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = $1
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $2
+  AND ($3::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= $4::float8
+  AND (NOT $5::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = $6
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $6 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $6)))
+ORDER BY CASE WHEN $7::boolean THEN chirps.importance_score END DESC, chirps.created_at
+`
+
+type GetChirpsByUserIdParams struct {
+	UserID        uuid.UUID
+	Namespace     string
+	IncludeHidden bool
+	MinSentiment  float64
+	VerifiedOnly  bool
+	ViewerID      uuid.UUID
+	ByImportance  bool
+}
+
+type GetChirpsByUserIdRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirpsByUserId(ctx context.Context, arg GetChirpsByUserIdParams) ([]GetChirpsByUserIdRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByUserId,
+		arg.UserID,
+		arg.Namespace,
+		arg.IncludeHidden,
+		arg.MinSentiment,
+		arg.VerifiedOnly,
+		arg.ViewerID,
+		arg.ByImportance,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsByUserIdRow
+	for rows.Next() {
+		var i GetChirpsByUserIdRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsForExport = `-- name: GetChirpsForExport :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE (chirps.created_at, chirps.id) > ($1::timestamp, $2::uuid)
+  AND chirps.created_at < $3::timestamp
+  AND chirps.deleted_at IS NULL
+ORDER BY chirps.created_at, chirps.id
+LIMIT $4
+`
+
+type GetChirpsForExportParams struct {
+	CursorCreatedAt time.Time
+	CursorID        uuid.UUID
+	CreatedBefore   time.Time
+	BatchSize       int32
+}
+
+type GetChirpsForExportRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetChirpsForExport(ctx context.Context, arg GetChirpsForExportParams) ([]GetChirpsForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsForExport,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.CreatedBefore,
+		arg.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsForExportRow
+	for rows.Next() {
+		var i GetChirpsForExportRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsForScoring = `-- name: GetChirpsForScoring :many
+SELECT id, created_at, impression_count,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE created_at >= $1::timestamp AND deleted_at IS NULL
+`
+
+type GetChirpsForScoringRow struct {
+	ID              uuid.UUID
+	CreatedAt       sql.NullTime
+	ImpressionCount int64
+	LikeCount       int64
+	ReplyCount      int64
+}
+
+func (q *Queries) GetChirpsForScoring(ctx context.Context, since time.Time) ([]GetChirpsForScoringRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsForScoring, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsForScoringRow
+	for rows.Next() {
+		var i GetChirpsForScoringRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.ImpressionCount,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirpsByUser = `-- name: GetDeletedChirpsByUser :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited FROM chirps
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC
+`
+
+func (q *Queries) GetDeletedChirpsByUser(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getDeletedChirpsByUser, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +826,22 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.IsNsfw,
+			&i.IsHidden,
+			&i.WordCount,
+			&i.ReadingTimeSeconds,
+			&i.Visibility,
+			&i.FlaggedReason,
+			&i.DeletedAt,
+			&i.ImpressionCount,
+			&i.Namespace,
+			&i.SentimentScore,
+			&i.RootID,
+			&i.ImportanceScore,
+			&i.ThreadDepth,
+			&i.ContentWarning,
+			&i.AdminEdited,
 		); err != nil {
 			return nil, err
 		}
@@ -110,12 +856,14 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirpsByUserId = `-- name: GetChirpsByUserId :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE user_id = $1 ORDER BY created_at
+const getFlaggedChirps = `-- name: GetFlaggedChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited FROM chirps
+WHERE flagged_reason IS NOT NULL AND deleted_at IS NULL
+ORDER BY created_at DESC
 `
 
-func (q *Queries) GetChirpsByUserId(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByUserId, userID)
+func (q *Queries) GetFlaggedChirps(ctx context.Context) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getFlaggedChirps)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +877,530 @@ func (q *Queries) GetChirpsByUserId(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.IsNsfw,
+			&i.IsHidden,
+			&i.WordCount,
+			&i.ReadingTimeSeconds,
+			&i.Visibility,
+			&i.FlaggedReason,
+			&i.DeletedAt,
+			&i.ImpressionCount,
+			&i.Namespace,
+			&i.SentimentScore,
+			&i.RootID,
+			&i.ImportanceScore,
+			&i.ThreadDepth,
+			&i.ContentWarning,
+			&i.AdminEdited,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getThreadChirps = `-- name: GetThreadChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.root_id = $1
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $2
+  AND (chirps.visibility = 'public' OR chirps.user_id = $3
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $3 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $3)))
+ORDER BY chirps.created_at
+`
+
+type GetThreadChirpsParams struct {
+	RootID    uuid.UUID
+	Namespace string
+	ViewerID  uuid.UUID
+}
+
+type GetThreadChirpsRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetThreadChirps(ctx context.Context, arg GetThreadChirpsParams) ([]GetThreadChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getThreadChirps, arg.RootID, arg.Namespace, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetThreadChirpsRow
+	for rows.Next() {
+		var i GetThreadChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserChirpsAsc = `-- name: GetUserChirpsAsc :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = $1
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $2
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = $3
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $3 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $3)))
+  AND (chirps.created_at, chirps.id) > ($4::timestamp, $5::uuid)
+ORDER BY chirps.created_at, chirps.id
+LIMIT $6
+`
+
+type GetUserChirpsAscParams struct {
+	UserID          uuid.UUID
+	Namespace       string
+	ViewerID        uuid.UUID
+	CursorCreatedAt time.Time
+	CursorID        uuid.UUID
+	PageSize        int32
+}
+
+type GetUserChirpsAscRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetUserChirpsAsc(ctx context.Context, arg GetUserChirpsAscParams) ([]GetUserChirpsAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserChirpsAsc,
+		arg.UserID,
+		arg.Namespace,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserChirpsAscRow
+	for rows.Next() {
+		var i GetUserChirpsAscRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserChirpsDesc = `-- name: GetUserChirpsDesc :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = $1
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $2
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = $3
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $3 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $3)))
+  AND (chirps.created_at, chirps.id) < ($4::timestamp, $5::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $6
+`
+
+type GetUserChirpsDescParams struct {
+	UserID          uuid.UUID
+	Namespace       string
+	ViewerID        uuid.UUID
+	CursorCreatedAt time.Time
+	CursorID        uuid.UUID
+	PageSize        int32
+}
+
+type GetUserChirpsDescRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+}
+
+func (q *Queries) GetUserChirpsDesc(ctx context.Context, arg GetUserChirpsDescParams) ([]GetUserChirpsDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserChirpsDesc,
+		arg.UserID,
+		arg.Namespace,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserChirpsDescRow
+	for rows.Next() {
+		var i GetUserChirpsDescRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserHourlyChirpCounts = `-- name: GetUserHourlyChirpCounts :many
+-- One row per hour for the last 7 days, ending with the current hour, so
+-- hours without chirps come back with a count of 0. Only chirps anyone may
+-- read are counted, since the chart is shared by every viewer.
+SELECT hours.hour::timestamp AS hour, COUNT(chirps.id) AS count
+FROM generate_series(
+    date_trunc('hour', NOW()::timestamp - INTERVAL '7 days') + INTERVAL '1 hour',
+    date_trunc('hour', NOW()::timestamp),
+    INTERVAL '1 hour'
+) AS hours(hour)
+LEFT JOIN chirps ON chirps.user_id = $1
+    AND chirps.deleted_at IS NULL
+    AND NOT chirps.is_hidden
+    AND chirps.visibility = 'public'
+    AND date_trunc('hour', chirps.created_at) = hours.hour
+GROUP BY hours.hour
+ORDER BY hours.hour
+`
+
+type GetUserHourlyChirpCountsRow struct {
+	Hour  time.Time
+	Count int64
+}
+
+func (q *Queries) GetUserHourlyChirpCounts(ctx context.Context, userID uuid.UUID) ([]GetUserHourlyChirpCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserHourlyChirpCounts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserHourlyChirpCountsRow
+	for rows.Next() {
+		var i GetUserHourlyChirpCountsRow
+		if err := rows.Scan(&i.Hour, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const importChirps = `-- name: ImportChirps :many
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, word_count, reading_time_seconds, sentiment_score, namespace, root_id)
+SELECT i.id, i.created_at, i.created_at, i.body, i.user_id, i.word_count, i.reading_time_seconds, i.sentiment_score, users.namespace, i.id
+FROM unnest(
+    $1::uuid[],
+    $2::timestamp[],
+    $3::text[],
+    $4::uuid[],
+    $5::integer[],
+    $6::integer[],
+    $7::float8[]
+) AS i(id, created_at, body, user_id, word_count, reading_time_seconds, sentiment_score)
+JOIN users ON users.id = i.user_id
+ON CONFLICT (id) DO NOTHING
+RETURNING id
+`
+
+type ImportChirpsParams struct {
+	Ids             []uuid.UUID
+	CreatedAts      []time.Time
+	Bodies          []string
+	UserIds         []uuid.UUID
+	WordCounts      []int32
+	ReadingTimes    []int32
+	SentimentScores []float64
+}
+
+func (q *Queries) ImportChirps(ctx context.Context, arg ImportChirpsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, importChirps,
+		pq.Array(arg.Ids),
+		pq.Array(arg.CreatedAts),
+		pq.Array(arg.Bodies),
+		pq.Array(arg.UserIds),
+		pq.Array(arg.WordCounts),
+		pq.Array(arg.ReadingTimes),
+		pq.Array(arg.SentimentScores),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirpsBefore = `-- name: PurgeDeletedChirpsBefore :execrows
+-- chirp_versions has no foreign key to chirps, so that versions survive
+-- archiving; purged chirps take theirs with them here.
+WITH versions AS (
+    DELETE FROM chirp_versions
+    WHERE chirp_id IN (SELECT id FROM chirps WHERE deleted_at < $1::timestamp)
+)
+DELETE FROM chirps WHERE deleted_at < $1::timestamp
+`
+
+func (q *Queries) PurgeDeletedChirpsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirpsBefore, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps SET deleted_at = NULL
+WHERE id = $1 AND user_id = $2
+  AND deleted_at >= $3::timestamp
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	UserID       uuid.UUID
+	DeletedSince time.Time
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID, arg.DeletedSince)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.parent_id, chirps.is_nsfw, chirps.is_hidden, chirps.word_count, chirps.reading_time_seconds, chirps.visibility, chirps.flagged_reason, chirps.deleted_at, chirps.impression_count, chirps.namespace, chirps.sentiment_score, chirps.root_id, chirps.importance_score, chirps.thread_depth, chirps.content_warning, chirps.admin_edited,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count,
+    ts_rank(to_tsvector('english', chirps.body), search.query)::float8 AS rank
+FROM chirps,
+    (SELECT CASE WHEN $1::boolean THEN to_tsquery('english', $2::text)
+        ELSE plainto_tsquery('english', $2::text) END AS query) search
+WHERE to_tsvector('english', chirps.body) @@ search.query
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = $3
+  AND ($4::uuid = '00000000-0000-0000-0000-000000000000' OR chirps.user_id = $4)
+  AND ($5::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= $6::float8
+  AND (NOT $7::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = $8
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = $8 AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = $8)))
+ORDER BY rank DESC, chirps.created_at DESC
+`
+
+type SearchChirpsParams struct {
+	Prefix        bool
+	Query         string
+	Namespace     string
+	AuthorID      uuid.UUID
+	IncludeHidden bool
+	MinSentiment  float64
+	VerifiedOnly  bool
+	ViewerID      uuid.UUID
+}
+
+type SearchChirpsRow struct {
+	Chirp      Chirp
+	LikeCount  int64
+	ReplyCount int64
+	Rank       float64
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Prefix,
+		arg.Query,
+		arg.Namespace,
+		arg.AuthorID,
+		arg.IncludeHidden,
+		arg.MinSentiment,
+		arg.VerifiedOnly,
+		arg.ViewerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ParentID,
+			&i.Chirp.IsNsfw,
+			&i.Chirp.IsHidden,
+			&i.Chirp.WordCount,
+			&i.Chirp.ReadingTimeSeconds,
+			&i.Chirp.Visibility,
+			&i.Chirp.FlaggedReason,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.ImpressionCount,
+			&i.Chirp.Namespace,
+			&i.Chirp.SentimentScore,
+			&i.Chirp.RootID,
+			&i.Chirp.ImportanceScore,
+			&i.Chirp.ThreadDepth,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.AdminEdited,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -142,3 +1414,179 @@ func (q *Queries) GetChirpsByUserId(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const setChirpContentWarning = `-- name: SetChirpContentWarning :one
+UPDATE chirps SET content_warning = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
+`
+
+type SetChirpContentWarningParams struct {
+	ID             uuid.UUID
+	ContentWarning sql.NullString
+}
+
+func (q *Queries) SetChirpContentWarning(ctx context.Context, arg SetChirpContentWarningParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpContentWarning, arg.ID, arg.ContentWarning)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
+	)
+	return i, err
+}
+
+const setChirpHidden = `-- name: SetChirpHidden :one
+UPDATE chirps SET is_hidden = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
+`
+
+type SetChirpHiddenParams struct {
+	ID       uuid.UUID
+	IsHidden bool
+}
+
+func (q *Queries) SetChirpHidden(ctx context.Context, arg SetChirpHiddenParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpHidden, arg.ID, arg.IsHidden)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
+	)
+	return i, err
+}
+
+const setChirpNsfw = `-- name: SetChirpNsfw :one
+UPDATE chirps SET is_nsfw = true, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, deleted_at, impression_count, namespace, sentiment_score, root_id, importance_score, thread_depth, content_warning, admin_edited
+`
+
+func (q *Queries) SetChirpNsfw(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpNsfw, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.IsNsfw,
+		&i.IsHidden,
+		&i.WordCount,
+		&i.ReadingTimeSeconds,
+		&i.Visibility,
+		&i.FlaggedReason,
+		&i.DeletedAt,
+		&i.ImpressionCount,
+		&i.Namespace,
+		&i.SentimentScore,
+		&i.RootID,
+		&i.ImportanceScore,
+		&i.ThreadDepth,
+		&i.ContentWarning,
+		&i.AdminEdited,
+	)
+	return i, err
+}
+
+const setImportanceScores = `-- name: SetImportanceScores :exec
+UPDATE chirps SET importance_score = s.score
+FROM unnest($1::uuid[], $2::float8[]) AS s(id, score)
+WHERE chirps.id = s.id
+`
+
+type SetImportanceScoresParams struct {
+	Ids    []uuid.UUID
+	Scores []float64
+}
+
+func (q *Queries) SetImportanceScores(ctx context.Context, arg SetImportanceScoresParams) error {
+	_, err := q.db.ExecContext(ctx, setImportanceScores, pq.Array(arg.Ids), pq.Array(arg.Scores))
+	return err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, id)
+	return err
+}
+
+const softDeleteChirpsByIds = `-- name: SoftDeleteChirpsByIds :many
+UPDATE chirps SET deleted_at = NOW()
+WHERE id = ANY($1::uuid[]) AND user_id = $2
+  AND deleted_at IS NULL
+RETURNING id
+`
+
+type SoftDeleteChirpsByIdsParams struct {
+	Ids    []uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) SoftDeleteChirpsByIds(ctx context.Context, arg SoftDeleteChirpsByIdsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, softDeleteChirpsByIds, pq.Array(arg.Ids), arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -13,16 +13,17 @@ import (
 )
 
 const createRefreshToken = `-- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at, scope)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
-RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at
+RETURNING token, created_at, updated_at, user_id, expires_at, revoked_at, scope
 `
 
 type CreateRefreshTokenParams struct {
@@ -30,6 +31,7 @@ type CreateRefreshTokenParams struct {
 	UserID    uuid.UUID
 	ExpiresAt sql.NullTime
 	RevokedAt sql.NullTime
+	Scope     string
 }
 
 func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error) {
@@ -38,6 +40,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		arg.UserID,
 		arg.ExpiresAt,
 		arg.RevokedAt,
+		arg.Scope,
 	)
 	var i RefreshToken
 	err := row.Scan(
@@ -47,6 +50,7 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.Scope,
 	)
 	return i, err
 }
@@ -61,7 +65,7 @@ func (q *Queries) DeleteRefreshTokens(ctx context.Context) error {
 }
 
 const getRefreshToken = `-- name: GetRefreshToken :one
-SELECT token, created_at, updated_at, user_id, expires_at, revoked_at FROM refresh_tokens WHERE token=$1
+SELECT token, created_at, updated_at, user_id, expires_at, revoked_at, scope FROM refresh_tokens WHERE token=$1
 `
 
 func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshToken, error) {
@@ -74,6 +78,7 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (RefreshTok
 		&i.UserID,
 		&i.ExpiresAt,
 		&i.RevokedAt,
+		&i.Scope,
 	)
 	return i, err
 }
@@ -6,16 +6,419 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 )
 
-type Chirp struct {
+type ChirpVisibility string
+
+const (
+	ChirpVisibilityPublic ChirpVisibility = "public"
+	ChirpVisibilityMutual ChirpVisibility = "mutual"
+)
+
+func (e *ChirpVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ChirpVisibility(s)
+	case string:
+		*e = ChirpVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ChirpVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullChirpVisibility struct {
+	ChirpVisibility ChirpVisibility
+	Valid           bool // Valid is true if ChirpVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullChirpVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.ChirpVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ChirpVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullChirpVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ChirpVisibility), nil
+}
+
+type JobStatus string
+
+const (
+	JobStatusPending JobStatus = "pending"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDone    JobStatus = "done"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+func (e *JobStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = JobStatus(s)
+	case string:
+		*e = JobStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for JobStatus: %T", src)
+	}
+	return nil
+}
+
+type NullJobStatus struct {
+	JobStatus JobStatus
+	Valid     bool // Valid is true if JobStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullJobStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.JobStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.JobStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullJobStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.JobStatus), nil
+}
+
+type NotificationType string
+
+const (
+	NotificationTypeLike    NotificationType = "like"
+	NotificationTypeReply   NotificationType = "reply"
+	NotificationTypeMention NotificationType = "mention"
+	NotificationTypeFollow  NotificationType = "follow"
+	NotificationTypeRepost  NotificationType = "repost"
+)
+
+func (e *NotificationType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = NotificationType(s)
+	case string:
+		*e = NotificationType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for NotificationType: %T", src)
+	}
+	return nil
+}
+
+type NullNotificationType struct {
+	NotificationType NotificationType
+	Valid            bool // Valid is true if NotificationType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullNotificationType) Scan(value interface{}) error {
+	if value == nil {
+		ns.NotificationType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.NotificationType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullNotificationType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.NotificationType), nil
+}
+
+type PushPlatform string
+
+const (
+	PushPlatformIos     PushPlatform = "ios"
+	PushPlatformAndroid PushPlatform = "android"
+	PushPlatformWeb     PushPlatform = "web"
+)
+
+func (e *PushPlatform) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = PushPlatform(s)
+	case string:
+		*e = PushPlatform(s)
+	default:
+		return fmt.Errorf("unsupported scan type for PushPlatform: %T", src)
+	}
+	return nil
+}
+
+type NullPushPlatform struct {
+	PushPlatform PushPlatform
+	Valid        bool // Valid is true if PushPlatform is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullPushPlatform) Scan(value interface{}) error {
+	if value == nil {
+		ns.PushPlatform, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.PushPlatform.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullPushPlatform) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.PushPlatform), nil
+}
+
+type AuditLog struct {
+	ID         uuid.UUID
+	ActorID    uuid.NullUUID
+	Action     string
+	EntityType string
+	EntityID   uuid.NullUUID
+	Details    json.RawMessage
+	IpAddress  sql.NullString
+	CreatedAt  sql.NullTime
+}
+
+type BlockedEmailDomain struct {
+	Domain    string
+	CreatedAt sql.NullTime
+}
+
+type ChirpEmbedding struct {
+	ChirpID      uuid.UUID
+	Embedding    interface{}
+	ModelVersion string
+	CreatedAt    time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt sql.NullTime
+}
+
+type ChirpMedium struct {
 	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Url       string
+	MimeType  string
+	AltText   string
+	Position  int32
 	CreatedAt sql.NullTime
-	UpdatedAt sql.NullTime
+}
+
+type ChirpMediaArchive struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Url       string
+	MimeType  string
+	AltText   string
+	Position  int32
+	CreatedAt sql.NullTime
+}
+
+type ChirpRead struct {
+	UserID      uuid.UUID
+	ChirpID     uuid.UUID
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+	ViewCount   int32
+}
+
+type ChirpTopic struct {
+	ChirpID uuid.UUID
+	Topic   string
+}
+
+type ChirpTranslation struct {
+	ChirpID        uuid.UUID
+	Language       string
+	TranslatedBody string
+	CreatedAt      time.Time
+}
+
+type ChirpVersion struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
 	Body      sql.NullString
+	EditedBy  uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpView struct {
+	ChirpID   uuid.UUID
+	ViewerKey string
+	CreatedAt time.Time
+}
+
+type Chirp struct {
+	ID                 uuid.UUID
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+	Body               sql.NullString
+	UserID             uuid.UUID
+	ParentID           uuid.NullUUID
+	IsNsfw             bool
+	IsHidden           bool
+	WordCount          int32
+	ReadingTimeSeconds int32
+	Visibility         ChirpVisibility
+	FlaggedReason      sql.NullString
+	DeletedAt          sql.NullTime
+	ImpressionCount    int64
+	Namespace          string
+	SentimentScore     float64
+	RootID             uuid.UUID
+	ImportanceScore    float64
+	ThreadDepth        int32
+	ContentWarning     sql.NullString
+	AdminEdited        bool
+}
+
+type ChirpsArchive struct {
+	ID                 uuid.UUID
+	CreatedAt          sql.NullTime
+	UpdatedAt          sql.NullTime
+	Body               sql.NullString
+	UserID             uuid.UUID
+	ArchivedAt         sql.NullTime
+	ParentID           uuid.NullUUID
+	IsNsfw             bool
+	IsHidden           bool
+	WordCount          int32
+	ReadingTimeSeconds int32
+	Visibility         ChirpVisibility
+	FlaggedReason      sql.NullString
+	Namespace          string
+	SentimentScore     float64
+	RootID             uuid.NullUUID
+	ImpressionCount    int64
+	ThreadDepth        int32
+	ContentWarning     sql.NullString
+	AdminEdited        bool
+	ImportanceScore    float64
+}
+
+type EmailOtpSession struct {
+	Token          string
+	UserID         uuid.UUID
+	OtpHash        string
+	FailedAttempts int32
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+	UsedAt         sql.NullTime
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  sql.NullTime
+}
+
+type JobQueue struct {
+	ID           int64
+	Type         string
+	Payload      json.RawMessage
+	Status       JobStatus
+	AttemptCount int32
+	RunAt        time.Time
+	CreatedAt    time.Time
+	ProcessedAt  sql.NullTime
+	Error        sql.NullString
+}
+
+type ListMember struct {
+	ListID    uuid.UUID
 	UserID    uuid.UUID
+	CreatedAt sql.NullTime
+}
+
+type List struct {
+	ID          uuid.UUID
+	OwnerID     uuid.UUID
+	Name        string
+	Description string
+	IsPublic    bool
+	CreatedAt   sql.NullTime
+	UpdatedAt   sql.NullTime
+}
+
+type Namespace struct {
+	Name            string
+	ChirpsPerMinute sql.NullInt32
+	Features        []string
+	CreatedAt       time.Time
+}
+
+type Notification struct {
+	ID          uuid.UUID
+	RecipientID uuid.UUID
+	ActorID     uuid.UUID
+	Type        NotificationType
+	ChirpID     uuid.NullUUID
+	ReadAt      sql.NullTime
+	CreatedAt   sql.NullTime
+}
+
+type OauthClient struct {
+	ID          string
+	SecretHash  string
+	Name        string
+	RedirectUri string
+	CreatedAt   time.Time
+}
+
+type OauthCode struct {
+	Code        string
+	ClientID    string
+	UserID      uuid.UUID
+	RedirectUri string
+	Scope       string
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
+
+type OauthIdentity struct {
+	UserID               uuid.UUID
+	Provider             string
+	ProviderUserID       string
+	AccessTokenEncrypted []byte
+	CreatedAt            time.Time
+}
+
+type PixelEvent struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Referer   string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+type PushToken struct {
+	DeviceToken string
+	UserID      uuid.UUID
+	Platform    PushPlatform
+	CreatedAt   time.Time
 }
 
 type RefreshToken struct {
@@ -25,13 +428,73 @@ type RefreshToken struct {
 	UserID    uuid.UUID
 	ExpiresAt sql.NullTime
 	RevokedAt sql.NullTime
+	Scope     string
+}
+
+type RequestFingerprint struct {
+	Fingerprint string
+	ChirpID     uuid.UUID
+	CreatedAt   time.Time
+}
+
+type RevokedToken struct {
+	Jti       string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+type ShortLink struct {
+	Code          string
+	ChirpID       uuid.NullUUID
+	CreatedBy     uuid.UUID
+	CreatedAt     time.Time
+	ClickCount    int64
+	TargetUrl     sql.NullString
+	AutoGenerated bool
+}
+
+type SigningKey struct {
+	KeyID     string
+	PublicKey []byte
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type TopicSubscription struct {
+	UserID    uuid.UUID
+	Topic     string
+	CreatedAt sql.NullTime
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      sql.NullTime
-	UpdatedAt      sql.NullTime
-	Email          sql.NullString
-	HashedPassword string
-	IsChirpyRed    bool
+	ID                    uuid.UUID
+	CreatedAt             sql.NullTime
+	UpdatedAt             sql.NullTime
+	Email                 sql.NullString
+	HashedPassword        string
+	IsChirpyRed           bool
+	IsVerified            bool
+	IsAdmin               bool
+	EmailMfaEnabled       bool
+	Namespace             string
+	EmailNotifications    bool
+	PendingEmail          sql.NullString
+	PendingEmailToken     sql.NullString
+	PendingEmailExpiresAt sql.NullTime
+}
+
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	ChirpID       uuid.UUID
+	AttemptNumber int32
+	StatusCode    sql.NullInt32
+	Error         sql.NullString
+	DeliveredAt   time.Time
+}
+
+type Webhook struct {
+	ID        uuid.UUID
+	Url       string
+	CreatedAt time.Time
 }
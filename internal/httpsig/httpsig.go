@@ -0,0 +1,175 @@
+// Package httpsig signs and verifies requests following the draft HTTP
+// Signatures spec (draft-cavage-http-signatures) with Ed25519 keys.
+package httpsig
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm = "ed25519"
+	// MaxClockSkew is how far a request's Date may be from the verifier's
+	// clock.
+	MaxClockSkew = 5 * time.Minute
+)
+
+// signedHeaders are the components every signature must cover.
+var signedHeaders = []string{"(request-target)", "host", "date", "digest"}
+
+var (
+	ErrMissingSignature = errors.New("missing Signature header")
+	ErrMalformed        = errors.New("malformed Signature header")
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrDigestMismatch   = errors.New("digest does not match body")
+	ErrStale            = errors.New("request date outside allowed clock skew")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// KeyStore looks up the public key for a key ID, returning ErrUnknownKey
+// when there is none.
+type KeyStore interface {
+	PublicKey(ctx context.Context, keyID string) (ed25519.PublicKey, error)
+}
+
+// Sign adds Date (if unset), Digest and Signature headers to req. The body
+// is read to compute the digest and replaced so it can still be sent.
+func Sign(req *http.Request, keyID string, privateKey ed25519.PrivateKey) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	if req.Header.Get("Date") == "" {
+		req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+	req.Header.Set("Digest", digest(body))
+	sig := ed25519.Sign(privateKey, []byte(signingString(req, signedHeaders)))
+	req.Header.Set("Signature", fmt.Sprintf(`keyId=%q,algorithm=%q,headers=%q,signature=%q`,
+		keyID, algorithm, strings.Join(signedHeaders, " "), base64.StdEncoding.EncodeToString(sig)))
+	return nil
+}
+
+// Verify checks req's Signature header against the key it names in
+// keyStore. The signature must cover (request-target), host, date and
+// digest, the digest must match the body and the date must be within
+// MaxClockSkew.
+func Verify(req *http.Request, keyStore KeyStore) error {
+	params, err := parseSignature(req.Header.Get("Signature"))
+	if err != nil {
+		return err
+	}
+	headers := strings.Fields(params["headers"])
+	for _, h := range signedHeaders {
+		if !slices.Contains(headers, h) {
+			return fmt.Errorf("%w: signature does not cover %s", ErrMalformed, h)
+		}
+	}
+	if alg := params["algorithm"]; alg != "" && alg != algorithm && alg != "hs2019" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrMalformed, alg)
+	}
+	sig, err := base64.StdEncoding.DecodeString(params["signature"])
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	date, err := http.ParseTime(req.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("%w: bad Date header", ErrMalformed)
+	}
+	if skew := time.Since(date); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return ErrStale
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	if req.Header.Get("Digest") != digest(body) {
+		return ErrDigestMismatch
+	}
+
+	key, err := keyStore.PublicKey(req.Context(), params["keyId"])
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key, []byte(signingString(req, headers)), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signingString joins the named components, one "name: value" per line.
+func signingString(req *http.Request, headers []string) string {
+	lines := make([]string, len(headers))
+	for i, h := range headers {
+		var v string
+		switch h {
+		case "(request-target)":
+			v = strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+		case "host":
+			v = req.Host
+			if v == "" {
+				v = req.URL.Host
+			}
+		default:
+			v = strings.Join(req.Header.Values(h), ", ")
+		}
+		lines[i] = h + ": " + v
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseSignature splits a Signature header into its key="value" params.
+func parseSignature(header string) (map[string]string, error) {
+	if header == "" {
+		return nil, ErrMissingSignature
+	}
+	params := map[string]string{}
+	for header != "" {
+		name, rest, ok := strings.Cut(header, "=")
+		if !ok || !strings.HasPrefix(rest, `"`) {
+			return nil, ErrMalformed
+		}
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, ErrMalformed
+		}
+		params[strings.TrimSpace(name)] = rest[1 : end+1]
+		header = strings.TrimPrefix(strings.TrimSpace(rest[end+2:]), ",")
+	}
+	for _, p := range []string{"keyId", "headers", "signature"} {
+		if params[p] == "" {
+			return nil, fmt.Errorf("%w: missing %s", ErrMalformed, p)
+		}
+	}
+	return params, nil
+}
+
+func digest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "SHA-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// readBody returns req's body, leaving a fresh reader over the same bytes
+// in its place.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
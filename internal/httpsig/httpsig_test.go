@@ -0,0 +1,106 @@
+package httpsig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mapKeyStore map[string]ed25519.PublicKey
+
+func (m mapKeyStore) PublicKey(ctx context.Context, keyID string) (ed25519.PublicKey, error) {
+	key, ok := m[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+func signedRequest(t *testing.T, priv ed25519.PrivateKey, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest("POST", "https://chirpy.example/api/chirps?draft=1", strings.NewReader(body))
+	if err := Sign(req, "backend-1", priv); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	return req
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := mapKeyStore{"backend-1": pub}
+
+	req := signedRequest(t, priv, `{"body":"hello"}`)
+	if err := Verify(req, keys); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	// Signing and verifying both leave the body readable.
+	if body, _ := io.ReadAll(req.Body); string(body) != `{"body":"hello"}` {
+		t.Errorf("got body %q after verifying", body)
+	}
+	if !strings.Contains(req.Header.Get("Signature"), `headers="(request-target) host date digest"`) {
+		t.Errorf("got Signature %q", req.Header.Get("Signature"))
+	}
+
+	empty := httptest.NewRequest("GET", "https://chirpy.example/api/users/me", nil)
+	if err := Sign(empty, "backend-1", priv); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := Verify(empty, keys); err != nil {
+		t.Errorf("Verify failed for a bodiless request: %v", err)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := mapKeyStore{"backend-1": pub}
+
+	tests := []struct {
+		name   string
+		tamper func(*http.Request)
+		keys   KeyStore
+		want   error
+	}{
+		{"body", func(r *http.Request) { r.Body = io.NopCloser(strings.NewReader(`{"body":"evil"}`)) }, keys, ErrDigestMismatch},
+		{"body and digest", func(r *http.Request) {
+			r.Body = io.NopCloser(strings.NewReader(`{"body":"evil"}`))
+			r.Header.Set("Digest", digest([]byte(`{"body":"evil"}`)))
+		}, keys, ErrInvalidSignature},
+		{"path", func(r *http.Request) { r.URL.Path = "/api/users" }, keys, ErrInvalidSignature},
+		{"method", func(r *http.Request) { r.Method = "DELETE" }, keys, ErrInvalidSignature},
+		{"host", func(r *http.Request) { r.Host = "evil.example" }, keys, ErrInvalidSignature},
+		{"stale date", func(r *http.Request) {
+			r.Header.Set("Date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+		}, keys, ErrStale},
+		{"unsigned", func(r *http.Request) { r.Header.Del("Signature") }, keys, ErrMissingSignature},
+		{"missing digest component", func(r *http.Request) {
+			r.Header.Set("Signature", strings.Replace(r.Header.Get("Signature"), " digest", "", 1))
+		}, keys, ErrMalformed},
+		{"unknown key", func(r *http.Request) {}, mapKeyStore{}, ErrUnknownKey},
+		{"wrong key", func(r *http.Request) {}, mapKeyStore{"backend-1": otherPub}, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := signedRequest(t, priv, `{"body":"hello"}`)
+			tt.tamper(req)
+			if err := Verify(req, tt.keys); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
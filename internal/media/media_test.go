@@ -0,0 +1,57 @@
+package media
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("test-key")
+
+func signedParts(t *testing.T, rawURL string, expiresIn time.Duration) (expires, sig string) {
+	t.Helper()
+	signed, err := GenerateSignedURL(rawURL, expiresIn, testKey)
+	if err != nil {
+		t.Fatalf("GenerateSignedURL failed: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("parsing signed url: %v", err)
+	}
+	return u.Query().Get("expires"), u.Query().Get("sig")
+}
+
+func TestVerifySignedURL(t *testing.T) {
+	rawURL := "https://cdn.example.com/a.png"
+	validExp, validSig := signedParts(t, rawURL, time.Hour)
+	expiredExp, expiredSig := signedParts(t, rawURL, -time.Hour)
+
+	tests := []struct {
+		name    string
+		url     string
+		expires string
+		sig     string
+		wantErr error
+	}{
+		{"valid", rawURL, validExp, validSig, nil},
+		{"expired", rawURL, expiredExp, expiredSig, ErrExpired},
+		{"tampered url", "https://cdn.example.com/b.png", validExp, validSig, ErrInvalidSignature},
+		{"tampered expiry", rawURL, expiredExp, validSig, ErrInvalidSignature},
+		{"garbage signature", rawURL, validExp, "not-hex", ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignedURL(tt.url, tt.expires, tt.sig, testKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got err=%v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateSignedURLWithoutKey(t *testing.T) {
+	if _, err := GenerateSignedURL("https://cdn.example.com/a.png", time.Hour, nil); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("got err=%v, want ErrNoSigningKey", err)
+	}
+}
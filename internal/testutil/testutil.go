@@ -0,0 +1,58 @@
+// Package testutil holds setup helpers shared by Chirpy's handler tests.
+//
+// Package main cannot be imported, so building the apiConfig and its mux is
+// left to the caller: NewTestServer takes the finished handler and owns only
+// the httptest plumbing.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// Store is the part of database.Querier these helpers write through, so
+// any mock store satisfying Querier can be passed in.
+type Store interface {
+	CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error)
+}
+
+// NewTestServer starts an httptest.Server for handler and closes it when
+// the test finishes.
+func NewTestServer(t testing.TB, handler http.Handler) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// MakeAuthHeader returns an Authorization header value carrying a JWT for
+// userID. A negative expiry yields a token that has already expired.
+func MakeAuthHeader(t testing.TB, userID uuid.UUID, secret []byte, expiry time.Duration) string {
+	t.Helper()
+	token, err := auth.MakeJWT(userID, string(secret), expiry)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+	return "Bearer " + token
+}
+
+// MustCreateUser inserts a user with a unique email into store.
+func MustCreateUser(t testing.TB, store Store) database.User {
+	t.Helper()
+	u, err := store.CreateUser(context.Background(), database.CreateUserParams{
+		Email: sql.NullString{String: "user-" + uuid.NewString() + "@example.com", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	return u
+}
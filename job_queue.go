@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/lib/pq"
+)
+
+const (
+	jobWorkers = 4
+	// jobLease is how long a claimed job is left to its worker before
+	// another may claim it, in case the first one died mid-job.
+	jobLease = 5 * time.Minute
+	// jobPollInterval is how often idle workers look for due jobs without
+	// being woken, which picks up retries and jobs whose NOTIFY was missed.
+	jobPollInterval = 10 * time.Second
+	maxJobAttempts  = 5
+	maxJobBackoff   = 10 * time.Minute
+	// jobQueueChannel is the channel the job_queue insert trigger notifies.
+	jobQueueChannel = "job_queue"
+)
+
+// Job is a unit of background work. Its JSON encoding is the payload stored
+// in job_queue, and JobType picks the handler that runs it.
+type Job interface {
+	JobType() string
+}
+
+// JobHandler runs one job from its payload. An error schedules a retry, so
+// a handler should only return one before it has had any effect.
+type JobHandler func(ctx context.Context, payload json.RawMessage) error
+
+// jobHandlerFor adapts fn into a JobHandler that decodes the payload into
+// a T first.
+func jobHandlerFor[T any](fn func(context.Context, T) error) JobHandler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var v T
+		if err := json.Unmarshal(payload, &v); err != nil {
+			return fmt.Errorf("decoding payload: %w", err)
+		}
+		return fn(ctx, v)
+	}
+}
+
+// JobQueue runs side effects of a request after the request has been
+// answered. Jobs are rows in job_queue, so they outlast a restart; workers
+// claim them one at a time with SELECT ... FOR UPDATE SKIP LOCKED, waking on
+// the table's NOTIFY and polling in case one was missed.
+type JobQueue struct {
+	db  database.Querier
+	now func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	// wake holds at most one pending wake-up for the workers.
+	wake chan struct{}
+}
+
+func newJobQueue(db database.Querier, now func() time.Time) *JobQueue {
+	return &JobQueue{
+		db:       db,
+		now:      now,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Register makes handler run every job of jobType, replacing any handler
+// registered before.
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = handler
+}
+
+// Enqueue stores job to be run by the next free worker.
+func (q *JobQueue) Enqueue(ctx context.Context, job Job) error {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.EnqueueJob(ctx, database.EnqueueJobParams{Type: job.JobType(), Payload: payload})
+	return err
+}
+
+// jobBackoff is the wait before retrying a job that has failed attempt
+// times: 5s doubling per failure, capped at maxJobBackoff.
+func jobBackoff(attempt int32) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+	if attempt > 20 {
+		return maxJobBackoff
+	}
+	return min(5*time.Second<<(attempt-1), maxJobBackoff)
+}
+
+// runNext claims one due job and runs it, recording the outcome. It
+// reports whether there was a job to run.
+func (q *JobQueue) runNext(ctx context.Context) (bool, error) {
+	now := q.now()
+	job, err := q.db.ClaimJob(ctx, database.ClaimJobParams{Now: now.UTC(), LeaseUntil: now.Add(jobLease).UTC()})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := q.run(ctx, job); err != nil {
+		log.Printf("Job %d (%s) failed on attempt %d: %s", job.ID, job.Type, job.AttemptCount, err)
+		status := database.JobStatusPending
+		if job.AttemptCount >= maxJobAttempts {
+			status = database.JobStatusFailed
+		}
+		return true, q.db.FailJob(ctx, database.FailJobParams{
+			ID:     job.ID,
+			Status: status,
+			RunAt:  q.now().Add(jobBackoff(job.AttemptCount)).UTC(),
+			Error:  sql.NullString{String: err.Error(), Valid: true},
+		})
+	}
+	return true, q.db.CompleteJob(ctx, job.ID)
+}
+
+// run calls job's handler, turning a panic into an error so one bad
+// handler cannot take a worker down.
+func (q *JobQueue) run(ctx context.Context, job database.JobQueue) (err error) {
+	q.mu.RLock()
+	handler, ok := q.handlers[job.Type]
+	q.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler registered for %s", job.Type)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// signal wakes one idle worker, if none is already due to wake.
+func (q *JobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// work runs jobs until ctx is done, sleeping while there are none until it
+// is woken or jobPollInterval passes. A job that has started is finished
+// even if ctx is cancelled meanwhile.
+func (q *JobQueue) work(ctx context.Context) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		ran, err := q.runNext(context.WithoutCancel(ctx))
+		if err != nil {
+			log.Printf("Error running jobs: %s", err)
+		}
+		if ran && err == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWorkers runs n workers until ctx is done and every one has finished
+// its current job.
+func (q *JobQueue) runWorkers(ctx context.Context, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() { q.work(ctx) })
+	}
+	wg.Wait()
+}
+
+// listen wakes a worker whenever Postgres announces a new job, until ctx is
+// done. It holds a connection of its own to dbURL, reconnecting when it
+// drops; notifications missed meanwhile wait for the next poll.
+func (q *JobQueue) listen(ctx context.Context, dbURL string) {
+	l := pq.NewListener(dbURL, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Job queue listener: %s", err)
+		}
+	})
+	defer l.Close()
+	if err := l.Listen(jobQueueChannel); err != nil {
+		log.Printf("Error listening for jobs, falling back to polling: %s", err)
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		// A nil notification means the connection was re-established,
+		// which is as good a reason as any to look for work.
+		case <-l.Notify:
+			q.signal()
+		}
+	}
+}
+
+// RegisterJobHandler makes handler run every queued job of jobType.
+func (cfg *apiConfig) RegisterJobHandler(jobType string, handler JobHandler) {
+	cfg.jobs.Register(jobType, handler)
+}
+
+// enqueueJob queues job, logging rather than failing the request when it
+// cannot be stored: the request's own work is already done by then.
+func (cfg *apiConfig) enqueueJob(ctx context.Context, job Job) {
+	if err := cfg.jobs.Enqueue(ctx, job); err != nil {
+		log.Printf("Error queueing %s job: %s", job.JobType(), err)
+	}
+}
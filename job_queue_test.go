@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// runJobs runs every job queued on cfg until none are due.
+func runJobs(t *testing.T, cfg *apiConfig) {
+	t.Helper()
+	for {
+		ran, err := cfg.jobs.runNext(context.Background())
+		if err != nil {
+			t.Fatalf("runNext: %v", err)
+		}
+		if !ran {
+			return
+		}
+	}
+}
+
+type testJob struct {
+	N int `json:"n"`
+}
+
+func (testJob) JobType() string { return "test" }
+
+func TestJobQueueRunsJobs(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	var got []int
+	cfg.RegisterJobHandler("test", jobHandlerFor(func(ctx context.Context, j testJob) error {
+		got = append(got, j.N)
+		return nil
+	}))
+	for n := range 3 {
+		if err := cfg.jobs.Enqueue(context.Background(), testJob{N: n}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if string(store.jobs[0].Payload) != `{"n":0}` || store.jobs[0].Type != "test" {
+		t.Errorf("stored job %s %s", store.jobs[0].Type, store.jobs[0].Payload)
+	}
+	runJobs(t, cfg)
+	if len(got) != 3 || got[0] != 0 || got[2] != 2 {
+		t.Errorf("handled %v, want [0 1 2]", got)
+	}
+	for _, j := range store.jobs {
+		if j.Status != database.JobStatusDone || !j.ProcessedAt.Valid || j.AttemptCount != 1 {
+			t.Errorf("job %d: got status %s, attempts %d", j.ID, j.Status, j.AttemptCount)
+		}
+	}
+}
+
+func TestJobQueueRetriesFailures(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	calls := 0
+	cfg.RegisterJobHandler("test", func(ctx context.Context, payload json.RawMessage) error {
+		calls++
+		return errors.New("provider down")
+	})
+	cfg.jobs.Enqueue(context.Background(), testJob{})
+	now := time.Now()
+	cfg.jobs.now = func() time.Time { return now }
+
+	for attempt := int32(1); attempt <= maxJobAttempts; attempt++ {
+		runJobs(t, cfg)
+		job := store.jobs[0]
+		if job.AttemptCount != attempt || job.Error.String != "provider down" {
+			t.Fatalf("attempt %d: got %d attempts, error %q", attempt, job.AttemptCount, job.Error.String)
+		}
+		if attempt < maxJobAttempts {
+			if job.Status != database.JobStatusPending || job.RunAt.Before(now.Add(jobBackoff(attempt)).UTC()) {
+				t.Fatalf("attempt %d: got status %s at %s, want a retry after backoff", attempt, job.Status, job.RunAt)
+			}
+			// Nothing is due again until the backoff has passed.
+			if ran, _ := cfg.jobs.runNext(context.Background()); ran {
+				t.Fatalf("attempt %d: retried before the backoff", attempt)
+			}
+			now = now.Add(maxJobBackoff)
+		}
+	}
+	if store.jobs[0].Status != database.JobStatusFailed {
+		t.Errorf("got status %s after %d attempts, want failed", store.jobs[0].Status, maxJobAttempts)
+	}
+	now = now.Add(time.Hour)
+	runJobs(t, cfg)
+	if calls != maxJobAttempts {
+		t.Errorf("handler ran %d times, want %d", calls, maxJobAttempts)
+	}
+}
+
+func TestJobQueueReclaimsExpiredLeases(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.jobs.Enqueue(context.Background(), testJob{})
+	// A worker claims the job and dies before finishing it.
+	if _, err := store.ClaimJob(context.Background(), database.ClaimJobParams{Now: time.Now().UTC(), LeaseUntil: time.Now().Add(jobLease).UTC()}); err != nil {
+		t.Fatal(err)
+	}
+	ran := false
+	cfg.RegisterJobHandler("test", jobHandlerFor(func(ctx context.Context, j testJob) error {
+		ran = true
+		return nil
+	}))
+	runJobs(t, cfg)
+	if ran {
+		t.Fatal("ran a job whose lease had not expired")
+	}
+	cfg.jobs.now = func() time.Time { return time.Now().Add(jobLease + time.Second) }
+	runJobs(t, cfg)
+	if !ran || store.jobs[0].Status != database.JobStatusDone || store.jobs[0].AttemptCount != 2 {
+		t.Errorf("got ran %v, status %s, attempts %d", ran, store.jobs[0].Status, store.jobs[0].AttemptCount)
+	}
+}
+
+func TestJobQueueRecoversFromPanic(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.RegisterJobHandler("test", func(context.Context, json.RawMessage) error { panic("handler bug") })
+	cfg.jobs.Enqueue(context.Background(), testJob{})
+	cfg.jobs.Enqueue(context.Background(), unknownJob{})
+	runJobs(t, cfg)
+	if j := store.jobs[0]; j.Status != database.JobStatusPending || j.Error.String != "handler panicked: handler bug" {
+		t.Errorf("panicking job: got status %s, error %q", j.Status, j.Error.String)
+	}
+	if j := store.jobs[1]; j.Error.String != "no handler registered for unknown" {
+		t.Errorf("unknown job: got error %q", j.Error.String)
+	}
+}
+
+type unknownJob struct{}
+
+func (unknownJob) JobType() string { return "unknown" }
+
+func TestJobQueueWorkersWakeOnSignal(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	done := make(chan int, 1)
+	cfg.RegisterJobHandler("test", jobHandlerFor(func(ctx context.Context, j testJob) error {
+		done <- j.N
+		return nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		cfg.jobs.runWorkers(ctx, 2)
+		close(stopped)
+	}()
+
+	cfg.jobs.Enqueue(context.Background(), testJob{N: 7})
+	cfg.jobs.signal()
+	select {
+	case n := <-done:
+		if n != 7 {
+			t.Errorf("got job %d, want 7", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("workers did not pick up the job after a wake-up")
+	}
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("workers did not stop when their context was cancelled")
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+func respondWithError(w http.ResponseWriter, code int, msg string) {
+	type errResp struct {
+		Error string `json:"error"`
+	}
+	respondWithJSON(w, code, errResp{
+		Error: msg,
+	})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload any) {
+	contentType := "application/json"
+	if hw, ok := w.(*hypermediaWriter); ok {
+		var err error
+		if payload, contentType, err = hw.render(payload); err != nil {
+			log.Printf("Error rendering %s: %s", hw.format, err)
+			w.WriteHeader(500)
+			return
+		}
+	}
+	stop := startWriterPhase(w, "marshal")
+	dat, err := json.Marshal(payload)
+	stop()
+	if err != nil {
+		log.Printf("Error marshalling JSON: %s", err)
+		w.WriteHeader(500)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(code)
+	w.Write(dat)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+const (
+	leaderboardSize     = 20
+	leaderboardCacheTTL = time.Hour
+)
+
+// leaderboardPeriods are the windows ?period can pick, by how far back
+// they reach; zero means all time.
+var leaderboardPeriods = map[string]time.Duration{
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"all": 0,
+}
+
+// leaderboardEntry is one ranked user. The board is public, so User
+// carries no email.
+type leaderboardEntry struct {
+	Rank  int      `json:"rank"`
+	User  userResp `json:"user"`
+	Score int64    `json:"score"`
+}
+
+// leaderboardCache holds each namespace's leaderboard per period for
+// leaderboardCacheTTL, since scoring every user is too expensive to do per
+// request.
+type leaderboardCache struct {
+	mu      sync.RWMutex
+	entries map[leaderboardKey]leaderboardCacheEntry
+}
+
+type leaderboardKey struct {
+	namespace string
+	period    string
+}
+
+type leaderboardCacheEntry struct {
+	ranks     []leaderboardEntry
+	expiresAt time.Time
+}
+
+func (c *leaderboardCache) get(key leaderboardKey, now time.Time) ([]leaderboardEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.ranks, true
+}
+
+func (c *leaderboardCache) put(key leaderboardKey, ranks []leaderboardEntry, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[leaderboardKey]leaderboardCacheEntry)
+	}
+	c.entries[key] = leaderboardCacheEntry{ranks: ranks, expiresAt: expiresAt}
+}
+
+// handlerGetLeaderboard ranks the top users of a period by engagement: one
+// point per chirp posted, three per like received and five per new
+// follower. Only the request's namespace is ranked, and hidden chirps and
+// likes on them earn nothing.
+func (cfg *apiConfig) handlerGetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "30d"
+	}
+	window, ok := leaderboardPeriods[period]
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "period must be 7d, 30d or all")
+		return
+	}
+	namespace := namespaceOf(r.Context())
+	key := leaderboardKey{namespace: namespace, period: period}
+	now := time.Now()
+	if ranks, ok := cfg.leaderboardCache.get(key, now); ok {
+		respondWithJSON(w, http.StatusOK, ranks)
+		return
+	}
+
+	var since time.Time
+	if window > 0 {
+		since = now.Add(-window).UTC()
+	}
+	rows, err := cfg.db.GetLeaderboard(r.Context(), database.GetLeaderboardParams{
+		Since:     since,
+		Namespace: namespace,
+		Size:      leaderboardSize,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	ranks := make([]leaderboardEntry, 0, len(rows))
+	for i, row := range rows {
+		ranks = append(ranks, leaderboardEntry{
+			Rank: i + 1,
+			User: userResp{
+				ID:          row.ID,
+				CreatedAt:   row.CreatedAt.Time,
+				UpdatedAt:   row.UpdatedAt.Time,
+				IsChirpyRed: row.IsChirpyRed,
+				IsVerified:  row.IsVerified,
+			},
+			Score: row.Score,
+		})
+	}
+	cfg.leaderboardCache.put(key, ranks, now.Add(leaderboardCacheTTL))
+	respondWithJSON(w, http.StatusOK, ranks)
+}
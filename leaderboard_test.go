@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func getLeaderboard(t *testing.T, h http.Handler, query string) []leaderboardEntry {
+	t.Helper()
+	rec := serve(h, "GET", "/api/leaderboard"+query, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var ranks []leaderboardEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &ranks); err != nil {
+		t.Fatal(err)
+	}
+	return ranks
+}
+
+func TestLeaderboardScoreOrdering(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	carol, carolToken := seedUser(t, cfg, store, "carol@example.com")
+
+	// Alice: two chirps (2). Bob: one chirp that Carol likes (1 + 3).
+	// Carol: one follower (5).
+	postChirp(t, h, `{"body":"first"}`, aliceToken)
+	postChirp(t, h, `{"body":"second"}`, aliceToken)
+	liked := postChirp(t, h, `{"body":"likeable"}`, bobToken)
+	serve(h, "POST", "/api/chirps/"+liked.ID.String()+"/like", "", carolToken)
+	serve(h, "POST", "/api/users/"+carol.ID.String()+"/follow", "", aliceToken)
+
+	ranks := getLeaderboard(t, h, "")
+	want := []struct {
+		id    uuid.UUID
+		score int64
+	}{{carol.ID, 5}, {bob.ID, 4}, {alice.ID, 2}}
+	if len(ranks) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(ranks), len(want), ranks)
+	}
+	for i, w := range want {
+		if ranks[i].Rank != i+1 || ranks[i].User.ID != w.id || ranks[i].Score != w.score {
+			t.Errorf("rank %d: got %+v, want user %s with score %d", i+1, ranks[i], w.id, w.score)
+		}
+	}
+}
+
+func TestLeaderboardSkipsHiddenChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	postChirp(t, h, `{"body":"visible"}`, aliceToken)
+	hidden := postChirp(t, h, `{"body":"hidden"}`, aliceToken)
+	serve(h, "POST", "/api/chirps/"+hidden.ID.String()+"/like", "", bobToken)
+	store.chirps[1].IsHidden = true
+
+	rec := serve(h, "GET", "/api/leaderboard", "", "")
+	if strings.Contains(rec.Body.String(), "@example.com") {
+		t.Errorf("leaderboard leaks an email: %s", rec.Body.String())
+	}
+	if ranks := getLeaderboard(t, h, "?period=all"); len(ranks) != 1 || ranks[0].Score != 1 {
+		t.Errorf("got %+v, want only the visible chirp to score", ranks)
+	}
+}
+
+func TestLeaderboardNamespaces(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	store.CreateNamespace(context.Background(), database.CreateNamespaceParams{Name: "team"})
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	store.users[1].Namespace = "team"
+	postChirp(t, h, `{"body":"default"}`, aliceToken)
+	serveHost(h, "team.chirpy.dev", "POST", "/api/chirps", `{"body":"team"}`, bobToken)
+
+	if ranks := getLeaderboard(t, h, ""); len(ranks) != 1 || ranks[0].User.ID != alice.ID {
+		t.Errorf("default namespace: got %+v, want only alice", ranks)
+	}
+	rec := serveHost(h, "team.chirpy.dev", "GET", "/api/leaderboard", "", "")
+	var ranks []leaderboardEntry
+	json.Unmarshal(rec.Body.Bytes(), &ranks)
+	if len(ranks) != 1 || ranks[0].User.ID != bob.ID {
+		t.Errorf("team namespace: got %+v, want only bob", ranks)
+	}
+	if store.leaderboardQueries != 2 {
+		t.Errorf("got %d queries, want one per namespace", store.leaderboardQueries)
+	}
+}
+
+func TestLeaderboardPeriods(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"recent"}`, token)
+	postChirp(t, h, `{"body":"older"}`, token)
+	store.chirps[1].CreatedAt.Time = time.Now().Add(-10 * 24 * time.Hour)
+
+	for query, want := range map[string]int64{"?period=7d": 1, "?period=30d": 2, "?period=all": 2} {
+		if ranks := getLeaderboard(t, h, query); len(ranks) != 1 || ranks[0].Score != want {
+			t.Errorf("%s: got %+v, want a score of %d", query, ranks, want)
+		}
+	}
+	if rec := serve(h, "GET", "/api/leaderboard?period=1y", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown period: got status %d, want 400", rec.Code)
+	}
+}
+
+func TestLeaderboardCache(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"first"}`, token)
+
+	getLeaderboard(t, h, "")
+	postChirp(t, h, `{"body":"second"}`, token)
+	if ranks := getLeaderboard(t, h, ""); len(ranks) != 1 || ranks[0].Score != 1 {
+		t.Errorf("within the TTL: got %+v, want the cached score of 1", ranks)
+	}
+	if store.leaderboardQueries != 1 {
+		t.Errorf("got %d queries within the TTL, want 1", store.leaderboardQueries)
+	}
+	// Each period is cached on its own.
+	getLeaderboard(t, h, "?period=all")
+	if store.leaderboardQueries != 2 {
+		t.Errorf("got %d queries after a second period, want 2", store.leaderboardQueries)
+	}
+
+	c := &cfg.leaderboardCache
+	c.mu.Lock()
+	for key, entry := range c.entries {
+		entry.expiresAt = time.Now()
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+	if ranks := getLeaderboard(t, h, ""); len(ranks) != 1 || ranks[0].Score != 2 {
+		t.Errorf("after the TTL: got %+v, want a fresh score of 2", ranks)
+	}
+	if store.leaderboardQueries != 3 {
+		t.Errorf("got %d queries after the TTL, want 3", store.leaderboardQueries)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var errMailerNotConfigured = errors.New("no mailer is configured")
+
+// Mailer delivers plain-text email.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NoopMailer is used outside dev until a mail provider is configured.
+type NoopMailer struct{}
+
+func (NoopMailer) Send(to, subject, body string) error {
+	return errMailerNotConfigured
+}
+
+// LogMailer prints each email to stdout instead of sending it, for local
+// development.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	fmt.Printf("To: %s\nSubject: %s\n\n%s\n", to, subject, body)
+	return nil
+}
+
+// sentMail is one email captured by MockMailer.
+type sentMail struct {
+	To, Subject, Body string
+}
+
+// MockMailer records emails instead of sending them, for tests.
+type MockMailer struct {
+	mu   sync.Mutex
+	Sent []sentMail
+}
+
+func (m *MockMailer) Send(to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Sent = append(m.Sent, sentMail{To: to, Subject: subject, Body: body})
+	return nil
+}
+
+func newMailer(platform string) Mailer {
+	if platform == "dev" {
+		return LogMailer{}
+	}
+	return NoopMailer{}
+}
@@ -1,29 +1,136 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
+	"net/netip"
 	"os"
+	"os/signal"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/azs06/Chirpy/internal/database"
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
-	db             *database.Queries
-	platform       string
-	tokenSecret    string
-	polkaKey       string
+	fileserverHits    atomic.Int32
+	db                database.Querier
+	platform          string
+	tokenSecret       string
+	polkaKey          string
+	chirpRetention    time.Duration
+	apiVersion        string
+	baseURL           string
+	trustedProxies    []netip.Prefix
+	adminAllowedCIDRs []netip.Prefix
+
+	leaderboardCache leaderboardCache
+
+	consecutiveDBErrors atomic.Int32
+	lastDBError         atomic.Int64
+
+	blockedDomains domainBlocklist
+	revokedTokens  revocationFilter
+	pendingViews   sync.Map
+	impressions    sync.WaitGroup
+	chirpReads     chan chirpRead
+	feedCache      sync.Map
+	healthHistory  *healthHistory
+	moderation     moderationClient
+	translator     Translator
+	embedder       Embedder
+	// embeddingModel is stored as the model_version of each embedding.
+	embeddingModel string
+	// usePgvector turns on similarity search; see handlerGetSimilarChirps.
+	usePgvector bool
+	mailer      Mailer
+	pushSender  PushSender
+	experiments *experimentRegistry
+	// identityProviders are the OAuth providers users can link accounts
+	// at; identityTokenKey encrypts the access tokens they hand out.
+	identityProviders map[string]IdentityProvider
+	identityTokenKey  []byte
+
+	maxFollowsPerUser   int
+	maxFollowersPerUser int
+
+	chirpsPerMinute int
+	// maxGetRetries is how many times a GET that failed with 503 is retried
+	// before the client sees it; see middlewareRetry.
+	maxGetRetries int
+	// maxReplyDepth is the deepest thread_depth a reply may have; zero
+	// means no limit.
+	maxReplyDepth  int32
+	userRateLimits sync.Map
+	// redis backs the rate limiters when REDIS_URL is set; see rateLimiter.
+	redis        *redis.Client
+	rateLimiters sync.Map
+
+	maxChirpsPerUser        int
+	maxChirpsPerPremiumUser int
+	chirpCounts             sync.Map
+
+	duplicateChirpCooldown time.Duration
+	recentBodiesMu         sync.Mutex
+	recentBodies           map[uuid.UUID]*chirpBodyRing
+	duplicateCooldowns     sync.Map
+
+	adminStats atomic.Pointer[adminStatsEntry]
+	jobs       *JobQueue
+	http2Push  bool
+	// exposeTiming adds a Server-Timing header to responses.
+	exposeTiming bool
+	webhooks     *webhookDispatcher
+
+	userCache sync.Map
+	// activityCharts caches activity charts by user ID.
+	activityCharts sync.Map
+	// namespaces caches namespace lookups by name; see lookupNamespace.
+	namespaces sync.Map
+	// sitemapCache holds each namespace's sitemap entries; see sitemapEntries.
+	sitemapCache sync.Map
+	// robotsOverride replaces the default robots.txt when set.
+	robotsOverride []byte
+	sfGroup        singleflight.Group
+
+	// cookieSigningKey signs the auth cookie; cookie login is off when it
+	// is empty.
+	cookieSigningKey []byte
+	// mediaSigningKey is MEDIA_SIGNING_KEY, which signs chirp media URLs.
+	mediaSigningKey []byte
+	// staticExtensions is ALLOWED_STATIC_EXTENSIONS, the file types /app/
+	// may serve; see safeFileServer.
+	staticExtensions string
+	// mediaAllowedOrigins lists the hosts chirp media may link to; nil
+	// allows any.
+	mediaAllowedOrigins []string
+	// cors is the CORS_ALLOWED_ORIGINS policy; CORS headers are off when
+	// it is empty.
+	cors corsPolicy
+	// shadowSampleRate is the fraction of requests that routes wrapped in
+	// shadowMiddleware mirror to a dark-launched replacement.
+	shadowSampleRate float64
+	// now stands in for time.Now in tests; see timeNow.
+	now func() time.Time
+	// shortCode stands in for randomShortCode in tests; see newShortCode.
+	shortCode func() (string, error)
 }
 
 type userResp struct {
@@ -34,14 +141,93 @@ type userResp struct {
 	Token        string    `json:"token"`
 	RefreshToken string    `json:"refresh_token"`
 	IsChirpyRed  bool      `json:"is_chirpy_red"`
+	IsVerified   bool      `json:"is_verified"`
+
+	FollowersCount int64 `json:"followers_count"`
+	FollowingCount int64 `json:"following_count"`
+	ChirpsCount    int64 `json:"chirps_count"`
+
+	// PendingEmail is the address an email change is waiting on, only
+	// shown to the user themselves.
+	PendingEmail string `json:"pending_email,omitempty"`
+	// Experiments is only filled in for GET /api/users/me.
+	Experiments map[string]string `json:"experiments,omitempty"`
+}
+
+func newUserResp(u database.User) userResp {
+	return userResp{
+		ID:          u.ID,
+		CreatedAt:   u.CreatedAt.Time,
+		UpdatedAt:   u.UpdatedAt.Time,
+		Email:       u.Email.String,
+		IsChirpyRed: u.IsChirpyRed,
+		IsVerified:  u.IsVerified,
+	}
 }
 
 type chirpResp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserId    string    `json:"user_id"`
+	ID         uuid.UUID   `json:"id"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Body       string      `json:"body"`
+	UserId     string      `json:"user_id"`
+	ParentId   *uuid.UUID  `json:"parent_id,omitempty"`
+	LikeCount  int64       `json:"like_count"`
+	ReplyCount int64       `json:"reply_count"`
+	IsNsfw     bool        `json:"is_nsfw"`
+	IsHidden   bool        `json:"is_hidden"`
+	Media      []mediaResp `json:"media,omitempty"`
+	// ContentWarning is the author's warning to show before the body.
+	ContentWarning string `json:"content_warning,omitempty"`
+	// AdminEdited is set once an admin has corrected the body.
+	AdminEdited bool `json:"admin_edited"`
+
+	WordCount          int32                    `json:"word_count"`
+	ReadingTimeSeconds int32                    `json:"reading_time_seconds"`
+	Visibility         database.ChirpVisibility `json:"visibility"`
+	SizeTier           string                   `json:"size_tier"`
+	Flagged            bool                     `json:"flagged,omitempty"`
+	ImpressionCount    int64                    `json:"impression_count"`
+	Sentiment          float64                  `json:"sentiment"`
+	// RenderedBody is Body as sanitized HTML; see wantsRenderedBody.
+	RenderedBody string `json:"rendered_body,omitempty"`
+	// Warnings are advisory notes on a chirp just posted, such as images
+	// without alt text. It is null everywhere else.
+	Warnings []string `json:"warnings"`
+	// SearchRank is how well the chirp matched ?q=, for search results only.
+	SearchRank *float64 `json:"search_rank,omitempty"`
+	// Similarity is the cosine similarity to the chirp asked about, for
+	// GET /api/chirps/{chirpId}/similar only.
+	Similarity *float64 `json:"similarity,omitempty"`
+	// ExpandedURLs are the short links Chirpy put in Body in place of long
+	// URLs.
+	ExpandedURLs []expandedURL `json:"expanded_urls,omitempty"`
+}
+
+func newChirpResp(c database.Chirp) chirpResp {
+	resp := chirpResp{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt.Time,
+		UpdatedAt: c.UpdatedAt.Time,
+		Body:      c.Body.String,
+		UserId:    c.UserID.String(),
+		IsNsfw:    c.IsNsfw,
+		IsHidden:  c.IsHidden,
+
+		ContentWarning: c.ContentWarning.String,
+		AdminEdited:    c.AdminEdited,
+
+		WordCount:          c.WordCount,
+		ReadingTimeSeconds: c.ReadingTimeSeconds,
+		Visibility:         c.Visibility,
+		SizeTier:           chirpSizeTier(c.Body.String),
+		ImpressionCount:    c.ImpressionCount,
+		Sentiment:          c.SentimentScore,
+	}
+	if c.ParentID.Valid {
+		resp.ParentId = &c.ParentID.UUID
+	}
+	return resp
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -53,6 +239,9 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 
 func (cfg *apiConfig) resetMetrics() {
 	cfg.fileserverHits.Store(0)
+	cfg.revokedTokens.hits.Store(0)
+	cfg.revokedTokens.misses.Store(0)
+	cfg.revokedTokens.falsePositives.Store(0)
 }
 
 func sanitize(s string) string {
@@ -72,33 +261,148 @@ func sanitize(s string) string {
 
 func newServer(p string, cfg *apiConfig) *http.Server {
 	mux := http.NewServeMux()
-	mux.Handle("/app/", http.StripPrefix("/app/", cfg.middlewareMetricsInc(http.FileServer(http.Dir("./")))))
+	mux.Handle("/app/", cfg.middlewareAppPush(http.StripPrefix("/app/", cfg.middlewareMetricsInc(safeFileServer("./", cfg.staticExtensions)))))
+	spec := newSpecBuilder("Chirpy", cfg.apiVersion)
+	route := func(pattern string, handler http.Handler, doc routeDoc) {
+		mux.Handle(pattern, handler)
+		spec.add(pattern, doc)
+	}
+	handle := func(pattern string, handler http.HandlerFunc, doc routeDoc) {
+		route(pattern, handler, doc)
+	}
+	handleAdmin := func(pattern string, handler http.HandlerFunc, doc routeDoc) {
+		route(pattern, cfg.middlewareAdminAllowlist(handler), doc)
+	}
+	handleAuthed := func(pattern string, handler http.HandlerFunc, doc routeDoc) {
+		route(pattern, cfg.requireAuth(handler), doc)
+	}
+	handleUserLimited := func(pattern string, handler http.HandlerFunc, doc routeDoc) {
+		route(pattern, cfg.requireAuth(cfg.middlewareUserRateLimit(handler)), doc)
+	}
 	mux.Handle("/assets/", http.StripPrefix("/assets/", http.FileServer(http.Dir("./assets"))))
-	mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, req *http.Request) {
+	handle("GET /api/healthz", func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
-	})
-	mux.HandleFunc("GET /admin/metrics", cfg.handlerMetrics)
-	mux.HandleFunc("POST /admin/reset", cfg.handlerReset)
+	}, routeDoc{Summary: "Liveness check", Produces: "text/plain"})
+	mux.HandleFunc("GET /share/chirps/{chirpId}", cfg.handlerShareChirp)
+	mux.HandleFunc("GET /s/{code}", cfg.handlerFollowShortLink)
+	mux.HandleFunc("GET /sitemap.xml", cfg.handlerSitemap)
+	mux.HandleFunc("GET /robots.txt", cfg.handlerRobots)
+	mux.HandleFunc("GET /pixel/chirps/{chirpId}", cfg.handlerChirpPixel)
+	handleAdmin("GET /admin/metrics", cfg.handlerMetrics, routeDoc{Summary: "Fileserver hit count", Produces: "text/html"})
+	handleAdmin("GET /admin/health-history", cfg.handlerHealthHistory, routeDoc{Summary: "Recent health checks", Response: []healthRecord{}})
+	handleAdmin("GET /admin/stats", cfg.handlerAdminStats, routeDoc{Summary: "Site-wide counts", Response: adminStatsResp{}, Auth: true})
+	handleAdmin("POST /admin/import/chirps", cfg.handlerImportChirps, routeDoc{Summary: "Bulk import chirps from NDJSON (dev only)", Response: importChirpsResp{}})
+	handleAdmin("POST /admin/reset", cfg.handlerReset, routeDoc{Summary: "Delete all users (dev only)", Produces: "text/plain"})
+	handleAdmin("GET /admin/audit-log", cfg.handlerGetAuditLog, routeDoc{Summary: "Audit log", Response: []auditLogResp{}, Auth: true})
+	handleAdmin("GET /admin/chirps/archive", cfg.handlerGetArchivedChirps, routeDoc{Summary: "Archived chirps", Response: []archivedChirpResp{}})
+	handleAdmin("GET /admin/export/chirps", cfg.handlerExportChirps, routeDoc{Summary: "Export chirps as CSV", Produces: "text/csv", Auth: true})
+	handleAdmin("POST /admin/namespaces", cfg.handlerCreateNamespace, routeDoc{Summary: "Create a namespace", Request: createNamespaceParams{}, Response: namespaceResp{}, Status: http.StatusCreated, Auth: true})
+	handleAdmin("POST /admin/oauth-clients", cfg.handlerCreateOAuthClient, routeDoc{Summary: "Register an OAuth client", Request: createOAuthClientParams{}, Response: oauthClientResp{}, Status: http.StatusCreated, Auth: true})
+	handleAdmin("POST /admin/signing-keys", cfg.handlerCreateSigningKey, routeDoc{Summary: "Register a request signing key", Request: createSigningKeyParams{}, Response: signingKeyResp{}, Status: http.StatusCreated, Auth: true})
+	handleAdmin("POST /admin/webhooks", cfg.handlerCreateWebhook, routeDoc{Summary: "Register a webhook", Request: createWebhookParams{}, Response: webhookResp{}, Status: http.StatusCreated, Auth: true})
+	handleAdmin("GET /admin/webhooks/{webhookId}/deliveries", cfg.handlerGetWebhookDeliveries, routeDoc{Summary: "Webhook delivery attempts", Response: []webhookDeliveryResp{}, Auth: true})
+	handleAdmin("GET /admin/experiments", cfg.handlerGetExperiments, routeDoc{Summary: "Experiments and their assignments", Response: []experimentResp{}, Auth: true})
+	handleAdmin("GET /admin/flagged-chirps", cfg.handlerGetFlaggedChirps, routeDoc{Summary: "Chirps flagged by moderation", Response: []flaggedChirpResp{}, Auth: true})
+	handleAdmin("POST /admin/chirps/{chirpId}/hide", cfg.handlerHideChirp, routeDoc{Summary: "Hide a chirp", Response: chirpResp{}, Auth: true})
+	handleAdmin("DELETE /admin/chirps/{chirpId}/hide", cfg.handlerUnhideChirp, routeDoc{Summary: "Unhide a chirp", Response: chirpResp{}, Auth: true})
+	handleAdmin("POST /admin/blocked-domains", cfg.handlerAddBlockedDomain, routeDoc{Summary: "Block an email domain", Request: blockedDomainParams{}, Auth: true})
+	handleAdmin("DELETE /admin/blocked-domains/{domain}", cfg.handlerDeleteBlockedDomain, routeDoc{Summary: "Unblock an email domain", Auth: true})
+	handleAdmin("POST /admin/users/{userId}/verify", cfg.handlerVerifyUser, routeDoc{Summary: "Verify a user (dev only)", Response: userResp{}})
+	handleAdmin("DELETE /admin/users/{userId}/verify", cfg.handlerUnverifyUser, routeDoc{Summary: "Unverify a user (dev only)", Response: userResp{}})
 
-	mux.HandleFunc("POST /api/chirps", cfg.handlerCreateChirp)
-	mux.HandleFunc("GET /api/chirps", cfg.handlerGetChirps)
-	mux.HandleFunc("GET /api/chirps/{chirpId}", cfg.handlerGetChirpByID)
-	mux.HandleFunc("DELETE /api/chirps/{chirpId}", cfg.handlerDeleteChirp)
+	handleUserLimited("POST /api/chirps", cfg.handlerCreateChirp, routeDoc{Summary: "Post a chirp", Request: createChirpParams{}, Response: chirpResp{}, Status: http.StatusCreated, Auth: true})
+	handle("GET /api/chirps", cfg.handlerGetChirps, routeDoc{Summary: "List chirps", Response: []chirpResp{}})
+	handle("GET /api/chirps/{chirpId}", cfg.handlerGetChirpByID, routeDoc{Summary: "Get a chirp", Response: chirpResp{}})
+	handle("GET /api/chirps/{chirpId}/thread", cfg.handlerGetChirpThread, routeDoc{Summary: "A chirp with its ancestors and replies", Response: threadResp{}})
+	handle("GET /api/chirps/{chirpId}/context", cfg.handlerGetChirpContext, routeDoc{Summary: "A chirp with its author's neighbouring chirps", Response: chirpContextResp{}})
+	handle("GET /api/chirps/{chirpId}/stats", cfg.handlerGetChirpStats, routeDoc{Summary: "Chirp engagement stats", Response: chirpStatsResp{}})
+	handleAuthed("POST /api/chirps/{chirpId}/translate", cfg.handlerTranslateChirp, routeDoc{Summary: "Translate a chirp", Request: translateChirpParams{}, Response: translationResp{}, Auth: true})
+	handleAuthed("DELETE /api/chirps", cfg.handlerDeleteChirps, routeDoc{Summary: "Delete several of your chirps", Request: deleteChirpsParams{}, Response: deleteChirpsResp{}, Auth: true})
+	handleAuthed("DELETE /api/chirps/{chirpId}", cfg.handlerDeleteChirp, routeDoc{Summary: "Delete a chirp", Auth: true})
+	handleAuthed("POST /api/chirps/{chirpId}/restore", cfg.handlerRestoreChirp, routeDoc{Summary: "Restore a deleted chirp", Response: chirpResp{}, Auth: true})
+	handle("POST /api/chirps/{chirpId}/embed", cfg.handlerEmbedChirp, routeDoc{Summary: "Compute a chirp's embedding (admin only)", Response: chirpEmbeddingResp{}, Auth: true})
+	handle("GET /api/chirps/{chirpId}/similar", cfg.handlerGetSimilarChirps, routeDoc{Summary: "Chirps similar to this one", Response: []chirpResp{}})
+	handle("GET /api/chirps/{chirpId}/embed", cfg.handlerGetChirpEmbed, routeDoc{Summary: "Embeddable HTML for a chirp", Produces: "text/html"})
+	handle("PATCH /api/chirps/{chirpId}", cfg.handlerAdminEditChirp, routeDoc{Summary: "Correct a chirp's body (admin only)", Request: adminEditChirpParams{}, Response: chirpResp{}, Auth: true})
+	handleAuthed("POST /api/chirps/{chirpId}/flag", cfg.handlerSelfFlagChirp, routeDoc{Summary: "Flag your own chirp as sensitive", Request: selfFlagParams{}, Response: chirpResp{}, Auth: true})
+	handleAuthed("POST /api/chirps/{chirpId}/share", cfg.handlerCreateShortLink, routeDoc{Summary: "Create a short link to a chirp", Response: shortLinkResp{}, Status: http.StatusCreated, Auth: true})
+	handleAuthed("POST /api/chirps/{chirpId}/mark-read", cfg.handlerMarkChirpRead, routeDoc{Summary: "Mark a chirp read", Auth: true})
+	handleUserLimited("POST /api/chirps/{chirpId}/like", cfg.handlerLikeChirp, routeDoc{Summary: "Like a chirp", Auth: true})
+	handleAuthed("DELETE /api/chirps/{chirpId}/like", cfg.handlerUnlikeChirp, routeDoc{Summary: "Unlike a chirp", Auth: true})
 
-	mux.HandleFunc("POST /api/users", cfg.handlerCreateUser)
-	mux.HandleFunc("PUT /api/users", cfg.handlerUpdateUser)
+	handle("POST /api/users", cfg.handlerCreateUser, routeDoc{Summary: "Sign up", Request: createUserParams{}, Response: userResp{}, Status: http.StatusCreated})
+	handleAuthed("PUT /api/users", cfg.handlerUpdateUser, routeDoc{Summary: "Update your email and password", Request: updateUserParams{}, Response: userResp{}, Auth: true})
+	handleAuthed("GET /api/users/me", cfg.handlerGetMe, routeDoc{Summary: "Your profile", Response: userResp{}, Auth: true})
+	handleAuthed("GET /api/users/me/identities", cfg.handlerGetIdentities, routeDoc{Summary: "Your linked accounts", Response: []identityResp{}, Auth: true})
+	handleAuthed("POST /api/users/me/link/{provider}", cfg.handlerStartLink, routeDoc{Summary: "Start linking an account at an OAuth provider", Response: linkStartResp{}, Auth: true})
+	handleAuthed("DELETE /api/users/me/link/{provider}", cfg.handlerUnlink, routeDoc{Summary: "Unlink an OAuth provider account", Status: http.StatusNoContent, Auth: true})
+	handle("GET /api/auth/link/{provider}/callback", cfg.handlerLinkCallback, routeDoc{Summary: "Finish linking an OAuth provider account", Response: identityResp{}, Status: http.StatusCreated})
+	handleAuthed("GET /api/users/me/activity", cfg.handlerGetMyActivity, routeDoc{Summary: "Your activity summary", Response: activityResp{}, Auth: true})
+	handleAuthed("GET /api/users/me/deleted-chirps", cfg.handlerGetDeletedChirps, routeDoc{Summary: "Your recycle bin", Response: []deletedChirpResp{}, Auth: true})
+	handleAuthed("POST /api/users/me/push-tokens", cfg.handlerRegisterPushToken, routeDoc{Summary: "Register a device for push notifications", Request: registerPushTokenParams{}, Response: pushTokenResp{}, Status: http.StatusCreated, Auth: true})
+	handleAuthed("PUT /api/users/me/email-notifications", cfg.handlerSetEmailNotifications, routeDoc{Summary: "Turn digest emails on or off", Request: setEmailNotificationsParams{}, Response: notificationSettingsResp{}, Auth: true})
+	handleAuthed("PUT /api/users/me/email-mfa", cfg.handlerSetEmailMFA, routeDoc{Summary: "Turn email OTP on or off", Request: setEmailMFAParams{}, Response: mfaSettingsResp{}, Auth: true})
+	handleAuthed("GET /api/users/me/unread-chirps", cfg.handlerGetUnreadChirps, routeDoc{Summary: "Unread chirps from people you follow", Response: unreadChirpsResp{}, Auth: true})
+	route("POST /api/users/lookup", cfg.requireAuth(cfg.middlewareFixedUserRateLimit(userLookupsPerMinute, http.HandlerFunc(cfg.handlerLookupUsers))), routeDoc{Summary: "Look up several users by ID", Request: userLookupParams{}, Response: map[string]userResp{}, Auth: true})
+	handle("GET /api/users/{userId}", cfg.handlerGetUser, routeDoc{Summary: "Get a user", Response: userResp{}})
+	handleUserLimited("POST /api/users/{userId}/follow", cfg.handlerFollowUser, routeDoc{Summary: "Follow a user", Auth: true})
+	handleAuthed("DELETE /api/users/{userId}/follow", cfg.handlerUnfollowUser, routeDoc{Summary: "Unfollow a user", Auth: true})
+	handle("GET /api/users/{userId}/followers", cfg.handlerGetFollowers, routeDoc{Summary: "A user's followers", Response: followUsersResp{}})
+	handle("GET /api/users/{userId}/followers/mutual", cfg.handlerGetMutualFollows, routeDoc{Summary: "Followers a user follows back", Response: mutualFollowsResp{}})
+	handle("GET /api/users/{userId}/following", cfg.handlerGetFollowing, routeDoc{Summary: "Users a user follows", Response: followUsersResp{}})
+	handleAuthed("GET /api/users/{userId}/relationship", cfg.handlerGetRelationship, routeDoc{Summary: "How you and a user follow each other", Response: Relationship{}, Auth: true})
+	handle("GET /api/users/{userId}/activity-chart", cfg.handlerGetActivityChart, routeDoc{Summary: "Hourly chirp counts for the last 7 days", Response: []activityChartPoint{}})
+	handle("GET /api/users/{userId}/chirps", cfg.handlerGetUserChirps, routeDoc{Summary: "A user's chirps", Response: userChirpsResp{}})
+	handle("GET /api/users/{userId}/lists", cfg.handlerGetUserLists, routeDoc{Summary: "A user's lists", Response: []listResp{}})
+	handle("GET /api/leaderboard", cfg.handlerGetLeaderboard, routeDoc{Summary: "Top users by engagement", Response: []leaderboardEntry{}})
+
+	handle("POST /api/login", cfg.handlerLogin, routeDoc{Summary: "Log in", Request: loginParams{}, Response: userResp{}})
+	handle("POST /api/auth/email-otp", cfg.handlerEmailOTP, routeDoc{Summary: "Complete a login with an email OTP", Request: emailOTPParams{}, Response: userResp{}})
+	if len(cfg.cookieSigningKey) > 0 {
+		handle("POST /api/auth/cookie-login", cfg.handlerCookieLogin, routeDoc{Summary: "Log in with a session cookie", Request: loginParams{}, Response: userResp{}})
+		handle("POST /api/auth/cookie-logout", cfg.handlerCookieLogout, routeDoc{Summary: "Clear the session cookie"})
+	}
+	handle("POST /api/refresh", cfg.handlerRefresh, routeDoc{Summary: "Exchange a refresh token for an access token", Response: refreshResp{}, Auth: true})
+	handle("POST /api/revoke", cfg.handlerRevoke, routeDoc{Summary: "Revoke a refresh token", Auth: true})
+	handle("GET /api/auth/authorize", cfg.handlerAuthorize, routeDoc{Summary: "OAuth authorization page", Produces: "text/html"})
+	handle("POST /api/auth/authorize", cfg.handlerAuthorizeDecision, routeDoc{Summary: "Approve or deny an OAuth client", Status: http.StatusSeeOther, Auth: true})
+	handle("POST /api/auth/token-exchange", cfg.handlerTokenExchange, routeDoc{Summary: "Exchange an OAuth authorization code for tokens", Response: oauthTokenResp{}})
+	handle("POST /api/auth/confirm-email-change", cfg.handlerConfirmEmailChange, routeDoc{Summary: "Confirm a new email address", Request: confirmEmailChangeParams{}, Response: userResp{}})
+	handleAuthed("POST /api/auth/introspect", cfg.handlerIntrospect, routeDoc{Summary: "Introspect an access token", Request: introspectParams{}, Response: introspectResp{}, Auth: true})
 
-	mux.HandleFunc("POST /api/login", cfg.handlerLogin)
-	mux.HandleFunc("POST /api/refresh", cfg.handlerRefresh)
-	mux.HandleFunc("POST /api/revoke", cfg.handlerRevoke)
+	handleAuthed("POST /api/lists", cfg.handlerCreateList, routeDoc{Summary: "Create a list", Request: createListParams{}, Response: listResp{}, Status: http.StatusCreated, Auth: true})
+	handleAuthed("POST /api/lists/{listId}/members", cfg.handlerAddListMember, routeDoc{Summary: "Add a list member", Request: addListMemberParams{}, Auth: true})
+	handleAuthed("DELETE /api/lists/{listId}/members/{userId}", cfg.handlerRemoveListMember, routeDoc{Summary: "Remove a list member", Auth: true})
+	handle("GET /api/lists/{listId}/feed", cfg.handlerGetListFeed, routeDoc{Summary: "Chirps by a list's members", Response: listFeedResp{}})
 
-	mux.HandleFunc("POST /api/polka/webhooks", cfg.handlerWebhook)
+	handleAuthed("POST /api/topics/{topic}/subscribe", cfg.handlerSubscribeTopic, routeDoc{Summary: "Subscribe to a topic", Auth: true})
+	handleAuthed("DELETE /api/topics/{topic}/subscribe", cfg.handlerUnsubscribeTopic, routeDoc{Summary: "Unsubscribe from a topic", Auth: true})
+	handleAuthed("GET /api/feed", cfg.handlerGetHomeFeed, routeDoc{Summary: "Your home feed", Response: homeFeedResp{}, Auth: true})
+	handleAuthed("GET /api/feed/topics", cfg.handlerGetTopicFeed, routeDoc{Summary: "Chirps in your subscribed topics", Response: topicFeedResp{}, Auth: true})
+
+	handleAuthed("GET /api/notifications", cfg.handlerGetNotifications, routeDoc{Summary: "Your notifications", Response: notificationsResp{}, Auth: true})
+	handleAuthed("POST /api/notifications/read-all", cfg.handlerReadAllNotifications, routeDoc{Summary: "Mark all notifications read", Auth: true})
+
+	handle("POST /api/polka/webhooks", cfg.handlerWebhook, routeDoc{Summary: "Polka payment events", Request: polkaWebhookParams{}})
+	handle("GET /api/media/verify", cfg.handlerVerifyMedia, routeDoc{Summary: "Check a signed media URL", Status: http.StatusOK})
+
+	if cfg.platform == "dev" {
+		handle("GET /api/debug/echo", cfg.handlerDebugEcho, routeDoc{Summary: "Echo the request's headers (dev only)", Response: debugEchoResp{}})
+	}
+
+	var openAPISpec []byte
+	handle("GET /api/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(openAPISpec)
+	}, routeDoc{Summary: "This OpenAPI document", Response: map[string]any{}})
+	openAPISpec = spec.Build()
 
 	return &http.Server{
 		Addr:    ":" + p,
-		Handler: mux,
+		Handler: middlewareRequestID(cfg.middlewareServerTiming(middlewareClientIP(middlewareDevice(middlewareAccessLog(cfg.middlewareCORS(cfg.middlewareAPIVersion(cfg.middlewareDBErrors(cfg.middlewareNamespace(cfg.middlewareSignatureAuth(cfg.middlewareCookieAuth(cfg.middlewareBodyLog(middlewareRetry(cfg.maxGetRetries, []int{http.StatusServiceUnavailable}, middlewareMediaType(middlewareMuxErrors(mux))))))))))))))),
 	}
 }
 
@@ -112,6 +416,10 @@ func main() {
 	if !ok {
 		log.Fatal("Token not set")
 	}
+	mediaSigningKey := os.Getenv("MEDIA_SIGNING_KEY")
+	if mediaSigningKey == "" {
+		log.Fatal("MEDIA_SIGNING_KEY not set")
+	}
 	dbURL, ok := os.LookupEnv("DB_URL")
 
 	if !ok {
@@ -119,21 +427,307 @@ func main() {
 	}
 
 	port := "8080"
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = defaultGRPCPort
+	}
 	db, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatal(err)
 	}
+	schema, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := runMigrations(db, schema); err != nil {
+		log.Fatal(err)
+	}
 	polkaKey := os.Getenv("POLKA_KEY")
+	retentionDays := 365
+	if v, ok := os.LookupEnv("CHIRP_RETENTION_DAYS"); ok {
+		retentionDays, err = strconv.Atoi(v)
+		if err != nil || retentionDays < 0 {
+			log.Fatal("CHIRP_RETENTION_DAYS must be a non-negative integer")
+		}
+	}
+	apiVersion := os.Getenv("API_VERSION")
+	if apiVersion == "" {
+		apiVersion = "1.0"
+	}
+	if !slices.Contains(supportedAPIVersions, apiVersion) {
+		log.Fatalf("API_VERSION %q is not supported", apiVersion)
+	}
+	trustedProxies, err := parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	healthHistorySize := defaultHealthHistorySize
+	if v, ok := os.LookupEnv("HEALTH_HISTORY_SIZE"); ok {
+		healthHistorySize, err = strconv.Atoi(v)
+		if err != nil || healthHistorySize < 1 {
+			log.Fatal("HEALTH_HISTORY_SIZE must be a positive integer")
+		}
+	}
+	maxFollows := defaultMaxFollowsPerUser
+	if v, ok := os.LookupEnv("MAX_FOLLOWS_PER_USER"); ok {
+		maxFollows, err = strconv.Atoi(v)
+		if err != nil || maxFollows < 0 {
+			log.Fatal("MAX_FOLLOWS_PER_USER must be a non-negative integer")
+		}
+	}
+	maxFollowers := 0
+	if v, ok := os.LookupEnv("MAX_FOLLOWERS_PER_USER"); ok {
+		maxFollowers, err = strconv.Atoi(v)
+		if err != nil || maxFollowers < 0 {
+			log.Fatal("MAX_FOLLOWERS_PER_USER must be a non-negative integer")
+		}
+	}
+	chirpsPerMinute := defaultChirpsPerMinute
+	if v, ok := os.LookupEnv("CHIRPS_PER_MINUTE"); ok {
+		chirpsPerMinute, err = strconv.Atoi(v)
+		if err != nil || chirpsPerMinute < 0 {
+			log.Fatal("CHIRPS_PER_MINUTE must be a non-negative integer")
+		}
+	}
+	maxGetRetries := defaultMaxRetries
+	if v, ok := os.LookupEnv("GET_RETRIES"); ok {
+		maxGetRetries, err = strconv.Atoi(v)
+		if err != nil || maxGetRetries < 0 {
+			log.Fatal("GET_RETRIES must be a non-negative integer")
+		}
+	}
+	maxReplyDepth := defaultMaxReplyDepth
+	if v, ok := os.LookupEnv("MAX_THREAD_DEPTH"); ok {
+		maxReplyDepth, err = strconv.Atoi(v)
+		if err != nil || maxReplyDepth < 0 {
+			log.Fatal("MAX_THREAD_DEPTH must be a non-negative integer")
+		}
+	}
+	maxChirps := defaultMaxChirpsPerUser
+	if v, ok := os.LookupEnv("MAX_CHIRPS_PER_USER"); ok {
+		maxChirps, err = strconv.Atoi(v)
+		if err != nil || maxChirps < 0 {
+			log.Fatal("MAX_CHIRPS_PER_USER must be a non-negative integer")
+		}
+	}
+	maxPremiumChirps := defaultMaxChirpsPerPremiumUser
+	if v, ok := os.LookupEnv("MAX_CHIRPS_PER_PREMIUM_USER"); ok {
+		maxPremiumChirps, err = strconv.Atoi(v)
+		if err != nil || maxPremiumChirps < 0 {
+			log.Fatal("MAX_CHIRPS_PER_PREMIUM_USER must be a non-negative integer")
+		}
+	}
+	duplicateCooldown := defaultDuplicateChirpCooldown
+	if v, ok := os.LookupEnv("DUPLICATE_CHIRP_COOLDOWN"); ok {
+		duplicateCooldown, err = time.ParseDuration(v)
+		if err != nil || duplicateCooldown < 0 {
+			log.Fatal("DUPLICATE_CHIRP_COOLDOWN must be a non-negative duration")
+		}
+	}
+	webhookWorkers := defaultWebhookWorkers
+	if v, ok := os.LookupEnv("WEBHOOK_WORKERS"); ok {
+		webhookWorkers, err = strconv.Atoi(v)
+		if err != nil || webhookWorkers < 1 {
+			log.Fatal("WEBHOOK_WORKERS must be a positive integer")
+		}
+	}
+	webhookTimeout := defaultWebhookDeliveryTimeout
+	if v, ok := os.LookupEnv("WEBHOOK_DELIVERY_TIMEOUT"); ok {
+		webhookTimeout, err = time.ParseDuration(v)
+		if err != nil || webhookTimeout <= 0 {
+			log.Fatal("WEBHOOK_DELIVERY_TIMEOUT must be a positive duration")
+		}
+	}
+	http2Push := true
+	if v, ok := os.LookupEnv("ENABLE_HTTP2_PUSH"); ok {
+		http2Push, err = strconv.ParseBool(v)
+		if err != nil {
+			log.Fatal("ENABLE_HTTP2_PUSH must be true or false")
+		}
+	}
+	exposeTiming := false
+	if v, ok := os.LookupEnv("EXPOSE_TIMING"); ok {
+		exposeTiming, err = strconv.ParseBool(v)
+		if err != nil {
+			log.Fatal("EXPOSE_TIMING must be true or false")
+		}
+	}
+	shadowSampleRate := 0.0
+	if v, ok := os.LookupEnv("SHADOW_SAMPLE_RATE"); ok {
+		shadowSampleRate, err = strconv.ParseFloat(v, 64)
+		if err != nil || shadowSampleRate < 0 || shadowSampleRate > 1 {
+			log.Fatal("SHADOW_SAMPLE_RATE must be between 0 and 1")
+		}
+	}
+	var digestSchedule *cronSchedule
+	if v := os.Getenv("DIGEST_CRON"); v != "" {
+		digestSchedule, err = parseCron(v)
+		if err != nil {
+			log.Fatalf("DIGEST_CRON: %s", err)
+		}
+	}
+	digestLookback := defaultDigestLookback
+	if v, ok := os.LookupEnv("DIGEST_LOOKBACK_HOURS"); ok {
+		hours, err := strconv.Atoi(v)
+		if err != nil || hours < 1 {
+			log.Fatal("DIGEST_LOOKBACK_HOURS must be a positive integer")
+		}
+		digestLookback = time.Duration(hours) * time.Hour
+	}
+	var robotsOverride []byte
+	if path := os.Getenv("ROBOTS_OVERRIDE_PATH"); path != "" {
+		robotsOverride, err = os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("reading ROBOTS_OVERRIDE_PATH: %s", err)
+		}
+	}
+	var redisClient *redis.Client
+	if v := os.Getenv("REDIS_URL"); v != "" {
+		opts, err := redis.ParseURL(v)
+		if err != nil {
+			log.Fatalf("REDIS_URL: %s", err)
+		}
+		redisClient = redis.NewClient(opts)
+	}
+	embedder, embeddingModel := newEmbedder(os.Getenv("EMBEDDING_PROVIDER"))
+	identityProviders := newIdentityProviders(os.Getenv)
+	identityTokenKey, err := hex.DecodeString(os.Getenv("IDENTITY_TOKEN_KEY"))
+	if len(identityProviders) > 0 && (err != nil || len(identityTokenKey) != 32) {
+		log.Fatal("IDENTITY_TOKEN_KEY must be 64 hex characters when an identity provider is configured")
+	}
+	adminAllowedCIDR, ok := os.LookupEnv("ADMIN_ALLOWED_CIDR")
+	if !ok {
+		adminAllowedCIDR = defaultAdminAllowedCIDR
+	}
+	adminAllowedCIDRs, err := parseAdminAllowedCIDR(adminAllowedCIDR)
+	if err != nil {
+		log.Fatal(err)
+	}
 	cfg := &apiConfig{
-		platform:    platform,
-		db:          database.New(db),
-		tokenSecret: tokenSecret,
-		polkaKey:    polkaKey,
+		platform:       platform,
+		db:             database.New(timedDB{db}),
+		tokenSecret:    tokenSecret,
+		polkaKey:       polkaKey,
+		chirpRetention: time.Duration(retentionDays) * 24 * time.Hour,
+		apiVersion:     apiVersion,
+		baseURL:        strings.TrimSuffix(os.Getenv("BASE_URL"), "/"),
+		trustedProxies: trustedProxies,
+
+		adminAllowedCIDRs: adminAllowedCIDRs,
+		healthHistory:     newHealthHistory(healthHistorySize),
+		translator:        newTranslator(os.Getenv("TRANSLATION_PROVIDER")),
+		embedder:          embedder,
+		embeddingModel:    embeddingModel,
+		usePgvector:       os.Getenv("USE_PGVECTOR") == "true",
+		mailer:            newMailer(platform),
+		pushSender:        newPushSender(os.Getenv("FIREBASE_SERVER_KEY")),
+		experiments:       newExperimentRegistry(os.Getenv("EXPERIMENTS_PATH")),
+		identityProviders: identityProviders,
+		identityTokenKey:  identityTokenKey,
+
+		maxFollowsPerUser:       maxFollows,
+		maxFollowersPerUser:     maxFollowers,
+		chirpsPerMinute:         chirpsPerMinute,
+		maxGetRetries:           maxGetRetries,
+		maxReplyDepth:           int32(maxReplyDepth),
+		redis:                   redisClient,
+		maxChirpsPerUser:        maxChirps,
+		maxChirpsPerPremiumUser: maxPremiumChirps,
+		duplicateChirpCooldown:  duplicateCooldown,
+		http2Push:               http2Push,
+		exposeTiming:            exposeTiming,
+		cookieSigningKey:        []byte(os.Getenv("COOKIE_SIGNING_KEY")),
+		mediaSigningKey:         []byte(mediaSigningKey),
+		staticExtensions:        os.Getenv("ALLOWED_STATIC_EXTENSIONS"),
+		mediaAllowedOrigins:     parseMediaAllowedOrigins(os.Getenv("MEDIA_ALLOWED_ORIGINS")),
+		cors:                    parseCORSOrigins(os.Getenv("CORS_ALLOWED_ORIGINS")),
+		shadowSampleRate:        shadowSampleRate,
+		robotsOverride:          robotsOverride,
+		chirpReads:              make(chan chirpRead, chirpReadBufferSize),
+	}
+	if err := cfg.experiments.load(); err != nil {
+		log.Fatalf("loading experiments: %s", err)
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go cfg.experiments.runExperimentReloader(context.Background(), hup)
+	if err := cfg.loadRevokedTokens(context.Background()); err != nil {
+		log.Fatalf("loading revoked tokens: %s", err)
+	}
+	cfg.webhooks = newWebhookDispatcher(cfg.db, webhookWorkers, webhookTimeout)
+	cfg.jobs = newJobQueue(cfg.db, time.Now)
+	cfg.registerJobHandlers()
+	if url := os.Getenv("MODERATION_WEBHOOK_URL"); url != "" {
+		cfg.moderation = newHTTPModerationClient(url, os.Getenv("MODERATION_WEBHOOK_SECRET"))
 	}
+	if retentionDays > 0 {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		go cfg.runChirpArchiver(context.Background(), ticker.C)
+	}
+	purgeTicker := time.NewTicker(chirpPurgeInterval)
+	defer purgeTicker.Stop()
+	go cfg.runDeletedChirpPurger(context.Background(), purgeTicker.C)
+	viewTicker := time.NewTicker(chirpViewFlushInterval)
+	defer viewTicker.Stop()
+	go cfg.runChirpViewFlusher(context.Background(), viewTicker.C)
+	go cfg.runChirpReadRecorder(context.Background(), cfg.chirpReads)
+	fingerprintTicker := time.NewTicker(fingerprintRetention)
+	defer fingerprintTicker.Stop()
+	go cfg.runFingerprintPurger(context.Background(), fingerprintTicker.C)
+	go cfg.webhooks.run(context.Background())
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		cfg.jobs.runWorkers(jobsCtx, jobWorkers)
+		close(jobsDone)
+	}()
+	go cfg.jobs.listen(jobsCtx, dbURL)
+	webhookRetryTicker := time.NewTicker(webhookRetryInterval)
+	defer webhookRetryTicker.Stop()
+	go cfg.webhooks.runRetries(context.Background(), webhookRetryTicker.C)
+	if digestSchedule != nil {
+		go cfg.runDigestJob(context.Background(), digestSchedule, digestLookback)
+	}
+	importanceTicker := time.NewTicker(importanceScoreInterval)
+	defer importanceTicker.Stop()
+	go cfg.runImportanceScorer(context.Background(), importanceTicker.C)
+	healthTicker := time.NewTicker(healthCheckInterval)
+	defer healthTicker.Stop()
+	go cfg.runHealthCollector(context.Background(), healthTicker.C)
 	fmt.Println("Starting Server on port " + port)
 	s := newServer(port, cfg)
-	err = s.ListenAndServe()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	grpcListener, err := net.Listen("tcp", ":"+grpcPort)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Println("Starting gRPC server on port " + grpcPort)
+	grpcServer := newGRPCServer(cfg)
+	serveErr := make(chan error, 2)
+	go func() { serveErr <- s.ListenAndServe() }()
+	go func() { serveErr <- grpcServer.Serve(grpcListener) }()
+	select {
+	case err := <-serveErr:
+		log.Fatal(err)
+	case <-ctx.Done():
+	}
+	fmt.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), eventDrainTimeout)
+	defer cancel()
+	if err := s.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %s", err)
+	}
+	grpcServer.GracefulStop()
+	// Handlers have returned, so nothing more will be queued. Workers
+	// finish the job in hand; anything left waits for the next start.
+	stopJobs()
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), eventDrainTimeout)
+	defer cancelDrain()
+	select {
+	case <-jobsDone:
+	case <-drainCtx.Done():
+		log.Printf("Error draining jobs: %s", drainCtx.Err())
+	}
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// markdownRenderer is CommonMark without extensions. Raw HTML in the body
+// is dropped by goldmark and anything else unsafe by sanitizeHTML.
+var markdownRenderer = goldmark.New()
+
+// allowedTags are the only elements that survive sanitizeHTML.
+var allowedTags = map[atom.Atom]bool{atom.Strong: true, atom.Em: true, atom.Code: true, atom.A: true}
+
+// safeLinkSchemes are the href schemes kept on links.
+var safeLinkSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// wantsRenderedBody reports whether r asked for rendered_body, with
+// render=html or an Accept header listing text/html.
+func wantsRenderedBody(r *http.Request) bool {
+	return r.URL.Query().Get("render") == "html" || acceptsMediaType(r, "text/html")
+}
+
+// renderBodies fills in RenderedBody on each chirp.
+func renderBodies(chirps []chirpResp) {
+	for i := range chirps {
+		chirps[i].RenderedBody = renderMarkdown(chirps[i].Body)
+	}
+}
+
+// renderMarkdown renders a chirp body as CommonMark and sanitizes the
+// result for display.
+func renderMarkdown(body string) string {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(body), &buf); err != nil {
+		return html.EscapeString(body)
+	}
+	return sanitizeHTML(buf.String())
+}
+
+// sanitizeHTML keeps only <strong>, <em>, <code> and <a href> with an
+// http, https or mailto URL, dropping every other tag and attribute but
+// keeping their text. The contents of <script> and <style> are dropped
+// too. Links that lose their href are unwrapped.
+func sanitizeHTML(s string) string {
+	var out strings.Builder
+	z := html.NewTokenizer(strings.NewReader(s))
+	skip := 0
+	// openLinks records, for each <a> still open, whether it was kept, so
+	// its end tag is only written for links that were.
+	var openLinks []bool
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// Close anything left open so the fragment stays balanced.
+			for i := len(openLinks) - 1; i >= 0; i-- {
+				if openLinks[i] {
+					out.WriteString("</a>")
+				}
+			}
+			return strings.TrimSpace(out.String())
+		case html.TextToken:
+			if skip == 0 {
+				out.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.DataAtom == atom.Script || tok.DataAtom == atom.Style {
+				skip++
+				continue
+			}
+			if skip > 0 || !allowedTags[tok.DataAtom] {
+				continue
+			}
+			if tok.DataAtom == atom.A {
+				href, ok := safeHref(tok)
+				openLinks = append(openLinks, ok)
+				if ok {
+					out.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener">`)
+				}
+				continue
+			}
+			out.WriteString("<" + tok.Data + ">")
+		case html.EndTagToken:
+			tok := z.Token()
+			if tok.DataAtom == atom.Script || tok.DataAtom == atom.Style {
+				skip = max(skip-1, 0)
+				continue
+			}
+			if skip > 0 || !allowedTags[tok.DataAtom] {
+				continue
+			}
+			if tok.DataAtom == atom.A {
+				if len(openLinks) == 0 {
+					continue
+				}
+				kept := openLinks[len(openLinks)-1]
+				openLinks = openLinks[:len(openLinks)-1]
+				if kept {
+					out.WriteString("</a>")
+				}
+				continue
+			}
+			out.WriteString("</" + tok.Data + ">")
+		}
+	}
+}
+
+func safeHref(tok html.Token) (string, bool) {
+	for _, a := range tok.Attr {
+		if a.Key != "href" {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(a.Val))
+		if err != nil || !safeLinkSchemes[strings.ToLower(u.Scheme)] {
+			return "", false
+		}
+		return u.String(), true
+	}
+	return "", false
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"plain", "hello world", "hello world"},
+		{"strong", "**bold** move", "<strong>bold</strong> move"},
+		{"em", "_very_ nice", "<em>very</em> nice"},
+		{"code", "run `go test`", "run <code>go test</code>"},
+		{"link", "[docs](https://example.com/a?b=1&c=2)", `<a href="https://example.com/a?b=1&amp;c=2" rel="nofollow noopener">docs</a>`},
+		{"autolink", "<https://example.com>", `<a href="https://example.com" rel="nofollow noopener">https://example.com</a>`},
+		{"heading stripped", "# Title", "Title"},
+		{"list stripped", "- one\n- two", "one\ntwo"},
+		{"image stripped", "![alt](https://example.com/x.png)", ""},
+		{"entities", "a < b & c", "a &lt; b &amp; c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderMarkdown(tt.body); got != tt.want {
+				t.Errorf("renderMarkdown(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownXSS(t *testing.T) {
+	tests := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[click](javascript:alert(1))",
+		"[click](JaVaScRiPt:alert(1))",
+		"[click](data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==)",
+		"[click](vbscript:msgbox(1))",
+		`<a href="https://ok.example" onclick="alert(1)">x</a>`,
+		"**<iframe src=https://evil.example></iframe>**",
+		"`<script>alert(1)</script>`",
+		`<svg onload=alert(1)>`,
+		`[x](https://ok.example "title\" onmouseover=\"alert(1))`,
+	}
+	for _, body := range tests {
+		got := renderMarkdown(body)
+		z := html.NewTokenizer(strings.NewReader(got))
+		for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+			if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+				continue
+			}
+			tok := z.Token()
+			if !allowedTags[tok.DataAtom] {
+				t.Errorf("renderMarkdown(%q) = %q, has <%s>", body, got, tok.Data)
+			}
+			for _, a := range tok.Attr {
+				if a.Key != "href" && a.Key != "rel" {
+					t.Errorf("renderMarkdown(%q) = %q, has attribute %s", body, got, a.Key)
+				}
+				if a.Key == "href" && !strings.HasPrefix(a.Val, "https://") {
+					t.Errorf("renderMarkdown(%q) = %q, links to %s", body, got, a.Val)
+				}
+			}
+		}
+	}
+}
+
+func TestSanitizeHTMLUnwrapsUnsafeLinks(t *testing.T) {
+	got := sanitizeHTML(`<p><a href="javascript:alert(1)">a <em>b</em></a> <a href="/x">c</a></p><style>p{}</style>`)
+	if want := "a <em>b</em> c"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestGetChirpsRenderedBody(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"**hi** there"}`, token)
+
+	get := func(path, accept string) chirpResp {
+		t.Helper()
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got status %d", path, rec.Code)
+		}
+		if strings.HasPrefix(strings.TrimSpace(rec.Body.String()), "[") {
+			var list []chirpResp
+			json.Unmarshal(rec.Body.Bytes(), &list)
+			return list[0]
+		}
+		var c chirpResp
+		json.Unmarshal(rec.Body.Bytes(), &c)
+		return c
+	}
+	one := "/api/chirps/" + chirp.ID.String()
+	if c := get(one, ""); c.RenderedBody != "" || c.Body != "**hi** there" {
+		t.Errorf("got %+v without asking, want no rendered_body", c)
+	}
+	for _, c := range []chirpResp{get(one+"?render=html", ""), get(one, "text/html"), get("/api/chirps?render=html", "")} {
+		if c.RenderedBody != "<strong>hi</strong> there" || c.Body != "**hi** there" {
+			t.Errorf("got body %q rendered %q, want both", c.Body, c.RenderedBody)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"cmp"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// schemaFiles bundles the goose migrations so a deploy needs nothing but the
+// binary and a database.
+//
+//go:embed sql/schema/*.sql
+var schemaFiles embed.FS
+
+type migration struct {
+	version int64
+	name    string
+	up      string
+}
+
+// loadMigrations reads every .sql file at the root of fsys, ordered by the
+// number before the first underscore rather than by file name.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []migration
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must start with a version and an underscore", name)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version %q", name, prefix)
+		}
+		dat, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, up: gooseUp(string(dat))})
+	}
+	slices.SortFunc(migrations, func(a, b migration) int {
+		return cmp.Compare(a.version, b.version)
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migrations[i-1].name, migrations[i].name, migrations[i].version)
+		}
+	}
+	return migrations, nil
+}
+
+// gooseUp returns the statements between "-- +goose Up" and
+// "-- +goose Down". Files without annotations are used whole.
+func gooseUp(src string) string {
+	_, up, ok := strings.Cut(src, "-- +goose Up")
+	if !ok {
+		return src
+	}
+	up, _, _ = strings.Cut(up, "-- +goose Down")
+	return strings.TrimSpace(up)
+}
+
+// runMigrations applies, in version order, every migration in fsys not yet
+// recorded in schema_migrations. Each one runs in its own transaction along
+// with its schema_migrations row, so a failure leaves earlier migrations in
+// place and rolls back only the one that failed.
+func runMigrations(db *sql.DB, fsys fs.FS) error {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+)`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations: %w", err)
+	}
+	if err := importGooseVersions(db); err != nil {
+		return err
+	}
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+// importGooseVersions seeds an empty schema_migrations from goose's own
+// bookkeeping, so databases migrated with the goose CLI are not migrated
+// twice.
+func importGooseVersions(db *sql.DB) error {
+	var hasGoose bool
+	if err := db.QueryRow(`SELECT to_regclass('goose_db_version') IS NOT NULL`).Scan(&hasGoose); err != nil {
+		return fmt.Errorf("checking for goose_db_version: %w", err)
+	}
+	if !hasGoose {
+		return nil
+	}
+	_, err := db.Exec(`INSERT INTO schema_migrations (version)
+SELECT g.version_id FROM goose_db_version g
+WHERE g.version_id > 0 AND g.is_applied
+  AND g.id = (SELECT MAX(id) FROM goose_db_version WHERE version_id = g.version_id)
+  AND NOT EXISTS (SELECT 1 FROM schema_migrations)`)
+	if err != nil {
+		return fmt.Errorf("importing goose versions: %w", err)
+	}
+	return nil
+}
+
+func appliedMigrations(db *sql.DB) (map[int64]bool, error) {
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(m.up); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version) VALUES ($1)`, m.version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// fakeMigrationDB is a database/sql driver that understands just the
+// bookkeeping statements runMigrations issues. Everything else is recorded
+// in executed, or fails if it contains failOn. Work done in a transaction
+// only lands on commit.
+type fakeMigrationDB struct {
+	mu       sync.Mutex
+	applied  []int64
+	goose    []int64 // nil means there is no goose_db_version table
+	executed []string
+	failOn   string
+}
+
+type fakeMigrationConn struct {
+	db *fakeMigrationDB
+	tx *fakeMigrationTx
+}
+
+type fakeMigrationTx struct {
+	conn     *fakeMigrationConn
+	applied  []int64
+	executed []string
+}
+
+func (d *fakeMigrationDB) Connect(context.Context) (driver.Conn, error) {
+	return &fakeMigrationConn{db: d}, nil
+}
+
+func (d *fakeMigrationDB) Driver() driver.Driver { return nil }
+
+func (c *fakeMigrationConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *fakeMigrationConn) Close() error { return nil }
+
+func (c *fakeMigrationConn) Begin() (driver.Tx, error) {
+	c.tx = &fakeMigrationTx{conn: c}
+	return c.tx, nil
+}
+
+func (c *fakeMigrationConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	d := c.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations (version)\nSELECT"):
+		if len(d.applied) == 0 {
+			d.applied = append(d.applied, d.goose...)
+		}
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		v := args[0].Value.(int64)
+		if c.tx != nil {
+			c.tx.applied = append(c.tx.applied, v)
+		} else {
+			d.applied = append(d.applied, v)
+		}
+	case d.failOn != "" && strings.Contains(query, d.failOn):
+		return nil, errors.New("syntax error")
+	case c.tx != nil:
+		c.tx.executed = append(c.tx.executed, query)
+	default:
+		d.executed = append(d.executed, query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeMigrationConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	d := c.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	switch {
+	case strings.HasPrefix(query, "SELECT to_regclass"):
+		return &fakeRows{cols: []string{"exists"}, vals: []driver.Value{d.goose != nil}}, nil
+	case strings.HasPrefix(query, "SELECT version FROM schema_migrations"):
+		rows := &fakeRows{cols: []string{"version"}}
+		for _, v := range d.applied {
+			rows.vals = append(rows.vals, v)
+		}
+		return rows, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+func (tx *fakeMigrationTx) Commit() error {
+	d := tx.conn.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.applied = append(d.applied, tx.applied...)
+	d.executed = append(d.executed, tx.executed...)
+	tx.conn.tx = nil
+	return nil
+}
+
+func (tx *fakeMigrationTx) Rollback() error {
+	tx.conn.tx = nil
+	return nil
+}
+
+// fakeRows yields one single-column row per value.
+type fakeRows struct {
+	cols []string
+	vals []driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.vals) == 0 {
+		return io.EOF
+	}
+	dest[0], r.vals = r.vals[0], r.vals[1:]
+	return nil
+}
+
+func migrationFS(files ...string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for _, name := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte("-- +goose Up\nRUN " + name + ";\n\n-- +goose Down\nUNDO " + name + ";\n")}
+	}
+	return fsys
+}
+
+func TestRunMigrationsNumericOrder(t *testing.T) {
+	fake := &fakeMigrationDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	// Listed, and sorted by name, out of numeric order.
+	fsys := migrationFS("10_ten.sql", "2_two.sql", "001_one.sql", "003_three.sql")
+
+	if err := runMigrations(db, fsys); err != nil {
+		t.Fatalf("runMigrations: %v", err)
+	}
+	want := []string{"RUN 001_one.sql;", "RUN 2_two.sql;", "RUN 003_three.sql;", "RUN 10_ten.sql;"}
+	if !slices.Equal(fake.executed, want) {
+		t.Errorf("executed %q, want %q", fake.executed, want)
+	}
+	if !slices.Equal(fake.applied, []int64{1, 2, 3, 10}) {
+		t.Errorf("recorded versions %v, want [1 2 3 10]", fake.applied)
+	}
+
+	// A second run finds everything applied.
+	fake.executed = nil
+	if err := runMigrations(db, fsys); err != nil {
+		t.Fatalf("second runMigrations: %v", err)
+	}
+	if len(fake.executed) != 0 {
+		t.Errorf("second run executed %q, want nothing", fake.executed)
+	}
+}
+
+func TestRunMigrationsSkipsApplied(t *testing.T) {
+	fake := &fakeMigrationDB{applied: []int64{1, 3}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	if err := runMigrations(db, migrationFS("001_one.sql", "002_two.sql", "003_three.sql")); err != nil {
+		t.Fatalf("runMigrations: %v", err)
+	}
+	if !slices.Equal(fake.executed, []string{"RUN 002_two.sql;"}) {
+		t.Errorf("executed %q, want only 002", fake.executed)
+	}
+}
+
+func TestRunMigrationsImportsGoose(t *testing.T) {
+	fake := &fakeMigrationDB{goose: []int64{1, 2}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	if err := runMigrations(db, migrationFS("001_one.sql", "002_two.sql", "003_three.sql")); err != nil {
+		t.Fatalf("runMigrations: %v", err)
+	}
+	if !slices.Equal(fake.executed, []string{"RUN 003_three.sql;"}) {
+		t.Errorf("executed %q, want only the migration goose had not run", fake.executed)
+	}
+}
+
+func TestRunMigrationsRollsBackFailure(t *testing.T) {
+	fake := &fakeMigrationDB{failOn: "002_bad"}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	err := runMigrations(db, migrationFS("001_ok.sql", "002_bad.sql", "003_later.sql"))
+	if err == nil || !strings.Contains(err.Error(), "002_bad.sql") {
+		t.Fatalf("got error %v, want one naming 002_bad.sql", err)
+	}
+	if !slices.Equal(fake.applied, []int64{1}) {
+		t.Errorf("recorded versions %v, want only [1]", fake.applied)
+	}
+	if !slices.Equal(fake.executed, []string{"RUN 001_ok.sql;"}) {
+		t.Errorf("executed %q, want only 001", fake.executed)
+	}
+}
+
+func TestLoadMigrationsRejectsBadNames(t *testing.T) {
+	for _, files := range [][]string{
+		{"create_users.sql"},
+		{"abc_users.sql"},
+		{"001_users.sql", "1_again.sql"},
+	} {
+		if _, err := loadMigrations(migrationFS(files...)); err == nil {
+			t.Errorf("loadMigrations(%v): want an error", files)
+		}
+	}
+}
+
+func TestEmbeddedSchemaLoads(t *testing.T) {
+	schema, err := fs.Sub(schemaFiles, "sql/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := loadMigrations(schema)
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	for i, m := range migrations {
+		if m.version != int64(i+1) {
+			t.Errorf("migration %d is %s, want versions to run 1..N without gaps", i, m.name)
+		}
+		if m.up == "" || strings.Contains(m.up, "+goose Down") {
+			t.Errorf("%s: got up section %q", m.name, m.up)
+		}
+	}
+}
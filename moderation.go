@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const moderationTimeout = 3 * time.Second
+
+// moderationClient asks an external moderator whether a chirp body may be
+// published.
+type moderationClient interface {
+	Moderate(ctx context.Context, body string) (moderationVerdict, error)
+}
+
+type moderationVerdict struct {
+	Verdict string `json:"verdict"`
+	Reason  string `json:"reason"`
+}
+
+func (v moderationVerdict) rejected() bool {
+	return v.Verdict == "reject"
+}
+
+// httpModerationClient posts chirp bodies to MODERATION_WEBHOOK_URL,
+// authenticating with a shared secret in the X-Moderation-Secret header.
+type httpModerationClient struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func newHTTPModerationClient(url, secret string) *httpModerationClient {
+	return &httpModerationClient{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: moderationTimeout},
+	}
+}
+
+func (c *httpModerationClient) Moderate(ctx context.Context, body string) (moderationVerdict, error) {
+	dat, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return moderationVerdict{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(dat))
+	if err != nil {
+		return moderationVerdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Moderation-Secret", c.secret)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return moderationVerdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return moderationVerdict{}, fmt.Errorf("moderator returned status %d", resp.StatusCode)
+	}
+	var v moderationVerdict
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return moderationVerdict{}, fmt.Errorf("decoding moderator response: %w", err)
+	}
+	return v, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type mockModerator struct {
+	verdict moderationVerdict
+	err     error
+	seen    []string
+}
+
+func (m *mockModerator) Moderate(ctx context.Context, body string) (moderationVerdict, error) {
+	m.seen = append(m.seen, body)
+	return m.verdict, m.err
+}
+
+func TestCreateChirpModeration(t *testing.T) {
+	tests := []struct {
+		name       string
+		moderator  *mockModerator
+		wantStatus int
+		wantError  string
+	}{
+		{"accept", &mockModerator{verdict: moderationVerdict{Verdict: "accept"}}, http.StatusCreated, ""},
+		{"reject", &mockModerator{verdict: moderationVerdict{Verdict: "reject", Reason: "toxic"}}, http.StatusUnprocessableEntity, "toxic"},
+		{"moderator error fails open", &mockModerator{err: errors.New("moderator returned status 500")}, http.StatusCreated, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			cfg := newTestConfig(store)
+			cfg.moderation = tt.moderator
+			_, token := seedUser(t, cfg, store, "alice@example.com")
+			rec := serve(newServer("0", cfg).Handler, "POST", "/api/chirps", `{"body":"hello there"}`, token)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if len(tt.moderator.seen) != 1 || tt.moderator.seen[0] != "hello there" {
+				t.Errorf("moderator saw %q", tt.moderator.seen)
+			}
+			if tt.wantError != "" {
+				var resp struct {
+					Error string `json:"error"`
+				}
+				json.Unmarshal(rec.Body.Bytes(), &resp)
+				if resp.Error != tt.wantError {
+					t.Errorf("got error %q, want %q", resp.Error, tt.wantError)
+				}
+				if len(store.chirps) != 0 {
+					t.Error("rejected chirp was stored")
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPModerationClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Moderation-Secret") != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		switch {
+		case strings.Contains(req.Body, "slow"):
+			time.Sleep(200 * time.Millisecond)
+		case strings.Contains(req.Body, "broken"):
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		case strings.Contains(req.Body, "bad"):
+			w.Write([]byte(`{"verdict":"reject","reason":"bad words"}`))
+			return
+		}
+		w.Write([]byte(`{"verdict":"accept"}`))
+	}))
+	defer srv.Close()
+
+	c := newHTTPModerationClient(srv.URL, "s3cret")
+	c.client.Timeout = 50 * time.Millisecond
+
+	if v, err := c.Moderate(t.Context(), "nice chirp"); err != nil || v.rejected() {
+		t.Errorf("accept: got %+v, %v", v, err)
+	}
+	if v, err := c.Moderate(t.Context(), "bad chirp"); err != nil || !v.rejected() || v.Reason != "bad words" {
+		t.Errorf("reject: got %+v, %v", v, err)
+	}
+	if _, err := c.Moderate(t.Context(), "broken chirp"); err == nil {
+		t.Error("non-2xx: expected an error")
+	}
+	if _, err := c.Moderate(t.Context(), "slow chirp"); err == nil {
+		t.Error("timeout: expected an error")
+	}
+	if _, err := newHTTPModerationClient(srv.URL, "wrong").Moderate(t.Context(), "nice chirp"); err == nil {
+		t.Error("wrong secret: expected an error")
+	}
+}
+
+func TestCreateChirpModerationTimeoutFailsOpen(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"verdict":"reject","reason":"too late"}`))
+	}))
+	defer srv.Close()
+
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	moderator := newHTTPModerationClient(srv.URL, "")
+	moderator.client.Timeout = 50 * time.Millisecond
+	cfg.moderation = moderator
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	rec := serve(newServer("0", cfg).Handler, "POST", "/api/chirps", `{"body":"hello"}`, token)
+	if rec.Code != http.StatusCreated {
+		t.Errorf("got status %d, want 201", rec.Code)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "net/http"
+
+// muxErrorWriter records the status ServeMux answers an unmatched request
+// with and drops its plain-text body.
+type muxErrorWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (m *muxErrorWriter) WriteHeader(code int) { m.status = code }
+
+func (m *muxErrorWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+// middlewareMuxErrors answers requests no route matches with JSON errors,
+// like the rest of the API, in place of ServeMux's plain-text 404 and 405.
+// Headers the mux sets, such as Allow on a 405, are kept.
+func middlewareMuxErrors(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern != "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		rec := &muxErrorWriter{ResponseWriter: w, status: http.StatusNotFound}
+		mux.ServeHTTP(rec, r)
+		msg := "not found"
+		if rec.status == http.StatusMethodNotAllowed {
+			msg = "method not allowed"
+		}
+		respondWithError(w, rec.status, msg)
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMuxErrorsAreJSON(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	cases := []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/api/nope", http.StatusNotFound, `{"error":"not found"}`},
+		{"GET", "/nowhere", http.StatusNotFound, `{"error":"not found"}`},
+		{"PATCH", "/api/chirps", http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("X-Request-Id", "req-404")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != c.status || rec.Body.String() != c.body {
+			t.Errorf("%s %s: got %d %s, want %d %s", c.method, c.path, rec.Code, rec.Body.String(), c.status, c.body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s: got Content-Type %q", c.method, c.path, ct)
+		}
+		if id := rec.Header().Get("X-Request-Id"); id != "req-404" {
+			t.Errorf("%s %s: got X-Request-Id %q, want req-404", c.method, c.path, id)
+		}
+	}
+
+	rec := serve(h, "PATCH", "/api/chirps", "", "")
+	if rec.Header().Get("Allow") == "" {
+		t.Error("405 lost the Allow header")
+	}
+	if rec := serve(h, "GET", "/api/healthz", "", ""); rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("matched route: got %d %q", rec.Code, rec.Body.String())
+	}
+}
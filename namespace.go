@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultNamespace  = "default"
+	namespaceCacheTTL = time.Minute
+)
+
+// namespaceNameRE matches names usable as a DNS label, since requests pick
+// their namespace by subdomain.
+var namespaceNameRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type namespaceContextKey struct{}
+
+func withNamespace(ctx context.Context, ns database.Namespace) context.Context {
+	return context.WithValue(ctx, namespaceContextKey{}, ns)
+}
+
+// namespaceFromContext returns the namespace the request was routed to, or
+// the default namespace for calls that do not go through
+// middlewareNamespace, such as gRPC and background jobs.
+func namespaceFromContext(ctx context.Context) database.Namespace {
+	if ns, ok := ctx.Value(namespaceContextKey{}).(database.Namespace); ok {
+		return ns
+	}
+	return database.Namespace{Name: defaultNamespace}
+}
+
+// namespaceOf is the name of ctx's namespace, for scoping queries.
+func namespaceOf(ctx context.Context) string {
+	return namespaceFromContext(ctx).Name
+}
+
+// namespaceForHost picks the namespace a Host header asks for: the first
+// label of a name with a subdomain, e.g. "team" for team.chirpy.dev. Bare
+// domains and IP addresses use the default namespace.
+func namespaceForHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return defaultNamespace
+	}
+	labels := strings.Split(strings.ToLower(host), ".")
+	if len(labels) < 3 {
+		return defaultNamespace
+	}
+	return labels[0]
+}
+
+type namespaceCacheEntry struct {
+	ns      database.Namespace
+	found   bool
+	expires time.Time
+}
+
+// lookupNamespace loads a namespace, caching hits and misses for
+// namespaceCacheTTL so unknown subdomains do not query on every request.
+func (cfg *apiConfig) lookupNamespace(ctx context.Context, name string) (database.Namespace, bool, error) {
+	if v, ok := cfg.namespaces.Load(name); ok {
+		if e := v.(namespaceCacheEntry); cfg.timeNow().Before(e.expires) {
+			return e.ns, e.found, nil
+		}
+	}
+	ns, err := cfg.db.GetNamespace(ctx, name)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.Namespace{}, false, err
+	}
+	found := err == nil
+	cfg.namespaces.Store(name, namespaceCacheEntry{ns: ns, found: found, expires: cfg.timeNow().Add(namespaceCacheTTL)})
+	return ns, found, nil
+}
+
+// middlewareNamespace routes each request to the namespace named by its
+// Host header, falling back to the default namespace for hosts that do not
+// name one.
+func (cfg *apiConfig) middlewareNamespace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ns, found, err := cfg.lookupNamespace(r.Context(), namespaceForHost(r.Host))
+		if err == nil && !found {
+			ns, found, err = cfg.lookupNamespace(r.Context(), defaultNamespace)
+		}
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		if !found {
+			ns = database.Namespace{Name: defaultNamespace}
+		}
+		next.ServeHTTP(w, r.WithContext(withNamespace(r.Context(), ns)))
+	})
+}
+
+type createNamespaceParams struct {
+	Name string `json:"name"`
+	// ChirpsPerMinute overrides CHIRPS_PER_MINUTE for the namespace's
+	// users; null keeps the deployment default.
+	ChirpsPerMinute *int     `json:"chirps_per_minute"`
+	Features        []string `json:"features"`
+}
+
+type namespaceResp struct {
+	Name            string    `json:"name"`
+	ChirpsPerMinute *int      `json:"chirps_per_minute"`
+	Features        []string  `json:"features"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func newNamespaceResp(ns database.Namespace) namespaceResp {
+	resp := namespaceResp{Name: ns.Name, Features: ns.Features, CreatedAt: ns.CreatedAt}
+	if resp.Features == nil {
+		resp.Features = []string{}
+	}
+	if ns.ChirpsPerMinute.Valid {
+		limit := int(ns.ChirpsPerMinute.Int32)
+		resp.ChirpsPerMinute = &limit
+	}
+	return resp
+}
+
+// handlerCreateNamespace adds a community to the deployment. Only admins of
+// the default namespace may create namespaces.
+func (cfg *apiConfig) handlerCreateNamespace(w http.ResponseWriter, r *http.Request) {
+	userId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	caller, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: userId, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if caller.User.Namespace != defaultNamespace {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	var params createNamespaceParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if !namespaceNameRE.MatchString(params.Name) {
+		respondWithError(w, http.StatusBadRequest, "name must be a lowercase DNS label")
+		return
+	}
+	if params.ChirpsPerMinute != nil && *params.ChirpsPerMinute < 0 {
+		respondWithError(w, http.StatusBadRequest, "chirps_per_minute must not be negative")
+		return
+	}
+	if _, err := cfg.db.GetNamespace(r.Context(), params.Name); err == nil {
+		respondWithError(w, http.StatusConflict, "namespace already exists")
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	arg := database.CreateNamespaceParams{Name: params.Name, Features: params.Features}
+	if arg.Features == nil {
+		arg.Features = []string{}
+	}
+	if params.ChirpsPerMinute != nil {
+		arg.ChirpsPerMinute = sql.NullInt32{Int32: int32(*params.ChirpsPerMinute), Valid: true}
+	}
+	ns, err := cfg.db.CreateNamespace(r.Context(), arg)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.namespaces.Delete(ns.Name)
+	cfg.audit(withActor(r.Context(), userId), "namespace.created", "namespace", uuid.Nil, map[string]string{
+		"name": ns.Name,
+	})
+	respondWithJSON(w, http.StatusCreated, newNamespaceResp(ns))
+}
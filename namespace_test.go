@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+)
+
+func TestNamespaceForHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"team.chirpy.dev", "team"},
+		{"Team.Chirpy.dev:8080", "team"},
+		{"chirpy.dev", defaultNamespace},
+		{"localhost:8080", defaultNamespace},
+		{"127.0.0.1:8080", defaultNamespace},
+		{"[::1]:8080", defaultNamespace},
+	}
+	for _, tt := range tests {
+		if got := namespaceForHost(tt.host); got != tt.want {
+			t.Errorf("namespaceForHost(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func serveHost(h http.Handler, host, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = host
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCreateNamespace(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	_, userToken := seedUser(t, cfg, store, "user@example.com")
+
+	if rec := serve(h, "POST", "/admin/namespaces", `{"name":"team"}`, userToken); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d for a non-admin, want 403", rec.Code)
+	}
+	if rec := serve(h, "POST", "/admin/namespaces", `{"name":"Not A Label"}`, adminToken); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an invalid name, want 400", rec.Code)
+	}
+	rec := serve(h, "POST", "/admin/namespaces", `{"name":"team","chirps_per_minute":5,"features":["polls"]}`, adminToken)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp namespaceResp
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Name != "team" || resp.ChirpsPerMinute == nil || *resp.ChirpsPerMinute != 5 || len(resp.Features) != 1 {
+		t.Errorf("got %+v, want team with a limit of 5 and one feature", resp)
+	}
+	if rec := serve(h, "POST", "/admin/namespaces", `{"name":"team"}`, adminToken); rec.Code != http.StatusConflict {
+		t.Errorf("got status %d creating team twice, want 409", rec.Code)
+	}
+
+	// Admins of other namespaces cannot create namespaces.
+	teamAdmin := serveHost(h, "team.chirpy.dev", "POST", "/api/users", `{"email":"boss@example.com","password":"pw"}`, "")
+	var created struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(teamAdmin.Body.Bytes(), &created)
+	var token string
+	for i := range store.users {
+		if store.users[i].ID.String() == created.ID {
+			store.users[i].IsAdmin = true
+			token, _ = auth.MakeJWT(store.users[i].ID, cfg.tokenSecret, time.Hour)
+		}
+	}
+	if rec := serve(h, "POST", "/admin/namespaces", `{"name":"other"}`, token); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d for an admin outside the default namespace, want 403", rec.Code)
+	}
+}
+
+func TestNamespacesAreIsolated(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	if rec := serve(h, "POST", "/admin/namespaces", `{"name":"team"}`, adminToken); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d creating namespace", rec.Code)
+	}
+
+	const team = "team.chirpy.dev"
+	if rec := serveHost(h, team, "POST", "/api/users", `{"email":"alice@example.com","password":"pw"}`, ""); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d creating team user: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveHost(h, "chirpy.dev", "POST", "/api/login", `{"email":"alice@example.com","password":"pw"}`, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d logging in to the default namespace as a team user, want 401", rec.Code)
+	}
+	rec := serveHost(h, team, "POST", "/api/login", `{"email":"alice@example.com","password":"pw"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d logging in to team: %s", rec.Code, rec.Body.String())
+	}
+	var login struct {
+		Token string `json:"token"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &login)
+
+	rec = serveHost(h, team, "POST", "/api/chirps", `{"body":"team only"}`, login.Token)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d posting: %s", rec.Code, rec.Body.String())
+	}
+	var chirp chirpResp
+	json.Unmarshal(rec.Body.Bytes(), &chirp)
+	serve(h, "POST", "/api/chirps", `{"body":"default only"}`, adminToken)
+
+	list := func(host string) []chirpResp {
+		var got []chirpResp
+		json.Unmarshal(serveHost(h, host, "GET", "/api/chirps", "", "").Body.Bytes(), &got)
+		return got
+	}
+	if got := list(team); len(got) != 1 || got[0].Body != "team only" {
+		t.Errorf("got %+v from team, want only its own chirp", got)
+	}
+	if got := list("chirpy.dev"); len(got) != 1 || got[0].Body != "default only" {
+		t.Errorf("got %+v from the default namespace, want only its own chirp", got)
+	}
+	if rec := serveHost(h, "chirpy.dev", "GET", "/api/chirps/"+chirp.ID.String(), "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d fetching a team chirp from the default namespace, want 404", rec.Code)
+	}
+	if rec := serveHost(h, team, "GET", "/api/chirps/"+chirp.ID.String(), "", ""); rec.Code != http.StatusOK {
+		t.Errorf("got status %d fetching a team chirp from team, want 200", rec.Code)
+	}
+	// Unknown subdomains are served from the default namespace.
+	if got := list("nobody.chirpy.dev"); len(got) != 1 || got[0].Body != "default only" {
+		t.Errorf("got %+v from an unknown subdomain, want the default namespace", got)
+	}
+}
+
+func TestNamespaceRateLimitOverride(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 10
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	serve(h, "POST", "/admin/namespaces", `{"name":"slow","chirps_per_minute":1}`, adminToken)
+	_, slowToken := seedUser(t, cfg, store, "slow@example.com")
+	store.users[1].Namespace = "slow"
+
+	for i, want := range []int{http.StatusCreated, http.StatusTooManyRequests} {
+		if rec := serveHost(h, "slow.chirpy.dev", "POST", "/api/chirps", `{"body":"hi"}`, slowToken); rec.Code != want {
+			t.Errorf("request %d: got status %d, want %d", i+1, rec.Code, want)
+		}
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"hi again"}`, adminToken); rec.Code != http.StatusCreated {
+		t.Errorf("got status %d in the default namespace, want its limit to apply", rec.Code)
+	}
+}
+
+func TestUserLookupsStayInNamespace(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	serve(h, "POST", "/admin/namespaces", `{"name":"team"}`, adminToken)
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	bob, _ := seedUser(t, cfg, store, "bob@example.com")
+	store.users[2].Namespace = "team"
+	const team = "team.chirpy.dev"
+
+	// Warm the profile cache from bob's own namespace first.
+	if rec := serveHost(h, team, "GET", "/api/users/"+bob.ID.String(), "", ""); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d fetching bob from team, want 200", rec.Code)
+	}
+	for _, path := range []string{"", "/chirps", "/followers", "/following", "/followers/mutual", "/relationship"} {
+		if rec := serve(h, "GET", "/api/users/"+bob.ID.String()+path, "", token); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s from the default namespace: got status %d, want 404", path, rec.Code)
+		}
+	}
+	if rec := serve(h, "POST", "/api/users/"+bob.ID.String()+"/follow", "", token); rec.Code != http.StatusNotFound {
+		t.Errorf("following bob from the default namespace: got status %d, want 404", rec.Code)
+	}
+}
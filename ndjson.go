@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+// acceptsNDJSON reports whether the Accept header lists
+// application/x-ndjson.
+func acceptsNDJSON(r *http.Request) bool {
+	return acceptsMediaType(r, ndjsonContentType)
+}
+
+// acceptsMediaType reports whether the Accept header lists mediaType.
+func acceptsMediaType(r *http.Request, mediaType string) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mt, _, err := mime.ParseMediaType(strings.TrimSpace(v)); err == nil && mt == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNDJSON writes one chirp per line, flushing after each so the
+// client can start on the first chirp before the last is encoded.
+func writeNDJSON(w http.ResponseWriter, chirps []chirpResp) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for _, c := range chirps {
+		if err := enc.Encode(c); err != nil {
+			log.Printf("Error writing NDJSON: %s", err)
+			return
+		}
+		rc.Flush()
+	}
+}
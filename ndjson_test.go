@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetChirpsNDJSON(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	for i := range 5 {
+		postChirp(t, h, fmt.Sprintf(`{"body":"chirp %d"}`, i), token)
+	}
+
+	req := httptest.NewRequest("GET", "/api/chirps", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/x-ndjson" {
+		t.Errorf("got Content-Type %q", got)
+	}
+	if !rec.Flushed {
+		t.Error("response was never flushed")
+	}
+	scanner := bufio.NewScanner(rec.Body)
+	n := 0
+	for scanner.Scan() {
+		var c chirpResp
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil {
+			t.Fatalf("line %d: %v", n+1, err)
+		}
+		if c.Body != fmt.Sprintf("chirp %d", n) {
+			t.Errorf("line %d: got body %q", n+1, c.Body)
+		}
+		n++
+	}
+	if n != len(store.chirps) {
+		t.Errorf("got %d lines, want %d", n, len(store.chirps))
+	}
+}
+
+func TestGetChirpsJSONArrayByDefault(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"hello"}`, token)
+
+	for _, accept := range []string{"", "application/json"} {
+		req := httptest.NewRequest("GET", "/api/chirps", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var chirps []chirpResp
+		if err := json.Unmarshal(rec.Body.Bytes(), &chirps); err != nil || len(chirps) != 1 {
+			t.Errorf("Accept %q: got %s, want a one-element array", accept, rec.Body.String())
+		}
+	}
+}
+
+func TestAcceptsNDJSON(t *testing.T) {
+	tests := map[string]bool{
+		"":                     false,
+		"application/json":     false,
+		"application/x-ndjson": true,
+		"application/json, application/x-ndjson;q=0.9": true,
+	}
+	for accept, want := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept", accept)
+		if got := acceptsNDJSON(req); got != want {
+			t.Errorf("acceptsNDJSON(%q) = %v, want %v", accept, got, want)
+		}
+	}
+}
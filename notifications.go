@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// mentionPattern matches @-mentions. Users have no handle, so a mention is
+// the user's email address prefixed with @, e.g. "@alice@example.com".
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([^\s@]+@[^\s@]+\.[^\s@]+)`)
+
+func mentionedEmails(body string) []string {
+	var emails []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		emails = append(emails, strings.TrimRight(m[1], ".,!?:;"))
+	}
+	return emails
+}
+
+// notify records a notification for recipient. Like audit, failures are
+// logged rather than failing the request that triggered them, and users
+// are never notified about their own actions.
+func (cfg *apiConfig) notify(ctx context.Context, recipient, actor uuid.UUID, typ database.NotificationType, chirpID uuid.UUID) {
+	if recipient == actor {
+		return
+	}
+	n, err := cfg.db.CreateNotification(ctx, database.CreateNotificationParams{
+		RecipientID: recipient,
+		ActorID:     actor,
+		Type:        typ,
+		ChirpID:     uuid.NullUUID{UUID: chirpID, Valid: chirpID != uuid.Nil},
+	})
+	if err != nil {
+		log.Printf("notification %s for %s: %v", typ, recipient, err)
+		return
+	}
+	cfg.enqueueJob(ctx, notificationCreatedPayload{Notification: n, Namespace: namespaceOf(ctx)})
+}
+
+// notifyMentions notifies every existing user mentioned in body, looking
+// them up in ctx's namespace.
+func (cfg *apiConfig) notifyMentions(ctx context.Context, actor, chirpID uuid.UUID, body string) {
+	seen := make(map[uuid.UUID]bool)
+	for _, email := range mentionedEmails(body) {
+		user, err := cfg.db.GetUserByEmail(ctx, database.GetUserByEmailParams{
+			Email:     sql.NullString{String: email, Valid: true},
+			Namespace: namespaceOf(ctx),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			log.Printf("resolving mention %s: %v", email, err)
+			continue
+		}
+		if seen[user.ID] {
+			continue
+		}
+		seen[user.ID] = true
+		cfg.notify(ctx, user.ID, actor, database.NotificationTypeMention, chirpID)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	// oauthCodeTTL is how long a client has to exchange an authorization
+	// code.
+	oauthCodeTTL = 10 * time.Minute
+	// oauthTokenTTL is the lifetime of access tokens issued to clients.
+	oauthTokenTTL = time.Hour
+	// oauthScope is the only scope clients may ask for. Tokens issued for
+	// it may only be used for safe methods; see scopeAllows.
+	oauthScope = "read"
+)
+
+var authorizeTemplate = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Authorize {{.ClientName}}</title>
+</head>
+<body>
+<p><strong>{{.ClientName}}</strong> wants to read your Chirpy account.</p>
+<form method="POST" action="/api/auth/authorize">
+<input type="hidden" name="client_id" value="{{.ClientID}}">
+<input type="hidden" name="redirect_uri" value="{{.RedirectURI}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<button type="submit" name="decision" value="approve">Approve</button>
+<button type="submit" name="decision" value="deny">Deny</button>
+</form>
+</body>
+</html>
+`))
+
+type authorizeData struct {
+	ClientName  string
+	ClientID    string
+	RedirectURI string
+	State       string
+	Scope       string
+}
+
+type createOAuthClientParams struct {
+	Name        string `json:"name"`
+	RedirectURI string `json:"redirect_uri"`
+}
+
+type oauthClientResp struct {
+	ClientID string `json:"client_id"`
+	// ClientSecret is only ever returned here; Chirpy keeps a hash.
+	ClientSecret string    `json:"client_secret"`
+	Name         string    `json:"name"`
+	RedirectURI  string    `json:"redirect_uri"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type oauthTokenResp struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// oauthErrorResp is the error body RFC 6749 section 5.2 defines for the
+// token endpoint.
+type oauthErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// handlerCreateOAuthClient registers a third-party app that may send users
+// through /api/auth/authorize.
+func (cfg *apiConfig) handlerCreateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var params createOAuthClientParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	params.Name = strings.TrimSpace(params.Name)
+	if params.Name == "" {
+		respondWithError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if u, err := url.Parse(params.RedirectURI); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || u.Fragment != "" {
+		respondWithError(w, http.StatusBadRequest, "redirect_uri must be an absolute http(s) URL without a fragment")
+		return
+	}
+	secret := auth.MakeRefreshToken()
+	secretHash, err := auth.HashPassword(secret)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	client, err := cfg.db.CreateOAuthClient(r.Context(), database.CreateOAuthClientParams{
+		ID:          uuid.NewString(),
+		SecretHash:  secretHash,
+		Name:        params.Name,
+		RedirectUri: params.RedirectURI,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), adminId), "oauth_client.created", "user", adminId, map[string]string{"client_id": client.ID})
+	respondWithJSON(w, http.StatusCreated, oauthClientResp{
+		ClientID:     client.ID,
+		ClientSecret: secret,
+		Name:         client.Name,
+		RedirectURI:  client.RedirectUri,
+		CreatedAt:    client.CreatedAt,
+	})
+}
+
+// authorizeClient checks the client and redirect URI of an authorization
+// request, answering it with an error when there is nothing to ask the
+// user. A bad client or redirect URI is reported here rather than
+// redirected, so codes and errors only ever go to registered URIs.
+func (cfg *apiConfig) authorizeClient(w http.ResponseWriter, r *http.Request, vals url.Values) (database.OauthClient, bool) {
+	client, err := cfg.db.GetOAuthClient(r.Context(), vals.Get("client_id"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return database.OauthClient{}, false
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return database.OauthClient{}, false
+	}
+	if vals.Get("redirect_uri") != client.RedirectUri {
+		http.Error(w, "redirect_uri does not match the client's", http.StatusBadRequest)
+		return database.OauthClient{}, false
+	}
+	if rt := vals.Get("response_type"); rt != "" && rt != "code" {
+		redirectWithParams(w, r, client.RedirectUri, url.Values{"error": {"unsupported_response_type"}, "state": {vals.Get("state")}})
+		return database.OauthClient{}, false
+	}
+	if scope := vals.Get("scope"); scope != "" && scope != oauthScope {
+		redirectWithParams(w, r, client.RedirectUri, url.Values{"error": {"invalid_scope"}, "state": {vals.Get("state")}})
+		return database.OauthClient{}, false
+	}
+	return client, true
+}
+
+// handlerAuthorize shows the page where the user approves or denies a
+// client's request. Users arrive here from the client's site, and the auth
+// cookie is SameSite strict, so the page cannot tell who is logged in;
+// handlerAuthorizeDecision checks that when the form is submitted.
+func (cfg *apiConfig) handlerAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	client, ok := cfg.authorizeClient(w, r, q)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Keep the page out of other sites' frames so approval cannot be
+	// clickjacked.
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(http.StatusOK)
+	if err := authorizeTemplate.Execute(w, authorizeData{
+		ClientName:  client.Name,
+		ClientID:    client.ID,
+		RedirectURI: client.RedirectUri,
+		State:       q.Get("state"),
+		Scope:       oauthScope,
+	}); err != nil {
+		fmt.Println(err)
+	}
+}
+
+// handlerAuthorizeDecision takes the user's answer from the authorization
+// page and sends them back to the client with a code or an access_denied
+// error. Browsers only send the auth cookie along when the form is posted
+// from Chirpy's own page, so other sites cannot decide for the user.
+func (cfg *apiConfig) handlerAuthorizeDecision(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		http.Error(w, "log in to Chirpy first", http.StatusUnauthorized)
+		return
+	}
+	userId, err := cfg.authenticateRequest(r, token)
+	if errors.Is(err, errInsufficientScope) {
+		http.Error(w, "this token cannot approve clients", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, "log in to Chirpy first", http.StatusUnauthorized)
+		return
+	}
+	client, ok := cfg.authorizeClient(w, r, r.PostForm)
+	if !ok {
+		return
+	}
+	state := r.PostForm.Get("state")
+	if r.PostForm.Get("decision") != "approve" {
+		redirectWithParams(w, r, client.RedirectUri, url.Values{"error": {"access_denied"}, "state": {state}})
+		return
+	}
+	code, err := cfg.db.CreateOAuthCode(r.Context(), database.CreateOAuthCodeParams{
+		Code:        auth.MakeRefreshToken(),
+		ClientID:    client.ID,
+		UserID:      userId,
+		RedirectUri: client.RedirectUri,
+		Scope:       oauthScope,
+		ExpiresAt:   cfg.timeNow().Add(oauthCodeTTL).UTC(),
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), userId), "oauth.authorized", "user", userId, map[string]string{"client_id": client.ID})
+	redirectWithParams(w, r, client.RedirectUri, url.Values{"code": {code.Code}, "state": {state}})
+}
+
+// redirectWithParams sends the browser to target with params added to its
+// query, leaving out empty ones.
+func redirectWithParams(w http.ResponseWriter, r *http.Request, target string, params url.Values) {
+	u, err := url.Parse(target)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	q := u.Query()
+	for k, vs := range params {
+		if len(vs) > 0 && vs[0] != "" {
+			q.Set(k, vs[0])
+		}
+	}
+	u.RawQuery = q.Encode()
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+// handlerTokenExchange trades an authorization code for an access token and
+// refresh token, as the token endpoint of RFC 6749 section 4.1.3. Clients
+// authenticate with client_id and client_secret in the form or with HTTP
+// Basic auth. A code is consumed by its first exchange, even a failed one.
+func (cfg *apiConfig) handlerTokenExchange(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if err := r.ParseForm(); err != nil {
+		respondWithJSON(w, http.StatusBadRequest, oauthErrorResp{Error: "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		respondWithJSON(w, http.StatusBadRequest, oauthErrorResp{Error: "unsupported_grant_type"})
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	client, err := cfg.db.GetOAuthClient(r.Context(), clientID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithJSON(w, http.StatusUnauthorized, oauthErrorResp{Error: "invalid_client"})
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if match, err := auth.CheckHashedPassword(clientSecret, client.SecretHash); err != nil || !match {
+		respondWithJSON(w, http.StatusUnauthorized, oauthErrorResp{Error: "invalid_client"})
+		return
+	}
+
+	code, err := cfg.db.ConsumeOAuthCode(r.Context(), r.PostForm.Get("code"))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	redirectURI := r.PostForm.Get("redirect_uri")
+	if err != nil || code.ClientID != client.ID || (redirectURI != "" && redirectURI != code.RedirectUri) {
+		respondWithJSON(w, http.StatusBadRequest, oauthErrorResp{Error: "invalid_grant", ErrorDescription: "unknown or already used code"})
+		return
+	}
+	if !cfg.timeNow().Before(code.ExpiresAt) {
+		respondWithJSON(w, http.StatusBadRequest, oauthErrorResp{Error: "invalid_grant", ErrorDescription: "code expired"})
+		return
+	}
+
+	user, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: code.UserID, Namespace: namespaceOf(r.Context())})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	token, refreshToken, err := cfg.issueTokens(r.Context(), user.User, oauthTokenTTL, oauthScope)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, oauthTokenResp{
+		AccessToken:  token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(oauthTokenTTL / time.Second),
+		RefreshToken: refreshToken,
+		Scope:        code.Scope,
+	})
+}
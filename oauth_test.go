@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveForm(h http.Handler, path string, form url.Values, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+type oauthFixture struct {
+	h      http.Handler
+	cfg    *apiConfig
+	clock  *fakeClock
+	client oauthClientResp
+	alice  string
+	// code is the authorization code alice's approval redirected with.
+	code string
+}
+
+// setupOAuth registers a client and has alice approve it.
+func setupOAuth(t *testing.T) oauthFixture {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	rec := serve(h, "POST", "/admin/oauth-clients", `{"name":"Birdwatch","redirect_uri":"https://birdwatch.example/callback"}`, adminToken)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating client: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var client oauthClientResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &client); err != nil {
+		t.Fatal(err)
+	}
+
+	q := url.Values{"client_id": {client.ClientID}, "redirect_uri": {client.RedirectURI}, "state": {"xyz"}, "scope": {"read"}}
+	rec = serve(h, "GET", "/api/auth/authorize?"+q.Encode(), "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Birdwatch") {
+		t.Fatalf("authorize page: got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	q.Set("decision", "approve")
+	rec = serveForm(h, "/api/auth/authorize", q, alice)
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("approving: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.Host != "birdwatch.example" || loc.Query().Get("state") != "xyz" || loc.Query().Get("code") == "" {
+		t.Fatalf("got redirect %s", loc)
+	}
+	return oauthFixture{h: h, cfg: cfg, clock: clock, client: client, alice: alice, code: loc.Query().Get("code")}
+}
+
+func exchangeForm(client oauthClientResp, code string) url.Values {
+	return url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {client.ClientID},
+		"client_secret": {client.ClientSecret},
+	}
+}
+
+func TestOAuthCodeExchange(t *testing.T) {
+	f := setupOAuth(t)
+	h, client, code := f.h, f.client, f.code
+
+	wrongSecret := exchangeForm(client, code)
+	wrongSecret.Set("client_secret", "nope")
+	if rec := serveForm(h, "/api/auth/token-exchange", wrongSecret, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d with the wrong secret, want 401", rec.Code)
+	}
+
+	rec := serveForm(h, "/api/auth/token-exchange", exchangeForm(client, code), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp oauthTokenResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TokenType != "Bearer" || resp.RefreshToken == "" || resp.Scope != "read" {
+		t.Errorf("got %+v", resp)
+	}
+	if _, err := f.cfg.validateJWT(t.Context(), resp.AccessToken); err != nil {
+		t.Errorf("access token does not validate: %v", err)
+	}
+	if rec := serve(h, "GET", "/api/users/me", "", resp.AccessToken); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "alice@example.com") {
+		t.Errorf("got status %d for /api/users/me: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveForm(h, "/api/auth/token-exchange", exchangeForm(client, code), "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "invalid_grant") {
+		t.Errorf("reusing a code: got status %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestOAuthTokenIsReadOnly(t *testing.T) {
+	f := setupOAuth(t)
+	rec := serveForm(f.h, "/api/auth/token-exchange", exchangeForm(f.client, f.code), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp oauthTokenResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := serve(f.h, "POST", "/api/chirps", `{"body":"posted by Birdwatch"}`, resp.AccessToken); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d posting a chirp with an OAuth token, want 403", rec.Code)
+	}
+	if rec := serve(f.h, "POST", "/api/chirps", `{"body":"posted by alice"}`, f.alice); rec.Code != http.StatusCreated {
+		t.Errorf("got status %d posting a chirp with alice's own token, want 201", rec.Code)
+	}
+
+	rec = serve(f.h, "POST", "/api/refresh", "", resp.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refreshing: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var refreshed refreshResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &refreshed); err != nil {
+		t.Fatal(err)
+	}
+	if rec := serve(f.h, "GET", "/api/users/me", "", refreshed.Token); rec.Code != http.StatusOK {
+		t.Errorf("got status %d for /api/users/me with a refreshed token, want 200", rec.Code)
+	}
+	if rec := serve(f.h, "POST", "/api/chirps", `{"body":"posted by Birdwatch"}`, refreshed.Token); rec.Code != http.StatusForbidden {
+		t.Errorf("got status %d posting a chirp with a refreshed OAuth token, want 403", rec.Code)
+	}
+}
+
+func TestOAuthExpiredCode(t *testing.T) {
+	f := setupOAuth(t)
+	f.clock.t = f.clock.t.Add(oauthCodeTTL + time.Second)
+
+	rec := serveForm(f.h, "/api/auth/token-exchange", exchangeForm(f.client, f.code), "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want 400", rec.Code)
+	}
+	var resp oauthErrorResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != "invalid_grant" || resp.ErrorDescription != "code expired" {
+		t.Errorf("got %+v", resp)
+	}
+}
+
+func TestOAuthAuthorizeRejections(t *testing.T) {
+	f := setupOAuth(t)
+	form := url.Values{"client_id": {f.client.ClientID}, "redirect_uri": {f.client.RedirectURI}, "state": {"xyz"}}
+
+	denied := url.Values{"decision": {"deny"}}
+	for k, v := range form {
+		denied[k] = v
+	}
+	rec := serveForm(f.h, "/api/auth/authorize", denied, f.alice)
+	if got := rec.Header().Get("Location"); rec.Code != http.StatusSeeOther || got != "https://birdwatch.example/callback?error=access_denied&state=xyz" {
+		t.Errorf("denying: got status %d, Location %q", rec.Code, got)
+	}
+
+	elsewhere := url.Values{"client_id": {f.client.ClientID}, "redirect_uri": {"https://evil.example/steal"}}
+	if rec := serve(f.h, "GET", "/api/auth/authorize?"+elsewhere.Encode(), "", ""); rec.Code != http.StatusBadRequest || rec.Header().Get("Location") != "" {
+		t.Errorf("got status %d for an unregistered redirect_uri, want 400 without a redirect", rec.Code)
+	}
+	if rec := serve(f.h, "GET", "/api/auth/authorize?client_id=nope", "", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an unknown client, want 400", rec.Code)
+	}
+
+	form.Set("decision", "approve")
+	if rec := serveForm(f.h, "/api/auth/authorize", form, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d approving while logged out, want 401", rec.Code)
+	}
+	form.Set("scope", "write")
+	rec = serveForm(f.h, "/api/auth/authorize", form, f.alice)
+	if got := rec.Header().Get("Location"); !strings.Contains(got, "error=invalid_scope") {
+		t.Errorf("got Location %q for an unsupported scope", got)
+	}
+
+	other := exchangeForm(f.client, f.code)
+	other.Set("grant_type", "password")
+	if rec := serveForm(f.h, "/api/auth/token-exchange", other, ""); !strings.Contains(rec.Body.String(), "unsupported_grant_type") {
+		t.Errorf("got %d %s for the password grant", rec.Code, rec.Body.String())
+	}
+}
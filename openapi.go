@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// routeDoc describes a route for the OpenAPI document. Request and Response
+// are zero values of the types the handler decodes and encodes; their
+// schemas are derived by reflection.
+type routeDoc struct {
+	Summary string
+	// Request is the JSON body the route reads, nil if it reads none.
+	Request any
+	// Response is the JSON body of a successful response. A nil Response
+	// with no Produces means the route answers 204 No Content.
+	Response any
+	// Status is the success status when it is not 200 or 204.
+	Status int
+	// Produces is the content type of a non-JSON response, described as a
+	// plain string.
+	Produces string
+	// Auth marks routes that require a bearer JWT.
+	Auth bool
+}
+
+type specRoute struct {
+	method string
+	path   string
+	doc    routeDoc
+}
+
+// specBuilder collects the routes registered in newServer and renders them
+// as an OpenAPI 3.0 document.
+type specBuilder struct {
+	title   string
+	version string
+	routes  []specRoute
+}
+
+func newSpecBuilder(title, version string) *specBuilder {
+	return &specBuilder{title: title, version: version}
+}
+
+// add records a route under its ServeMux pattern, e.g. "GET /api/chirps".
+func (b *specBuilder) add(pattern string, doc routeDoc) {
+	method, path, _ := strings.Cut(pattern, " ")
+	b.routes = append(b.routes, specRoute{method: strings.ToLower(method), path: path, doc: doc})
+}
+
+var pathParamRE = regexp.MustCompile(`\{(\w+)(?:\.\.\.)?\}`)
+
+// Build renders the collected routes. Named struct types become shared
+// component schemas so types like chirpResp are described once.
+func (b *specBuilder) Build() []byte {
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"error": map[string]any{"type": "string"}},
+		},
+	}
+	paths := map[string]map[string]any{}
+	for _, rt := range b.routes {
+		op := map[string]any{
+			"summary":   rt.doc.Summary,
+			"responses": b.responses(rt.doc, schemas),
+		}
+		var params []any
+		for _, m := range pathParamRE.FindAllStringSubmatch(rt.path, -1) {
+			params = append(params, map[string]any{
+				"name":     m[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]any{"type": "string"},
+			})
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+		if rt.doc.Request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(rt.doc.Request), schemas)},
+				},
+			}
+		}
+		if rt.doc.Auth {
+			op["security"] = []any{map[string]any{"bearerAuth": []string{}}}
+		}
+		path := pathParamRE.ReplaceAllString(rt.path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]any{}
+		}
+		paths[path][rt.method] = op
+	}
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": b.title, "version": b.version},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+	dat, _ := json.Marshal(doc)
+	return dat
+}
+
+func (b *specBuilder) responses(doc routeDoc, schemas map[string]any) map[string]any {
+	status := doc.Status
+	ok := map[string]any{}
+	switch {
+	case doc.Produces != "":
+		ok["content"] = map[string]any{doc.Produces: map[string]any{"schema": map[string]any{"type": "string"}}}
+	case doc.Response != nil:
+		ok["content"] = map[string]any{
+			"application/json": map[string]any{"schema": schemaFor(reflect.TypeOf(doc.Response), schemas)},
+		}
+	default:
+		if status == 0 {
+			status = http.StatusNoContent
+		}
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok["description"] = http.StatusText(status)
+	return map[string]any{
+		strconv.Itoa(status): ok,
+		"default": map[string]any{
+			"description": "Error",
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/Error"}},
+			},
+		},
+	}
+}
+
+var (
+	timeType = reflect.TypeFor[time.Time]()
+	uuidType = reflect.TypeFor[uuid.UUID]()
+	rawType  = reflect.TypeFor[json.RawMessage]()
+)
+
+// schemaFor describes t as encoding/json would encode it, registering named
+// structs in schemas and referring to them by name.
+func schemaFor(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case uuidType:
+		return map[string]any{"type": "string", "format": "uuid"}
+	case rawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := schemaFor(t.Elem(), schemas)
+		if _, isRef := s["$ref"]; isRef {
+			return map[string]any{"allOf": []any{s}, "nullable": true}
+		}
+		s["nullable"] = true
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		if _, ok := schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			schemas[t.Name()] = map[string]any{}
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+	addStructFields(t, props, schemas)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+// addStructFields adds t's JSON fields to props, flattening embedded
+// structs the way encoding/json does.
+func addStructFields(t reflect.Type, props, schemas map[string]any) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			addStructFields(f.Type, props, schemas)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = schemaFor(f.Type, schemas)
+	}
+}
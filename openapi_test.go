@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPISpec(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	rec := serve(h, "GET", "/api/openapi.json", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas         map[string]json.RawMessage `json:"schemas"`
+			SecuritySchemes map[string]struct {
+				Type         string `json:"type"`
+				Scheme       string `json:"scheme"`
+				BearerFormat string `json:"bearerFormat"`
+			} `json:"securitySchemes"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.0.") {
+		t.Errorf("got openapi %q, want 3.0.x", doc.OpenAPI)
+	}
+	if s := doc.Components.SecuritySchemes["bearerAuth"]; s.Type != "http" || s.Scheme != "bearer" || s.BearerFormat != "JWT" {
+		t.Errorf("got bearerAuth scheme %+v", s)
+	}
+
+	for path, methods := range map[string][]string{
+		"/api/chirps":                          {"get", "post", "delete"},
+		"/api/chirps/{chirpId}":                {"get", "delete"},
+		"/api/users":                           {"post", "put"},
+		"/api/login":                           {"post"},
+		"/api/refresh":                         {"post"},
+		"/api/users/{userId}/followers":        {"get"},
+		"/api/feed":                            {"get"},
+		"/api/healthz":                         {"get"},
+		"/api/openapi.json":                    {"get"},
+		"/admin/webhooks":                      {"post"},
+		"/api/lists/{listId}/members/{userId}": {"delete"},
+	} {
+		for _, m := range methods {
+			if _, ok := doc.Paths[path][m]; !ok {
+				t.Errorf("missing %s %s", strings.ToUpper(m), path)
+			}
+		}
+	}
+
+	var createChirp struct {
+		RequestBody struct {
+			Content map[string]struct {
+				Schema struct {
+					Ref string `json:"$ref"`
+				} `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]json.RawMessage `json:"responses"`
+		Security  []map[string][]string      `json:"security"`
+	}
+	if err := json.Unmarshal(doc.Paths["/api/chirps"]["post"], &createChirp); err != nil {
+		t.Fatal(err)
+	}
+	ref := createChirp.RequestBody.Content["application/json"].Schema.Ref
+	if ref != "#/components/schemas/createChirpParams" {
+		t.Errorf("got request schema %q", ref)
+	}
+	if _, ok := createChirp.Responses["201"]; !ok || len(createChirp.Security) != 1 {
+		t.Errorf("got responses %v and security %v, want a 201 behind bearerAuth", createChirp.Responses, createChirp.Security)
+	}
+
+	var chirp struct {
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(doc.Components.Schemas["chirpResp"], &chirp); err != nil {
+		t.Fatal(err)
+	}
+	if chirp.Properties["id"]["format"] != "uuid" || chirp.Properties["created_at"]["format"] != "date-time" || chirp.Properties["like_count"]["type"] != "integer" {
+		t.Errorf("got chirpResp properties %v", chirp.Properties)
+	}
+	var follower struct {
+		Properties map[string]any `json:"properties"`
+	}
+	json.Unmarshal(doc.Components.Schemas["followUserResp"], &follower)
+	if _, ok := follower.Properties["email"]; !ok {
+		t.Errorf("embedded userResp fields were not flattened into followUserResp: %v", follower.Properties)
+	}
+}
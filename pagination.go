@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// encodeCursor produces an opaque keyset cursor pointing just past the row
+// with the given created_at and id.
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errors.New("invalid cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, uuid.Nil, errors.New("invalid cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errors.New("invalid cursor")
+	}
+	idUUID, err := uuid.Parse(id)
+	if err != nil {
+		return time.Time{}, uuid.Nil, errors.New("invalid cursor")
+	}
+	return createdAt, idUUID, nil
+}
+
+// parsePageSize reads the limit query param, defaulting to defaultPageSize
+// and capping at maxPageSize.
+func parsePageSize(r *http.Request) (int32, error) {
+	limit := r.URL.Query().Get("limit")
+	if limit == "" {
+		return defaultPageSize, nil
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil || n < 1 {
+		return 0, errors.New("invalid limit")
+	}
+	return int32(min(n, maxPageSize)), nil
+}
+
+// writePaginationLinks adds an RFC 5988 Link header pointing at the next
+// page, for clients that page through headers rather than the body. baseURL
+// may already carry a query string. Nothing is written on the last page.
+func writePaginationLinks(w http.ResponseWriter, baseURL, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+	sep := "?"
+	if strings.Contains(baseURL, "?") {
+		sep = "&"
+	}
+	w.Header().Add("Link", "<"+baseURL+sep+"cursor="+url.QueryEscape(nextCursor)+`>; rel="next"`)
+}
+
+// encodeOffsetCursor is for ranked feeds whose ordering shifts over time,
+// where a keyset cursor cannot be used.
+func encodeOffsetCursor(offset int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset|" + strconv.Itoa(int(offset))))
+}
+
+func decodeOffsetCursor(cursor string) (int32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	n, ok := strings.CutPrefix(string(raw), "offset|")
+	if !ok {
+		return 0, errors.New("invalid cursor")
+	}
+	offset, err := strconv.Atoi(n)
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return int32(offset), nil
+}
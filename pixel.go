@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// transparentGIF is a 1×1 transparent GIF89a, the smallest valid one.
+var transparentGIF = []byte{
+	'G', 'I', 'F', '8', '9', 'a',
+	0x01, 0x00, 0x01, 0x00, // 1×1
+	0x80, 0x00, 0x00, // two-colour global palette
+	0x00, 0x00, 0x00, 0xff, 0xff, 0xff,
+	0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, // colour 0 is transparent
+	0x2c, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x00,
+	0x02, 0x02, 0x44, 0x01, 0x00,
+	0x3b,
+}
+
+// handlerChirpPixel serves a tracking pixel for chirps embedded off-site,
+// counting an impression and logging where it was loaded from. The image
+// is returned even for unknown chirps so a broken pixel never shows on a
+// page; the write happens in the background like recordImpressions.
+func (cfg *apiConfig) handlerChirpPixel(w http.ResponseWriter, r *http.Request) {
+	if chirpID, err := uuid.Parse(r.PathValue("chirpId")); err == nil {
+		arg := database.RecordPixelEventParams{
+			ChirpID:   chirpID,
+			Namespace: namespaceOf(r.Context()),
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+			CreatedAt: cfg.timeNow().UTC(),
+		}
+		ctx := context.WithoutCancel(r.Context())
+		cfg.impressions.Go(func() {
+			if err := cfg.db.RecordPixelEvent(ctx, arg); err != nil {
+				log.Printf("Error recording pixel event for chirp %s: %s", chirpID, err)
+			}
+		})
+	}
+	w.Header().Set("Content-Type", "image/gif")
+	w.Header().Set("Cache-Control", "no-store, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	w.Write(transparentGIF)
+}
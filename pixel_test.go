@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTransparentGIF(t *testing.T) {
+	if len(transparentGIF) != 43 {
+		t.Errorf("got %d bytes, want 43", len(transparentGIF))
+	}
+	img, err := gif.Decode(bytes.NewReader(transparentGIF))
+	if err != nil {
+		t.Fatalf("decoding pixel: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 1 || b.Dy() != 1 {
+		t.Errorf("got %v, want a 1×1 image", b)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("got alpha %d, want a transparent pixel", a)
+	}
+}
+
+func TestChirpPixel(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"hello"}`, token)
+
+	req := httptest.NewRequest("GET", "/pixel/chirps/"+chirp.ID.String(), nil)
+	req.Header.Set("Referer", "https://blog.example/post")
+	req.Header.Set("User-Agent", "test-agent")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	cfg.impressions.Wait()
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), transparentGIF) {
+		t.Fatalf("got status %d and %d bytes, want the transparent GIF", rec.Code, rec.Body.Len())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/gif" {
+		t.Errorf("got Content-Type %q, want image/gif", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "no-store, must-revalidate" {
+		t.Errorf("got Cache-Control %q", cc)
+	}
+	if store.chirps[0].ImpressionCount != 1 {
+		t.Errorf("got %d impressions, want 1", store.chirps[0].ImpressionCount)
+	}
+	if len(store.pixelEvents) != 1 {
+		t.Fatalf("got %d pixel events, want 1", len(store.pixelEvents))
+	}
+	if e := store.pixelEvents[0]; e.ChirpID != chirp.ID || e.Referer != "https://blog.example/post" || e.UserAgent != "test-agent" || e.CreatedAt.IsZero() {
+		t.Errorf("got event %+v", e)
+	}
+
+	// Unknown chirps still get an image but record nothing.
+	rec = serve(h, "GET", "/pixel/chirps/"+uuid.NewString(), "", "")
+	cfg.impressions.Wait()
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), transparentGIF) || len(store.pixelEvents) != 1 {
+		t.Errorf("got status %d with %d events for an unknown chirp", rec.Code, len(store.pixelEvents))
+	}
+}
+
+func TestChirpEmbedIncludesPixel(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example"
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"hello"}`, token)
+
+	html := serve(h, "GET", "/api/chirps/"+chirp.ID.String()+"/embed", "", "").Body.String()
+	if want := `<img src="https://chirpy.example/pixel/chirps/` + chirp.ID.String() + `"`; !strings.Contains(html, want) {
+		t.Errorf("embed missing pixel %s:\n%s", want, html)
+	}
+}
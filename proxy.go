@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// parsePrefixList parses a comma-separated list of CIDR blocks. Bare
+// addresses are accepted as single-host prefixes. what names the setting in
+// error messages.
+func parsePrefixList(s, what string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !strings.Contains(part, "/") {
+			addr, err := netip.ParseAddr(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %q: %w", what, part, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", what, part, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// parseTrustedProxies parses the comma-separated TRUSTED_PROXIES list.
+func parseTrustedProxies(s string) ([]netip.Prefix, error) {
+	return parsePrefixList(s, "trusted proxy")
+}
+
+// remoteAddr returns the address of the peer that opened the connection.
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func (cfg *apiConfig) isTrustedProxy(addr netip.Addr) bool {
+	for _, p := range cfg.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg *apiConfig) fromTrustedProxy(r *http.Request) bool {
+	addr, ok := remoteAddr(r)
+	return ok && cfg.isTrustedProxy(addr)
+}
+
+// realIP returns the client address for r. X-Forwarded-For is only read
+// when the request arrives from a trusted proxy, and is walked from the
+// right so a client cannot spoof its address by prepending entries.
+func realIP(r *http.Request, cfg *apiConfig) (netip.Addr, bool) {
+	addr, ok := remoteAddr(r)
+	if !ok || !cfg.isTrustedProxy(addr) {
+		return addr, ok
+	}
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !cfg.isTrustedProxy(addr) {
+			break
+		}
+	}
+	return addr, true
+}
+
+// requestBaseURL returns the scheme and host clients used to reach us, for
+// building absolute URLs. BASE_URL wins when set; otherwise the forwarded
+// headers are only honoured for requests arriving from a trusted proxy.
+func requestBaseURL(r *http.Request, cfg *apiConfig) string {
+	if cfg.baseURL != "" {
+		return cfg.baseURL
+	}
+	scheme, host := "http", r.Host
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if cfg.fromTrustedProxy(r) {
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if fwdHost := r.Header.Get("X-Forwarded-Host"); fwdHost != "" {
+			host = strings.TrimSpace(strings.Split(fwdHost, ",")[0])
+		}
+	}
+	return scheme + "://" + host
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestBaseURL(t *testing.T) {
+	proxies, err := parseTrustedProxies("127.0.0.0/8, ::1, 10.1.0.0/16")
+	if err != nil {
+		t.Fatalf("parseTrustedProxies failed: %v", err)
+	}
+	tests := []struct {
+		name       string
+		baseURL    string
+		remoteAddr string
+		proto      string
+		host       string
+		want       string
+	}{
+		{"direct request", "", "203.0.113.5:4000", "", "", "http://chirpy.test"},
+		{"untrusted proxy headers ignored", "", "203.0.113.5:4000", "https", "evil.example", "http://chirpy.test"},
+		{"trusted loopback proxy", "", "127.0.0.1:4000", "https", "chirpy.example", "https://chirpy.example"},
+		{"trusted ipv6 loopback proxy", "", "[::1]:4000", "https", "chirpy.example", "https://chirpy.example"},
+		{"trusted cidr proxy", "", "10.1.2.3:4000", "https", "a.example, b.example", "https://a.example"},
+		{"bogus proto ignored", "", "127.0.0.1:4000", "gopher", "", "http://chirpy.test"},
+		{"base url wins", "https://chirpy.example", "127.0.0.1:4000", "http", "other.example", "https://chirpy.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &apiConfig{baseURL: tt.baseURL, trustedProxies: proxies}
+			r := httptest.NewRequest("GET", "http://chirpy.test/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.proto != "" {
+				r.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if tt.host != "" {
+				r.Header.Set("X-Forwarded-Host", tt.host)
+			}
+			if got := requestBaseURL(r, cfg); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxiesInvalid(t *testing.T) {
+	if _, err := parseTrustedProxies("10.0.0.0/8,not-a-cidr"); err == nil {
+		t.Error("expected an error for an invalid CIDR")
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+const eventNotificationCreated = "notification.created"
+
+// notificationCreatedPayload is queued for each notification stored, to
+// push it to the recipient's devices. Namespace is the one the notification
+// was created in, which the job has no request to take it from.
+type notificationCreatedPayload struct {
+	Notification database.Notification
+	Namespace    string
+}
+
+func (notificationCreatedPayload) JobType() string { return eventNotificationCreated }
+
+var errFirebaseNotImplemented = errors.New("firebase push delivery is not implemented")
+
+// PushSender delivers a push notification to one device.
+type PushSender interface {
+	Send(ctx context.Context, deviceToken, title, body string) error
+}
+
+// NoopPushSender is used when no push provider is configured.
+type NoopPushSender struct{}
+
+func (NoopPushSender) Send(ctx context.Context, deviceToken, title, body string) error {
+	return nil
+}
+
+// FirebasePushSender will deliver through Firebase Cloud Messaging. It is
+// a stub until the FCM client is wired up.
+type FirebasePushSender struct {
+	serverKey string
+}
+
+func (f *FirebasePushSender) Send(ctx context.Context, deviceToken, title, body string) error {
+	return errFirebaseNotImplemented
+}
+
+// newPushSender picks Firebase when FIREBASE_SERVER_KEY is set.
+func newPushSender(firebaseServerKey string) PushSender {
+	if firebaseServerKey != "" {
+		return &FirebasePushSender{serverKey: firebaseServerKey}
+	}
+	return NoopPushSender{}
+}
+
+// pushVerbs completes "<actor> ..." for each notification type.
+var pushVerbs = map[database.NotificationType]string{
+	database.NotificationTypeLike:    "liked your chirp",
+	database.NotificationTypeReply:   "replied to your chirp",
+	database.NotificationTypeMention: "mentioned you",
+	database.NotificationTypeFollow:  "followed you",
+	database.NotificationTypeRepost:  "reposted your chirp",
+}
+
+// pushNotification sends a new notification to each of the recipient's
+// registered devices. A failed device is logged and the rest still get it;
+// only failing to look up who to send to is retried.
+func (cfg *apiConfig) pushNotification(ctx context.Context, p notificationCreatedPayload) error {
+	n := p.Notification
+	tokens, err := cfg.db.GetPushTokens(ctx, n.RecipientID)
+	if err != nil {
+		return fmt.Errorf("loading push tokens for %s: %w", n.RecipientID, err)
+	}
+	if len(tokens) == 0 {
+		return nil
+	}
+	namespace := p.Namespace
+	if namespace == "" {
+		// Queued before payloads carried a namespace.
+		namespace = defaultNamespace
+	}
+	actor, err := cfg.db.GetUserById(ctx, database.GetUserByIdParams{ID: n.ActorID, Namespace: namespace})
+	if err != nil {
+		return fmt.Errorf("loading actor %s for push: %w", n.ActorID, err)
+	}
+	body := actor.User.Email.String + " " + pushVerbs[n.Type]
+	for _, t := range tokens {
+		if err := cfg.pushSender.Send(ctx, t.DeviceToken, "Chirpy", body); err != nil {
+			log.Printf("push %s to %s device: %v", n.Type, t.Platform, err)
+		}
+	}
+	return nil
+}
+
+var pushPlatforms = []database.PushPlatform{database.PushPlatformIos, database.PushPlatformAndroid, database.PushPlatformWeb}
+
+type registerPushTokenParams struct {
+	DeviceToken string `json:"device_token"`
+	Platform    string `json:"platform"`
+}
+
+type pushTokenResp struct {
+	DeviceToken string    `json:"device_token"`
+	Platform    string    `json:"platform"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// handlerRegisterPushToken records a device to push the caller's
+// notifications to.
+func (cfg *apiConfig) handlerRegisterPushToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	params := registerPushTokenParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if params.DeviceToken == "" {
+		respondWithError(w, http.StatusBadRequest, "device_token is required")
+		return
+	}
+	// Apps that leave platform out get the one their User-Agent names.
+	if params.Platform == "" {
+		params.Platform = deviceInfoFromContext(r.Context()).Platform
+	}
+	platform := database.PushPlatform(params.Platform)
+	if !slices.Contains(pushPlatforms, platform) {
+		respondWithError(w, http.StatusBadRequest, "platform must be ios, android or web")
+		return
+	}
+	token, err := cfg.db.RegisterPushToken(r.Context(), database.RegisterPushTokenParams{
+		DeviceToken: params.DeviceToken,
+		UserID:      userId,
+		Platform:    platform,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, pushTokenResp{
+		DeviceToken: token.DeviceToken,
+		Platform:    string(token.Platform),
+		CreatedAt:   token.CreatedAt,
+	})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+)
+
+type pushCall struct {
+	deviceToken, title, body string
+}
+
+type mockPushSender struct {
+	mu    sync.Mutex
+	calls []pushCall
+}
+
+func (m *mockPushSender) Send(ctx context.Context, deviceToken, title, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, pushCall{deviceToken, title, body})
+	return nil
+}
+
+func TestRegisterPushToken(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+
+	tests := []struct {
+		body  string
+		token string
+		want  int
+	}{
+		{`{"device_token":"abc","platform":"ios"}`, "", http.StatusUnauthorized},
+		{`{"device_token":"","platform":"ios"}`, aliceToken, http.StatusBadRequest},
+		{`{"device_token":"abc","platform":"blackberry"}`, aliceToken, http.StatusBadRequest},
+		{`{"device_token":"abc","platform":"ios"}`, aliceToken, http.StatusCreated},
+		{`{"device_token":"def","platform":"web"}`, aliceToken, http.StatusCreated},
+		// Signing in to Bob's account on the same device moves the token.
+		{`{"device_token":"abc","platform":"ios"}`, bobToken, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		if rec := serve(h, "POST", "/api/users/me/push-tokens", tt.body, tt.token); rec.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.body, rec.Code, tt.want)
+		}
+	}
+	if got, _ := store.GetPushTokens(t.Context(), alice.ID); len(got) != 1 || got[0].DeviceToken != "def" {
+		t.Errorf("got alice's tokens %+v, want only def", got)
+	}
+	if got, _ := store.GetPushTokens(t.Context(), bob.ID); len(got) != 1 || got[0].DeviceToken != "abc" {
+		t.Errorf("got bob's tokens %+v, want only abc", got)
+	}
+}
+
+func TestNotificationsArePushed(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	sender := &mockPushSender{}
+	cfg.pushSender = sender
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	_, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	serve(h, "POST", "/api/users/me/push-tokens", `{"device_token":"alice-phone","platform":"ios"}`, aliceToken)
+	serve(h, "POST", "/api/users/me/push-tokens", `{"device_token":"alice-laptop","platform":"web"}`, aliceToken)
+	serve(h, "POST", "/api/users/me/push-tokens", `{"device_token":"bob-phone","platform":"android"}`, bobToken)
+
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	runJobs(t, cfg)
+	want := []pushCall{
+		{"alice-phone", "Chirpy", "bob@example.com followed you"},
+		{"alice-laptop", "Chirpy", "bob@example.com followed you"},
+	}
+	if !slices.Equal(sender.calls, want) {
+		t.Errorf("got pushes %+v, want %+v", sender.calls, want)
+	}
+
+	sender.calls = nil
+	postChirp(t, h, `{"body":"hey @alice@example.com"}`, bobToken)
+	runJobs(t, cfg)
+	if len(sender.calls) != 2 || sender.calls[0].body != "bob@example.com mentioned you" {
+		t.Errorf("got pushes %+v for a mention, want one per alice device", sender.calls)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimiter admits up to a fixed number of hits per key in each window.
+type RateLimiter interface {
+	Allow(key string) (allowed bool, remaining int, reset time.Time, err error)
+}
+
+// memoryRateLimiter keeps a sliding window per key in process memory, so
+// each app instance enforces the limit on its own.
+type memoryRateLimiter struct {
+	limit   int
+	windows *sync.Map
+	now     func() time.Time
+}
+
+func (m *memoryRateLimiter) Allow(key string) (bool, int, time.Time, error) {
+	v, _ := m.windows.LoadOrStore(key, &userRateWindow{})
+	ok, remaining, reset := v.(*userRateWindow).allow(m.now(), m.limit)
+	return ok, remaining, reset, nil
+}
+
+// redisTimeout bounds each Allow so a slow Redis cannot stall requests.
+const redisTimeout = 100 * time.Millisecond
+
+// RedisRateLimiter counts hits in fixed windows shared by every instance
+// pointed at the same Redis. Each window is one key, rl:{key}:{window
+// start}, that expires with the window.
+type RedisRateLimiter struct {
+	client *redis.Client
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+func NewRedisRateLimiter(client *redis.Client, limit int, window time.Duration, now func() time.Time) *RedisRateLimiter {
+	return &RedisRateLimiter{client: client, limit: limit, window: window, now: now}
+}
+
+func (l *RedisRateLimiter) Allow(key string) (bool, int, time.Time, error) {
+	start := l.now().Truncate(l.window)
+	reset := start.Add(l.window)
+	redisKey := fmt.Sprintf("rl:%s:%d", key, start.Unix())
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	pipe := l.client.TxPipeline()
+	incr := pipe.Incr(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, l.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, 0, reset, err
+	}
+	count := int(incr.Val())
+	return count <= l.limit, max(l.limit-count, 0), reset, nil
+}
+
+// rateLimitKey names what a limit applies to, as {type}:{id}.
+func rateLimitKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+// rateLimiter returns the limiter enforcing limit hits per
+// userRateLimitWindow: Redis-backed when REDIS_URL is set, in memory
+// otherwise. Limits vary by namespace, so there is one limiter per limit.
+func (cfg *apiConfig) rateLimiter(limit int) RateLimiter {
+	if v, ok := cfg.rateLimiters.Load(limit); ok {
+		return v.(RateLimiter)
+	}
+	var l RateLimiter = cfg.memoryRateLimiter(limit)
+	if cfg.redis != nil {
+		l = NewRedisRateLimiter(cfg.redis, limit, userRateLimitWindow, cfg.timeNow)
+	}
+	v, _ := cfg.rateLimiters.LoadOrStore(limit, l)
+	return v.(RateLimiter)
+}
+
+// memoryRateLimiter is the in-process limiter, used outright without
+// Redis and as the fallback when Redis fails.
+func (cfg *apiConfig) memoryRateLimiter(limit int) RateLimiter {
+	return &memoryRateLimiter{limit: limit, windows: &cfg.userRateLimits, now: cfg.timeNow}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	m := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: m.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return m, client
+}
+
+func TestRedisRateLimiter(t *testing.T) {
+	m, client := newTestRedis(t)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 30, 0, time.UTC)}
+	l := NewRedisRateLimiter(client, 3, time.Minute, clock.Now)
+	windowStart := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i, want := range []struct {
+		allowed   bool
+		remaining int
+	}{{true, 2}, {true, 1}, {true, 0}, {false, 0}} {
+		allowed, remaining, reset, err := l.Allow("user:alice")
+		if err != nil {
+			t.Fatalf("Allow failed: %v", err)
+		}
+		if allowed != want.allowed || remaining != want.remaining {
+			t.Errorf("hit %d: got %v with %d remaining, want %v with %d", i+1, allowed, remaining, want.allowed, want.remaining)
+		}
+		if !reset.Equal(windowStart.Add(time.Minute)) {
+			t.Errorf("hit %d: got reset %v, want the end of the window", i+1, reset)
+		}
+	}
+	key := fmt.Sprintf("rl:user:alice:%d", windowStart.Unix())
+	if got, err := m.Get(key); err != nil || got != "4" {
+		t.Errorf("got %s = %q (%v), want 4", key, got, err)
+	}
+	if ttl := m.TTL(key); ttl != time.Minute {
+		t.Errorf("got TTL %v, want the window", ttl)
+	}
+
+	// A second instance shares the count; the next window starts afresh.
+	other := NewRedisRateLimiter(client, 3, time.Minute, clock.Now)
+	if allowed, _, _, _ := other.Allow("user:alice"); allowed {
+		t.Error("another instance admitted a hit past the shared limit")
+	}
+	if allowed, _, _, _ := other.Allow("user:bob"); !allowed {
+		t.Error("bob was limited by alice's hits")
+	}
+	clock.t = clock.t.Add(time.Minute)
+	if allowed, remaining, _, _ := l.Allow("user:alice"); !allowed || remaining != 2 {
+		t.Errorf("next window: got %v with %d remaining, want allowed with 2", allowed, remaining)
+	}
+}
+
+func TestUserRateLimitRedisFallback(t *testing.T) {
+	m, client := newTestRedis(t)
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 2
+	cfg.redis = client
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"via redis"}`, token); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201", rec.Code)
+	}
+	if len(m.Keys()) != 1 {
+		t.Errorf("got Redis keys %v, want the user's window", m.Keys())
+	}
+
+	// With Redis gone the in-memory limiter takes over rather than failing
+	// the request.
+	m.Close()
+	for i, want := range []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests} {
+		rec := serve(h, "POST", "/api/chirps", fmt.Sprintf(`{"body":"fallback %d"}`, i), token)
+		if rec.Code != want {
+			t.Errorf("request %d without Redis: got status %d, want %d", i+1, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const maxRequestIDLen = 128
+
+// validRequestID reports whether a client-supplied X-Request-Id is short
+// printable ASCII, safe to echo back and log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// middlewareRequestID tags every response with an X-Request-Id, the
+// client's own when it sent a valid one and a new UUID otherwise, so a
+// response can be matched to its request in the logs.
+func middlewareRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-Id")
+		if !validRequestID(id) {
+			id = uuid.NewString()
+			r.Header.Set("X-Request-Id", id)
+		}
+		w.Header().Set("X-Request-Id", id)
+		next.ServeHTTP(w, r)
+	})
+}
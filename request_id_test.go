@@ -0,0 +1,33 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	for _, c := range []struct{ sent, want string }{
+		{"abc-123", "abc-123"},
+		{"", ""},
+		{"has space", ""},
+		{strings.Repeat("x", maxRequestIDLen+1), ""},
+	} {
+		req := httptest.NewRequest("GET", "/api/healthz", nil)
+		if c.sent != "" {
+			req.Header.Set("X-Request-Id", c.sent)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		got := rec.Header().Get("X-Request-Id")
+		if c.want != "" && got != c.want {
+			t.Errorf("sent %q: got %q, want it echoed", c.sent, got)
+		}
+		if c.want == "" && uuid.Validate(got) != nil {
+			t.Errorf("sent %q: got %q, want a new UUID", c.sent, got)
+		}
+	}
+}
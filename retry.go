@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+const (
+	// defaultMaxRetries is GET_RETRIES when it is unset: a GET that failed
+	// with 503 is tried again after 50ms, 100ms and 200ms.
+	defaultMaxRetries = 3
+	retryBaseDelay    = 50 * time.Millisecond
+)
+
+// retryWriter holds a response back until middlewareRetry decides whether
+// to send it or try again. A handler that flushes commits the response, and
+// is then never retried.
+type retryWriter struct {
+	w         http.ResponseWriter
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	committed bool
+}
+
+func newRetryWriter(w http.ResponseWriter) *retryWriter {
+	return &retryWriter{w: w, header: w.Header().Clone()}
+}
+
+func (rw *retryWriter) Header() http.Header {
+	if rw.committed {
+		return rw.w.Header()
+	}
+	return rw.header
+}
+
+func (rw *retryWriter) WriteHeader(code int) {
+	if rw.committed {
+		rw.w.WriteHeader(code)
+		return
+	}
+	if rw.status == 0 {
+		rw.status = code
+	}
+}
+
+func (rw *retryWriter) Write(p []byte) (int, error) {
+	if rw.committed {
+		return rw.w.Write(p)
+	}
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	return rw.body.Write(p)
+}
+
+func (rw *retryWriter) Flush() {
+	rw.commit()
+	http.NewResponseController(rw.w).Flush()
+}
+
+func (rw *retryWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := rw.w.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (rw *retryWriter) Unwrap() http.ResponseWriter { return rw.w }
+
+// commit sends what the handler has written so far to the client.
+func (rw *retryWriter) commit() {
+	if rw.committed {
+		return
+	}
+	rw.committed = true
+	clear(rw.w.Header())
+	maps.Copy(rw.w.Header(), rw.header)
+	if rw.status != 0 {
+		rw.w.WriteHeader(rw.status)
+	}
+	rw.w.Write(rw.body.Bytes())
+}
+
+// middlewareRetry retries GET and HEAD requests without a body when next
+// answers with one of the statuses in retryOn, up to maxRetries times with
+// exponential backoff, so a brief outage behind the API does not reach
+// the client. Other methods are never retried, and a maxRetries of zero
+// turns retrying off.
+func middlewareRetry(maxRetries int, retryOn []int, next http.Handler) http.Handler {
+	if maxRetries <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method != http.MethodGet && r.Method != http.MethodHead) || r.ContentLength > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for attempt := 0; ; attempt++ {
+			rw := newRetryWriter(w)
+			next.ServeHTTP(rw, r)
+			if rw.committed || attempt == maxRetries || !slices.Contains(retryOn, rw.status) {
+				rw.commit()
+				return
+			}
+			select {
+			case <-r.Context().Done():
+				rw.commit()
+				return
+			case <-time.After(retryBaseDelay << attempt):
+			}
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+// flakyHandler answers 503 for its first failures calls, then 200.
+type flakyHandler struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.calls++
+	w.Header().Set("X-Attempt", "set")
+	if f.calls <= f.failures {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
+
+func TestRetryRecoversGET(t *testing.T) {
+	inner := &flakyHandler{failures: 2}
+	h := middlewareRetry(3, []int{http.StatusServiceUnavailable}, inner)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/api/chirps", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("got %d %q, want 200 ok", rec.Code, rec.Body.String())
+	}
+	if inner.calls != 3 {
+		t.Errorf("got %d calls, want 3", inner.calls)
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "" {
+		t.Error("headers from a failed attempt leaked into the response")
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	inner := &flakyHandler{failures: 10}
+	h := middlewareRetry(2, []int{http.StatusServiceUnavailable}, inner)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("HEAD", "/api/chirps", nil))
+	if rec.Code != http.StatusServiceUnavailable || inner.calls != 3 {
+		t.Errorf("got %d after %d calls, want 503 after 3", rec.Code, inner.calls)
+	}
+}
+
+func TestRetrySkipsNonIdempotent(t *testing.T) {
+	for _, method := range []string{"POST", "PUT", "DELETE"} {
+		inner := &flakyHandler{failures: 1}
+		h := middlewareRetry(3, []int{http.StatusServiceUnavailable}, inner)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/api/chirps", nil))
+		if rec.Code != http.StatusServiceUnavailable || inner.calls != 1 {
+			t.Errorf("%s: got %d after %d calls, want 503 after 1", method, rec.Code, inner.calls)
+		}
+	}
+}
+
+func TestRetryThroughServer(t *testing.T) {
+	store := &failingStore{memStore: newMemStore(), err: &pq.Error{Code: "08006"}}
+	cfg := newTestConfig(store.memStore)
+	cfg.db = store
+	cfg.maxGetRetries = 1
+	h := newServer("0", cfg).Handler
+	if rec := serve(h, "GET", "/api/chirps", "", ""); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("got status %d, want 503 once retries run out", rec.Code)
+	}
+
+	store.err = nil
+	_, token := seedUser(t, cfg, store.memStore, "alice@example.com")
+	postChirp(t, h, `{"body":"streamed"}`, token)
+	req := httptest.NewRequest("GET", "/api/chirps", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !rec.Flushed {
+		t.Errorf("streamed GET: got %d, flushed %v", rec.Code, rec.Flushed)
+	}
+}
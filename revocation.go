@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bits-and-blooms/bloom/v3"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+const (
+	// revocationFilterCapacity is the fewest revocations the filter is
+	// sized for; it grows to twice the revoked count found at startup.
+	revocationFilterCapacity    = 100000
+	revocationFalsePositiveRate = 0.001
+)
+
+var errTokenRevoked = errors.New("token has been revoked")
+
+// revocationFilter is a bloom filter of revoked access token IDs. A token
+// the filter has never seen is certainly not revoked, so most requests
+// skip the revoked_tokens lookup; a possible member is confirmed against
+// the database. The zero value holds nothing.
+type revocationFilter struct {
+	mu     sync.RWMutex
+	filter *bloom.BloomFilter
+
+	// hits counts IDs the filter may contain, each costing a DB lookup,
+	// and misses those it ruled out. falsePositives counts hits the DB
+	// did not confirm.
+	hits, misses, falsePositives atomic.Int64
+}
+
+func newRevocationBloom(n int) *bloom.BloomFilter {
+	return bloom.NewWithEstimates(uint(max(2*n, revocationFilterCapacity)), revocationFalsePositiveRate)
+}
+
+func (f *revocationFilter) add(jti string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.filter == nil {
+		f.filter = newRevocationBloom(0)
+	}
+	f.filter.AddString(jti)
+}
+
+func (f *revocationFilter) mayContain(jti string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.filter != nil && f.filter.TestString(jti)
+}
+
+// loadRevokedTokens fills the filter from the unexpired rows of
+// revoked_tokens. It runs once at startup.
+func (cfg *apiConfig) loadRevokedTokens(ctx context.Context) error {
+	ids, err := cfg.db.GetRevokedTokenIDs(ctx)
+	if err != nil {
+		return err
+	}
+	filter := newRevocationBloom(len(ids))
+	for _, id := range ids {
+		filter.AddString(id)
+	}
+	f := &cfg.revokedTokens
+	f.mu.Lock()
+	f.filter = filter
+	f.mu.Unlock()
+	return nil
+}
+
+// revokeToken records an access token as revoked until it expires.
+func (cfg *apiConfig) revokeToken(ctx context.Context, claims auth.Claims) error {
+	if err := cfg.db.RevokeToken(ctx, database.RevokeTokenParams{
+		Jti:       claims.ID,
+		ExpiresAt: claims.ExpiresAt,
+	}); err != nil {
+		return err
+	}
+	cfg.revokedTokens.add(claims.ID)
+	return nil
+}
+
+// tokenRevoked reports whether the access token with ID jti was revoked,
+// consulting the database only when the filter cannot rule it out.
+func (cfg *apiConfig) tokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+	f := &cfg.revokedTokens
+	if !f.mayContain(jti) {
+		f.misses.Add(1)
+		return false, nil
+	}
+	f.hits.Add(1)
+	revoked, err := cfg.db.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	if !revoked {
+		f.falsePositives.Add(1)
+	}
+	return revoked, nil
+}
+
+// parseAccessToken validates an access token and checks it has not been
+// revoked.
+func (cfg *apiConfig) parseAccessToken(ctx context.Context, token string) (auth.Claims, error) {
+	_, stop := startPhase(ctx, "auth")
+	claims, err := auth.ParseJWT(token, cfg.tokenSecret)
+	stop()
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	revoked, err := cfg.tokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return auth.Claims{}, err
+	}
+	if revoked {
+		return auth.Claims{}, errTokenRevoked
+	}
+	return claims, nil
+}
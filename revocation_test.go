@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+func tokenID(t *testing.T, cfg *apiConfig, token string) string {
+	t.Helper()
+	claims, err := auth.ParseJWT(token, cfg.tokenSecret)
+	if err != nil {
+		t.Fatalf("ParseJWT failed: %v", err)
+	}
+	return claims.ID
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, token := seedUser(t, cfg, store, "alice@example.com")
+	other, err := auth.MakeJWT(alice.ID, cfg.tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+
+	if rec := serve(h, "GET", "/api/notifications", "", token); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d before revoking, want 200", rec.Code)
+	}
+	if store.revokedLookups != 0 || cfg.revokedTokens.misses.Load() != 1 {
+		t.Errorf("got %d lookups and %d misses, want the filter to rule the token out", store.revokedLookups, cfg.revokedTokens.misses.Load())
+	}
+	if rec := serve(h, "POST", "/api/revoke", "", token); rec.Code != http.StatusNoContent {
+		t.Fatalf("got status %d revoking, want 204", rec.Code)
+	}
+	if rec := serve(h, "GET", "/api/notifications", "", token); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d with a revoked token, want 401", rec.Code)
+	}
+	if rec := serve(h, "GET", "/api/notifications", "", other); rec.Code != http.StatusOK {
+		t.Errorf("got status %d with another of alice's tokens, want 200", rec.Code)
+	}
+	if got := cfg.revokedTokens.hits.Load(); got < 1 {
+		t.Errorf("got %d filter hits, want the revoked token to hit", got)
+	}
+}
+
+func TestRevocationFalsePositive(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	// Adding the ID to the filter alone stands in for a collision with
+	// some other revoked token.
+	cfg.revokedTokens.add(tokenID(t, cfg, token))
+
+	if rec := serve(h, "GET", "/api/notifications", "", token); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d for a false positive, want 200", rec.Code)
+	}
+	if store.revokedLookups != 1 {
+		t.Errorf("got %d DB lookups, want 1 to confirm the filter hit", store.revokedLookups)
+	}
+	if got := cfg.revokedTokens.falsePositives.Load(); got != 1 {
+		t.Errorf("got %d false positives, want 1", got)
+	}
+}
+
+func TestLoadRevokedTokens(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, revoked := seedUser(t, cfg, store, "alice@example.com")
+	_, live := seedUser(t, cfg, store, "bob@example.com")
+	store.revoked = []database.RevokedToken{
+		{Jti: tokenID(t, cfg, revoked), ExpiresAt: time.Now().Add(time.Hour)},
+		{Jti: tokenID(t, cfg, live), ExpiresAt: time.Now().Add(-time.Hour)},
+	}
+	if err := cfg.loadRevokedTokens(context.Background()); err != nil {
+		t.Fatalf("loadRevokedTokens failed: %v", err)
+	}
+
+	if !cfg.revokedTokens.mayContain(tokenID(t, cfg, revoked)) {
+		t.Error("filter is missing a revoked token loaded at startup")
+	}
+	if rec := serve(h, "GET", "/api/notifications", "", revoked); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d with a revoked token, want 401", rec.Code)
+	}
+}
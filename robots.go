@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"text/template"
+)
+
+var robotsTemplate = template.Must(template.New("robots").Parse(`User-agent: *
+Disallow: /api/
+Disallow: /admin/
+Disallow: /debug/
+Disallow: /share/
+Allow: /app/
+Allow: /sitemap.xml
+
+# Opt out of AI training crawlers.
+User-agent: GPTBot
+Disallow: /
+
+Sitemap: {{.BaseURL}}/sitemap.xml
+`))
+
+// handlerRobots tells crawlers which paths to index. ROBOTS_OVERRIDE_PATH
+// replaces the built-in rules with the contents of a file.
+func (cfg *apiConfig) handlerRobots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if cfg.robotsOverride != nil {
+		w.Write(cfg.robotsOverride)
+		return
+	}
+	if err := robotsTemplate.Execute(w, struct{ BaseURL string }{requestBaseURL(r, cfg)}); err != nil {
+		fmt.Println(err)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRobotsDefaults(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.baseURL = "https://chirpy.example"
+	rec := serve(newServer("0", cfg).Handler, "GET", "/robots.txt", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("got Content-Type %q, want text/plain", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"Disallow: /api/\n",
+		"Disallow: /admin/\n",
+		"Disallow: /debug/\n",
+		"Disallow: /share/\n",
+		"Allow: /app/\n",
+		"Allow: /sitemap.xml\n",
+		"User-agent: GPTBot\nDisallow: /\n",
+		"Sitemap: https://chirpy.example/sitemap.xml\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("robots.txt is missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestRobotsOverride(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.robotsOverride = []byte("User-agent: *\nDisallow: /\n")
+	rec := serve(newServer("0", cfg).Handler, "GET", "/robots.txt", "", "")
+	if got := rec.Body.String(); got != "User-agent: *\nDisallow: /\n" {
+		t.Errorf("got %q, want the override file", got)
+	}
+}
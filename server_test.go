@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/testutil"
+	"github.com/google/uuid"
+)
+
+// newHTTPTestServer serves a fresh in-memory Chirpy over a real listener.
+func newHTTPTestServer(t testing.TB) (*httptest.Server, *apiConfig, *memStore) {
+	t.Helper()
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	return testutil.NewTestServer(t, newServer("0", cfg).Handler), cfg, store
+}
+
+// do sends a request to srv and returns the status and body.
+func do(t testing.TB, srv *httptest.Server, method, path, body, authHeader string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	dat, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, dat
+}
+
+func authFor(t testing.TB, cfg *apiConfig, userID uuid.UUID) string {
+	return testutil.MakeAuthHeader(t, userID, []byte(cfg.tokenSecret), time.Hour)
+}
+
+func TestServerHealthz(t *testing.T) {
+	srv, _, _ := newHTTPTestServer(t)
+	code, body := do(t, srv, "GET", "/api/healthz", "", "")
+	if code != http.StatusOK || string(body) != "OK" {
+		t.Errorf("got %d %q, want 200 \"OK\"", code, body)
+	}
+}
+
+func TestServerCreateUser(t *testing.T) {
+	srv, _, _ := newHTTPTestServer(t)
+	code, body := do(t, srv, "POST", "/api/users", `{"email":"new@example.com","password":"pw"}`, "")
+	if code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201", code)
+	}
+	var u userResp
+	if err := json.Unmarshal(body, &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "new@example.com" || u.ID == uuid.Nil {
+		t.Errorf("got user %+v", u)
+	}
+}
+
+func TestServerLoginAndRefresh(t *testing.T) {
+	srv, _, _ := newHTTPTestServer(t)
+	do(t, srv, "POST", "/api/users", `{"email":"a@example.com","password":"secret"}`, "")
+	code, body := do(t, srv, "POST", "/api/login", `{"email":"a@example.com","password":"secret"}`, "")
+	if code != http.StatusOK {
+		t.Fatalf("login: got status %d, want 200", code)
+	}
+	var login userResp
+	if err := json.Unmarshal(body, &login); err != nil {
+		t.Fatal(err)
+	}
+	if login.Token == "" || login.RefreshToken == "" {
+		t.Fatalf("login returned no tokens: %s", body)
+	}
+	if code, _ := do(t, srv, "POST", "/api/refresh", "", "Bearer "+login.RefreshToken); code != http.StatusOK {
+		t.Errorf("refresh: got status %d, want 200", code)
+	}
+}
+
+func TestServerLoginWrongPassword(t *testing.T) {
+	srv, _, _ := newHTTPTestServer(t)
+	do(t, srv, "POST", "/api/users", `{"email":"a@example.com","password":"secret"}`, "")
+	if code, _ := do(t, srv, "POST", "/api/login", `{"email":"a@example.com","password":"nope"}`, ""); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", code)
+	}
+}
+
+func TestServerRevokedRefreshToken(t *testing.T) {
+	srv, _, _ := newHTTPTestServer(t)
+	do(t, srv, "POST", "/api/users", `{"email":"a@example.com","password":"secret"}`, "")
+	_, body := do(t, srv, "POST", "/api/login", `{"email":"a@example.com","password":"secret"}`, "")
+	var login userResp
+	if err := json.Unmarshal(body, &login); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := do(t, srv, "POST", "/api/revoke", "", "Bearer "+login.RefreshToken); code != http.StatusNoContent {
+		t.Fatalf("revoke: got status %d, want 204", code)
+	}
+	if code, _ := do(t, srv, "POST", "/api/refresh", "", "Bearer "+login.RefreshToken); code != http.StatusUnauthorized {
+		t.Errorf("refresh after revoke: got status %d, want 401", code)
+	}
+}
+
+func TestServerExpiredToken(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	expired := testutil.MakeAuthHeader(t, u.ID, []byte(cfg.tokenSecret), -time.Minute)
+	if code, _ := do(t, srv, "POST", "/api/chirps", `{"body":"hi"}`, expired); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", code)
+	}
+}
+
+func TestServerTokenWrongSecret(t *testing.T) {
+	srv, _, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	forged := testutil.MakeAuthHeader(t, u.ID, []byte("not-the-secret"), time.Hour)
+	if code, _ := do(t, srv, "POST", "/api/chirps", `{"body":"hi"}`, forged); code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", code)
+	}
+}
+
+func TestServerCreateChirp(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	code, body := do(t, srv, "POST", "/api/chirps", `{"body":"this is a kerfuffle"}`, authFor(t, cfg, u.ID))
+	if code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201", code)
+	}
+	var c chirpResp
+	if err := json.Unmarshal(body, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Body != "this is a ****" || c.UserId != u.ID.String() {
+		t.Errorf("got chirp %+v", c)
+	}
+}
+
+func TestServerChirpTooLong(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	body := `{"body":"` + strings.Repeat("a", 141) + `"}`
+	if code, _ := do(t, srv, "POST", "/api/chirps", body, authFor(t, cfg, u.ID)); code != http.StatusBadRequest {
+		t.Errorf("got status %d, want 400", code)
+	}
+}
+
+func TestServerDeleteChirp(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	author := testutil.MustCreateUser(t, store)
+	other := testutil.MustCreateUser(t, store)
+	_, body := do(t, srv, "POST", "/api/chirps", `{"body":"delete me"}`, authFor(t, cfg, author.ID))
+	var c chirpResp
+	if err := json.Unmarshal(body, &c); err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/chirps/" + c.ID.String()
+	if code, _ := do(t, srv, "DELETE", path, "", authFor(t, cfg, other.ID)); code != http.StatusForbidden {
+		t.Errorf("non-author delete: got status %d, want 403", code)
+	}
+	if code, _ := do(t, srv, "DELETE", path, "", authFor(t, cfg, author.ID)); code != http.StatusNoContent {
+		t.Errorf("author delete: got status %d, want 204", code)
+	}
+	if code, _ := do(t, srv, "GET", path, "", ""); code != http.StatusNotFound {
+		t.Errorf("get after delete: got status %d, want 404", code)
+	}
+}
+
+func TestServerUpdateUser(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	code, body := do(t, srv, "PUT", "/api/users", `{"email":"renamed@example.com","password":"pw"}`, authFor(t, cfg, u.ID))
+	if code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", code)
+	}
+	var got userResp
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != u.ID || got.Email != u.Email.String || got.PendingEmail != "renamed@example.com" {
+		t.Errorf("got user %+v, want the email change pending", got)
+	}
+}
+
+func TestServerPolkaWebhook(t *testing.T) {
+	srv, cfg, store := newHTTPTestServer(t)
+	u := testutil.MustCreateUser(t, store)
+	body := `{"event":"user.upgraded","data":{"user_id":"` + u.ID.String() + `"}}`
+	if code, _ := do(t, srv, "POST", "/api/polka/webhooks", body, "ApiKey wrong"); code != http.StatusUnauthorized {
+		t.Errorf("bad key: got status %d, want 401", code)
+	}
+	if code, _ := do(t, srv, "POST", "/api/polka/webhooks", body, "ApiKey "+cfg.polkaKey); code != http.StatusNoContent {
+		t.Fatalf("got status %d, want 204", code)
+	}
+	if !store.userByID(u.ID).IsChirpyRed {
+		t.Error("user was not upgraded")
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// timingContext accumulates how long a request spent in each named phase,
+// in the order the phases were first seen.
+type timingContext struct {
+	mu     sync.Mutex
+	names  []string
+	phases map[string]time.Duration
+}
+
+func newTimingContext() *timingContext {
+	return &timingContext{phases: make(map[string]time.Duration)}
+}
+
+// start begins timing one occurrence of a phase. Calling the returned func
+// adds the elapsed time to the phase's total.
+func (t *timingContext) start(name string) func() {
+	began := time.Now()
+	return func() {
+		elapsed := time.Since(began)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if _, ok := t.phases[name]; !ok {
+			t.names = append(t.names, name)
+		}
+		t.phases[name] += elapsed
+	}
+}
+
+// header formats the totals as a Server-Timing value, in milliseconds.
+func (t *timingContext) header() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	metrics := make([]string, 0, len(t.names))
+	for _, name := range t.names {
+		ms := float64(t.phases[name].Microseconds()) / 1000
+		metrics = append(metrics, name+";dur="+strconv.FormatFloat(ms, 'f', -1, 64))
+	}
+	return strings.Join(metrics, ", ")
+}
+
+type timingContextKey struct{}
+
+func withTimingContext(ctx context.Context, t *timingContext) context.Context {
+	return context.WithValue(ctx, timingContextKey{}, t)
+}
+
+// startPhase times a phase of the request ctx belongs to, returning ctx for
+// the phase's own calls and a func that ends the phase. It does nothing when
+// the request is not being timed.
+func startPhase(ctx context.Context, name string) (context.Context, func()) {
+	t, ok := ctx.Value(timingContextKey{}).(*timingContext)
+	if !ok {
+		return ctx, func() {}
+	}
+	return ctx, t.start(name)
+}
+
+// startWriterPhase is startPhase for code that has the ResponseWriter but
+// not the request, such as respondWithJSON.
+func startWriterPhase(w http.ResponseWriter, name string) func() {
+	for w != nil {
+		if tw, ok := w.(*timingWriter); ok {
+			return tw.timings.start(name)
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = u.Unwrap()
+	}
+	return func() {}
+}
+
+// timingWriter adds the Server-Timing header as the response headers are
+// written, after the handler has done its work.
+type timingWriter struct {
+	http.ResponseWriter
+	timings     *timingContext
+	wroteHeader bool
+}
+
+func (w *timingWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if v := w.timings.header(); v != "" {
+			w.Header().Set("Server-Timing", v)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Push forwards to the underlying writer, which a type assertion on the
+// wrapper would otherwise hide.
+func (w *timingWriter) Push(target string, opts *http.PushOptions) error {
+	if p, ok := w.ResponseWriter.(http.Pusher); ok {
+		return p.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+func (w *timingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// middlewareServerTiming times requests and reports the phases in a
+// Server-Timing header when EXPOSE_TIMING is set.
+func (cfg *apiConfig) middlewareServerTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.exposeTiming {
+			next.ServeHTTP(w, r)
+			return
+		}
+		timings := newTimingContext()
+		next.ServeHTTP(&timingWriter{ResponseWriter: w, timings: timings}, r.WithContext(withTimingContext(r.Context(), timings)))
+	})
+}
+
+// validateJWT returns the user an access token was issued to, rejecting
+// revoked tokens. Verifying the signature is timed as the request's auth
+// phase; see parseAccessToken.
+func (cfg *apiConfig) validateJWT(ctx context.Context, token string) (uuid.UUID, error) {
+	claims, err := cfg.parseAccessToken(ctx, token)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// timedDB times the queries sqlc issues as the request's db phase.
+type timedDB struct {
+	db *sql.DB
+}
+
+func (t timedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, stop := startPhase(ctx, "db")
+	defer stop()
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t timedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, stop := startPhase(ctx, "db")
+	defer stop()
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t timedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, stop := startPhase(ctx, "db")
+	defer stop()
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t timedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, stop := startPhase(ctx, "db")
+	defer stop()
+	return t.db.QueryRowContext(ctx, query, args...)
+}
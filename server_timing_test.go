@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var serverTimingRE = regexp.MustCompile(`^(\w+);dur=(\d+(?:\.\d+)?)$`)
+
+// parseServerTiming checks v is a well-formed Server-Timing value and
+// returns its durations by name.
+func parseServerTiming(t *testing.T, v string) map[string]float64 {
+	t.Helper()
+	durs := map[string]float64{}
+	for _, metric := range strings.Split(v, ", ") {
+		m := serverTimingRE.FindStringSubmatch(metric)
+		if m == nil {
+			t.Fatalf("malformed Server-Timing metric %q in %q", metric, v)
+		}
+		durs[m[1]], _ = strconv.ParseFloat(m[2], 64)
+	}
+	return durs
+}
+
+func TestServerTimingHeader(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.exposeTiming = true
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	postChirp(t, h, `{"body":"timed"}`, token)
+
+	rec := serve(h, "GET", "/api/feed", "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	durs := parseServerTiming(t, rec.Header().Get("Server-Timing"))
+	for _, phase := range []string{"auth", "marshal"} {
+		if _, ok := durs[phase]; !ok {
+			t.Errorf("got phases %v, want %s", durs, phase)
+		}
+	}
+
+	cfg.exposeTiming = false
+	if v := serve(h, "GET", "/api/feed", "", token).Header().Get("Server-Timing"); v != "" {
+		t.Errorf("got Server-Timing %q with EXPOSE_TIMING off, want none", v)
+	}
+}
+
+func TestServerTimingPhaseDurations(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.exposeTiming = true
+	h := cfg.middlewareServerTiming(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for range 2 {
+			_, stop := startPhase(r.Context(), "db")
+			time.Sleep(2 * time.Millisecond)
+			stop()
+		}
+		respondWithJSON(w, http.StatusOK, map[string]string{"ok": "yes"})
+	}))
+	rec := serve(h, "GET", "/", "", "")
+	v := rec.Header().Get("Server-Timing")
+	if !strings.HasPrefix(v, "db;dur=") {
+		t.Fatalf("got Server-Timing %q, want db first", v)
+	}
+	durs := parseServerTiming(t, v)
+	if durs["db"] < 4 {
+		t.Errorf("got db %vms, want both phases summed to at least 4ms", durs["db"])
+	}
+	if durs["marshal"] <= 0 {
+		t.Errorf("got marshal %vms, want a positive duration", durs["marshal"])
+	}
+}
+
+func TestStartPhaseWithoutTiming(t *testing.T) {
+	ctx := context.Background()
+	got, stop := startPhase(ctx, "db")
+	stop()
+	if got != ctx {
+		t.Error("startPhase changed an untimed context")
+	}
+}
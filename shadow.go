@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"hash"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+)
+
+// shadowHandler mirrors a sample of requests to a second handler so a
+// replacement can be compared against the live implementation.
+type shadowHandler struct {
+	primary    http.Handler
+	shadow     http.Handler
+	sampleRate float64
+	// wg tracks in-flight shadow requests.
+	wg sync.WaitGroup
+}
+
+// shadowMiddleware serves every request from primary and, for a sampleRate
+// fraction of them, replays a copy against shadow in the background once
+// primary is done. The shadow response is discarded; a status or body that
+// differs from primary's is logged as a warning.
+func shadowMiddleware(primary, shadow http.Handler, sampleRate float64) http.Handler {
+	return &shadowHandler{primary: primary, shadow: shadow, sampleRate: sampleRate}
+}
+
+func (h *shadowHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.sampleRate <= 0 || rand.Float64() >= h.sampleRate {
+		h.primary.ServeHTTP(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		// The client is gone or sent a broken body; let primary see the
+		// same failure and skip the comparison.
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		h.primary.ServeHTTP(w, r)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	shadowReq := r.Clone(context.WithoutCancel(r.Context()))
+	shadowReq.Body = io.NopCloser(bytes.NewReader(body))
+
+	rec := &hashingRecorder{ResponseWriter: w, status: http.StatusOK, sum: sha256.New()}
+	h.primary.ServeHTTP(rec, r)
+	primaryStatus, primarySum := rec.status, rec.sum.Sum(nil)
+
+	h.wg.Go(func() {
+		out := &discardRecorder{header: http.Header{}, status: http.StatusOK, sum: sha256.New()}
+		h.shadow.ServeHTTP(out, shadowReq)
+		bodyDiffers := !bytes.Equal(out.sum.Sum(nil), primarySum)
+		if out.status != primaryStatus || bodyDiffers {
+			slog.Warn("shadow response differs",
+				"method", shadowReq.Method,
+				"path", shadowReq.URL.Path,
+				"primary_status", primaryStatus,
+				"shadow_status", out.status,
+				"body_differs", bodyDiffers,
+			)
+		}
+	})
+}
+
+// hashingRecorder passes a response through while recording its status and
+// a hash of its body.
+type hashingRecorder struct {
+	http.ResponseWriter
+	status int
+	sum    hash.Hash
+}
+
+func (r *hashingRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *hashingRecorder) Write(b []byte) (int, error) {
+	r.sum.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *hashingRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// discardRecorder is the shadow handler's ResponseWriter, keeping only the
+// status and a hash of the body.
+type discardRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	sum         hash.Hash
+}
+
+func (r *discardRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *discardRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
+func (r *discardRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.sum.Write(b)
+}
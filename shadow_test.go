@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShadowReturnsPrimaryResponse(t *testing.T) {
+	var shadowBodies atomic.Int32
+	primary := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("primary:" + string(body)))
+	})
+	shadow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if body, _ := io.ReadAll(r.Body); string(body) == "hello" {
+			shadowBodies.Add(1)
+		}
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("shadow"))
+	})
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	h := shadowMiddleware(primary, shadow, 1)
+	for range 5 {
+		rec := serve(h, "POST", "/api/chirps", "hello", "")
+		if rec.Code != http.StatusCreated || rec.Body.String() != "primary:hello" {
+			t.Fatalf("got %d %q, want the primary response", rec.Code, rec.Body.String())
+		}
+	}
+	h.(*shadowHandler).wg.Wait()
+	if got := shadowBodies.Load(); got != 5 {
+		t.Errorf("shadow saw the request body %d times, want 5", got)
+	}
+	out := logs.String()
+	if strings.Count(out, "shadow response differs") != 5 || !strings.Contains(out, "level=WARN") ||
+		!strings.Contains(out, "primary_status=201") || !strings.Contains(out, "shadow_status=418") {
+		t.Errorf("got logs %q, want a warning per request with both statuses", out)
+	}
+}
+
+func TestShadowMatchingResponseIsNotLogged(t *testing.T) {
+	same := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("same"))
+	})
+	var logs bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	h := shadowMiddleware(same, same, 1)
+	serve(h, "GET", "/api/chirps", "", "")
+	h.(*shadowHandler).wg.Wait()
+	if logs.Len() != 0 {
+		t.Errorf("got logs %q for matching responses, want none", logs.String())
+	}
+}
+
+func TestShadowSampleRate(t *testing.T) {
+	primary := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range []struct {
+		rate     float64
+		min, max int32
+	}{
+		{0, 0, 0},
+		{0.25, 400, 600},
+		{1, 2000, 2000},
+	} {
+		var calls atomic.Int32
+		shadow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls.Add(1) })
+		h := shadowMiddleware(primary, shadow, tt.rate)
+		for range 2000 {
+			serve(h, "GET", "/", "", "")
+		}
+		h.(*shadowHandler).wg.Wait()
+		if got := calls.Load(); got < tt.min || got > tt.max {
+			t.Errorf("rate %v: shadow called %d times in 2000, want %d-%d", tt.rate, got, tt.min, tt.max)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	shortCodeLen      = 6
+	shortCodeAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	// maxShortCodeAttempts bounds the retries on a code collision; at 62^6
+	// codes a second collision in a row means something else is wrong.
+	maxShortCodeAttempts = 5
+)
+
+type shortLinkResp struct {
+	ShortURL string `json:"short_url"`
+}
+
+// randomShortCode returns shortCodeLen random base62 characters.
+func randomShortCode() (string, error) {
+	code := make([]byte, shortCodeLen)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(shortCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func (cfg *apiConfig) newShortCode() (string, error) {
+	if cfg.shortCode != nil {
+		return cfg.shortCode()
+	}
+	return randomShortCode()
+}
+
+// handlerCreateShortLink gives a chirp the caller can see a new short link.
+// Each call makes a fresh code; codes are never reused across chirps.
+func (cfg *apiConfig) handlerCreateShortLink(w http.ResponseWriter, r *http.Request) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	userId := user.ID
+	chirpId, err := uuid.Parse(r.PathValue("chirpId"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid chirp id")
+		return
+	}
+	chirp, visible, err := cfg.visibleChirp(r, chirpId, userId)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "chirp not found")
+		return
+	}
+
+	for range maxShortCodeAttempts {
+		code, err := cfg.newShortCode()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		link, err := cfg.db.CreateShortLink(r.Context(), database.CreateShortLinkParams{
+			Code:      code,
+			ChirpID:   uuid.NullUUID{UUID: chirp.Chirp.ID, Valid: true},
+			CreatedBy: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// CreateShortLink returns nothing when the code is taken.
+			continue
+		}
+		if err != nil {
+			cfg.respondWithDBError(w, err)
+			return
+		}
+		respondWithJSON(w, http.StatusCreated, shortLinkResp{ShortURL: requestBaseURL(r, cfg) + "/s/" + link.Code})
+		return
+	}
+	log.Printf("Error creating short link for chirp %s: %d code collisions in a row", chirp.Chirp.ID, maxShortCodeAttempts)
+	respondWithError(w, http.StatusServiceUnavailable, "could not allocate a short link, try again")
+}
+
+// handlerFollowShortLink counts a click on a short link and redirects to the
+// chirp's share page, which decides what the visitor may see, or to the URL
+// an auto-generated link stands in for.
+func (cfg *apiConfig) handlerFollowShortLink(w http.ResponseWriter, r *http.Request) {
+	link, err := cfg.db.ClickShortLink(r.Context(), r.PathValue("code"))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if link.TargetUrl.Valid {
+		http.Redirect(w, r, link.TargetUrl.String, http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, requestBaseURL(r, cfg)+"/share/chirps/"+link.ChirpID.UUID.String(), http.StatusFound)
+}
+
+// autoShortenMinLen is the length above which a URL in a chirp body is
+// swapped for a short link.
+const autoShortenMinLen = 23
+
+// chirpURLPattern matches the http(s) URLs in a chirp body.
+var chirpURLPattern = regexp.MustCompile(`https?://[^\s]+`)
+
+type expandedURL struct {
+	Short    string `json:"short"`
+	Original string `json:"original"`
+}
+
+// longURLs returns the distinct URLs in body that are worth shortening, in
+// the order they first appear. Shortening needs BASE_URL so the links in
+// stored bodies are absolute; without it there are none.
+func (cfg *apiConfig) longURLs(body string) []string {
+	if cfg.baseURL == "" {
+		return nil
+	}
+	var urls []string
+	for _, u := range chirpURLPattern.FindAllString(body, -1) {
+		if len(u) > autoShortenMinLen && !slices.Contains(urls, u) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+// shortURLFor is the short form of the link with code.
+func (cfg *apiConfig) shortURLFor(code string) string {
+	return cfg.baseURL + "/s/" + code
+}
+
+// shortenedLen is the length body will have once longURLs are shortened,
+// which is what counts against the chirp length limit.
+func (cfg *apiConfig) shortenedLen(body string, urls []string) int {
+	n := len(body)
+	short := len(cfg.shortURLFor(strings.Repeat("x", shortCodeLen)))
+	for _, u := range urls {
+		n -= strings.Count(body, u) * (len(u) - short)
+	}
+	return n
+}
+
+// shortenURLs makes an auto-generated short link for each of urls and
+// swaps every occurrence in body for it, so a URL repeated in one chirp
+// shares a code. It returns the new body and the codes made, which
+// AttachShortLinksToChirp ties to the chirp once it is stored.
+func (cfg *apiConfig) shortenURLs(ctx context.Context, userId uuid.UUID, body string, urls []string) (string, []string, error) {
+	codes := make([]string, 0, len(urls))
+	replacements := make([]string, 0, 2*len(urls))
+	for _, u := range urls {
+		code, err := cfg.createAutoShortLink(ctx, userId, u)
+		if err != nil {
+			return "", nil, err
+		}
+		codes = append(codes, code)
+		replacements = append(replacements, u, cfg.shortURLFor(code))
+	}
+	return strings.NewReplacer(replacements...).Replace(body), codes, nil
+}
+
+func (cfg *apiConfig) createAutoShortLink(ctx context.Context, userId uuid.UUID, target string) (string, error) {
+	for range maxShortCodeAttempts {
+		code, err := cfg.newShortCode()
+		if err != nil {
+			return "", err
+		}
+		link, err := cfg.db.CreateAutoShortLink(ctx, database.CreateAutoShortLinkParams{
+			Code:      code,
+			TargetUrl: sql.NullString{String: target, Valid: true},
+			CreatedBy: userId,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return link.Code, nil
+	}
+	return "", fmt.Errorf("%d short code collisions in a row", maxShortCodeAttempts)
+}
+
+// attachExpandedURLs fills in the auto-generated short links in each
+// chirp's body along with the URLs they stand in for.
+func (cfg *apiConfig) attachExpandedURLs(ctx context.Context, chirps []chirpResp) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, 0, len(chirps))
+	for _, c := range chirps {
+		ids = append(ids, c.ID)
+	}
+	rows, err := cfg.db.GetExpandedURLsForChirps(ctx, ids)
+	if err != nil {
+		return err
+	}
+	byChirp := make(map[uuid.UUID][]expandedURL)
+	for _, l := range rows {
+		byChirp[l.ChirpID.UUID] = append(byChirp[l.ChirpID.UUID], expandedURL{
+			Short:    cfg.shortURLFor(l.Code),
+			Original: l.TargetUrl.String,
+		})
+	}
+	for i := range chirps {
+		chirps[i].ExpandedURLs = byChirp[chirps[i].ID]
+	}
+	return nil
+}
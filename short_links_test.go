@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func shareChirp(t *testing.T, h http.Handler, id, token string) string {
+	t.Helper()
+	rec := serve(h, "POST", "/api/chirps/"+id+"/share", "", token)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp shortLinkResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.ShortURL
+}
+
+func TestShortLinkRedirect(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example.com"
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"share me"}`, alice)
+
+	shortURL := shareChirp(t, h, chirp.ID.String(), alice)
+	if !regexp.MustCompile(`^https://chirpy\.example\.com/s/[0-9A-Za-z]{6}$`).MatchString(shortURL) {
+		t.Fatalf("got short_url %q", shortURL)
+	}
+	path := strings.TrimPrefix(shortURL, cfg.baseURL)
+
+	for range 2 {
+		rec := serve(h, "GET", path, "", "")
+		if rec.Code != http.StatusFound {
+			t.Fatalf("got status %d, want 302", rec.Code)
+		}
+		if got, want := rec.Header().Get("Location"), "https://chirpy.example.com/share/chirps/"+chirp.ID.String(); got != want {
+			t.Errorf("got Location %q, want %q", got, want)
+		}
+	}
+	if got := store.shortLinks[0].ClickCount; got != 2 {
+		t.Errorf("got click_count %d, want 2", got)
+	}
+
+	if rec := serve(h, "GET", "/s/nope00", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d for an unknown code, want 404", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps/"+chirp.ID.String()+"/share", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d without a token, want 401", rec.Code)
+	}
+}
+
+func TestShortLinkCollisionRetry(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	chirp := postChirp(t, h, `{"body":"share me twice"}`, alice)
+
+	codes := []string{"AAAAAA", "AAAAAA", "AAAAAA", "BBBBBB"}
+	cfg.shortCode = func() (string, error) {
+		code := codes[0]
+		codes = codes[1:]
+		return code, nil
+	}
+	if got := shareChirp(t, h, chirp.ID.String(), alice); !strings.HasSuffix(got, "/s/AAAAAA") {
+		t.Fatalf("got short_url %q, want code AAAAAA", got)
+	}
+	if got := shareChirp(t, h, chirp.ID.String(), alice); !strings.HasSuffix(got, "/s/BBBBBB") {
+		t.Errorf("got short_url %q, want the retry to land on BBBBBB", got)
+	}
+	if len(codes) != 0 {
+		t.Errorf("%d codes left unused", len(codes))
+	}
+
+	cfg.shortCode = func() (string, error) { return "AAAAAA", nil }
+	if rec := serve(h, "POST", "/api/chirps/"+chirp.ID.String()+"/share", "", alice); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d when every code collides, want 503", rec.Code)
+	}
+}
+
+func TestAutoShortenLongURLs(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example.com"
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	long := "https://example.com/articles/2026/a-rather-long-slug"
+	short := "https://go.dev/x"
+	body := "read " + long + " and " + long + " not " + short
+	chirp := postChirp(t, h, `{"body":"`+body+`"}`, alice)
+
+	if len(chirp.ExpandedURLs) != 1 {
+		t.Fatalf("got expanded_urls %+v, want one entry", chirp.ExpandedURLs)
+	}
+	got := chirp.ExpandedURLs[0]
+	if got.Original != long || !regexp.MustCompile(`^https://chirpy\.example\.com/s/[0-9A-Za-z]{6}$`).MatchString(got.Short) {
+		t.Fatalf("got expanded url %+v", got)
+	}
+	if want := "read " + got.Short + " and " + got.Short + " not " + short; chirp.Body != want {
+		t.Errorf("got body %q, want %q", chirp.Body, want)
+	}
+	if stored := store.chirps[0].Body.String; stored != chirp.Body {
+		t.Errorf("stored body %q, want the shortened %q", stored, chirp.Body)
+	}
+
+	rec := serve(h, "GET", "/api/chirps/"+chirp.ID.String(), "", "")
+	var fetched chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &fetched); err != nil {
+		t.Fatal(err)
+	}
+	if len(fetched.ExpandedURLs) != 1 || fetched.ExpandedURLs[0] != got {
+		t.Errorf("got expanded_urls %+v on GET, want [%+v]", fetched.ExpandedURLs, got)
+	}
+
+	rec = serve(h, "GET", strings.TrimPrefix(got.Short, cfg.baseURL), "", "")
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != long {
+		t.Errorf("got %d to %q, want 302 to %q", rec.Code, rec.Header().Get("Location"), long)
+	}
+}
+
+func TestAutoShortenLeavesShortURLs(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example.com"
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	body := "see https://go.dev/doc/effe"
+	chirp := postChirp(t, h, `{"body":"`+body+`"}`, alice)
+	if chirp.Body != body || chirp.ExpandedURLs != nil {
+		t.Errorf("got body %q with expanded_urls %+v, want it untouched", chirp.Body, chirp.ExpandedURLs)
+	}
+	if len(store.shortLinks) != 0 {
+		t.Errorf("got %d short links, want none", len(store.shortLinks))
+	}
+}
+
+func TestAutoShortenCountsShortFormTowardLimit(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example.com"
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+
+	body := "https://example.com/" + strings.Repeat("a", 130)
+	chirp := postChirp(t, h, `{"body":"`+body+`"}`, alice)
+	if len(chirp.Body) > 140 {
+		t.Errorf("got body of %d characters, want it shortened", len(chirp.Body))
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/azs06/Chirpy/internal/httpsig"
+	"github.com/google/uuid"
+)
+
+// signedRequestTokenTTL bounds the access token a verified signature is
+// exchanged for; it only has to outlive the one request.
+const signedRequestTokenTTL = time.Minute
+
+type createSigningKeyParams struct {
+	KeyID string `json:"key_id"`
+	// PublicKey is the raw 32-byte Ed25519 key, base64 encoded.
+	PublicKey string    `json:"public_key"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+type signingKeyResp struct {
+	KeyID     string    `json:"key_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// handlerCreateSigningKey registers a backend service's public key.
+// Requests signed with it act as user_id.
+func (cfg *apiConfig) handlerCreateSigningKey(w http.ResponseWriter, r *http.Request) {
+	adminId, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+	var params createSigningKeyParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	params.KeyID = strings.TrimSpace(params.KeyID)
+	if params.KeyID == "" {
+		respondWithError(w, http.StatusBadRequest, "key_id is required")
+		return
+	}
+	key, err := base64.StdEncoding.DecodeString(params.PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		respondWithError(w, http.StatusBadRequest, "public_key must be a base64 Ed25519 public key")
+		return
+	}
+	if _, err := cfg.db.GetUserById(r.Context(), database.GetUserByIdParams{ID: params.UserID, Namespace: namespaceOf(r.Context())}); errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusBadRequest, "user_id does not exist")
+		return
+	} else if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	if _, err := cfg.db.GetSigningKey(r.Context(), params.KeyID); err == nil {
+		respondWithError(w, http.StatusConflict, "key_id already registered")
+		return
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	sk, err := cfg.db.CreateSigningKey(r.Context(), database.CreateSigningKeyParams{
+		KeyID:     params.KeyID,
+		PublicKey: key,
+		UserID:    params.UserID,
+	})
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	cfg.audit(withActor(r.Context(), adminId), "signing_key.created", "user", sk.UserID, map[string]string{"key_id": sk.KeyID})
+	respondWithJSON(w, http.StatusCreated, signingKeyResp{KeyID: sk.KeyID, UserID: sk.UserID, CreatedAt: sk.CreatedAt})
+}
+
+// signingKeyStore serves registered keys to httpsig.Verify and remembers
+// whose key it handed out.
+type signingKeyStore struct {
+	db     database.Querier
+	userID uuid.UUID
+}
+
+func (s *signingKeyStore) PublicKey(ctx context.Context, keyID string) (ed25519.PublicKey, error) {
+	sk, err := s.db.GetSigningKey(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, httpsig.ErrUnknownKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.userID = sk.UserID
+	return ed25519.PublicKey(sk.PublicKey), nil
+}
+
+// middlewareSignatureAuth lets API handlers that read the Authorization
+// header accept signed requests too. A request with a Signature header and
+// no Authorization header is verified and, if it passes, handed on with a
+// short-lived bearer token for the key's user. One that fails gets 401.
+func (cfg *apiConfig) middlewareSignatureAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Signature") == "" || r.Header.Get("Authorization") != "" || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r = r.Clone(r.Context())
+		keys := &signingKeyStore{db: cfg.db}
+		if err := httpsig.Verify(r, keys); err != nil {
+			respondWithError(w, http.StatusUnauthorized, "invalid request signature")
+			return
+		}
+		token, err := auth.MakeJWT(keys.userID, cfg.tokenSecret, signedRequestTokenTTL)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+		next.ServeHTTP(w, r)
+	})
+}
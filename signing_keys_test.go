@@ -0,0 +1,81 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/azs06/Chirpy/internal/httpsig"
+)
+
+func TestSignedRequests(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	_, adminToken := seedAdmin(t, cfg, store, "admin@example.com")
+	bot, botToken := seedUser(t, cfg, store, "bot@example.com")
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	register := func(token, keyID, key string) int {
+		body := fmt.Sprintf(`{"key_id":%q,"public_key":%q,"user_id":%q}`, keyID, key, bot.ID)
+		return serve(h, "POST", "/admin/signing-keys", body, token).Code
+	}
+	encoded := base64.StdEncoding.EncodeToString(pub)
+	if code := register(botToken, "backend-1", encoded); code != http.StatusForbidden {
+		t.Errorf("got status %d registering as a non-admin, want 403", code)
+	}
+	if code := register(adminToken, "backend-1", "not-a-key"); code != http.StatusBadRequest {
+		t.Errorf("got status %d for a bad key, want 400", code)
+	}
+	if code := register(adminToken, "backend-1", encoded); code != http.StatusCreated {
+		t.Fatalf("got status %d registering, want 201", code)
+	}
+	if code := register(adminToken, "backend-1", encoded); code != http.StatusConflict {
+		t.Errorf("got status %d re-registering, want 409", code)
+	}
+
+	signed := func(method, path, body, keyID string) *http.Request {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if err := httpsig.Sign(req, keyID, priv); err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		return req
+	}
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(signed("POST", "/api/chirps", `{"body":"signed chirp"}`, "backend-1"))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d for a signed chirp: %s", rec.Code, rec.Body.String())
+	}
+	var chirp chirpResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+		t.Fatal(err)
+	}
+	if chirp.UserId != bot.ID.String() {
+		t.Errorf("got chirp by %s, want the key's user %s", chirp.UserId, bot.ID)
+	}
+
+	tampered := signed("POST", "/api/chirps", `{"body":"signed chirp"}`, "backend-1")
+	tampered.Body = http.NoBody
+	if rec := do(tampered); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d for a tampered body, want 401", rec.Code)
+	}
+	if rec := do(signed("GET", "/api/notifications", "", "unknown")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d for an unknown key, want 401", rec.Code)
+	}
+	if rec := do(signed("GET", "/api/notifications", "", "backend-1")); rec.Code != http.StatusOK {
+		t.Errorf("got status %d for a signed GET, want 200", rec.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	sitemapCacheTTL = time.Hour
+	// sitemapMaxURLs is the sitemap protocol's limit on URLs per file.
+	sitemapMaxURLs = 50000
+	sitemapXMLNS   = "http://www.sitemaps.org/schemas/sitemap/0.9"
+)
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapEntry is a page to list, stored relative to the base URL so one
+// cached list serves every host that resolves to the namespace.
+type sitemapEntry struct {
+	path    string
+	lastMod sql.NullTime
+}
+
+type sitemapCacheEntry struct {
+	entries   []sitemapEntry
+	expiresAt time.Time
+}
+
+// sitemapEntries lists the public chirps and users of the request's
+// namespace, reloading at most once every sitemapCacheTTL.
+func (cfg *apiConfig) sitemapEntries(r *http.Request) ([]sitemapEntry, error) {
+	ns := namespaceOf(r.Context())
+	now := cfg.timeNow()
+	if v, ok := cfg.sitemapCache.Load(ns); ok {
+		if entry := v.(*sitemapCacheEntry); now.Before(entry.expiresAt) {
+			return entry.entries, nil
+		}
+	}
+	chirps, err := cfg.db.GetSitemapChirps(r.Context(), ns)
+	if err != nil {
+		return nil, err
+	}
+	users, err := cfg.db.GetSitemapUsers(r.Context(), ns)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]sitemapEntry, 0, len(chirps)+len(users))
+	for _, c := range chirps {
+		entries = append(entries, sitemapEntry{path: "/app/?chirp=" + c.ID.String(), lastMod: c.UpdatedAt})
+	}
+	for _, u := range users {
+		entries = append(entries, sitemapEntry{path: "/app/?user=" + u.ID.String(), lastMod: u.UpdatedAt})
+	}
+	cfg.sitemapCache.Store(ns, &sitemapCacheEntry{entries: entries, expiresAt: now.Add(sitemapCacheTTL)})
+	return entries, nil
+}
+
+// handlerSitemap serves the sitemap for search engines. Up to
+// sitemapMaxURLs pages are listed directly; beyond that the response is a
+// sitemap index pointing at ?page=N files of sitemapMaxURLs each.
+func (cfg *apiConfig) handlerSitemap(w http.ResponseWriter, r *http.Request) {
+	entries, err := cfg.sitemapEntries(r)
+	if err != nil {
+		cfg.respondWithDBError(w, err)
+		return
+	}
+	pages := max((len(entries)+sitemapMaxURLs-1)/sitemapMaxURLs, 1)
+	page := 0
+	if p := r.URL.Query().Get("page"); p != "" {
+		if page, err = strconv.Atoi(p); err != nil || page < 1 || page > pages {
+			http.NotFound(w, r)
+			return
+		}
+	} else if pages == 1 {
+		page = 1
+	}
+
+	base := requestBaseURL(r, cfg)
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(sitemapCacheTTL.Seconds())))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(xml.Header))
+	enc := xml.NewEncoder(w)
+	if page == 0 {
+		err = encodeSitemapList(enc, "sitemapindex", "sitemap", pages, func(i int) any {
+			return sitemapRef{Loc: fmt.Sprintf("%s/sitemap.xml?page=%d", base, i+1)}
+		})
+	} else {
+		entries = entries[(page-1)*sitemapMaxURLs : min(page*sitemapMaxURLs, len(entries))]
+		err = encodeSitemapList(enc, "urlset", "url", len(entries), func(i int) any {
+			u := sitemapURL{Loc: base + entries[i].path}
+			if entries[i].lastMod.Valid {
+				u.LastMod = entries[i].lastMod.Time.UTC().Format(time.RFC3339)
+			}
+			return u
+		})
+	}
+	if err != nil {
+		log.Printf("Error writing sitemap: %s", err)
+	}
+}
+
+// encodeSitemapList streams a root element holding n children, encoding
+// each as it goes rather than building the whole document in memory.
+func encodeSitemapList(enc *xml.Encoder, root, child string, n int, item func(int) any) error {
+	start := xml.StartElement{Name: xml.Name{Local: root}, Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: sitemapXMLNS}}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := range n {
+		if err := enc.EncodeElement(item(i), xml.StartElement{Name: xml.Name{Local: child}}); err != nil {
+			return err
+		}
+	}
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return enc.Flush()
+}
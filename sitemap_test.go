@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type sitemapDoc struct {
+	XMLName  xml.Name
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+func getSitemap(t *testing.T, h http.Handler, path string) sitemapDoc {
+	t.Helper()
+	rec := serve(h, "GET", path, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/xml") {
+		t.Errorf("got Content-Type %q, want application/xml", ct)
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("sitemap is not well-formed XML: %v\n%s", err, rec.Body.String())
+	}
+	return doc
+}
+
+func countChirpURLs(doc sitemapDoc) int {
+	n := 0
+	for _, u := range doc.URLs {
+		if strings.Contains(u.Loc, "?chirp=") {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSitemapListsPublicChirps(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example"
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	seedUser(t, cfg, store, "bob@example.com")
+
+	public := postChirp(t, h, `{"body":"one"}`, token)
+	postChirp(t, h, `{"body":"two"}`, token)
+	postChirp(t, h, `{"body":"nsfw","is_nsfw":true}`, token)
+	postChirp(t, h, `{"body":"friends","visibility":"mutual"}`, token)
+	hidden := postChirp(t, h, `{"body":"hidden"}`, token)
+	for i := range store.chirps {
+		if store.chirps[i].ID == hidden.ID {
+			store.chirps[i].IsHidden = true
+		}
+	}
+
+	doc := getSitemap(t, h, "/sitemap.xml")
+	if doc.XMLName.Local != "urlset" {
+		t.Fatalf("got root element %q, want urlset", doc.XMLName.Local)
+	}
+	if got := countChirpURLs(doc); got != 2 {
+		t.Errorf("got %d chirp URLs, want the 2 public chirps", got)
+	}
+	if got := len(doc.URLs) - countChirpURLs(doc); got != 2 {
+		t.Errorf("got %d user URLs, want 2", got)
+	}
+	want := sitemapURL{
+		Loc:     "https://chirpy.example/app/?chirp=" + public.ID.String(),
+		LastMod: public.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if doc.URLs[0] != want {
+		t.Errorf("got first entry %+v, want %+v", doc.URLs[0], want)
+	}
+
+	// New chirps appear once the cached sitemap expires.
+	postChirp(t, h, `{"body":"three"}`, token)
+	if got := countChirpURLs(getSitemap(t, h, "/sitemap.xml")); got != 2 {
+		t.Errorf("got %d chirp URLs before the cache expired, want 2", got)
+	}
+	clock.t = clock.t.Add(sitemapCacheTTL)
+	if got := countChirpURLs(getSitemap(t, h, "/sitemap.xml")); got != 3 {
+		t.Errorf("got %d chirp URLs after the cache expired, want 3", got)
+	}
+}
+
+func TestSitemapIndex(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.baseURL = "https://chirpy.example"
+	h := newServer("0", cfg).Handler
+	for range sitemapMaxURLs + 1 {
+		store.chirps = append(store.chirps, database.Chirp{ID: uuid.New(), Visibility: database.ChirpVisibilityPublic})
+	}
+
+	index := getSitemap(t, h, "/sitemap.xml")
+	if index.XMLName.Local != "sitemapindex" || len(index.Sitemaps) != 2 {
+		t.Fatalf("got %s with %d sitemaps, want a sitemapindex of 2", index.XMLName.Local, len(index.Sitemaps))
+	}
+	if index.Sitemaps[1].Loc != "https://chirpy.example/sitemap.xml?page=2" {
+		t.Errorf("got second sitemap %q", index.Sitemaps[1].Loc)
+	}
+	if got := len(getSitemap(t, h, "/sitemap.xml?page=1").URLs); got != sitemapMaxURLs {
+		t.Errorf("got %d URLs on page 1, want %d", got, sitemapMaxURLs)
+	}
+	if got := len(getSitemap(t, h, "/sitemap.xml?page=2").URLs); got != 1 {
+		t.Errorf("got %d URLs on page 2, want 1", got)
+	}
+	if rec := serve(h, "GET", "/sitemap.xml?page=3", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("got status %d past the last page, want 404", rec.Code)
+	}
+}
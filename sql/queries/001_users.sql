@@ -1,11 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, namespace)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
@@ -13,10 +14,15 @@ RETURNING *;
 DELETE FROM users;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1;
+SELECT * FROM users WHERE email = $1 AND namespace = $2;
 
 -- name: GetUserById :one
-SELECT * FROM users WHERE id=$1;
+SELECT sqlc.embed(users),
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id) AS followers_count,
+    (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id AND chirps.deleted_at IS NULL) AS chirps_count
+FROM users
+WHERE users.id = $1 AND users.namespace = $2;
 
 
 -- name: UpdateUser :one
@@ -29,3 +35,91 @@ RETURNING *;
 UPDATE users SET is_chirpy_red = $2, updated_at = NOW()
 WHERE id = $1
 RETURNING *;
+
+-- name: SetUserVerified :one
+UPDATE users SET is_verified = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserActivity :one
+WITH events AS (
+    SELECT 'chirp' AS kind, chirps.created_at
+    FROM chirps
+    WHERE chirps.user_id = sqlc.arg(user_id) AND chirps.deleted_at IS NULL
+    UNION ALL
+    SELECT 'like', chirp_likes.created_at
+    FROM chirp_likes
+    JOIN chirps ON chirps.id = chirp_likes.chirp_id
+    WHERE chirps.user_id = sqlc.arg(user_id)
+    UNION ALL
+    SELECT 'follower', follows.created_at
+    FROM follows
+    WHERE follows.followee_id = sqlc.arg(user_id)
+    UNION ALL
+    SELECT 'reply', replies.created_at
+    FROM chirps replies
+    JOIN chirps parent ON parent.id = replies.parent_id
+    WHERE parent.user_id = sqlc.arg(user_id) AND replies.user_id <> sqlc.arg(user_id)
+      AND replies.deleted_at IS NULL
+)
+SELECT
+    COALESCE(SUM(CASE WHEN kind = 'chirp' AND created_at >= sqlc.arg(day_start)::timestamp THEN 1 ELSE 0 END), 0)::bigint AS chirps_today,
+    COALESCE(SUM(CASE WHEN kind = 'chirp' THEN 1 ELSE 0 END), 0)::bigint AS chirps_this_week,
+    COALESCE(SUM(CASE WHEN kind = 'like' AND created_at >= sqlc.arg(day_start)::timestamp THEN 1 ELSE 0 END), 0)::bigint AS likes_received_today,
+    COALESCE(SUM(CASE WHEN kind = 'follower' AND created_at >= sqlc.arg(day_start)::timestamp THEN 1 ELSE 0 END), 0)::bigint AS new_followers_today,
+    COALESCE(SUM(CASE WHEN kind = 'reply' AND created_at >= sqlc.arg(day_start)::timestamp THEN 1 ELSE 0 END), 0)::bigint AS replies_received_today
+FROM events
+WHERE created_at >= sqlc.arg(week_start)::timestamp;
+
+-- name: GetLeaderboard :many
+WITH points AS (
+    SELECT chirps.user_id, 1 AS points
+    FROM chirps
+    WHERE chirps.created_at >= sqlc.arg(since)::timestamp AND chirps.namespace = sqlc.arg(namespace)
+      AND chirps.deleted_at IS NULL AND NOT chirps.is_hidden
+    UNION ALL
+    SELECT chirps.user_id, 3
+    FROM chirp_likes
+    JOIN chirps ON chirps.id = chirp_likes.chirp_id
+    WHERE chirp_likes.created_at >= sqlc.arg(since)::timestamp AND chirps.namespace = sqlc.arg(namespace)
+      AND chirps.deleted_at IS NULL AND NOT chirps.is_hidden
+    UNION ALL
+    SELECT follows.followee_id, 5
+    FROM follows
+    JOIN users followee ON followee.id = follows.followee_id
+    WHERE follows.created_at >= sqlc.arg(since)::timestamp AND followee.namespace = sqlc.arg(namespace)
+), scores AS (
+    SELECT user_id, SUM(points) AS score
+    FROM points
+    GROUP BY user_id
+)
+SELECT users.id, users.created_at, users.updated_at, users.is_chirpy_red, users.is_verified,
+    scores.score::bigint AS score
+FROM scores
+JOIN users ON users.id = scores.user_id
+WHERE users.namespace = sqlc.arg(namespace)
+ORDER BY scores.score DESC, users.id
+LIMIT sqlc.arg(size);
+
+-- name: GetUsersByIds :many
+SELECT sqlc.embed(users),
+    (SELECT COUNT(*) FROM follows WHERE follows.followee_id = users.id) AS followers_count,
+    (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id) AS following_count,
+    (SELECT COUNT(*) FROM chirps WHERE chirps.user_id = users.id AND chirps.deleted_at IS NULL) AS chirps_count
+FROM users
+WHERE users.id = ANY(sqlc.arg(ids)::uuid[]) AND users.namespace = sqlc.arg(namespace);
+
+-- name: SetPendingEmail :one
+UPDATE users
+SET pending_email = $2, pending_email_token = $3, pending_email_expires_at = $4, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetUserByPendingEmailToken :one
+SELECT * FROM users WHERE pending_email_token = $1;
+
+-- name: ConfirmPendingEmail :one
+UPDATE users
+SET email = pending_email, pending_email = NULL, pending_email_token = NULL, pending_email_expires_at = NULL, updated_at = NOW()
+WHERE id = $1 AND pending_email_token = $2
+RETURNING *;
@@ -1,11 +1,33 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+-- The chirp and its media go in together, so a failed media insert leaves
+-- no chirp behind. chirp_media's foreign key is checked at the end of the
+-- statement, after the chirp exists.
+WITH new AS (SELECT gen_random_uuid() AS id), media AS (
+    INSERT INTO chirp_media (id, chirp_id, url, mime_type, alt_text, position, created_at)
+    SELECT gen_random_uuid(), (SELECT new.id FROM new), m.url, m.mime_type, m.alt_text, m.position - 1, NOW()
+    FROM unnest(
+        sqlc.arg(media_urls)::text[],
+        sqlc.arg(media_mime_types)::text[],
+        sqlc.arg(media_alt_texts)::text[]
+    ) WITH ORDINALITY AS m(url, mime_type, alt_text, position)
+)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, is_nsfw, word_count, reading_time_seconds, visibility, flagged_reason, sentiment_score, namespace, root_id, thread_depth)
 VALUES (
-    gen_random_uuid(),
+    (SELECT new.id FROM new),
     NOW(),
     NOW(),
-    $1,
-    $2
+    sqlc.arg(body),
+    sqlc.arg(user_id),
+    sqlc.arg(parent_id),
+    sqlc.arg(is_nsfw),
+    sqlc.arg(word_count),
+    sqlc.arg(reading_time_seconds),
+    sqlc.arg(visibility),
+    sqlc.arg(flagged_reason),
+    sqlc.arg(sentiment_score),
+    (SELECT users.namespace FROM users WHERE users.id = sqlc.arg(user_id)),
+    COALESCE((SELECT parent.root_id FROM chirps parent WHERE parent.id = sqlc.arg(parent_id)), (SELECT new.id FROM new)),
+    COALESCE((SELECT parent.thread_depth + 1 FROM chirps parent WHERE parent.id = sqlc.arg(parent_id)), 0)
 )
 RETURNING *;
 
@@ -13,13 +35,285 @@ RETURNING *;
 DELETE FROM chirps;
 
 -- name: GetChirps :many
- SELECT * FROM chirps ORDER BY created_at;
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (sqlc.arg(include_hidden)::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= sqlc.arg(min_sentiment)::float8
+  AND (NOT sqlc.arg(verified_only)::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY CASE WHEN sqlc.arg(by_importance)::boolean THEN chirps.importance_score END DESC, chirps.created_at;
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count,
+    ts_rank(to_tsvector('english', chirps.body), search.query)::float8 AS rank
+FROM chirps,
+    (SELECT CASE WHEN sqlc.arg(prefix)::boolean THEN to_tsquery('english', sqlc.arg(query)::text)
+        ELSE plainto_tsquery('english', sqlc.arg(query)::text) END AS query) search
+WHERE to_tsvector('english', chirps.body) @@ search.query
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (sqlc.arg(author_id)::uuid = '00000000-0000-0000-0000-000000000000' OR chirps.user_id = sqlc.arg(author_id))
+  AND (sqlc.arg(include_hidden)::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= sqlc.arg(min_sentiment)::float8
+  AND (NOT sqlc.arg(verified_only)::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY rank DESC, chirps.created_at DESC;
 
 -- name: GetChirpByID :one
-  SELECT * FROM chirps WHERE id = $1;
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.id = sqlc.arg(id) AND chirps.namespace = sqlc.arg(namespace) AND chirps.deleted_at IS NULL;
 
 -- name: GetChirpsByUserId :many
-SELECT * FROM chirps WHERE user_id = $1 ORDER BY created_at;
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id)
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (sqlc.arg(include_hidden)::boolean OR NOT chirps.is_hidden)
+  AND chirps.sentiment_score >= sqlc.arg(min_sentiment)::float8
+  AND (NOT sqlc.arg(verified_only)::boolean
+   OR EXISTS (SELECT 1 FROM users WHERE users.id = chirps.user_id AND users.is_verified))
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY CASE WHEN sqlc.arg(by_importance)::boolean THEN chirps.importance_score END DESC, chirps.created_at;
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetChirpOwners :many
+SELECT id, user_id FROM chirps WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: SoftDeleteChirpsByIds :many
+UPDATE chirps SET deleted_at = NOW()
+WHERE id = ANY(sqlc.arg(ids)::uuid[]) AND user_id = sqlc.arg(user_id)
+  AND deleted_at IS NULL
+RETURNING id;
+
+-- name: GetDeletedChirpsByUser :many
+SELECT * FROM chirps
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC;
+
+-- name: RestoreChirp :one
+UPDATE chirps SET deleted_at = NULL
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+  AND deleted_at >= sqlc.arg(deleted_since)::timestamp
+RETURNING *;
+
+-- name: PurgeDeletedChirpsBefore :execrows
+-- chirp_versions has no foreign key to chirps, so that versions survive
+-- archiving; purged chirps take theirs with them here.
+WITH versions AS (
+    DELETE FROM chirp_versions
+    WHERE chirp_id IN (SELECT id FROM chirps WHERE deleted_at < sqlc.arg(cutoff)::timestamp)
+)
+DELETE FROM chirps WHERE deleted_at < sqlc.arg(cutoff)::timestamp;
+
+-- name: ArchiveChirpsBefore :execrows
+-- Every statement in the query sees chirp_media as it was before the
+-- cascade from the DELETE, so the media of archived chirps is still there
+-- to copy.
+WITH archived AS (
+    DELETE FROM chirps WHERE created_at < sqlc.arg(cutoff) AND deleted_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score
+), media AS (
+    INSERT INTO chirp_media_archive (id, chirp_id, url, mime_type, alt_text, position, created_at)
+    SELECT chirp_media.id, chirp_media.chirp_id, chirp_media.url, chirp_media.mime_type, chirp_media.alt_text, chirp_media.position, chirp_media.created_at
+    FROM chirp_media
+    WHERE chirp_media.chirp_id IN (SELECT archived.id FROM archived)
+)
+INSERT INTO chirps_archive (id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score, archived_at)
+SELECT id, created_at, updated_at, body, user_id, parent_id, is_nsfw, is_hidden, word_count, reading_time_seconds, visibility, flagged_reason, namespace, sentiment_score, root_id, impression_count, thread_depth, content_warning, admin_edited, importance_score, NOW() FROM archived;
+
+-- name: GetArchivedChirps :many
+SELECT * FROM chirps_archive ORDER BY created_at;
+
+-- name: GetUserChirpsAsc :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id)
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+  AND (chirps.created_at, chirps.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY chirps.created_at, chirps.id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetUserChirpsDesc :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id)
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+  AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: SetChirpHidden :one
+UPDATE chirps SET is_hidden = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetChirpNsfw :one
+UPDATE chirps SET is_nsfw = true, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetChirpContentWarning :one
+UPDATE chirps SET content_warning = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetFlaggedChirps :many
+SELECT * FROM chirps
+WHERE flagged_reason IS NOT NULL AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetChirpsForExport :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE (chirps.created_at, chirps.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+  AND chirps.created_at < sqlc.arg(created_before)::timestamp
+  AND chirps.deleted_at IS NULL
+ORDER BY chirps.created_at, chirps.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: CountUserChirps :one
+SELECT COUNT(*) FROM chirps WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: GetThreadChirps :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.root_id = sqlc.arg(root_id)
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY chirps.created_at;
+
+-- name: GetChirpsBefore :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id) AND chirps.created_at < sqlc.arg(created_at)
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY chirps.created_at DESC
+LIMIT sqlc.arg(max_chirps);
+
+-- name: GetChirpsAfter :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id) AND chirps.created_at > sqlc.arg(created_at)
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY chirps.created_at
+LIMIT sqlc.arg(max_chirps);
+
+-- name: ImportChirps :many
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, word_count, reading_time_seconds, sentiment_score, namespace, root_id)
+SELECT i.id, i.created_at, i.created_at, i.body, i.user_id, i.word_count, i.reading_time_seconds, i.sentiment_score, users.namespace, i.id
+FROM unnest(
+    sqlc.arg(ids)::uuid[],
+    sqlc.arg(created_ats)::timestamp[],
+    sqlc.arg(bodies)::text[],
+    sqlc.arg(user_ids)::uuid[],
+    sqlc.arg(word_counts)::integer[],
+    sqlc.arg(reading_times)::integer[],
+    sqlc.arg(sentiment_scores)::float8[]
+) AS i(id, created_at, body, user_id, word_count, reading_time_seconds, sentiment_score)
+JOIN users ON users.id = i.user_id
+ON CONFLICT (id) DO NOTHING
+RETURNING id;
+
+-- name: GetChirpsForScoring :many
+SELECT id, created_at, impression_count,
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE created_at >= sqlc.arg(since)::timestamp AND deleted_at IS NULL;
+
+-- name: SetImportanceScores :exec
+UPDATE chirps SET importance_score = s.score
+FROM unnest(sqlc.arg(ids)::uuid[], sqlc.arg(scores)::float8[]) AS s(id, score)
+WHERE chirps.id = s.id;
+
+-- name: AdminUpdateChirp :one
+-- Every statement in the query sees the chirp as it was before the update,
+-- so the old body is what lands in chirp_versions.
+WITH previous AS (
+    SELECT id, body FROM chirps WHERE id = sqlc.arg(id) FOR UPDATE
+), saved AS (
+    INSERT INTO chirp_versions (chirp_id, body, edited_by)
+    SELECT previous.id, previous.body, sqlc.arg(edited_by) FROM previous
+)
+UPDATE chirps
+SET body = sqlc.arg(body), word_count = sqlc.arg(word_count), reading_time_seconds = sqlc.arg(reading_time_seconds),
+    sentiment_score = sqlc.arg(sentiment_score), admin_edited = true, updated_at = NOW()
+WHERE id = (SELECT previous.id FROM previous)
+RETURNING *;
 
--- name: DeleteChirpById :exec
-DELETE FROM chirps WHERE id = $1;
+-- name: GetUserHourlyChirpCounts :many
+-- One row per hour for the last 7 days, ending with the current hour, so
+-- hours without chirps come back with a count of 0. Only chirps anyone may
+-- read are counted, since the chart is shared by every viewer.
+SELECT hours.hour::timestamp AS hour, COUNT(chirps.id) AS count
+FROM generate_series(
+    date_trunc('hour', NOW()::timestamp - INTERVAL '7 days') + INTERVAL '1 hour',
+    date_trunc('hour', NOW()::timestamp),
+    INTERVAL '1 hour'
+) AS hours(hour)
+LEFT JOIN chirps ON chirps.user_id = sqlc.arg(user_id)
+    AND chirps.deleted_at IS NULL
+    AND NOT chirps.is_hidden
+    AND chirps.visibility = 'public'
+    AND date_trunc('hour', chirps.created_at) = hours.hour
+GROUP BY hours.hour
+ORDER BY hours.hour;
@@ -1,12 +1,13 @@
 -- name: CreateRefreshToken :one
-INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at)
+INSERT INTO refresh_tokens (token, created_at, updated_at, user_id, expires_at, revoked_at, scope)
 VALUES (
     $1,
     NOW(),
     NOW(),
     $2,
     $3,
-    $4
+    $4,
+    $5
 )
 RETURNING *;
 
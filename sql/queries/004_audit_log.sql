@@ -0,0 +1,18 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (id, actor_id, action, entity_type, entity_id, details, ip_address, created_at)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetAuditLogs :many
+SELECT * FROM audit_log
+WHERE created_at >= sqlc.arg(since) AND created_at < sqlc.arg(until)
+ORDER BY created_at DESC;
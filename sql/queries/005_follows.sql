@@ -0,0 +1,98 @@
+-- name: CreateFollow :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :exec
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: GetFollowers :many
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified,
+    EXISTS (
+        SELECT 1 FROM follows back
+        WHERE back.follower_id = follows.followee_id AND back.followee_id = follows.follower_id
+    ) AS is_mutual
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = $1 AND users.namespace = $2
+ORDER BY follows.created_at;
+
+-- name: GetFollowersPage :many
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified,
+    follows.created_at AS followed_at,
+    EXISTS (
+        SELECT 1 FROM follows back
+        WHERE back.follower_id = follows.followee_id AND back.followee_id = follows.follower_id
+    ) AS is_mutual
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg(followee_id) AND users.namespace = sqlc.arg(namespace)
+  AND (follows.created_at, users.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY follows.created_at, users.id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowingPage :many
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified,
+    follows.created_at AS followed_at,
+    EXISTS (
+        SELECT 1 FROM follows back
+        WHERE back.follower_id = follows.followee_id AND back.followee_id = follows.follower_id
+    ) AS is_mutual
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg(follower_id) AND users.namespace = sqlc.arg(namespace)
+  AND (follows.created_at, users.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY follows.created_at, users.id
+LIMIT sqlc.arg(page_size);
+
+-- name: GetMutualFollowsPage :many
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.is_verified,
+    f1.created_at AS followed_at
+FROM follows f1
+JOIN follows f2 ON f1.follower_id = f2.followee_id AND f1.followee_id = f2.follower_id
+JOIN users ON users.id = f1.followee_id
+WHERE f1.follower_id = sqlc.arg(user_id) AND users.namespace = sqlc.arg(namespace)
+  AND (f1.created_at, users.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY f1.created_at, users.id
+LIMIT sqlc.arg(page_size);
+
+-- name: CountMutualFollows :one
+SELECT COUNT(*) FROM follows f1
+JOIN follows f2 ON f1.follower_id = f2.followee_id AND f1.followee_id = f2.follower_id
+JOIN users ON users.id = f1.followee_id
+WHERE f1.follower_id = $1 AND users.namespace = $2;
+
+-- name: GetFollowRelationship :one
+SELECT
+    EXISTS (
+        SELECT 1 FROM follows f WHERE f.follower_id = sqlc.arg(caller_id) AND f.followee_id = sqlc.arg(target_id)
+    ) AS following,
+    EXISTS (
+        SELECT 1 FROM follows f WHERE f.follower_id = sqlc.arg(target_id) AND f.followee_id = sqlc.arg(caller_id)
+    ) AS followed_by;
+
+-- name: IsMutualFollow :one
+SELECT
+    EXISTS (
+        SELECT 1 FROM follows f WHERE f.follower_id = sqlc.arg(user_a) AND f.followee_id = sqlc.arg(user_b)
+    )
+    AND EXISTS (
+        SELECT 1 FROM follows f WHERE f.follower_id = sqlc.arg(user_b) AND f.followee_id = sqlc.arg(user_a)
+    ) AS mutual;
+
+-- name: GetHomeFeed :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE (chirps.user_id = sqlc.arg(user_id)
+   OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg(user_id)))
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(user_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(user_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(user_id))))
+  AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,4 @@
+-- name: GetMediaForChirps :many
+SELECT * FROM chirp_media
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ORDER BY chirp_id, position;
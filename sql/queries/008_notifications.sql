@@ -0,0 +1,17 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (id, recipient_id, actor_id, type, chirp_id, created_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW())
+RETURNING *;
+
+-- name: GetNotifications :many
+SELECT * FROM notifications
+WHERE recipient_id = sqlc.arg(recipient_id)
+  AND ((read_at IS NOT NULL) > sqlc.arg(cursor_read)::boolean
+    OR ((read_at IS NOT NULL) = sqlc.arg(cursor_read)::boolean
+      AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)))
+ORDER BY (read_at IS NOT NULL), created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: MarkAllNotificationsRead :exec
+UPDATE notifications SET read_at = NOW()
+WHERE recipient_id = $1 AND read_at IS NULL;
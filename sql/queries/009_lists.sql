@@ -0,0 +1,38 @@
+-- name: CreateList :one
+INSERT INTO lists (id, owner_id, name, description, is_public, created_at, updated_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, NOW(), NOW())
+RETURNING *;
+
+-- name: GetList :one
+SELECT * FROM lists WHERE id = $1;
+
+-- name: GetListsByOwner :many
+SELECT * FROM lists
+WHERE owner_id = sqlc.arg(owner_id)
+  AND (is_public OR sqlc.arg(include_private)::boolean)
+ORDER BY created_at;
+
+-- name: AddListMember :exec
+INSERT INTO list_members (list_id, user_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveListMember :exec
+DELETE FROM list_members WHERE list_id = $1 AND user_id = $2;
+
+-- name: GetListFeed :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+JOIN list_members ON list_members.user_id = chirps.user_id
+WHERE list_members.list_id = sqlc.arg(list_id)
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+  AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
@@ -0,0 +1,38 @@
+-- name: AddChirpTopic :exec
+INSERT INTO chirp_topics (chirp_id, topic)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: SubscribeTopic :exec
+INSERT INTO topic_subscriptions (user_id, topic, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnsubscribeTopic :exec
+DELETE FROM topic_subscriptions WHERE user_id = $1 AND topic = $2;
+
+-- name: GetTopicFeed :many
+WITH matches AS (
+    SELECT chirp_topics.chirp_id,
+        array_agg(chirp_topics.topic ORDER BY chirp_topics.topic)::text[] AS matched_topics,
+        COUNT(*) AS match_count
+    FROM chirp_topics
+    JOIN topic_subscriptions ON topic_subscriptions.topic = chirp_topics.topic
+    WHERE topic_subscriptions.user_id = sqlc.arg(user_id)
+    GROUP BY chirp_topics.chirp_id
+)
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count,
+    matches.matched_topics,
+    (matches.match_count / (1 + EXTRACT(EPOCH FROM NOW() - chirps.created_at) / 3600))::float8 AS score
+FROM matches
+JOIN chirps ON chirps.id = matches.chirp_id
+WHERE chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(user_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(user_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(user_id))))
+ORDER BY score DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
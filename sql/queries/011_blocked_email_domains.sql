@@ -0,0 +1,10 @@
+-- name: GetBlockedEmailDomains :many
+SELECT domain FROM blocked_email_domains;
+
+-- name: AddBlockedEmailDomain :exec
+INSERT INTO blocked_email_domains (domain, created_at)
+VALUES ($1, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlockedEmailDomain :execrows
+DELETE FROM blocked_email_domains WHERE domain = $1;
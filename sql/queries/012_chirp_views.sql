@@ -0,0 +1,13 @@
+-- name: RecordChirpViews :exec
+INSERT INTO chirp_views (chirp_id, viewer_key, created_at)
+SELECT views.chirp_id, views.viewer_key, NOW()
+FROM unnest(sqlc.arg(chirp_ids)::uuid[], sqlc.arg(viewer_keys)::text[]) AS views(chirp_id, viewer_key)
+JOIN chirps ON chirps.id = views.chirp_id
+ON CONFLICT DO NOTHING;
+
+-- name: GetChirpViewCount :one
+SELECT COUNT(*) FROM chirp_views WHERE chirp_id = $1;
+
+-- name: IncrementChirpImpressions :exec
+UPDATE chirps SET impression_count = impression_count + 1
+WHERE id = ANY(sqlc.arg(chirp_ids)::uuid[]);
@@ -0,0 +1,2 @@
+-- name: PingDatabase :exec
+SELECT 1;
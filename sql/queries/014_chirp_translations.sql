@@ -0,0 +1,8 @@
+-- name: GetChirpTranslation :one
+SELECT translated_body FROM chirp_translations
+WHERE chirp_id = $1 AND language = $2;
+
+-- name: CreateChirpTranslation :exec
+INSERT INTO chirp_translations (chirp_id, language, translated_body, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+-- name: GetRecentFingerprintChirp :one
+SELECT chirp_id FROM request_fingerprints
+WHERE fingerprint = sqlc.arg(fingerprint) AND created_at >= sqlc.arg(since)::timestamp;
+
+-- name: SaveRequestFingerprint :exec
+INSERT INTO request_fingerprints (fingerprint, chirp_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (fingerprint) DO UPDATE SET chirp_id = EXCLUDED.chirp_id, created_at = EXCLUDED.created_at;
+
+-- name: DeleteRequestFingerprintsBefore :execrows
+DELETE FROM request_fingerprints WHERE created_at < sqlc.arg(cutoff)::timestamp;
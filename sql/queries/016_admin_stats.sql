@@ -0,0 +1,24 @@
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: CountActiveUsersSince :one
+SELECT COUNT(DISTINCT user_id) FROM (
+    SELECT user_id FROM chirps WHERE created_at >= sqlc.arg(since)::timestamp
+    UNION
+    SELECT user_id FROM refresh_tokens WHERE created_at >= sqlc.arg(since)::timestamp
+) active;
+
+-- name: CountChirps :one
+SELECT COUNT(*) FROM chirps WHERE deleted_at IS NULL;
+
+-- name: CountChirpsSince :one
+SELECT COUNT(*) FROM chirps WHERE created_at >= sqlc.arg(since)::timestamp AND deleted_at IS NULL;
+
+-- name: GetAvgChirpLength :one
+SELECT COALESCE(AVG(LENGTH(body)), 0)::float8 AS avg_length FROM chirps WHERE deleted_at IS NULL;
+
+-- name: CountLikes :one
+SELECT COUNT(*) FROM chirp_likes;
+
+-- name: CountFollows :one
+SELECT COUNT(*) FROM follows;
@@ -0,0 +1,19 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, url, created_at)
+VALUES (gen_random_uuid(), $1, NOW())
+RETURNING *;
+
+-- name: GetWebhooks :many
+SELECT * FROM webhooks ORDER BY created_at;
+
+-- name: GetWebhookByID :one
+SELECT * FROM webhooks WHERE id = $1;
+
+-- name: RecordWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, webhook_id, chirp_id, attempt_number, status_code, error, delivered_at)
+VALUES (gen_random_uuid(), $1, $2, $3, $4, $5, NOW());
+
+-- name: GetWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY delivered_at DESC;
@@ -0,0 +1,20 @@
+-- name: SetUserEmailMFA :one
+UPDATE users SET email_mfa_enabled = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateEmailOTPSession :one
+INSERT INTO email_otp_sessions (token, user_id, otp_hash, created_at, expires_at)
+VALUES ($1, $2, $3, NOW(), $4)
+RETURNING *;
+
+-- name: GetEmailOTPSession :one
+SELECT * FROM email_otp_sessions WHERE token = $1;
+
+-- name: RecordEmailOTPFailure :exec
+UPDATE email_otp_sessions SET failed_attempts = failed_attempts + 1
+WHERE token = $1;
+
+-- name: UseEmailOTPSession :execrows
+UPDATE email_otp_sessions SET used_at = NOW()
+WHERE token = $1 AND used_at IS NULL;
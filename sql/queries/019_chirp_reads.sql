@@ -0,0 +1,22 @@
+-- name: RecordChirpRead :exec
+INSERT INTO chirp_reads (user_id, chirp_id, first_seen_at, last_seen_at, view_count)
+VALUES (sqlc.arg(user_id), sqlc.arg(chirp_id), sqlc.arg(seen_at), sqlc.arg(seen_at), 1)
+ON CONFLICT (user_id, chirp_id) DO UPDATE
+SET last_seen_at = GREATEST(chirp_reads.last_seen_at, EXCLUDED.last_seen_at),
+    view_count = chirp_reads.view_count + 1;
+
+-- name: GetUnreadChirps :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg(user_id))
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND NOT chirps.is_hidden
+  AND (chirps.visibility = 'public'
+   OR EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(user_id)))
+  AND NOT EXISTS (SELECT 1 FROM chirp_reads WHERE chirp_reads.user_id = sqlc.arg(user_id) AND chirp_reads.chirp_id = chirps.id)
+  AND (chirps.created_at, chirps.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_size);
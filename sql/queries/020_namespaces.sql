@@ -0,0 +1,7 @@
+-- name: CreateNamespace :one
+INSERT INTO namespaces (name, chirps_per_minute, features)
+VALUES (sqlc.arg(name), sqlc.arg(chirps_per_minute), sqlc.arg(features)::text[])
+RETURNING *;
+
+-- name: GetNamespace :one
+SELECT * FROM namespaces WHERE name = $1;
@@ -0,0 +1,13 @@
+-- name: GetSitemapChirps :many
+SELECT id, updated_at FROM chirps
+WHERE namespace = $1
+    AND visibility = 'public'
+    AND NOT is_hidden
+    AND NOT is_nsfw
+    AND deleted_at IS NULL
+ORDER BY created_at, id;
+
+-- name: GetSitemapUsers :many
+SELECT id, updated_at FROM users
+WHERE namespace = $1
+ORDER BY created_at, id;
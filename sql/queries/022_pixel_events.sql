@@ -0,0 +1,9 @@
+-- name: RecordPixelEvent :exec
+WITH counted AS (
+    UPDATE chirps SET impression_count = impression_count + 1
+    WHERE id = sqlc.arg(chirp_id) AND namespace = sqlc.arg(namespace) AND deleted_at IS NULL
+    RETURNING id
+)
+INSERT INTO pixel_events (id, chirp_id, referer, user_agent, created_at)
+SELECT gen_random_uuid(), counted.id, sqlc.arg(referer), sqlc.arg(user_agent), sqlc.arg(created_at)
+FROM counted;
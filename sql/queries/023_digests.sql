@@ -0,0 +1,15 @@
+-- name: SetUserEmailNotifications :one
+UPDATE users SET email_notifications = $2, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDigestNotificationCounts :many
+SELECT users.id AS user_id, users.email, notifications.type, COUNT(*) AS count
+FROM notifications
+JOIN users ON users.id = notifications.recipient_id
+WHERE notifications.read_at IS NULL
+    AND notifications.created_at >= sqlc.arg(since)::timestamp
+    AND users.email_notifications
+    AND users.email IS NOT NULL
+GROUP BY users.id, users.email, notifications.type
+ORDER BY users.id, notifications.type;
@@ -0,0 +1,9 @@
+-- name: RegisterPushToken :one
+INSERT INTO push_tokens (device_token, user_id, platform, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (device_token) DO UPDATE
+SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, created_at = EXCLUDED.created_at
+RETURNING device_token, user_id, platform, created_at;
+
+-- name: GetPushTokens :many
+SELECT * FROM push_tokens WHERE user_id = $1 ORDER BY created_at;
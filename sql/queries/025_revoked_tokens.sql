@@ -0,0 +1,10 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (jti, expires_at, revoked_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1) AS revoked;
+
+-- name: GetRevokedTokenIDs :many
+SELECT jti FROM revoked_tokens WHERE expires_at > NOW();
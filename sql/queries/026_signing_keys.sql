@@ -0,0 +1,7 @@
+-- name: CreateSigningKey :one
+INSERT INTO signing_keys (key_id, public_key, user_id, created_at)
+VALUES ($1, $2, $3, NOW())
+RETURNING *;
+
+-- name: GetSigningKey :one
+SELECT * FROM signing_keys WHERE key_id = $1;
@@ -0,0 +1,25 @@
+-- name: CreateShortLink :one
+INSERT INTO short_links (code, chirp_id, created_by, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT (code) DO NOTHING
+RETURNING code, chirp_id, created_by, created_at, click_count, target_url, auto_generated;
+
+-- name: CreateAutoShortLink :one
+INSERT INTO short_links (code, target_url, created_by, created_at, auto_generated)
+VALUES ($1, $2, $3, NOW(), true)
+ON CONFLICT (code) DO NOTHING
+RETURNING code, chirp_id, created_by, created_at, click_count, target_url, auto_generated;
+
+-- name: AttachShortLinksToChirp :exec
+UPDATE short_links SET chirp_id = sqlc.arg(chirp_id)
+WHERE code = ANY(sqlc.arg(codes)::text[]);
+
+-- name: GetExpandedURLsForChirps :many
+SELECT chirp_id, code, target_url FROM short_links
+WHERE auto_generated AND chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ORDER BY created_at, code;
+
+-- name: ClickShortLink :one
+UPDATE short_links SET click_count = click_count + 1
+WHERE code = $1 AND (auto_generated OR chirp_id IS NOT NULL)
+RETURNING chirp_id, target_url;
@@ -0,0 +1,16 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id, secret_hash, name, redirect_uri, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients WHERE id = $1;
+
+-- name: CreateOAuthCode :one
+INSERT INTO oauth_codes (code, client_id, user_id, redirect_uri, scope, expires_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, NOW())
+RETURNING *;
+
+-- name: ConsumeOAuthCode :one
+DELETE FROM oauth_codes WHERE code = $1
+RETURNING *;
@@ -0,0 +1,13 @@
+-- name: CreateOAuthIdentity :one
+INSERT INTO oauth_identities (user_id, provider, provider_user_id, access_token_encrypted, created_at)
+VALUES ($1, $2, $3, $4, NOW())
+RETURNING *;
+
+-- name: GetOAuthIdentityByProviderUser :one
+SELECT * FROM oauth_identities WHERE provider = $1 AND provider_user_id = $2;
+
+-- name: GetOAuthIdentities :many
+SELECT * FROM oauth_identities WHERE user_id = $1 ORDER BY created_at;
+
+-- name: DeleteOAuthIdentity :execrows
+DELETE FROM oauth_identities WHERE user_id = $1 AND provider = $2;
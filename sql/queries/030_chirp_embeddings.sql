@@ -0,0 +1,39 @@
+-- name: UpsertChirpEmbedding :one
+INSERT INTO chirp_embeddings (chirp_id, embedding, model_version, created_at)
+VALUES (sqlc.arg(chirp_id), CAST(sqlc.arg(embedding)::text AS vector), sqlc.arg(model_version), NOW())
+ON CONFLICT (chirp_id) DO UPDATE
+SET embedding = EXCLUDED.embedding, model_version = EXCLUDED.model_version, created_at = EXCLUDED.created_at
+RETURNING chirp_id, model_version, created_at;
+
+-- name: GetSimilarChirps :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count,
+    (1 - (e.embedding <=> target.embedding))::float8 AS similarity
+FROM chirps
+JOIN chirp_embeddings e ON e.chirp_id = chirps.id
+JOIN chirp_embeddings target ON target.chirp_id = sqlc.arg(chirp_id)
+WHERE chirps.id <> target.chirp_id
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY e.embedding <=> target.embedding
+LIMIT sqlc.arg(max_chirps);
+
+-- name: GetRandomChirps :many
+SELECT sqlc.embed(chirps),
+    (SELECT COUNT(*) FROM chirp_likes WHERE chirp_likes.chirp_id = chirps.id) AS like_count,
+    (SELECT COUNT(*) FROM chirps replies WHERE replies.parent_id = chirps.id AND replies.deleted_at IS NULL) AS reply_count
+FROM chirps
+WHERE chirps.id <> sqlc.arg(exclude_id)
+  AND NOT chirps.is_hidden
+  AND chirps.deleted_at IS NULL
+  AND chirps.namespace = sqlc.arg(namespace)
+  AND (chirps.visibility = 'public' OR chirps.user_id = sqlc.arg(viewer_id)
+   OR (EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = sqlc.arg(viewer_id) AND follows.followee_id = chirps.user_id)
+   AND EXISTS (SELECT 1 FROM follows WHERE follows.follower_id = chirps.user_id AND follows.followee_id = sqlc.arg(viewer_id))))
+ORDER BY random()
+LIMIT sqlc.arg(max_chirps);
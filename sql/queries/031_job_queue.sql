@@ -0,0 +1,29 @@
+-- name: EnqueueJob :one
+INSERT INTO job_queue (type, payload)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ClaimJob :one
+-- SKIP LOCKED lets workers claim jobs side by side without waiting on rows
+-- another worker is claiming.
+WITH next AS (
+    SELECT id FROM job_queue
+    WHERE status IN ('pending', 'running') AND run_at <= sqlc.arg(now)
+    ORDER BY run_at, id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE job_queue
+SET status = 'running', attempt_count = attempt_count + 1, run_at = sqlc.arg(lease_until)
+WHERE id = (SELECT id FROM next)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE job_queue SET status = 'done', processed_at = NOW(), error = NULL
+WHERE id = $1;
+
+-- name: FailJob :exec
+-- status is pending for a job that will be retried at run_at, failed for
+-- one that has used up its attempts.
+UPDATE job_queue SET status = sqlc.arg(status), run_at = sqlc.arg(run_at), error = sqlc.arg(error), processed_at = NOW()
+WHERE id = sqlc.arg(id);
@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE audit_log(
+    id UUID PRIMARY KEY NOT NULL,
+    actor_id UUID,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip_address TEXT,
+    created_at TIMESTAMP
+);
+CREATE INDEX audit_log_actor_id_created_at_idx ON audit_log(actor_id, created_at);
+
+-- +goose Down
+DROP TABLE audit_log;
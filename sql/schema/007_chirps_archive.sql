@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE chirps_archive(
+    id UUID PRIMARY KEY NOT NULL,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP,
+    body TEXT,
+    user_id UUID NOT NULL,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    archived_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE chirps_archive;
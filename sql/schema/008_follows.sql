@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE follows(
+    follower_id UUID NOT NULL,
+    followee_id UUID NOT NULL,
+    created_at TIMESTAMP,
+    PRIMARY KEY(follower_id, followee_id),
+    FOREIGN KEY(follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(followee_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX follows_followee_id_idx ON follows(followee_id);
+
+-- +goose Down
+DROP TABLE follows;
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN parent_id UUID REFERENCES chirps(id) ON DELETE SET NULL;
+CREATE INDEX chirps_parent_id_idx ON chirps(parent_id);
+ALTER TABLE chirps_archive ADD COLUMN parent_id UUID;
+
+CREATE TABLE chirp_likes(
+    chirp_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP,
+    PRIMARY KEY(chirp_id, user_id),
+    FOREIGN KEY(chirp_id) REFERENCES chirps(id) ON DELETE CASCADE,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- The like_count and reply_count sub-queries in GetChirps/GetChirpByID are
+-- answered by the chirp_likes primary key (chirp_id leads) and
+-- chirps_parent_id_idx. Check the plan after migrating with:
+--   EXPLAIN ANALYZE SELECT (SELECT COUNT(*) FROM chirp_likes WHERE chirp_id = c.id),
+--       (SELECT COUNT(*) FROM chirps r WHERE r.parent_id = c.id) FROM chirps c;
+-- Both sub-plans should show an Index Only Scan.
+
+-- +goose Down
+DROP TABLE chirp_likes;
+ALTER TABLE chirps_archive DROP COLUMN parent_id;
+DROP INDEX chirps_parent_id_idx;
+ALTER TABLE chirps DROP COLUMN parent_id;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN is_nsfw BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chirps_archive ADD COLUMN is_nsfw BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN is_nsfw;
+ALTER TABLE chirps DROP COLUMN is_nsfw;
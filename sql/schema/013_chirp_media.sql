@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE chirp_media (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP
+);
+CREATE INDEX chirp_media_chirp_id_idx ON chirp_media (chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_media;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TYPE notification_type AS ENUM ('like', 'reply', 'mention', 'follow', 'repost');
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY,
+    recipient_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type notification_type NOT NULL,
+    chirp_id UUID REFERENCES chirps(id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP
+);
+CREATE INDEX notifications_recipient_id_idx ON notifications (recipient_id, created_at);
+
+-- +goose Down
+DROP TABLE notifications;
+DROP TYPE notification_type;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE lists (
+    id UUID PRIMARY KEY,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_public BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP,
+    updated_at TIMESTAMP
+);
+CREATE INDEX lists_owner_id_idx ON lists (owner_id);
+
+CREATE TABLE list_members (
+    list_id UUID NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP,
+    PRIMARY KEY (list_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE list_members;
+DROP TABLE lists;
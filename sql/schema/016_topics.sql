@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE chirp_topics (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    topic TEXT NOT NULL,
+    PRIMARY KEY (chirp_id, topic)
+);
+CREATE INDEX chirp_topics_topic_idx ON chirp_topics (topic);
+
+CREATE TABLE topic_subscriptions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic TEXT NOT NULL,
+    created_at TIMESTAMP,
+    PRIMARY KEY (user_id, topic)
+);
+
+-- +goose Down
+DROP TABLE topic_subscriptions;
+DROP TABLE chirp_topics;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chirps_archive ADD COLUMN is_hidden BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN is_admin;
+ALTER TABLE chirps_archive DROP COLUMN is_hidden;
+ALTER TABLE chirps DROP COLUMN is_hidden;
@@ -0,0 +1,8 @@
+-- +goose Up
+CREATE TABLE blocked_email_domains (
+    domain TEXT PRIMARY KEY,
+    created_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE blocked_email_domains;
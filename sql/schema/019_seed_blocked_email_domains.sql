@@ -0,0 +1,13 @@
+-- +goose Up
+INSERT INTO blocked_email_domains (domain, created_at) VALUES
+    ('mailinator.com', NOW()),
+    ('guerrillamail.com', NOW()),
+    ('10minutemail.com', NOW()),
+    ('tempmail.com', NOW()),
+    ('yopmail.com', NOW())
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM blocked_email_domains WHERE domain IN (
+    'mailinator.com', 'guerrillamail.com', '10minutemail.com', 'tempmail.com', 'yopmail.com'
+);
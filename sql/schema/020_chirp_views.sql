@@ -0,0 +1,12 @@
+-- +goose Up
+-- viewer_key is "u:<user id>" for authenticated viewers and "ip:<sha256 of
+-- the client IP>" for anonymous ones, so each viewer counts once per chirp.
+CREATE TABLE chirp_views (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    viewer_key TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, viewer_key)
+);
+
+-- +goose Down
+DROP TABLE chirp_views;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE chirp_translations (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    language TEXT NOT NULL,
+    translated_body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, language)
+);
+
+-- +goose Down
+DROP TABLE chirp_translations;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Existing chirps keep 0 until rewritten; the counts are computed in Go
+-- (chirpMetrics) and cannot be reproduced faithfully in SQL.
+ALTER TABLE chirps ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chirps ADD COLUMN reading_time_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN reading_time_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN reading_time_seconds;
+ALTER TABLE chirps_archive DROP COLUMN word_count;
+ALTER TABLE chirps DROP COLUMN reading_time_seconds;
+ALTER TABLE chirps DROP COLUMN word_count;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TYPE chirp_visibility AS ENUM ('public', 'mutual');
+ALTER TABLE chirps ADD COLUMN visibility chirp_visibility NOT NULL DEFAULT 'public';
+ALTER TABLE chirps_archive ADD COLUMN visibility chirp_visibility NOT NULL DEFAULT 'public';
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN visibility;
+ALTER TABLE chirps DROP COLUMN visibility;
+DROP TYPE chirp_visibility;
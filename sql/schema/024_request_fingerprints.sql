@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE request_fingerprints (
+    fingerprint TEXT PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE request_fingerprints;
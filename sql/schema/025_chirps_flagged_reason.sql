@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN flagged_reason TEXT;
+ALTER TABLE chirps_archive ADD COLUMN flagged_reason TEXT;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN flagged_reason;
+ALTER TABLE chirps DROP COLUMN flagged_reason;
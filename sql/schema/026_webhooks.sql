@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    url TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL,
+    attempt_number INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMP NOT NULL
+);
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, delivered_at);
+
+-- +goose Down
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
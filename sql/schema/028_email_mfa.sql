@@ -0,0 +1,16 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE email_otp_sessions(
+    token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    otp_hash TEXT NOT NULL,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE email_otp_sessions;
+ALTER TABLE users DROP COLUMN email_mfa_enabled;
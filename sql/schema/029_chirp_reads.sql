@@ -0,0 +1,14 @@
+-- +goose Up
+-- chirp_reads records which chirps each user has read, unlike chirp_views,
+-- which only counts distinct viewers per chirp.
+CREATE TABLE chirp_reads (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    view_count INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+-- +goose Down
+DROP TABLE chirp_reads;
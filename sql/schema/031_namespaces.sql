@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE namespaces(
+    name TEXT PRIMARY KEY,
+    -- NULL falls back to the deployment's CHIRPS_PER_MINUTE.
+    chirps_per_minute INTEGER,
+    features TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+INSERT INTO namespaces (name) VALUES ('default');
+ALTER TABLE users ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default' REFERENCES namespaces(name);
+ALTER TABLE chirps ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default' REFERENCES namespaces(name);
+ALTER TABLE chirps_archive ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+CREATE INDEX users_namespace_email_idx ON users(namespace, email);
+CREATE INDEX chirps_namespace_created_at_idx ON chirps(namespace, created_at);
+
+-- +goose Down
+DROP INDEX chirps_namespace_created_at_idx;
+DROP INDEX users_namespace_email_idx;
+ALTER TABLE chirps_archive DROP COLUMN namespace;
+ALTER TABLE chirps DROP COLUMN namespace;
+ALTER TABLE users DROP COLUMN namespace;
+DROP TABLE namespaces;
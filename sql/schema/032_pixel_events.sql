@@ -0,0 +1,14 @@
+-- +goose Up
+-- pixel_events logs each load of a chirp's tracking pixel, the embeds and
+-- shared links that impression_count alone cannot tell apart.
+CREATE TABLE pixel_events (
+    id UUID PRIMARY KEY NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    referer TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX pixel_events_chirp_id_created_at_idx ON pixel_events(chirp_id, created_at);
+
+-- +goose Down
+DROP TABLE pixel_events;
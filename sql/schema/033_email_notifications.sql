@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN email_notifications BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN email_notifications;
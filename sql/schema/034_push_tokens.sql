@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TYPE push_platform AS ENUM ('ios', 'android', 'web');
+
+-- A device token belongs to whichever user registered it last, so signing
+-- into another account on the same device moves it.
+CREATE TABLE push_tokens (
+    device_token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform push_platform NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX push_tokens_user_id_idx ON push_tokens(user_id);
+
+-- +goose Down
+DROP TABLE push_tokens;
+DROP TYPE push_platform;
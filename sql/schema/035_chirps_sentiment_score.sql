@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN sentiment_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN sentiment_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN sentiment_score;
+ALTER TABLE chirps DROP COLUMN sentiment_score;
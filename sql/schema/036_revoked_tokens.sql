@@ -0,0 +1,11 @@
+-- +goose Up
+-- Access tokens revoked before they expire, by jti. Rows past expires_at
+-- can be dropped since the token would be rejected anyway.
+CREATE TABLE revoked_tokens (
+    jti TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE revoked_tokens;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Ed25519 public keys backend services sign requests with. A signed
+-- request acts as user_id.
+CREATE TABLE signing_keys (
+    key_id TEXT PRIMARY KEY,
+    public_key BYTEA NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE signing_keys;
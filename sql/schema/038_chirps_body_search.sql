@@ -0,0 +1,6 @@
+-- +goose Up
+-- Matches the expression SearchChirps ranks by, so searches can use it.
+CREATE INDEX chirps_body_search_idx ON chirps USING GIN (to_tsvector('english', body));
+
+-- +goose Down
+DROP INDEX chirps_body_search_idx;
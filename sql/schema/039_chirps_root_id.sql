@@ -0,0 +1,25 @@
+-- +goose Up
+-- root_id is the top-level chirp of the thread a chirp belongs to, itself
+-- for top-level chirps, so a whole thread is one indexed lookup.
+ALTER TABLE chirps ADD COLUMN root_id UUID;
+
+WITH RECURSIVE roots AS (
+    SELECT id, id AS root_id FROM chirps WHERE parent_id IS NULL
+  UNION ALL
+    SELECT chirps.id, roots.root_id
+    FROM chirps
+    JOIN roots ON chirps.parent_id = roots.id
+)
+UPDATE chirps SET root_id = roots.root_id
+FROM roots
+WHERE chirps.id = roots.id;
+
+ALTER TABLE chirps ALTER COLUMN root_id SET NOT NULL;
+CREATE INDEX chirps_root_id_created_at_idx ON chirps(root_id, created_at);
+
+ALTER TABLE chirps_archive ADD COLUMN root_id UUID;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN root_id;
+DROP INDEX chirps_root_id_created_at_idx;
+ALTER TABLE chirps DROP COLUMN root_id;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- Short codes for sharing chirps; GET /s/{code} redirects to the chirp.
+CREATE TABLE short_links (
+    code TEXT PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    click_count BIGINT NOT NULL DEFAULT 0
+);
+
+-- +goose Down
+DROP TABLE short_links;
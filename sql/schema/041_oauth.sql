@@ -0,0 +1,26 @@
+-- +goose Up
+-- Third-party apps that sign users in through Chirpy. Each registers the
+-- one redirect_uri authorization codes may be sent to.
+CREATE TABLE oauth_clients (
+    id TEXT PRIMARY KEY,
+    secret_hash TEXT NOT NULL,
+    name TEXT NOT NULL,
+    redirect_uri TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Authorization codes awaiting exchange for tokens. A code is deleted when
+-- it is exchanged, so each works once.
+CREATE TABLE oauth_codes (
+    code TEXT PRIMARY KEY,
+    client_id TEXT NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE oauth_codes;
+DROP TABLE oauth_clients;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- importance_score ranks chirps for GET /api/chirps?sort=importance. The
+-- importance scorer recomputes it for recent chirps; older scores are
+-- frozen.
+ALTER TABLE chirps ADD COLUMN importance_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+CREATE INDEX chirps_importance_score_idx ON chirps(importance_score DESC);
+
+-- +goose Down
+DROP INDEX chirps_importance_score_idx;
+ALTER TABLE chirps DROP COLUMN importance_score;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Accounts at third-party OAuth providers linked to Chirpy users. A user
+-- links at most one account per provider, and a provider account belongs
+-- to at most one user.
+CREATE TABLE oauth_identities (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider TEXT NOT NULL,
+    provider_user_id TEXT NOT NULL,
+    access_token_encrypted BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, provider),
+    UNIQUE (provider, provider_user_id)
+);
+
+-- +goose Down
+DROP TABLE oauth_identities;
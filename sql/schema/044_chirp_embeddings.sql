@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS vector;
+
+-- One embedding per chirp, from the model named by model_version.
+CREATE TABLE chirp_embeddings (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    embedding vector(384) NOT NULL,
+    model_version TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX chirp_embeddings_embedding_idx ON chirp_embeddings USING hnsw (embedding vector_cosine_ops);
+
+-- +goose Down
+DROP TABLE chirp_embeddings;
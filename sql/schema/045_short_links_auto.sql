@@ -0,0 +1,15 @@
+-- +goose Up
+-- Links Chirpy makes for long URLs in chirp bodies point at target_url
+-- rather than a chirp. chirp_id is the chirp whose body uses them, set
+-- once it is stored.
+ALTER TABLE short_links ALTER COLUMN chirp_id DROP NOT NULL;
+ALTER TABLE short_links ADD COLUMN target_url TEXT;
+ALTER TABLE short_links ADD COLUMN auto_generated BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX short_links_chirp_id_idx ON short_links(chirp_id) WHERE auto_generated;
+
+-- +goose Down
+DROP INDEX short_links_chirp_id_idx;
+DELETE FROM short_links WHERE chirp_id IS NULL;
+ALTER TABLE short_links DROP COLUMN auto_generated;
+ALTER TABLE short_links DROP COLUMN target_url;
+ALTER TABLE short_links ALTER COLUMN chirp_id SET NOT NULL;
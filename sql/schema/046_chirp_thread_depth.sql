@@ -0,0 +1,18 @@
+-- +goose Up
+-- thread_depth is how many replies deep a chirp is, 0 for top-level
+-- chirps, so reply chains can be capped without walking them.
+ALTER TABLE chirps ADD COLUMN thread_depth INT NOT NULL DEFAULT 0;
+
+WITH RECURSIVE depths AS (
+    SELECT id, 0 AS depth FROM chirps WHERE parent_id IS NULL
+  UNION ALL
+    SELECT chirps.id, depths.depth + 1
+    FROM chirps
+    JOIN depths ON chirps.parent_id = depths.id
+)
+UPDATE chirps SET thread_depth = depths.depth
+FROM depths
+WHERE chirps.id = depths.id AND depths.depth > 0;
+
+-- +goose Down
+ALTER TABLE chirps DROP COLUMN thread_depth;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- content_warning is shown in place of a chirp until the reader opens it.
+-- Authors set it on their own chirps.
+ALTER TABLE chirps ADD COLUMN content_warning TEXT;
+
+-- +goose Down
+ALTER TABLE chirps DROP COLUMN content_warning;
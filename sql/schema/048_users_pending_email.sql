@@ -0,0 +1,11 @@
+-- +goose Up
+-- An email change waits in pending_email until the new address confirms it
+-- with pending_email_token; until then users sign in with the old one.
+ALTER TABLE users ADD COLUMN pending_email TEXT;
+ALTER TABLE users ADD COLUMN pending_email_token TEXT UNIQUE;
+ALTER TABLE users ADD COLUMN pending_email_expires_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN pending_email_expires_at;
+ALTER TABLE users DROP COLUMN pending_email_token;
+ALTER TABLE users DROP COLUMN pending_email;
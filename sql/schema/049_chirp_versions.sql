@@ -0,0 +1,17 @@
+-- +goose Up
+-- admin_edited marks chirps whose body an admin has corrected.
+ALTER TABLE chirps ADD COLUMN admin_edited BOOLEAN NOT NULL DEFAULT false;
+
+-- Bodies chirps had before an admin replaced them, oldest first.
+CREATE TABLE chirp_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    body TEXT,
+    edited_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX chirp_versions_chirp_id_idx ON chirp_versions(chirp_id, created_at);
+
+-- +goose Down
+DROP TABLE chirp_versions;
+ALTER TABLE chirps DROP COLUMN admin_edited;
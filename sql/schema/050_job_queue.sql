@@ -0,0 +1,36 @@
+-- +goose Up
+-- Background work that has to survive a restart. Claiming a job pushes its
+-- run_at out by a lease, so a job whose worker died runs again once the
+-- lease is up.
+CREATE TYPE job_status AS ENUM ('pending', 'running', 'done', 'failed');
+
+CREATE TABLE job_queue (
+    id BIGSERIAL PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status job_status NOT NULL DEFAULT 'pending',
+    attempt_count INTEGER NOT NULL DEFAULT 0,
+    run_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP,
+    error TEXT
+);
+CREATE INDEX job_queue_due_idx ON job_queue(run_at) WHERE status IN ('pending', 'running');
+
+-- Wake listening workers as soon as a job is queued rather than at their
+-- next poll.
+-- +goose StatementBegin
+CREATE FUNCTION notify_job_queue() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('job_queue', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+CREATE TRIGGER job_queue_notify AFTER INSERT ON job_queue
+FOR EACH ROW EXECUTE FUNCTION notify_job_queue();
+
+-- +goose Down
+DROP TABLE job_queue;
+DROP FUNCTION notify_job_queue();
+DROP TYPE job_status;
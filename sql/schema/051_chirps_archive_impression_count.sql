@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE chirps_archive ADD COLUMN impression_count BIGINT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN impression_count;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE chirps_archive ADD COLUMN thread_depth INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN thread_depth;
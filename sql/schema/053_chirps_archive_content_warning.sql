@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE chirps_archive ADD COLUMN content_warning TEXT;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN content_warning;
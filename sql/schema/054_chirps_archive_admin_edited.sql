@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE chirps_archive ADD COLUMN admin_edited BOOLEAN NOT NULL DEFAULT false;
+
+-- Archiving deletes the chirp from chirps, and the cascade took its
+-- versions with it. They now outlive the chirp; PurgeDeletedChirpsBefore
+-- removes them when a chirp is purged for good.
+ALTER TABLE chirp_versions DROP CONSTRAINT chirp_versions_chirp_id_fkey;
+
+-- +goose Down
+DELETE FROM chirp_versions WHERE chirp_id NOT IN (SELECT id FROM chirps);
+ALTER TABLE chirp_versions ADD CONSTRAINT chirp_versions_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE;
+ALTER TABLE chirps_archive DROP COLUMN admin_edited;
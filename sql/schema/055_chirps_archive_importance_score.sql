@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE chirps_archive ADD COLUMN importance_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN importance_score;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Archiving deletes a chirp from chirps, and the cascade took the short
+-- links in its body with it, breaking /s/{code} wherever they were shared.
+-- Auto-generated links redirect by target_url alone, so they now only lose
+-- their chirp_id; ClickShortLink ignores share links left without one.
+ALTER TABLE short_links DROP CONSTRAINT short_links_chirp_id_fkey;
+ALTER TABLE short_links ADD CONSTRAINT short_links_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM short_links WHERE chirp_id IS NULL AND NOT auto_generated;
+ALTER TABLE short_links DROP CONSTRAINT short_links_chirp_id_fkey;
+ALTER TABLE short_links ADD CONSTRAINT short_links_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE;
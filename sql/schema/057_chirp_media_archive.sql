@@ -0,0 +1,16 @@
+-- +goose Up
+-- Media of archived chirps. Archiving deletes the chirp from chirps, and
+-- chirp_media's cascade took the attachments with it.
+CREATE TABLE chirp_media_archive (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP
+);
+CREATE INDEX chirp_media_archive_chirp_id_idx ON chirp_media_archive (chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_media_archive;
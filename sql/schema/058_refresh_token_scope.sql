@@ -0,0 +1,7 @@
+-- +goose Up
+-- The scope of the access tokens a refresh token mints, so refreshing an
+-- OAuth read token does not hand back a full-access one.
+ALTER TABLE refresh_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE refresh_tokens DROP COLUMN scope;
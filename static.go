@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// defaultStaticExtensions is ALLOWED_STATIC_EXTENSIONS when it is unset.
+const defaultStaticExtensions = ".html,.css,.js,.png,.jpg,.ico,.svg,.woff2"
+
+// parseStaticExtensions parses a comma-separated list of file extensions,
+// with or without the leading dot. An empty list means
+// defaultStaticExtensions.
+func parseStaticExtensions(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		s = defaultStaticExtensions
+	}
+	var exts []string
+	for e := range strings.SplitSeq(s, ",") {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if e == "" {
+			continue
+		}
+		if !strings.HasPrefix(e, ".") {
+			e = "." + e
+		}
+		exts = append(exts, e)
+	}
+	return exts
+}
+
+// safeFileServer serves the files in dir whose extension is in the
+// comma-separated allowedExts, and refuses the rest with 403, so source,
+// env and source map files next to the app are never handed out.
+// Directory paths, ending in a slash or empty once a prefix is stripped,
+// pass through for the index page.
+func safeFileServer(dir, allowedExts string) http.Handler {
+	exts := parseStaticExtensions(allowedExts)
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dirPath := r.URL.Path == "" || strings.HasSuffix(r.URL.Path, "/")
+		if !dirPath && !slices.Contains(exts, strings.ToLower(filepath.Ext(r.URL.Path))) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
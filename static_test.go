@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSafeFileServer(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"index.html", "page.html", "app.js", "main.go", ".env", "app.js.map"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := safeFileServer(dir, "")
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/main.go", http.StatusForbidden},
+		{"/.env", http.StatusForbidden},
+		{"/app.js.map", http.StatusForbidden},
+		{"/MAIN.GO", http.StatusForbidden},
+		{"/page.html", http.StatusOK},
+		{"/app.js", http.StatusOK},
+		{"/", http.StatusOK},
+		{"/missing.js", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", c.path, nil))
+		if rec.Code != c.want {
+			t.Errorf("%s: got status %d, want %d", c.path, rec.Code, c.want)
+		}
+	}
+
+	h = safeFileServer(dir, "go, map")
+	for path, want := range map[string]int{"/main.go": http.StatusOK, "/app.js.map": http.StatusOK, "/app.js": http.StatusForbidden} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("custom list, %s: got status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestAppServesOnlyAllowedFiles(t *testing.T) {
+	h := newServer("0", newTestConfig(newMemStore())).Handler
+	for path, want := range map[string]int{"/app/main.go": http.StatusForbidden, "/app/go.mod": http.StatusForbidden, "/app/": http.StatusOK} {
+		if rec := serve(h, "GET", path, "", ""); rec.Code != want {
+			t.Errorf("%s: got status %d, want %d", path, rec.Code, want)
+		}
+	}
+}
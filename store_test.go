@@ -0,0 +1,2332 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	"github.com/azs06/Chirpy/internal/auth"
+	"github.com/azs06/Chirpy/internal/database"
+)
+
+// memStore is an in-memory database.Querier for handler tests. Queries a
+// test doesn't exercise fall through to the nil embedded Querier and panic.
+type memStore struct {
+	database.Querier
+
+	mu            sync.Mutex
+	users         []database.User
+	chirps        []database.Chirp
+	archive       []database.ChirpsArchive
+	mediaArchive  []database.ChirpMediaArchive
+	refreshTokens []database.RefreshToken
+	auditLogs     []database.AuditLog
+	follows       []database.Follow
+	likes         []database.ChirpLike
+	media         []database.ChirpMedium
+	notifications []database.Notification
+	lists         []database.List
+	listMembers   []database.ListMember
+	chirpTopics   []database.ChirpTopic
+	topicSubs     []database.TopicSubscription
+	blocked       []string
+	views         []database.ChirpView
+	translations  []database.ChirpTranslation
+	fingerprints  []database.RequestFingerprint
+	webhooks      []database.Webhook
+	deliveries    []database.WebhookDelivery
+	otpSessions   []database.EmailOtpSession
+	reads         []database.ChirpRead
+	namespaces    []database.Namespace
+	pixelEvents   []database.PixelEvent
+	pushTokens    []database.PushToken
+	revoked       []database.RevokedToken
+	signingKeys   []database.SigningKey
+	shortLinks    []database.ShortLink
+	oauthClients  []database.OauthClient
+	oauthCodes    []database.OauthCode
+	identities    []database.OauthIdentity
+	chirpVersions []database.ChirpVersion
+	jobs          []database.JobQueue
+	// embeddings holds each chirp's embedding in pgvector's text form.
+	embeddings map[uuid.UUID]string
+
+	// revokedLookups counts IsTokenRevoked calls.
+	revokedLookups int
+
+	// activityParams records the last GetUserActivity call.
+	activityParams database.GetUserActivityParams
+	// leaderboardQueries counts GetLeaderboard calls.
+	leaderboardQueries int
+	// exportBatches counts GetChirpsForExport calls.
+	exportBatches int
+	// chirpCountQueries counts CountUserChirps calls.
+	chirpCountQueries int
+}
+
+func newMemStore() *memStore {
+	return &memStore{}
+}
+
+func newTestConfig(store database.Querier) *apiConfig {
+	cfg := &apiConfig{
+		platform:    "dev",
+		db:          store,
+		tokenSecret: "test-secret",
+		polkaKey:    "test-polka-key",
+		apiVersion:  "1.0",
+
+		mediaSigningKey: []byte("test-media-key"),
+
+		adminAllowedCIDRs: []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")},
+		pushSender:        NoopPushSender{},
+		embedder:          NoopEmbedder{},
+		mailer:            &MockMailer{},
+		experiments:       newExperimentRegistry(""),
+	}
+	cfg.jobs = newJobQueue(store, time.Now)
+	cfg.registerJobHandlers()
+	return cfg
+}
+
+// serve runs a single request through h, authenticating with token when it
+// is non-empty.
+func serve(h http.Handler, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// seedUser inserts a user directly into the store and returns it with a
+// valid access token for cfg.
+func seedUser(t *testing.T, cfg *apiConfig, store *memStore, email string) (database.User, string) {
+	t.Helper()
+	u, _ := store.CreateUser(context.Background(), database.CreateUserParams{
+		Email: sql.NullString{String: email, Valid: true},
+	})
+	token, err := auth.MakeJWT(u.ID, cfg.tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT failed: %v", err)
+	}
+	return u, token
+}
+
+func nullNow() sql.NullTime {
+	return sql.NullTime{Time: time.Now(), Valid: true}
+}
+
+func (s *memStore) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := database.User{
+		ID:             uuid.New(),
+		CreatedAt:      nullNow(),
+		UpdatedAt:      nullNow(),
+		Email:          arg.Email,
+		HashedPassword: arg.HashedPassword,
+		Namespace:      arg.Namespace,
+		// Matches the column default.
+		EmailNotifications: true,
+	}
+	if u.Namespace == "" {
+		u.Namespace = defaultNamespace
+	}
+	s.users = append(s.users, u)
+	return u, nil
+}
+
+func (s *memStore) GetUserByEmail(ctx context.Context, arg database.GetUserByEmailParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.Email == arg.Email && inNamespace(u.Namespace, arg.Namespace) {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetUsersByIds(ctx context.Context, arg database.GetUsersByIdsParams) ([]database.GetUsersByIdsRow, error) {
+	var rows []database.GetUsersByIdsRow
+	for _, id := range arg.Ids {
+		row, err := s.GetUserById(ctx, database.GetUserByIdParams{ID: id, Namespace: arg.Namespace})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, database.GetUsersByIdsRow(row))
+	}
+	return rows, nil
+}
+
+func (s *memStore) GetUserById(ctx context.Context, arg database.GetUserByIdParams) (database.GetUserByIdRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := arg.ID
+	for _, u := range s.users {
+		if u.ID != id || !inNamespace(u.Namespace, arg.Namespace) {
+			continue
+		}
+		row := database.GetUserByIdRow{User: u}
+		for _, f := range s.follows {
+			if f.FolloweeID == id {
+				row.FollowersCount++
+			}
+			if f.FollowerID == id {
+				row.FollowingCount++
+			}
+		}
+		for _, c := range s.chirps {
+			if c.DeletedAt.Valid {
+				continue
+			}
+			if c.UserID == id {
+				row.ChirpsCount++
+			}
+		}
+		return row, nil
+	}
+	return database.GetUserByIdRow{}, sql.ErrNoRows
+}
+
+func (s *memStore) CreateChirp(ctx context.Context, arg database.CreateChirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := database.Chirp{
+		ID:        uuid.New(),
+		CreatedAt: nullNow(),
+		UpdatedAt: nullNow(),
+		Body:      arg.Body,
+		UserID:    arg.UserID,
+		ParentID:  arg.ParentID,
+		IsNsfw:    arg.IsNsfw,
+
+		WordCount:          arg.WordCount,
+		ReadingTimeSeconds: arg.ReadingTimeSeconds,
+		Visibility:         arg.Visibility,
+		FlaggedReason:      arg.FlaggedReason,
+		SentimentScore:     arg.SentimentScore,
+		Namespace:          s.userByID(arg.UserID).Namespace,
+	}
+	c.RootID = c.ID
+	for _, parent := range s.chirps {
+		if arg.ParentID.Valid && parent.ID == arg.ParentID.UUID {
+			c.RootID = parent.RootID
+			c.ThreadDepth = parent.ThreadDepth + 1
+		}
+	}
+	s.chirps = append(s.chirps, c)
+	for i, url := range arg.MediaUrls {
+		s.media = append(s.media, database.ChirpMedium{
+			ID:        uuid.New(),
+			ChirpID:   c.ID,
+			Url:       url,
+			MimeType:  arg.MediaMimeTypes[i],
+			AltText:   arg.MediaAltTexts[i],
+			Position:  int32(i),
+			CreatedAt: nullNow(),
+		})
+	}
+	return c, nil
+}
+
+// counts returns the like and reply counts the chirp queries compute with
+// sub-queries.
+func (s *memStore) counts(id uuid.UUID) (likes, replies int64) {
+	for _, l := range s.likes {
+		if l.ChirpID == id {
+			likes++
+		}
+	}
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid {
+			continue
+		}
+		if c.ParentID.Valid && c.ParentID.UUID == id {
+			replies++
+		}
+	}
+	return likes, replies
+}
+
+func (s *memStore) CountUserChirps(ctx context.Context, userID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chirpCountQueries++
+	var n int64
+	for _, c := range s.chirps {
+		if c.UserID == userID && !c.DeletedAt.Valid {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memStore) GetChirpByID(ctx context.Context, arg database.GetChirpByIDParams) (database.GetChirpByIDRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid {
+			continue
+		}
+		if c.ID == arg.ID && inNamespace(c.Namespace, arg.Namespace) {
+			likes, replies := s.counts(c.ID)
+			return database.GetChirpByIDRow{Chirp: c, LikeCount: likes, ReplyCount: replies}, nil
+		}
+	}
+	return database.GetChirpByIDRow{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetChirps(ctx context.Context, arg database.GetChirpsParams) ([]database.GetChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetChirpsRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || c.SentimentScore < arg.MinSentiment {
+			continue
+		}
+		if (c.IsHidden && !arg.IncludeHidden) || (arg.VerifiedOnly && !s.userByID(c.UserID).IsVerified) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		likes, replies := s.counts(c.ID)
+		items = append(items, database.GetChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	if arg.ByImportance {
+		slices.SortStableFunc(items, func(a, b database.GetChirpsRow) int {
+			return cmp.Compare(b.Chirp.ImportanceScore, a.Chirp.ImportanceScore)
+		})
+	}
+	return items, nil
+}
+
+// SearchChirps approximates full-text search: every query word must appear
+// in the body, the last as a prefix in prefix mode, and rank is the number
+// of matching words.
+func (s *memStore) SearchChirps(ctx context.Context, arg database.SearchChirpsParams) ([]database.SearchChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	words := strings.Fields(strings.ToLower(arg.Query))
+	if arg.Prefix {
+		words = strings.Split(strings.TrimSuffix(strings.ToLower(arg.Query), ":*"), " & ")
+	}
+	var items []database.SearchChirpsRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || c.SentimentScore < arg.MinSentiment {
+			continue
+		}
+		if (arg.AuthorID != uuid.Nil && c.UserID != arg.AuthorID) || (c.IsHidden && !arg.IncludeHidden) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		tokens := strings.FieldsFunc(strings.ToLower(c.Body.String), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		rank := 0
+		for i, w := range words {
+			n := 0
+			for _, tok := range tokens {
+				if tok == w || (arg.Prefix && i == len(words)-1 && strings.HasPrefix(tok, w)) {
+					n++
+				}
+			}
+			if n == 0 {
+				rank = 0
+				break
+			}
+			rank += n
+		}
+		if rank > 0 {
+			likes, replies := s.counts(c.ID)
+			items = append(items, database.SearchChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies, Rank: float64(rank)})
+		}
+	}
+	slices.SortStableFunc(items, func(a, b database.SearchChirpsRow) int {
+		if a.Rank != b.Rank {
+			return cmp.Compare(b.Rank, a.Rank)
+		}
+		return b.Chirp.CreatedAt.Time.Compare(a.Chirp.CreatedAt.Time)
+	})
+	return items, nil
+}
+
+func (s *memStore) GetChirpsByUserId(ctx context.Context, arg database.GetChirpsByUserIdParams) ([]database.GetChirpsByUserIdRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetChirpsByUserIdRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || c.SentimentScore < arg.MinSentiment {
+			continue
+		}
+		if (c.IsHidden && !arg.IncludeHidden) || (arg.VerifiedOnly && !s.userByID(c.UserID).IsVerified) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		if c.UserID == arg.UserID {
+			likes, replies := s.counts(c.ID)
+			items = append(items, database.GetChirpsByUserIdRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+		}
+	}
+	if arg.ByImportance {
+		slices.SortStableFunc(items, func(a, b database.GetChirpsByUserIdRow) int {
+			return cmp.Compare(b.Chirp.ImportanceScore, a.Chirp.ImportanceScore)
+		})
+	}
+	return items, nil
+}
+
+func (s *memStore) GetChirpsForScoring(ctx context.Context, since time.Time) ([]database.GetChirpsForScoringRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetChirpsForScoringRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || c.CreatedAt.Time.Before(since) {
+			continue
+		}
+		likes, replies := s.counts(c.ID)
+		items = append(items, database.GetChirpsForScoringRow{ID: c.ID, CreatedAt: c.CreatedAt, ImpressionCount: c.ImpressionCount, LikeCount: likes, ReplyCount: replies})
+	}
+	return items, nil
+}
+
+func (s *memStore) SetImportanceScores(ctx context.Context, arg database.SetImportanceScoresParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		if j := slices.Index(arg.Ids, s.chirps[i].ID); j >= 0 {
+			s.chirps[i].ImportanceScore = arg.Scores[j]
+		}
+	}
+	return nil
+}
+
+// GetThreadChirps returns the visible chirps sharing arg.RootID in the
+// order they were created, which is the order s.chirps holds them in.
+func (s *memStore) GetThreadChirps(ctx context.Context, arg database.GetThreadChirpsParams) ([]database.GetThreadChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetThreadChirpsRow
+	for _, c := range s.chirps {
+		if c.RootID != arg.RootID || c.DeletedAt.Valid || c.IsHidden || !inNamespace(c.Namespace, arg.Namespace) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		likes, replies := s.counts(c.ID)
+		items = append(items, database.GetThreadChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return items, nil
+}
+
+// chirpsBeside returns the author's visible chirps created before or after
+// at, nearest first, as GetChirpsBefore and GetChirpsAfter do.
+func (s *memStore) chirpsBeside(userID uuid.UUID, at time.Time, after bool, namespace string, viewer uuid.UUID, limit int32) []database.Chirp {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []database.Chirp
+	for _, c := range s.chirps {
+		if c.UserID != userID || c.DeletedAt.Valid || c.IsHidden || !inNamespace(c.Namespace, namespace) || !s.visibleTo(c, viewer) {
+			continue
+		}
+		if (after && c.CreatedAt.Time.After(at)) || (!after && c.CreatedAt.Time.Before(at)) {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b database.Chirp) int { return a.CreatedAt.Time.Compare(b.CreatedAt.Time) })
+	if !after {
+		slices.Reverse(out)
+	}
+	return out[:min(len(out), int(limit))]
+}
+
+func (s *memStore) GetChirpsBefore(ctx context.Context, arg database.GetChirpsBeforeParams) ([]database.GetChirpsBeforeRow, error) {
+	var rows []database.GetChirpsBeforeRow
+	for _, c := range s.chirpsBeside(arg.UserID, arg.CreatedAt.Time, false, arg.Namespace, arg.ViewerID, arg.MaxChirps) {
+		rows = append(rows, database.GetChirpsBeforeRow{Chirp: c})
+	}
+	return rows, nil
+}
+
+func (s *memStore) GetChirpsAfter(ctx context.Context, arg database.GetChirpsAfterParams) ([]database.GetChirpsAfterRow, error) {
+	var rows []database.GetChirpsAfterRow
+	for _, c := range s.chirpsBeside(arg.UserID, arg.CreatedAt.Time, true, arg.Namespace, arg.ViewerID, arg.MaxChirps) {
+		rows = append(rows, database.GetChirpsAfterRow{Chirp: c})
+	}
+	return rows, nil
+}
+
+func (s *memStore) ImportChirps(ctx context.Context, arg database.ImportChirpsParams) ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []uuid.UUID
+	for i, id := range arg.Ids {
+		if s.userByID(arg.UserIds[i]).ID == uuid.Nil || slices.ContainsFunc(s.chirps, func(c database.Chirp) bool { return c.ID == id }) {
+			continue
+		}
+		created := sql.NullTime{Time: arg.CreatedAts[i], Valid: true}
+		s.chirps = append(s.chirps, database.Chirp{
+			ID:                 id,
+			RootID:             id,
+			CreatedAt:          created,
+			UpdatedAt:          created,
+			Body:               sql.NullString{String: arg.Bodies[i], Valid: true},
+			UserID:             arg.UserIds[i],
+			WordCount:          arg.WordCounts[i],
+			ReadingTimeSeconds: arg.ReadingTimes[i],
+			Visibility:         database.ChirpVisibilityPublic,
+			SentimentScore:     arg.SentimentScores[i],
+			Namespace:          s.userByID(arg.UserIds[i]).Namespace,
+		})
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *memStore) CreateChirpLike(ctx context.Context, arg database.CreateChirpLikeParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.likes {
+		if l.ChirpID == arg.ChirpID && l.UserID == arg.UserID {
+			return 0, nil
+		}
+	}
+	s.likes = append(s.likes, database.ChirpLike{ChirpID: arg.ChirpID, UserID: arg.UserID, CreatedAt: nullNow()})
+	return 1, nil
+}
+
+func (s *memStore) DeleteChirpLike(ctx context.Context, arg database.DeleteChirpLikeParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, l := range s.likes {
+		if l.ChirpID == arg.ChirpID && l.UserID == arg.UserID {
+			s.likes = append(s.likes[:i], s.likes[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetChirpOwners(ctx context.Context, ids []uuid.UUID) ([]database.GetChirpOwnersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []database.GetChirpOwnersRow
+	for _, c := range s.chirps {
+		if slices.Contains(ids, c.ID) {
+			rows = append(rows, database.GetChirpOwnersRow{ID: c.ID, UserID: c.UserID})
+		}
+	}
+	return rows, nil
+}
+
+func (s *memStore) SoftDeleteChirpsByIds(ctx context.Context, arg database.SoftDeleteChirpsByIdsParams) ([]uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted []uuid.UUID
+	for i, c := range s.chirps {
+		if c.UserID == arg.UserID && slices.Contains(arg.Ids, c.ID) && !c.DeletedAt.Valid {
+			s.chirps[i].DeletedAt = nullNow()
+			deleted = append(deleted, c.ID)
+		}
+	}
+	return deleted, nil
+}
+
+func (s *memStore) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.chirps {
+		if c.ID == id && !c.DeletedAt.Valid {
+			s.chirps[i].DeletedAt = nullNow()
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetDeletedChirpsByUser(ctx context.Context, userID uuid.UUID) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.UserID == userID && c.DeletedAt.Valid {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		return b.DeletedAt.Time.Compare(a.DeletedAt.Time)
+	})
+	return items, nil
+}
+
+func (s *memStore) RestoreChirp(ctx context.Context, arg database.RestoreChirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.chirps {
+		if c.ID == arg.ID && c.UserID == arg.UserID && c.DeletedAt.Valid && !c.DeletedAt.Time.Before(arg.DeletedSince) {
+			s.chirps[i].DeletedAt = sql.NullTime{}
+			return s.chirps[i], nil
+		}
+	}
+	return database.Chirp{}, sql.ErrNoRows
+}
+
+func (s *memStore) PurgeDeletedChirpsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	purged := map[uuid.UUID]bool{}
+	n := len(s.chirps)
+	s.chirps = slices.DeleteFunc(s.chirps, func(c database.Chirp) bool {
+		if c.DeletedAt.Valid && c.DeletedAt.Time.Before(cutoff) {
+			purged[c.ID] = true
+		}
+		return purged[c.ID]
+	})
+	s.chirpVersions = slices.DeleteFunc(s.chirpVersions, func(v database.ChirpVersion) bool {
+		return purged[v.ChirpID]
+	})
+	return int64(n - len(s.chirps)), nil
+}
+
+func (s *memStore) CreateRefreshToken(ctx context.Context, arg database.CreateRefreshTokenParams) (database.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := database.RefreshToken{
+		Token:     arg.Token,
+		CreatedAt: nullNow(),
+		UpdatedAt: nullNow(),
+		UserID:    arg.UserID,
+		ExpiresAt: arg.ExpiresAt,
+		RevokedAt: arg.RevokedAt,
+		Scope:     arg.Scope,
+	}
+	s.refreshTokens = append(s.refreshTokens, t)
+	return t, nil
+}
+
+func (s *memStore) CreateAuditLog(ctx context.Context, arg database.CreateAuditLogParams) (database.AuditLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := database.AuditLog{
+		ID:         uuid.New(),
+		ActorID:    arg.ActorID,
+		Action:     arg.Action,
+		EntityType: arg.EntityType,
+		EntityID:   arg.EntityID,
+		Details:    arg.Details,
+		IpAddress:  arg.IpAddress,
+		CreatedAt:  nullNow(),
+	}
+	s.auditLogs = append(s.auditLogs, e)
+	return e, nil
+}
+
+func (s *memStore) GetAuditLogs(ctx context.Context, arg database.GetAuditLogsParams) ([]database.AuditLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.AuditLog
+	for i := len(s.auditLogs) - 1; i >= 0; i-- {
+		e := s.auditLogs[i]
+		if !e.CreatedAt.Time.Before(arg.Since.Time) && e.CreatedAt.Time.Before(arg.Until.Time) {
+			items = append(items, e)
+		}
+	}
+	return items, nil
+}
+
+func (s *memStore) ArchiveChirpsBefore(ctx context.Context, cutoff sql.NullTime) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var kept []database.Chirp
+	var n int64
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid {
+			kept = append(kept, c)
+			continue
+		}
+		if !c.CreatedAt.Time.Before(cutoff.Time) {
+			kept = append(kept, c)
+			continue
+		}
+		s.archive = append(s.archive, database.ChirpsArchive{
+			ID:         c.ID,
+			CreatedAt:  c.CreatedAt,
+			UpdatedAt:  c.UpdatedAt,
+			Body:       c.Body,
+			UserID:     c.UserID,
+			ParentID:   c.ParentID,
+			IsNsfw:     c.IsNsfw,
+			IsHidden:   c.IsHidden,
+			ArchivedAt: nullNow(),
+
+			WordCount:          c.WordCount,
+			ReadingTimeSeconds: c.ReadingTimeSeconds,
+			Visibility:         c.Visibility,
+			FlaggedReason:      c.FlaggedReason,
+			ImpressionCount:    c.ImpressionCount,
+			ThreadDepth:        c.ThreadDepth,
+			ContentWarning:     c.ContentWarning,
+			AdminEdited:        c.AdminEdited,
+			ImportanceScore:    c.ImportanceScore,
+		})
+		for i := range s.shortLinks {
+			if s.shortLinks[i].ChirpID.UUID == c.ID {
+				s.shortLinks[i].ChirpID = uuid.NullUUID{}
+			}
+		}
+		s.media = slices.DeleteFunc(s.media, func(m database.ChirpMedium) bool {
+			if m.ChirpID != c.ID {
+				return false
+			}
+			s.mediaArchive = append(s.mediaArchive, database.ChirpMediaArchive(m))
+			return true
+		})
+		n++
+	}
+	s.chirps = kept
+	return n, nil
+}
+
+func (s *memStore) GetArchivedChirps(ctx context.Context) ([]database.ChirpsArchive, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]database.ChirpsArchive(nil), s.archive...), nil
+}
+
+// visibleTo mirrors the visibility clause of the chirp queries. Chirps
+// built directly in tests have no visibility and count as public.
+// inNamespace matches a row's namespace against a query's, reading an unset
+// row namespace as the column default.
+func inNamespace(rowNamespace, namespace string) bool {
+	if rowNamespace == "" {
+		rowNamespace = defaultNamespace
+	}
+	return rowNamespace == namespace
+}
+
+func (s *memStore) CreateNamespace(ctx context.Context, arg database.CreateNamespaceParams) (database.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ns := database.Namespace{
+		Name:            arg.Name,
+		ChirpsPerMinute: arg.ChirpsPerMinute,
+		Features:        arg.Features,
+		CreatedAt:       time.Now(),
+	}
+	s.namespaces = append(s.namespaces, ns)
+	return ns, nil
+}
+
+func (s *memStore) GetNamespace(ctx context.Context, name string) (database.Namespace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ns := range s.namespaces {
+		if ns.Name == name {
+			return ns, nil
+		}
+	}
+	if name == defaultNamespace {
+		return database.Namespace{Name: defaultNamespace}, nil
+	}
+	return database.Namespace{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetSitemapChirps(ctx context.Context, namespace string) ([]database.GetSitemapChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []database.GetSitemapChirpsRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || c.IsHidden || c.IsNsfw || c.Visibility != database.ChirpVisibilityPublic || !inNamespace(c.Namespace, namespace) {
+			continue
+		}
+		out = append(out, database.GetSitemapChirpsRow{ID: c.ID, UpdatedAt: c.UpdatedAt})
+	}
+	return out, nil
+}
+
+func (s *memStore) GetSitemapUsers(ctx context.Context, namespace string) ([]database.GetSitemapUsersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []database.GetSitemapUsersRow
+	for _, u := range s.users {
+		if inNamespace(u.Namespace, namespace) {
+			out = append(out, database.GetSitemapUsersRow{ID: u.ID, UpdatedAt: u.UpdatedAt})
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) visibleTo(c database.Chirp, viewer uuid.UUID) bool {
+	if c.Visibility != database.ChirpVisibilityMutual || c.UserID == viewer {
+		return true
+	}
+	return s.isFollowing(viewer, c.UserID) && s.isFollowing(c.UserID, viewer)
+}
+
+func (s *memStore) isFollowing(followerID, followeeID uuid.UUID) bool {
+	for _, f := range s.follows {
+		if f.FollowerID == followerID && f.FolloweeID == followeeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *memStore) CreateFollow(ctx context.Context, arg database.CreateFollowParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isFollowing(arg.FollowerID, arg.FolloweeID) {
+		return 0, nil
+	}
+	s.follows = append(s.follows, database.Follow{
+		FollowerID: arg.FollowerID,
+		FolloweeID: arg.FolloweeID,
+		CreatedAt:  nullNow(),
+	})
+	return 1, nil
+}
+
+func (s *memStore) DeleteFollow(ctx context.Context, arg database.DeleteFollowParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, f := range s.follows {
+		if f.FollowerID == arg.FollowerID && f.FolloweeID == arg.FolloweeID {
+			s.follows = append(s.follows[:i], s.follows[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memStore) userByID(id uuid.UUID) database.User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return database.User{}
+}
+
+func (s *memStore) GetFollowers(ctx context.Context, arg database.GetFollowersParams) ([]database.GetFollowersRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetFollowersRow
+	for _, f := range s.follows {
+		u := s.userByID(f.FollowerID)
+		if f.FolloweeID != arg.FolloweeID || !inNamespace(u.Namespace, arg.Namespace) {
+			continue
+		}
+		items = append(items, database.GetFollowersRow{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt,
+			UpdatedAt:   u.UpdatedAt,
+			Email:       u.Email,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+			IsMutual:    s.isFollowing(f.FolloweeID, f.FollowerID),
+		})
+	}
+	return items, nil
+}
+
+// followPage returns the follows matching keep that sort after the cursor,
+// ordered by (created_at, id) where id is other(follow), as the SQL does.
+func (s *memStore) followPage(keep func(database.Follow) bool, other func(database.Follow) uuid.UUID, cursorAt time.Time, cursorID uuid.UUID, pageSize int32) []database.Follow {
+	after := func(f database.Follow, at time.Time, id uuid.UUID) bool {
+		if !f.CreatedAt.Time.Equal(at) {
+			return f.CreatedAt.Time.After(at)
+		}
+		otherID := other(f)
+		return bytes.Compare(otherID[:], id[:]) > 0
+	}
+	var items []database.Follow
+	for _, f := range s.follows {
+		if keep(f) && after(f, cursorAt, cursorID) {
+			items = append(items, f)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Follow) int {
+		if after(a, b.CreatedAt.Time, other(b)) {
+			return 1
+		}
+		return -1
+	})
+	if len(items) > int(pageSize) {
+		items = items[:pageSize]
+	}
+	return items
+}
+
+func (s *memStore) GetFollowersPage(ctx context.Context, arg database.GetFollowersPageParams) ([]database.GetFollowersPageRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	follows := s.followPage(
+		func(f database.Follow) bool {
+			return f.FolloweeID == arg.FolloweeID && inNamespace(s.userByID(f.FollowerID).Namespace, arg.Namespace)
+		},
+		func(f database.Follow) uuid.UUID { return f.FollowerID },
+		arg.CursorCreatedAt, arg.CursorID, arg.PageSize,
+	)
+	items := make([]database.GetFollowersPageRow, 0, len(follows))
+	for _, f := range follows {
+		u := s.userByID(f.FollowerID)
+		items = append(items, database.GetFollowersPageRow{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt,
+			UpdatedAt:   u.UpdatedAt,
+			Email:       u.Email,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+			FollowedAt:  f.CreatedAt,
+			IsMutual:    s.isFollowing(f.FolloweeID, f.FollowerID),
+		})
+	}
+	return items, nil
+}
+
+func (s *memStore) GetFollowingPage(ctx context.Context, arg database.GetFollowingPageParams) ([]database.GetFollowingPageRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	follows := s.followPage(
+		func(f database.Follow) bool {
+			return f.FollowerID == arg.FollowerID && inNamespace(s.userByID(f.FolloweeID).Namespace, arg.Namespace)
+		},
+		func(f database.Follow) uuid.UUID { return f.FolloweeID },
+		arg.CursorCreatedAt, arg.CursorID, arg.PageSize,
+	)
+	items := make([]database.GetFollowingPageRow, 0, len(follows))
+	for _, f := range follows {
+		u := s.userByID(f.FolloweeID)
+		items = append(items, database.GetFollowingPageRow{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt,
+			UpdatedAt:   u.UpdatedAt,
+			Email:       u.Email,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+			FollowedAt:  f.CreatedAt,
+			IsMutual:    s.isFollowing(f.FolloweeID, f.FollowerID),
+		})
+	}
+	return items, nil
+}
+
+func (s *memStore) GetMutualFollowsPage(ctx context.Context, arg database.GetMutualFollowsPageParams) ([]database.GetMutualFollowsPageRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	follows := s.followPage(
+		func(f database.Follow) bool {
+			return f.FollowerID == arg.UserID && s.isFollowing(f.FolloweeID, f.FollowerID) &&
+				inNamespace(s.userByID(f.FolloweeID).Namespace, arg.Namespace)
+		},
+		func(f database.Follow) uuid.UUID { return f.FolloweeID },
+		arg.CursorCreatedAt, arg.CursorID, arg.PageSize,
+	)
+	items := make([]database.GetMutualFollowsPageRow, 0, len(follows))
+	for _, f := range follows {
+		u := s.userByID(f.FolloweeID)
+		items = append(items, database.GetMutualFollowsPageRow{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt,
+			UpdatedAt:   u.UpdatedAt,
+			Email:       u.Email,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+			FollowedAt:  f.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+func (s *memStore) CountMutualFollows(ctx context.Context, arg database.CountMutualFollowsParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for _, f := range s.follows {
+		if f.FollowerID == arg.FollowerID && s.isFollowing(f.FolloweeID, f.FollowerID) && inNamespace(s.userByID(f.FolloweeID).Namespace, arg.Namespace) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memStore) GetFollowRelationship(ctx context.Context, arg database.GetFollowRelationshipParams) (database.GetFollowRelationshipRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return database.GetFollowRelationshipRow{
+		Following:  s.isFollowing(arg.CallerID, arg.TargetID),
+		FollowedBy: s.isFollowing(arg.TargetID, arg.CallerID),
+	}, nil
+}
+
+// chirpBefore orders chirps by (created_at, id), matching the keyset
+// comparisons in the paginated queries.
+func chirpBefore(c database.Chirp, createdAt time.Time, id uuid.UUID) bool {
+	if !c.CreatedAt.Time.Equal(createdAt) {
+		return c.CreatedAt.Time.Before(createdAt)
+	}
+	return bytes.Compare(c.ID[:], id[:]) < 0
+}
+
+func (s *memStore) GetUserChirpsAsc(ctx context.Context, arg database.GetUserChirpsAscParams) ([]database.GetUserChirpsAscRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		cursor := database.Chirp{ID: arg.CursorID, CreatedAt: sql.NullTime{Time: arg.CursorCreatedAt, Valid: true}}
+		if c.UserID == arg.UserID && !c.IsHidden && s.visibleTo(c, arg.ViewerID) && chirpBefore(cursor, c.CreatedAt.Time, c.ID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	var rows []database.GetUserChirpsAscRow
+	for _, c := range items[:min(len(items), int(arg.PageSize))] {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetUserChirpsAscRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) GetChirpsForExport(ctx context.Context, arg database.GetChirpsForExportParams) ([]database.GetChirpsForExportRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exportBatches++
+	cursor := database.Chirp{ID: arg.CursorID, CreatedAt: sql.NullTime{Time: arg.CursorCreatedAt, Valid: true}}
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid {
+			continue
+		}
+		if chirpBefore(cursor, c.CreatedAt.Time, c.ID) && c.CreatedAt.Time.Before(arg.CreatedBefore) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return -1
+		}
+		return 1
+	})
+	var rows []database.GetChirpsForExportRow
+	for _, c := range items[:min(len(items), int(arg.BatchSize))] {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetChirpsForExportRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) GetUserChirpsDesc(ctx context.Context, arg database.GetUserChirpsDescParams) ([]database.GetUserChirpsDescRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		if c.UserID == arg.UserID && !c.IsHidden && s.visibleTo(c, arg.ViewerID) && chirpBefore(c, arg.CursorCreatedAt, arg.CursorID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return 1
+		}
+		return -1
+	})
+	var rows []database.GetUserChirpsDescRow
+	for _, c := range items[:min(len(items), int(arg.PageSize))] {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetUserChirpsDescRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) SetUserVerified(ctx context.Context, arg database.SetUserVerifiedParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.users {
+		if s.users[i].ID == arg.ID {
+			s.users[i].IsVerified = arg.IsVerified
+			s.users[i].UpdatedAt = nullNow()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetMediaForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]database.ChirpMedium, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.ChirpMedium
+	for _, m := range s.media {
+		if slices.Contains(chirpIds, m.ChirpID) {
+			items = append(items, m)
+		}
+	}
+	return items, nil
+}
+
+func (s *memStore) CreateNotification(ctx context.Context, arg database.CreateNotificationParams) (database.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := database.Notification{
+		ID:          uuid.New(),
+		RecipientID: arg.RecipientID,
+		ActorID:     arg.ActorID,
+		Type:        arg.Type,
+		ChirpID:     arg.ChirpID,
+		CreatedAt:   nullNow(),
+	}
+	s.notifications = append(s.notifications, n)
+	return n, nil
+}
+
+func (s *memStore) GetNotifications(ctx context.Context, arg database.GetNotificationsParams) ([]database.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Notification
+	for _, n := range s.notifications {
+		if n.RecipientID != arg.RecipientID {
+			continue
+		}
+		read := n.ReadAt.Valid
+		key := database.Chirp{ID: n.ID, CreatedAt: n.CreatedAt}
+		if (read && !arg.CursorRead) || (read == arg.CursorRead && chirpBefore(key, arg.CursorCreatedAt, arg.CursorID)) {
+			items = append(items, n)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Notification) int {
+		if a.ReadAt.Valid != b.ReadAt.Valid {
+			if a.ReadAt.Valid {
+				return 1
+			}
+			return -1
+		}
+		if c := b.CreatedAt.Time.Compare(a.CreatedAt.Time); c != 0 {
+			return c
+		}
+		return bytes.Compare(b.ID[:], a.ID[:])
+	})
+	if len(items) > int(arg.PageSize) {
+		items = items[:arg.PageSize]
+	}
+	return items, nil
+}
+
+func (s *memStore) MarkAllNotificationsRead(ctx context.Context, recipientID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, n := range s.notifications {
+		if n.RecipientID == recipientID && !n.ReadAt.Valid {
+			s.notifications[i].ReadAt = nullNow()
+		}
+	}
+	return nil
+}
+
+func (s *memStore) CreateList(ctx context.Context, arg database.CreateListParams) (database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l := database.List{
+		ID:          uuid.New(),
+		OwnerID:     arg.OwnerID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		IsPublic:    arg.IsPublic,
+		CreatedAt:   nullNow(),
+		UpdatedAt:   nullNow(),
+	}
+	s.lists = append(s.lists, l)
+	return l, nil
+}
+
+func (s *memStore) GetList(ctx context.Context, id uuid.UUID) (database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.lists {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return database.List{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetListsByOwner(ctx context.Context, arg database.GetListsByOwnerParams) ([]database.List, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.List
+	for _, l := range s.lists {
+		if l.OwnerID == arg.OwnerID && (l.IsPublic || arg.IncludePrivate) {
+			items = append(items, l)
+		}
+	}
+	return items, nil
+}
+
+func (s *memStore) AddListMember(ctx context.Context, arg database.AddListMemberParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.listMembers {
+		if m.ListID == arg.ListID && m.UserID == arg.UserID {
+			return nil
+		}
+	}
+	s.listMembers = append(s.listMembers, database.ListMember{ListID: arg.ListID, UserID: arg.UserID, CreatedAt: nullNow()})
+	return nil
+}
+
+func (s *memStore) RemoveListMember(ctx context.Context, arg database.RemoveListMemberParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listMembers = slices.DeleteFunc(s.listMembers, func(m database.ListMember) bool {
+		return m.ListID == arg.ListID && m.UserID == arg.UserID
+	})
+	return nil
+}
+
+func (s *memStore) GetListFeed(ctx context.Context, arg database.GetListFeedParams) ([]database.GetListFeedRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		member := slices.ContainsFunc(s.listMembers, func(m database.ListMember) bool {
+			return m.ListID == arg.ListID && m.UserID == c.UserID
+		})
+		if member && !c.IsHidden && s.visibleTo(c, arg.ViewerID) && chirpBefore(c, arg.CursorCreatedAt, arg.CursorID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return 1
+		}
+		return -1
+	})
+	if len(items) > int(arg.PageSize) {
+		items = items[:arg.PageSize]
+	}
+	rows := make([]database.GetListFeedRow, 0, len(items))
+	for _, c := range items {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetListFeedRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) AddChirpTopic(ctx context.Context, arg database.AddChirpTopicParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chirpTopics = append(s.chirpTopics, database.ChirpTopic{ChirpID: arg.ChirpID, Topic: arg.Topic})
+	return nil
+}
+
+func (s *memStore) SubscribeTopic(ctx context.Context, arg database.SubscribeTopicParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range s.topicSubs {
+		if sub.UserID == arg.UserID && sub.Topic == arg.Topic {
+			return nil
+		}
+	}
+	s.topicSubs = append(s.topicSubs, database.TopicSubscription{UserID: arg.UserID, Topic: arg.Topic, CreatedAt: nullNow()})
+	return nil
+}
+
+func (s *memStore) UnsubscribeTopic(ctx context.Context, arg database.UnsubscribeTopicParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topicSubs = slices.DeleteFunc(s.topicSubs, func(sub database.TopicSubscription) bool {
+		return sub.UserID == arg.UserID && sub.Topic == arg.Topic
+	})
+	return nil
+}
+
+func (s *memStore) GetTopicFeed(ctx context.Context, arg database.GetTopicFeedParams) ([]database.GetTopicFeedRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []database.GetTopicFeedRow
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		var matched []string
+		for _, ct := range s.chirpTopics {
+			subscribed := slices.ContainsFunc(s.topicSubs, func(sub database.TopicSubscription) bool {
+				return sub.UserID == arg.UserID && sub.Topic == ct.Topic
+			})
+			if ct.ChirpID == c.ID && subscribed {
+				matched = append(matched, ct.Topic)
+			}
+		}
+		if len(matched) == 0 || c.IsHidden || !s.visibleTo(c, arg.UserID) {
+			continue
+		}
+		slices.Sort(matched)
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetTopicFeedRow{
+			Chirp:         c,
+			LikeCount:     likes,
+			ReplyCount:    replies,
+			MatchedTopics: matched,
+			Score:         float64(len(matched)) / (1 + time.Since(c.CreatedAt.Time).Hours()),
+		})
+	}
+	slices.SortFunc(rows, func(a, b database.GetTopicFeedRow) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return bytes.Compare(b.Chirp.ID[:], a.Chirp.ID[:])
+	})
+	start := min(int(arg.PageOffset), len(rows))
+	end := min(start+int(arg.PageSize), len(rows))
+	return rows[start:end], nil
+}
+
+func (s *memStore) SetChirpHidden(ctx context.Context, arg database.SetChirpHiddenParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		if s.chirps[i].ID == arg.ID {
+			s.chirps[i].IsHidden = arg.IsHidden
+			s.chirps[i].UpdatedAt = nullNow()
+			return s.chirps[i], nil
+		}
+	}
+	return database.Chirp{}, sql.ErrNoRows
+}
+
+func (s *memStore) SetChirpNsfw(ctx context.Context, id uuid.UUID) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		if s.chirps[i].ID == id {
+			s.chirps[i].IsNsfw = true
+			s.chirps[i].UpdatedAt = nullNow()
+			return s.chirps[i], nil
+		}
+	}
+	return database.Chirp{}, sql.ErrNoRows
+}
+
+func (s *memStore) AdminUpdateChirp(ctx context.Context, arg database.AdminUpdateChirpParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		c := &s.chirps[i]
+		if c.ID == arg.ID {
+			s.chirpVersions = append(s.chirpVersions, database.ChirpVersion{
+				ID:        uuid.New(),
+				ChirpID:   c.ID,
+				Body:      c.Body,
+				EditedBy:  arg.EditedBy,
+				CreatedAt: time.Now(),
+			})
+			c.Body = arg.Body
+			c.WordCount, c.ReadingTimeSeconds = arg.WordCount, arg.ReadingTimeSeconds
+			c.SentimentScore = arg.SentimentScore
+			c.AdminEdited = true
+			c.UpdatedAt = nullNow()
+			return *c, nil
+		}
+	}
+	return database.Chirp{}, sql.ErrNoRows
+}
+
+func (s *memStore) SetChirpContentWarning(ctx context.Context, arg database.SetChirpContentWarningParams) (database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		if s.chirps[i].ID == arg.ID {
+			s.chirps[i].ContentWarning = arg.ContentWarning
+			s.chirps[i].UpdatedAt = nullNow()
+			return s.chirps[i], nil
+		}
+	}
+	return database.Chirp{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetFlaggedChirps(ctx context.Context) ([]database.Chirp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var flagged []database.Chirp
+	for _, c := range slices.Backward(s.chirps) {
+		if c.FlaggedReason.Valid && !c.DeletedAt.Valid {
+			flagged = append(flagged, c)
+		}
+	}
+	return flagged, nil
+}
+
+func (s *memStore) GetBlockedEmailDomains(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.blocked...), nil
+}
+
+func (s *memStore) AddBlockedEmailDomain(ctx context.Context, domain string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.blocked, domain) {
+		s.blocked = append(s.blocked, domain)
+	}
+	return nil
+}
+
+func (s *memStore) DeleteBlockedEmailDomain(ctx context.Context, domain string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.blocked)
+	s.blocked = slices.DeleteFunc(s.blocked, func(d string) bool { return d == domain })
+	return int64(n - len(s.blocked)), nil
+}
+
+func (s *memStore) IncrementChirpImpressions(ctx context.Context, chirpIDs []uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.chirps {
+		if slices.Contains(chirpIDs, s.chirps[i].ID) {
+			s.chirps[i].ImpressionCount++
+		}
+	}
+	return nil
+}
+
+func (s *memStore) RecordPixelEvent(ctx context.Context, arg database.RecordPixelEventParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.chirps {
+		if c.ID != arg.ChirpID || c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		s.chirps[i].ImpressionCount++
+		s.pixelEvents = append(s.pixelEvents, database.PixelEvent{
+			ID:        uuid.New(),
+			ChirpID:   arg.ChirpID,
+			Referer:   arg.Referer,
+			UserAgent: arg.UserAgent,
+			CreatedAt: arg.CreatedAt,
+		})
+	}
+	return nil
+}
+
+func (s *memStore) RecordChirpViews(ctx context.Context, arg database.RecordChirpViewsParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, chirpID := range arg.ChirpIds {
+		if !slices.ContainsFunc(s.chirps, func(c database.Chirp) bool { return c.ID == chirpID }) {
+			continue
+		}
+		v := database.ChirpView{ChirpID: chirpID, ViewerKey: arg.ViewerKeys[i], CreatedAt: time.Now()}
+		if !slices.ContainsFunc(s.views, func(o database.ChirpView) bool {
+			return o.ChirpID == v.ChirpID && o.ViewerKey == v.ViewerKey
+		}) {
+			s.views = append(s.views, v)
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetChirpViewCount(ctx context.Context, chirpID uuid.UUID) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for _, v := range s.views {
+		if v.ChirpID == chirpID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *memStore) GetHomeFeed(ctx context.Context, arg database.GetHomeFeedParams) ([]database.GetHomeFeedRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) {
+			continue
+		}
+		visible := c.UserID == arg.UserID || s.isFollowing(arg.UserID, c.UserID)
+		if visible && !c.IsHidden && s.visibleTo(c, arg.UserID) && chirpBefore(c, arg.CursorCreatedAt, arg.CursorID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return 1
+		}
+		return -1
+	})
+	if len(items) > int(arg.PageSize) {
+		items = items[:arg.PageSize]
+	}
+	rows := make([]database.GetHomeFeedRow, 0, len(items))
+	for _, c := range items {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetHomeFeedRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) UpdateUser(ctx context.Context, arg database.UpdateUserParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID != arg.ID {
+			continue
+		}
+		if arg.Email.Valid {
+			s.users[i].Email = arg.Email
+		}
+		s.users[i].HashedPassword = arg.HashedPassword
+		s.users[i].UpdatedAt = nullNow()
+		return s.users[i], nil
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) SetPendingEmail(ctx context.Context, arg database.SetPendingEmailParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == arg.ID {
+			s.users[i].PendingEmail = arg.PendingEmail
+			s.users[i].PendingEmailToken = arg.PendingEmailToken
+			s.users[i].PendingEmailExpiresAt = arg.PendingEmailExpiresAt
+			s.users[i].UpdatedAt = nullNow()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetUserByPendingEmailToken(ctx context.Context, token sql.NullString) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if u.PendingEmailToken.Valid && u.PendingEmailToken == token {
+			return u, nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) ConfirmPendingEmail(ctx context.Context, arg database.ConfirmPendingEmailParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == arg.ID && u.PendingEmailToken.Valid && u.PendingEmailToken == arg.PendingEmailToken {
+			s.users[i].Email = u.PendingEmail
+			s.users[i].PendingEmail = sql.NullString{}
+			s.users[i].PendingEmailToken = sql.NullString{}
+			s.users[i].PendingEmailExpiresAt = sql.NullTime{}
+			s.users[i].UpdatedAt = nullNow()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) ToggleChirpRed(ctx context.Context, arg database.ToggleChirpRedParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == arg.ID {
+			s.users[i].IsChirpyRed = arg.IsChirpyRed
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetRefreshToken(ctx context.Context, token string) (database.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.refreshTokens {
+		if t.Token == token {
+			return t, nil
+		}
+	}
+	return database.RefreshToken{}, sql.ErrNoRows
+}
+
+func (s *memStore) RevokeRefreshToken(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, t := range s.refreshTokens {
+		if t.Token == token {
+			s.refreshTokens[i].RevokedAt = nullNow()
+		}
+	}
+	return nil
+}
+
+func (s *memStore) PingDatabase(ctx context.Context) error {
+	return nil
+}
+
+func (s *memStore) GetChirpTranslation(ctx context.Context, arg database.GetChirpTranslationParams) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tr := range s.translations {
+		if tr.ChirpID == arg.ChirpID && tr.Language == arg.Language {
+			return tr.TranslatedBody, nil
+		}
+	}
+	return "", sql.ErrNoRows
+}
+
+func (s *memStore) CreateChirpTranslation(ctx context.Context, arg database.CreateChirpTranslationParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.translations = append(s.translations, database.ChirpTranslation{
+		ChirpID:        arg.ChirpID,
+		Language:       arg.Language,
+		TranslatedBody: arg.TranslatedBody,
+		CreatedAt:      time.Now(),
+	})
+	return nil
+}
+
+func (s *memStore) GetLeaderboard(ctx context.Context, arg database.GetLeaderboardParams) ([]database.GetLeaderboardRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.leaderboardQueries++
+	since := func(t sql.NullTime) bool { return !t.Time.Before(arg.Since) }
+	scores := map[uuid.UUID]int64{}
+	for _, c := range s.chirps {
+		if !c.DeletedAt.Valid && !c.IsHidden && inNamespace(c.Namespace, arg.Namespace) && since(c.CreatedAt) {
+			scores[c.UserID]++
+		}
+	}
+	for _, l := range s.likes {
+		i := slices.IndexFunc(s.chirps, func(c database.Chirp) bool { return c.ID == l.ChirpID })
+		if i < 0 {
+			continue
+		}
+		c := s.chirps[i]
+		if !c.DeletedAt.Valid && !c.IsHidden && inNamespace(c.Namespace, arg.Namespace) && since(l.CreatedAt) {
+			scores[c.UserID] += 3
+		}
+	}
+	for _, f := range s.follows {
+		if inNamespace(s.userByID(f.FolloweeID).Namespace, arg.Namespace) && since(f.CreatedAt) {
+			scores[f.FolloweeID] += 5
+		}
+	}
+	var rows []database.GetLeaderboardRow
+	for id, score := range scores {
+		u := s.userByID(id)
+		rows = append(rows, database.GetLeaderboardRow{
+			ID:          u.ID,
+			CreatedAt:   u.CreatedAt,
+			UpdatedAt:   u.UpdatedAt,
+			IsChirpyRed: u.IsChirpyRed,
+			IsVerified:  u.IsVerified,
+			Score:       score,
+		})
+	}
+	slices.SortFunc(rows, func(a, b database.GetLeaderboardRow) int {
+		if a.Score != b.Score {
+			return int(b.Score - a.Score)
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+	if len(rows) > int(arg.Size) {
+		rows = rows[:arg.Size]
+	}
+	return rows, nil
+}
+
+func (s *memStore) GetUserActivity(ctx context.Context, arg database.GetUserActivityParams) (database.GetUserActivityRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activityParams = arg
+	var row database.GetUserActivityRow
+	today := func(t sql.NullTime) bool { return !t.Time.Before(arg.DayStart) }
+	thisWeek := func(t sql.NullTime) bool { return !t.Time.Before(arg.WeekStart) }
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid {
+			continue
+		}
+		if c.UserID == arg.UserID && thisWeek(c.CreatedAt) {
+			row.ChirpsThisWeek++
+			if today(c.CreatedAt) {
+				row.ChirpsToday++
+			}
+		}
+		if c.ParentID.Valid && c.UserID != arg.UserID && today(c.CreatedAt) {
+			if slices.ContainsFunc(s.chirps, func(p database.Chirp) bool { return p.ID == c.ParentID.UUID && p.UserID == arg.UserID }) {
+				row.RepliesReceivedToday++
+			}
+		}
+	}
+	for _, l := range s.likes {
+		if today(l.CreatedAt) && slices.ContainsFunc(s.chirps, func(c database.Chirp) bool { return c.ID == l.ChirpID && c.UserID == arg.UserID }) {
+			row.LikesReceivedToday++
+		}
+	}
+	for _, f := range s.follows {
+		if f.FolloweeID == arg.UserID && today(f.CreatedAt) {
+			row.NewFollowersToday++
+		}
+	}
+	return row, nil
+}
+
+func (s *memStore) IsMutualFollow(ctx context.Context, arg database.IsMutualFollowParams) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isFollowing(arg.UserA, arg.UserB) && s.isFollowing(arg.UserB, arg.UserA), nil
+}
+
+func (s *memStore) GetRecentFingerprintChirp(ctx context.Context, arg database.GetRecentFingerprintChirpParams) (uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, f := range s.fingerprints {
+		if f.Fingerprint == arg.Fingerprint && !f.CreatedAt.Before(arg.Since) {
+			return f.ChirpID, nil
+		}
+	}
+	return uuid.Nil, sql.ErrNoRows
+}
+
+func (s *memStore) SaveRequestFingerprint(ctx context.Context, arg database.SaveRequestFingerprintParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fingerprints = slices.DeleteFunc(s.fingerprints, func(f database.RequestFingerprint) bool {
+		return f.Fingerprint == arg.Fingerprint
+	})
+	s.fingerprints = append(s.fingerprints, database.RequestFingerprint{
+		Fingerprint: arg.Fingerprint,
+		ChirpID:     arg.ChirpID,
+		CreatedAt:   time.Now(),
+	})
+	return nil
+}
+
+func (s *memStore) DeleteRequestFingerprintsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.fingerprints)
+	s.fingerprints = slices.DeleteFunc(s.fingerprints, func(f database.RequestFingerprint) bool {
+		return f.CreatedAt.Before(cutoff)
+	})
+	return int64(n - len(s.fingerprints)), nil
+}
+
+func (s *memStore) CreateWebhook(ctx context.Context, url string) (database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hook := database.Webhook{ID: uuid.New(), Url: url, CreatedAt: time.Now()}
+	s.webhooks = append(s.webhooks, hook)
+	return hook, nil
+}
+
+func (s *memStore) GetWebhooks(ctx context.Context) ([]database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.webhooks), nil
+}
+
+func (s *memStore) GetWebhookByID(ctx context.Context, id uuid.UUID) (database.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hook := range s.webhooks {
+		if hook.ID == id {
+			return hook, nil
+		}
+	}
+	return database.Webhook{}, sql.ErrNoRows
+}
+
+func (s *memStore) RecordWebhookDelivery(ctx context.Context, arg database.RecordWebhookDeliveryParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries = append(s.deliveries, database.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     arg.WebhookID,
+		ChirpID:       arg.ChirpID,
+		AttemptNumber: arg.AttemptNumber,
+		StatusCode:    arg.StatusCode,
+		Error:         arg.Error,
+		DeliveredAt:   time.Now(),
+	})
+	return nil
+}
+
+func (s *memStore) GetWebhookDeliveries(ctx context.Context, webhookID uuid.UUID) ([]database.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.WebhookDelivery
+	for _, d := range slices.Backward(s.deliveries) {
+		if d.WebhookID == webhookID {
+			items = append(items, d)
+		}
+	}
+	return items, nil
+}
+
+func (s *memStore) SetUserEmailMFA(ctx context.Context, arg database.SetUserEmailMFAParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == arg.ID {
+			s.users[i].EmailMfaEnabled = arg.EmailMfaEnabled
+			s.users[i].UpdatedAt = nullNow()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) SetUserEmailNotifications(ctx context.Context, arg database.SetUserEmailNotificationsParams) (database.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, u := range s.users {
+		if u.ID == arg.ID {
+			s.users[i].EmailNotifications = arg.EmailNotifications
+			s.users[i].UpdatedAt = nullNow()
+			return s.users[i], nil
+		}
+	}
+	return database.User{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetDigestNotificationCounts(ctx context.Context, since time.Time) ([]database.GetDigestNotificationCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := map[database.GetDigestNotificationCountsRow]int64{}
+	for _, n := range s.notifications {
+		u := s.userByID(n.RecipientID)
+		if n.ReadAt.Valid || n.CreatedAt.Time.Before(since) || !u.EmailNotifications || !u.Email.Valid {
+			continue
+		}
+		counts[database.GetDigestNotificationCountsRow{UserID: u.ID, Email: u.Email, Type: n.Type}]++
+	}
+	var out []database.GetDigestNotificationCountsRow
+	for row, n := range counts {
+		row.Count = n
+		out = append(out, row)
+	}
+	slices.SortFunc(out, func(a, b database.GetDigestNotificationCountsRow) int {
+		if c := strings.Compare(a.UserID.String(), b.UserID.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(string(a.Type), string(b.Type))
+	})
+	return out, nil
+}
+
+func (s *memStore) RegisterPushToken(ctx context.Context, arg database.RegisterPushTokenParams) (database.PushToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := database.PushToken{DeviceToken: arg.DeviceToken, UserID: arg.UserID, Platform: arg.Platform, CreatedAt: time.Now()}
+	for i := range s.pushTokens {
+		if s.pushTokens[i].DeviceToken == arg.DeviceToken {
+			s.pushTokens[i] = t
+			return t, nil
+		}
+	}
+	s.pushTokens = append(s.pushTokens, t)
+	return t, nil
+}
+
+func (s *memStore) GetPushTokens(ctx context.Context, userID uuid.UUID) ([]database.PushToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []database.PushToken
+	for _, t := range s.pushTokens {
+		if t.UserID == userID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (s *memStore) RevokeToken(ctx context.Context, arg database.RevokeTokenParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.ContainsFunc(s.revoked, func(t database.RevokedToken) bool { return t.Jti == arg.Jti }) {
+		s.revoked = append(s.revoked, database.RevokedToken{Jti: arg.Jti, ExpiresAt: arg.ExpiresAt, RevokedAt: time.Now()})
+	}
+	return nil
+}
+
+func (s *memStore) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revokedLookups++
+	return slices.ContainsFunc(s.revoked, func(t database.RevokedToken) bool { return t.Jti == jti }), nil
+}
+
+func (s *memStore) GetRevokedTokenIDs(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, t := range s.revoked {
+		if t.ExpiresAt.After(time.Now()) {
+			ids = append(ids, t.Jti)
+		}
+	}
+	return ids, nil
+}
+
+func (s *memStore) CreateSigningKey(ctx context.Context, arg database.CreateSigningKeyParams) (database.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := database.SigningKey{KeyID: arg.KeyID, PublicKey: arg.PublicKey, UserID: arg.UserID, CreatedAt: time.Now()}
+	s.signingKeys = append(s.signingKeys, k)
+	return k, nil
+}
+
+func (s *memStore) GetSigningKey(ctx context.Context, keyID string) (database.SigningKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, k := range s.signingKeys {
+		if k.KeyID == keyID {
+			return k, nil
+		}
+	}
+	return database.SigningKey{}, sql.ErrNoRows
+}
+
+func (s *memStore) CreateShortLink(ctx context.Context, arg database.CreateShortLinkParams) (database.ShortLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.shortLinks {
+		if l.Code == arg.Code {
+			return database.ShortLink{}, sql.ErrNoRows
+		}
+	}
+	l := database.ShortLink{Code: arg.Code, ChirpID: arg.ChirpID, CreatedBy: arg.CreatedBy, CreatedAt: time.Now()}
+	s.shortLinks = append(s.shortLinks, l)
+	return l, nil
+}
+
+func (s *memStore) CreateAutoShortLink(ctx context.Context, arg database.CreateAutoShortLinkParams) (database.ShortLink, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.shortLinks {
+		if l.Code == arg.Code {
+			return database.ShortLink{}, sql.ErrNoRows
+		}
+	}
+	l := database.ShortLink{Code: arg.Code, TargetUrl: arg.TargetUrl, CreatedBy: arg.CreatedBy, CreatedAt: time.Now(), AutoGenerated: true}
+	s.shortLinks = append(s.shortLinks, l)
+	return l, nil
+}
+
+func (s *memStore) AttachShortLinksToChirp(ctx context.Context, arg database.AttachShortLinksToChirpParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.shortLinks {
+		if slices.Contains(arg.Codes, s.shortLinks[i].Code) {
+			s.shortLinks[i].ChirpID = arg.ChirpID
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetExpandedURLsForChirps(ctx context.Context, chirpIds []uuid.UUID) ([]database.GetExpandedURLsForChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows []database.GetExpandedURLsForChirpsRow
+	for _, l := range s.shortLinks {
+		if l.AutoGenerated && l.ChirpID.Valid && slices.Contains(chirpIds, l.ChirpID.UUID) {
+			rows = append(rows, database.GetExpandedURLsForChirpsRow{ChirpID: l.ChirpID, Code: l.Code, TargetUrl: l.TargetUrl})
+		}
+	}
+	return rows, nil
+}
+
+func (s *memStore) ClickShortLink(ctx context.Context, code string) (database.ClickShortLinkRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.shortLinks {
+		l := s.shortLinks[i]
+		if l.Code == code && (l.AutoGenerated || l.ChirpID.Valid) {
+			s.shortLinks[i].ClickCount++
+			return database.ClickShortLinkRow{ChirpID: s.shortLinks[i].ChirpID, TargetUrl: s.shortLinks[i].TargetUrl}, nil
+		}
+	}
+	return database.ClickShortLinkRow{}, sql.ErrNoRows
+}
+
+func (s *memStore) CreateOAuthClient(ctx context.Context, arg database.CreateOAuthClientParams) (database.OauthClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := database.OauthClient{ID: arg.ID, SecretHash: arg.SecretHash, Name: arg.Name, RedirectUri: arg.RedirectUri, CreatedAt: time.Now()}
+	s.oauthClients = append(s.oauthClients, c)
+	return c, nil
+}
+
+func (s *memStore) GetOAuthClient(ctx context.Context, id string) (database.OauthClient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.oauthClients {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return database.OauthClient{}, sql.ErrNoRows
+}
+
+func (s *memStore) CreateOAuthCode(ctx context.Context, arg database.CreateOAuthCodeParams) (database.OauthCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := database.OauthCode{
+		Code:        arg.Code,
+		ClientID:    arg.ClientID,
+		UserID:      arg.UserID,
+		RedirectUri: arg.RedirectUri,
+		Scope:       arg.Scope,
+		ExpiresAt:   arg.ExpiresAt,
+		CreatedAt:   time.Now(),
+	}
+	s.oauthCodes = append(s.oauthCodes, c)
+	return c, nil
+}
+
+func (s *memStore) ConsumeOAuthCode(ctx context.Context, code string) (database.OauthCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.oauthCodes {
+		if c.Code == code {
+			s.oauthCodes = slices.Delete(s.oauthCodes, i, i+1)
+			return c, nil
+		}
+	}
+	return database.OauthCode{}, sql.ErrNoRows
+}
+
+func (s *memStore) CreateOAuthIdentity(ctx context.Context, arg database.CreateOAuthIdentityParams) (database.OauthIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range s.identities {
+		if (i.UserID == arg.UserID && i.Provider == arg.Provider) || (i.Provider == arg.Provider && i.ProviderUserID == arg.ProviderUserID) {
+			return database.OauthIdentity{}, errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	i := database.OauthIdentity{
+		UserID:               arg.UserID,
+		Provider:             arg.Provider,
+		ProviderUserID:       arg.ProviderUserID,
+		AccessTokenEncrypted: arg.AccessTokenEncrypted,
+		CreatedAt:            time.Now(),
+	}
+	s.identities = append(s.identities, i)
+	return i, nil
+}
+
+func (s *memStore) GetOAuthIdentityByProviderUser(ctx context.Context, arg database.GetOAuthIdentityByProviderUserParams) (database.OauthIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range s.identities {
+		if i.Provider == arg.Provider && i.ProviderUserID == arg.ProviderUserID {
+			return i, nil
+		}
+	}
+	return database.OauthIdentity{}, sql.ErrNoRows
+}
+
+func (s *memStore) GetOAuthIdentities(ctx context.Context, userID uuid.UUID) ([]database.OauthIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.OauthIdentity
+	for _, i := range s.identities {
+		if i.UserID == userID {
+			items = append(items, i)
+		}
+	}
+	return items, nil
+}
+
+func (s *memStore) DeleteOAuthIdentity(ctx context.Context, arg database.DeleteOAuthIdentityParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.identities)
+	s.identities = slices.DeleteFunc(s.identities, func(i database.OauthIdentity) bool {
+		return i.UserID == arg.UserID && i.Provider == arg.Provider
+	})
+	return int64(n - len(s.identities)), nil
+}
+
+func (s *memStore) UpsertChirpEmbedding(ctx context.Context, arg database.UpsertChirpEmbeddingParams) (database.UpsertChirpEmbeddingRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.embeddings == nil {
+		s.embeddings = map[uuid.UUID]string{}
+	}
+	s.embeddings[arg.ChirpID] = arg.Embedding
+	return database.UpsertChirpEmbeddingRow{ChirpID: arg.ChirpID, ModelVersion: arg.ModelVersion, CreatedAt: time.Now()}, nil
+}
+
+// parseVector reads pgvector's text form back into numbers.
+func parseVector(v string) []float64 {
+	var out []float64
+	for f := range strings.SplitSeq(strings.Trim(v, "[]"), ",") {
+		n, _ := strconv.ParseFloat(f, 64)
+		out = append(out, n)
+	}
+	return out
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func (s *memStore) GetSimilarChirps(ctx context.Context, arg database.GetSimilarChirpsParams) ([]database.GetSimilarChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	target, ok := s.embeddings[arg.ChirpID]
+	if !ok {
+		return nil, nil
+	}
+	var items []database.GetSimilarChirpsRow
+	for _, c := range s.chirps {
+		e, ok := s.embeddings[c.ID]
+		if !ok || c.ID == arg.ChirpID || c.IsHidden || c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		likes, replies := s.counts(c.ID)
+		items = append(items, database.GetSimilarChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies, Similarity: cosineSimilarity(parseVector(target), parseVector(e))})
+	}
+	slices.SortStableFunc(items, func(a, b database.GetSimilarChirpsRow) int { return cmp.Compare(b.Similarity, a.Similarity) })
+	return items[:min(len(items), int(arg.MaxChirps))], nil
+}
+
+// GetRandomChirps returns chirps in the order they were created; tests
+// only rely on which chirps come back.
+func (s *memStore) GetRandomChirps(ctx context.Context, arg database.GetRandomChirpsParams) ([]database.GetRandomChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.GetRandomChirpsRow
+	for _, c := range s.chirps {
+		if c.ID == arg.ExcludeID || c.IsHidden || c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || !s.visibleTo(c, arg.ViewerID) {
+			continue
+		}
+		likes, replies := s.counts(c.ID)
+		items = append(items, database.GetRandomChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return items[:min(len(items), int(arg.MaxChirps))], nil
+}
+
+func (s *memStore) CreateEmailOTPSession(ctx context.Context, arg database.CreateEmailOTPSessionParams) (database.EmailOtpSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := database.EmailOtpSession{
+		Token:     arg.Token,
+		UserID:    arg.UserID,
+		OtpHash:   arg.OtpHash,
+		CreatedAt: time.Now(),
+		ExpiresAt: arg.ExpiresAt,
+	}
+	s.otpSessions = append(s.otpSessions, session)
+	return session, nil
+}
+
+func (s *memStore) GetEmailOTPSession(ctx context.Context, token string) (database.EmailOtpSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.otpSessions {
+		if session.Token == token {
+			return session, nil
+		}
+	}
+	return database.EmailOtpSession{}, sql.ErrNoRows
+}
+
+func (s *memStore) RecordEmailOTPFailure(ctx context.Context, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.otpSessions {
+		if s.otpSessions[i].Token == token {
+			s.otpSessions[i].FailedAttempts++
+		}
+	}
+	return nil
+}
+
+func (s *memStore) UseEmailOTPSession(ctx context.Context, token string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.otpSessions {
+		if s.otpSessions[i].Token == token && !s.otpSessions[i].UsedAt.Valid {
+			s.otpSessions[i].UsedAt = nullNow()
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *memStore) RecordChirpRead(ctx context.Context, arg database.RecordChirpReadParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.reads {
+		if r.UserID == arg.UserID && r.ChirpID == arg.ChirpID {
+			if arg.SeenAt.After(r.LastSeenAt) {
+				s.reads[i].LastSeenAt = arg.SeenAt
+			}
+			s.reads[i].ViewCount++
+			return nil
+		}
+	}
+	s.reads = append(s.reads, database.ChirpRead{
+		UserID:      arg.UserID,
+		ChirpID:     arg.ChirpID,
+		FirstSeenAt: arg.SeenAt,
+		LastSeenAt:  arg.SeenAt,
+		ViewCount:   1,
+	})
+	return nil
+}
+
+func (s *memStore) GetUnreadChirps(ctx context.Context, arg database.GetUnreadChirpsParams) ([]database.GetUnreadChirpsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var items []database.Chirp
+	for _, c := range s.chirps {
+		if c.DeletedAt.Valid || !inNamespace(c.Namespace, arg.Namespace) || c.IsHidden || !s.isFollowing(arg.UserID, c.UserID) || !s.visibleTo(c, arg.UserID) {
+			continue
+		}
+		read := slices.ContainsFunc(s.reads, func(r database.ChirpRead) bool {
+			return r.UserID == arg.UserID && r.ChirpID == c.ID
+		})
+		if !read && chirpBefore(c, arg.CursorCreatedAt, arg.CursorID) {
+			items = append(items, c)
+		}
+	}
+	slices.SortFunc(items, func(a, b database.Chirp) int {
+		if chirpBefore(a, b.CreatedAt.Time, b.ID) {
+			return 1
+		}
+		return -1
+	})
+	if len(items) > int(arg.PageSize) {
+		items = items[:arg.PageSize]
+	}
+	rows := make([]database.GetUnreadChirpsRow, 0, len(items))
+	for _, c := range items {
+		likes, replies := s.counts(c.ID)
+		rows = append(rows, database.GetUnreadChirpsRow{Chirp: c, LikeCount: likes, ReplyCount: replies})
+	}
+	return rows, nil
+}
+
+func (s *memStore) EnqueueJob(ctx context.Context, arg database.EnqueueJobParams) (database.JobQueue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	job := database.JobQueue{
+		ID:        int64(len(s.jobs) + 1),
+		Type:      arg.Type,
+		Payload:   arg.Payload,
+		Status:    database.JobStatusPending,
+		RunAt:     now,
+		CreatedAt: now,
+	}
+	s.jobs = append(s.jobs, job)
+	return job, nil
+}
+
+func (s *memStore) ClaimJob(ctx context.Context, arg database.ClaimJobParams) (database.JobQueue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next *database.JobQueue
+	for i := range s.jobs {
+		j := &s.jobs[i]
+		if (j.Status != database.JobStatusPending && j.Status != database.JobStatusRunning) || j.RunAt.After(arg.Now) {
+			continue
+		}
+		if next == nil || j.RunAt.Before(next.RunAt) {
+			next = j
+		}
+	}
+	if next == nil {
+		return database.JobQueue{}, sql.ErrNoRows
+	}
+	next.Status = database.JobStatusRunning
+	next.AttemptCount++
+	next.RunAt = arg.LeaseUntil
+	return *next, nil
+}
+
+func (s *memStore) CompleteJob(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.jobs {
+		if s.jobs[i].ID == id {
+			s.jobs[i].Status = database.JobStatusDone
+			s.jobs[i].ProcessedAt = nullNow()
+			s.jobs[i].Error = sql.NullString{}
+		}
+	}
+	return nil
+}
+
+func (s *memStore) FailJob(ctx context.Context, arg database.FailJobParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.jobs {
+		if s.jobs[i].ID == arg.ID {
+			s.jobs[i].Status = arg.Status
+			s.jobs[i].RunAt = arg.RunAt
+			s.jobs[i].Error = arg.Error
+			s.jobs[i].ProcessedAt = nullNow()
+		}
+	}
+	return nil
+}
+
+func (s *memStore) GetUserHourlyChirpCounts(ctx context.Context, userID uuid.UUID) ([]database.GetUserHourlyChirpCountsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last := time.Now().UTC().Truncate(time.Hour)
+	rows := make([]database.GetUserHourlyChirpCountsRow, 0, 168)
+	for h := last.Add(-167 * time.Hour); !h.After(last); h = h.Add(time.Hour) {
+		row := database.GetUserHourlyChirpCountsRow{Hour: h}
+		for _, c := range s.chirps {
+			public := !c.DeletedAt.Valid && !c.IsHidden && c.Visibility == database.ChirpVisibilityPublic
+			if c.UserID == userID && public && c.CreatedAt.Time.UTC().Truncate(time.Hour).Equal(h) {
+				row.Count++
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
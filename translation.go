@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+)
+
+var errTranslationNotImplemented = errors.New("translation is not configured")
+
+// Translator renders text in targetLang, an ISO 639-1 code.
+type Translator interface {
+	Translate(ctx context.Context, text, targetLang string) (string, error)
+}
+
+// NoopTranslator is used when no TRANSLATION_PROVIDER is configured.
+type NoopTranslator struct{}
+
+func (NoopTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	return "", errTranslationNotImplemented
+}
+
+// MockTranslator tags text with the target language instead of
+// translating it, for tests and local development. Calls counts how many
+// translations it has performed.
+type MockTranslator struct {
+	Calls int
+}
+
+func (m *MockTranslator) Translate(ctx context.Context, text, targetLang string) (string, error) {
+	m.Calls++
+	return "[" + targetLang + "] " + text, nil
+}
+
+func newTranslator(provider string) Translator {
+	if provider == "mock" {
+		return &MockTranslator{}
+	}
+	return NoopTranslator{}
+}
+
+var iso6391Codes = strings.Fields(`
+	aa ab ae af ak am an ar as av ay az ba be bg bi bm bn bo br bs ca ce ch
+	co cr cs cu cv cy da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy
+	ga gd gl gn gu gv ha he hi ho hr ht hu hy hz ia id ie ig ii ik io is it
+	iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb lg li ln lo
+	lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny
+	oc oj om or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl
+	sm sn so sq sr ss st su sv sw ta te tg th ti tk tl tn to tr ts tt tw ty
+	ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`)
+
+func isISO6391(code string) bool {
+	return slices.Contains(iso6391Codes, code)
+}
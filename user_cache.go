@@ -0,0 +1,54 @@
+package main
+
+import (
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// userCacheTTL bounds how stale a cached profile can get. Follower, following
+// and chirp counts are not evicted on change, so they may lag by this much.
+const userCacheTTL = 5 * time.Minute
+
+// userCacheKey keys the profile cache. A user ID is only found in its own
+// namespace, so the namespace is part of the key.
+type userCacheKey struct {
+	namespace string
+	id        uuid.UUID
+}
+
+type userCacheEntry struct {
+	resp      userResp
+	expiresAt time.Time
+}
+
+// timeNow returns cfg.now() when a test has set it, and time.Now otherwise.
+func (cfg *apiConfig) timeNow() time.Time {
+	if cfg.now != nil {
+		return cfg.now()
+	}
+	return time.Now()
+}
+
+func (cfg *apiConfig) cachedUser(namespace string, id uuid.UUID) (userResp, bool) {
+	key := userCacheKey{namespace: namespace, id: id}
+	v, ok := cfg.userCache.Load(key)
+	if !ok {
+		return userResp{}, false
+	}
+	entry := v.(*userCacheEntry)
+	if !cfg.timeNow().Before(entry.expiresAt) {
+		cfg.userCache.CompareAndDelete(key, v)
+		return userResp{}, false
+	}
+	return entry.resp, true
+}
+
+func (cfg *apiConfig) storeUser(namespace string, resp userResp) {
+	cfg.userCache.Store(userCacheKey{namespace: namespace, id: resp.ID}, &userCacheEntry{resp: resp, expiresAt: cfg.timeNow().Add(userCacheTTL)})
+}
+
+func (cfg *apiConfig) evictUser(user database.User) {
+	cfg.userCache.Delete(userCacheKey{namespace: user.Namespace, id: user.ID})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// userLookupStore counts GetUserById calls per user.
+type userLookupStore struct {
+	*memStore
+	lookupMu sync.Mutex
+	lookups  map[uuid.UUID]int
+}
+
+func (s *userLookupStore) GetUserById(ctx context.Context, arg database.GetUserByIdParams) (database.GetUserByIdRow, error) {
+	s.lookupMu.Lock()
+	s.lookups[arg.ID]++
+	s.lookupMu.Unlock()
+	return s.memStore.GetUserById(ctx, arg)
+}
+
+func (s *userLookupStore) count(id uuid.UUID) int {
+	s.lookupMu.Lock()
+	defer s.lookupMu.Unlock()
+	return s.lookups[id]
+}
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+func newUserCacheTest(t *testing.T) (http.Handler, *apiConfig, *userLookupStore, *fakeClock) {
+	t.Helper()
+	store := &userLookupStore{memStore: newMemStore(), lookups: map[uuid.UUID]int{}}
+	cfg := newTestConfig(store)
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	return newServer("0", cfg).Handler, cfg, store, clock
+}
+
+func getProfile(t *testing.T, h http.Handler, id uuid.UUID, query, token string) userResp {
+	t.Helper()
+	rec := serve(h, "GET", "/api/users/"+id.String()+query, "", token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d", rec.Code)
+	}
+	var resp userResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestUserCacheHitAndMiss(t *testing.T) {
+	h, cfg, store, _ := newUserCacheTest(t)
+	u, _ := seedUser(t, cfg, store.memStore, "alice@example.com")
+
+	if got := getProfile(t, h, u.ID, "", ""); got.ID != u.ID || got.Email != "" {
+		t.Errorf("got %+v, want alice's public profile", got)
+	}
+	if n := store.count(u.ID); n != 1 {
+		t.Fatalf("miss: got %d lookups, want 1", n)
+	}
+	if got := getProfile(t, h, u.ID, "", ""); got.ID != u.ID || got.Email != "" {
+		t.Errorf("hit: got %+v, want alice's public profile", got)
+	}
+	if n := store.count(u.ID); n != 1 {
+		t.Errorf("hit: got %d lookups, want still 1", n)
+	}
+}
+
+func TestUserCacheTTL(t *testing.T) {
+	h, cfg, store, clock := newUserCacheTest(t)
+	u, _ := seedUser(t, cfg, store.memStore, "alice@example.com")
+	getProfile(t, h, u.ID, "", "")
+
+	clock.t = clock.t.Add(userCacheTTL - time.Second)
+	getProfile(t, h, u.ID, "", "")
+	if n := store.count(u.ID); n != 1 {
+		t.Errorf("before expiry: got %d lookups, want 1", n)
+	}
+	clock.t = clock.t.Add(time.Second)
+	getProfile(t, h, u.ID, "", "")
+	if n := store.count(u.ID); n != 2 {
+		t.Errorf("at expiry: got %d lookups, want 2", n)
+	}
+}
+
+func TestUserCacheEvictedOnUpdate(t *testing.T) {
+	h, cfg, store, _ := newUserCacheTest(t)
+	u, token := seedUser(t, cfg, store.memStore, "alice@example.com")
+	getProfile(t, h, u.ID, "", "")
+
+	rec := serve(h, "PUT", "/api/users", `{"email":"alice@new.example.com","password":"hunter2"}`, token)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: got status %d", rec.Code)
+	}
+	confirm := confirmationToken(t, cfg.mailer.(*MockMailer), "alice@new.example.com")
+	if rec := serve(h, "POST", "/api/auth/confirm-email-change", `{"token":"`+confirm+`"}`, ""); rec.Code != http.StatusOK {
+		t.Fatalf("confirm: got status %d", rec.Code)
+	}
+	before := store.count(u.ID)
+	getProfile(t, h, u.ID, "", "")
+	if n := store.count(u.ID); n != before+1 {
+		t.Errorf("after the email change got %d lookups, want %d", n, before+1)
+	}
+}
+
+func TestUserCacheBust(t *testing.T) {
+	h, cfg, store, _ := newUserCacheTest(t)
+	u, _ := seedUser(t, cfg, store.memStore, "alice@example.com")
+	_, token := seedUser(t, cfg, store.memStore, "bob@example.com")
+	_, admin := seedAdmin(t, cfg, store.memStore, "admin@example.com")
+	getProfile(t, h, u.ID, "", "")
+
+	getProfile(t, h, u.ID, "?cache_bust=true", token)
+	if n := store.count(u.ID); n != 1 {
+		t.Errorf("non-admin cache_bust: got %d lookups of the profile, want 1", n)
+	}
+	getProfile(t, h, u.ID, "?cache_bust=true", admin)
+	if n := store.count(u.ID); n != 2 {
+		t.Errorf("admin cache_bust: got %d lookups of the profile, want 2", n)
+	}
+}
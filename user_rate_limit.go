@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultChirpsPerMinute = 10
+	userRateLimitWindow    = time.Minute
+)
+
+type userRateWindow struct {
+	mu   sync.Mutex
+	hits []time.Time
+}
+
+// allow drops hits older than userRateLimitWindow and records a new one
+// unless limit hits remain. It returns how many hits are left in the
+// window afterwards and when the oldest hit leaves it, freeing a slot.
+func (w *userRateWindow) allow(now time.Time, limit int) (ok bool, remaining int, reset time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	cutoff := now.Add(-userRateLimitWindow)
+	i := 0
+	for i < len(w.hits) && !w.hits[i].After(cutoff) {
+		i++
+	}
+	w.hits = w.hits[i:]
+	ok = len(w.hits) < limit
+	if ok {
+		w.hits = append(w.hits, now)
+	}
+	return ok, limit - len(w.hits), w.hits[0].Add(userRateLimitWindow)
+}
+
+// writeRateLimitHeaders tells clients their quota on every rate-limited
+// response, not just the 429s, so they can slow down before hitting it.
+func writeRateLimitHeaders(w http.ResponseWriter, limit, remaining int, resetAt time.Time) {
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(max(remaining, 0)))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+}
+
+// middlewareUserRateLimit allows each authenticated user cfg.chirpsPerMinute
+// requests per minute to the wrapped route, or their namespace's limit when
+// it sets one; zero disables the limit. It keys
+// on the user ID from the access token rather than the client address, so
+// users sharing a NAT do not throttle each other, and it is independent of
+// any per-IP limiting in front of it. Requests without a valid token pass
+// through for the handler to reject.
+func (cfg *apiConfig) middlewareUserRateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := cfg.chirpsPerMinute
+		if ns := namespaceFromContext(r.Context()); ns.ChirpsPerMinute.Valid {
+			limit = int(ns.ChirpsPerMinute.Int32)
+		}
+		if limit <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cfg.limitUser(w, r, limit, next)
+	})
+}
+
+// middlewareFixedUserRateLimit is middlewareUserRateLimit with a limit of
+// its own, for routes whose budget does not follow the chirp rate.
+func (cfg *apiConfig) middlewareFixedUserRateLimit(limit int, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg.limitUser(w, r, limit, next)
+	})
+}
+
+// limitUser serves r with next unless its user has used up limit requests
+// to the route this minute. Requests requireAuth did not authenticate are
+// left for next to turn away.
+func (cfg *apiConfig) limitUser(w http.ResponseWriter, r *http.Request, limit int, next http.Handler) {
+	user, ok := authUserFromContext(r.Context())
+	if !ok {
+		next.ServeHTTP(w, r)
+		return
+	}
+	userID := user.ID
+	// Each route gets its own budget, so a burst of follows does not
+	// eat into the same user's chirp budget.
+	key := rateLimitKey("user", userID.String()+":"+r.Pattern)
+	ok, remaining, reset, err := cfg.rateLimiter(limit).Allow(key)
+	if err != nil {
+		log.Printf("Error checking rate limit in Redis, falling back to memory: %s", err)
+		ok, remaining, reset, _ = cfg.memoryRateLimiter(limit).Allow(key)
+	}
+	writeRateLimitHeaders(w, limit, remaining, reset)
+	if !ok {
+		wait := reset.Sub(cfg.timeNow())
+		secs := int((wait + time.Second - 1) / time.Second)
+		w.Header().Set("Retry-After", strconv.Itoa(max(secs, 1)))
+		respondWithError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+	next.ServeHTTP(w, r)
+}
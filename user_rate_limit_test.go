@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserRateLimitExceedAndRecover(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 3
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	post := func(i int) *http.Response {
+		return serve(h, "POST", "/api/chirps", fmt.Sprintf(`{"body":"chirp %d"}`, i), token).Result()
+	}
+
+	for i := range 3 {
+		if res := post(i); res.StatusCode != http.StatusCreated {
+			t.Fatalf("chirp %d of 3: got status %d, want 201", i+1, res.StatusCode)
+		}
+	}
+	rec := serve(h, "POST", "/api/chirps", `{"body":"one too many"}`, token)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("over the limit: got status %d, want 429", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"error":"rate limit exceeded"`) {
+		t.Errorf("got body %s", rec.Body.String())
+	}
+	retry, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retry < 1 || retry > 60 {
+		t.Errorf("got Retry-After %q, want 1-60 seconds", rec.Header().Get("Retry-After"))
+	}
+
+	// Age the window so the earliest hits fall out of it.
+	cfg.userRateLimits.Range(func(_, v any) bool {
+		w := v.(*userRateWindow)
+		for i := range w.hits {
+			w.hits[i] = w.hits[i].Add(-userRateLimitWindow)
+		}
+		return true
+	})
+	if res := post(3); res.StatusCode != http.StatusCreated {
+		t.Errorf("after the window: got status %d, want 201", res.StatusCode)
+	}
+}
+
+func TestUserRateLimitIsPerUser(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 1
+	h := newServer("0", cfg).Handler
+	_, alice := seedUser(t, cfg, store, "alice@example.com")
+	_, bob := seedUser(t, cfg, store, "bob@example.com")
+
+	// Both requests come from the same httptest address, as if behind one NAT.
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"from alice"}`, alice); rec.Code != http.StatusCreated {
+		t.Fatalf("alice: got status %d, want 201", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"from bob"}`, bob); rec.Code != http.StatusCreated {
+		t.Errorf("bob after alice: got status %d, want 201", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"alice again"}`, alice); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("alice again: got status %d, want 429", rec.Code)
+	}
+}
+
+func TestUserRateLimitPassesUnauthenticated(t *testing.T) {
+	cfg := newTestConfig(newMemStore())
+	cfg.chirpsPerMinute = 1
+	h := newServer("0", cfg).Handler
+	for range 2 {
+		if rec := serve(h, "POST", "/api/chirps", `{"body":"anon"}`, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("got status %d, want 401", rec.Code)
+		}
+	}
+}
+
+func TestUserRateWindowRetryAfter(t *testing.T) {
+	var w userRateWindow
+	start := time.Now()
+	w.allow(start, 1)
+	ok, _, reset := w.allow(start.Add(20*time.Second), 1)
+	if wait := reset.Sub(start.Add(20 * time.Second)); ok || wait != 40*time.Second {
+		t.Errorf("got %v, %v; want refused with 40s wait", ok, wait)
+	}
+	if ok, _, _ := w.allow(start.Add(userRateLimitWindow+time.Millisecond), 1); !ok {
+		t.Error("hit should have left the window")
+	}
+}
+
+func TestUserRateLimitHeaders(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.chirpsPerMinute = 3
+	clock := &fakeClock{t: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	cfg.now = clock.Now
+	h := newServer("0", cfg).Handler
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	reset := strconv.FormatInt(clock.t.Add(userRateLimitWindow).Unix(), 10)
+
+	for i, want := range []string{"2", "1", "0", "0"} {
+		rec := serve(h, "POST", "/api/chirps", fmt.Sprintf(`{"body":"headers %d"}`, i), token)
+		if got := rec.Header().Get("X-RateLimit-Remaining"); got != want {
+			t.Errorf("request %d (status %d): got X-RateLimit-Remaining %q, want %s", i+1, rec.Code, got, want)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "3" {
+			t.Errorf("request %d: got X-RateLimit-Limit %q, want 3", i+1, got)
+		}
+		if got := rec.Header().Get("X-RateLimit-Reset"); got != reset {
+			t.Errorf("request %d: got X-RateLimit-Reset %q, want %s", i+1, got, reset)
+		}
+	}
+
+	clock.t = clock.t.Add(userRateLimitWindow)
+	rec := serve(h, "POST", "/api/chirps", `{"body":"next window"}`, token)
+	if rec.Code != http.StatusCreated || rec.Header().Get("X-RateLimit-Remaining") != "2" {
+		t.Errorf("after the window: got status %d with %q remaining, want 201 with 2", rec.Code, rec.Header().Get("X-RateLimit-Remaining"))
+	}
+}
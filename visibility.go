@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) isMutualFollow(ctx context.Context, userA, userB uuid.UUID) (bool, error) {
+	return cfg.db.IsMutualFollow(ctx, database.IsMutualFollowParams{UserA: userA, UserB: userB})
+}
+
+// canViewChirp reports whether viewer may see chirp. Mutual-only chirps are
+// visible to their author and to users who follow and are followed by the
+// author. viewer is uuid.Nil for anonymous callers.
+func (cfg *apiConfig) canViewChirp(ctx context.Context, chirp database.Chirp, viewer uuid.UUID) (bool, error) {
+	if chirp.Visibility != database.ChirpVisibilityMutual || chirp.UserID == viewer {
+		return true, nil
+	}
+	if viewer == uuid.Nil {
+		return false, nil
+	}
+	return cfg.isMutualFollow(ctx, viewer, chirp.UserID)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestIsMutualFollow(t *testing.T) {
+	tests := []struct {
+		name       string
+		aFollowsB  bool
+		bFollowsA  bool
+		wantMutual bool
+	}{
+		{"mutual", true, true, true},
+		{"only a follows b", true, false, false},
+		{"only b follows a", false, true, false},
+		{"neither", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemStore()
+			cfg := newTestConfig(store)
+			h := newServer("0", cfg).Handler
+			a, aToken := seedUser(t, cfg, store, "a@example.com")
+			b, bToken := seedUser(t, cfg, store, "b@example.com")
+			if tt.aFollowsB {
+				serve(h, "POST", "/api/users/"+b.ID.String()+"/follow", "", aToken)
+			}
+			if tt.bFollowsA {
+				serve(h, "POST", "/api/users/"+a.ID.String()+"/follow", "", bToken)
+			}
+
+			for _, args := range [][2]string{{"a", "b"}, {"b", "a"}} {
+				x, y := a.ID, b.ID
+				if args[0] == "b" {
+					x, y = y, x
+				}
+				got, err := cfg.isMutualFollow(t.Context(), x, y)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != tt.wantMutual {
+					t.Errorf("isMutualFollow(%s, %s) = %v, want %v", args[0], args[1], got, tt.wantMutual)
+				}
+			}
+
+			c := postChirp(t, h, `{"body":"friends only","visibility":"mutual"}`, aToken)
+			wantStatus := http.StatusNotFound
+			if tt.wantMutual {
+				wantStatus = http.StatusOK
+			}
+			if rec := serve(h, "GET", "/api/chirps/"+c.ID.String(), "", bToken); rec.Code != wantStatus {
+				t.Errorf("GET mutual chirp as b: got status %d, want %d", rec.Code, wantStatus)
+			}
+		})
+	}
+}
+
+func TestMutualChirpVisibility(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	h := newServer("0", cfg).Handler
+	alice, aliceToken := seedUser(t, cfg, store, "alice@example.com")
+	bob, bobToken := seedUser(t, cfg, store, "bob@example.com")
+	_, carolToken := seedUser(t, cfg, store, "carol@example.com")
+	serve(h, "POST", "/api/users/"+bob.ID.String()+"/follow", "", aliceToken)
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", bobToken)
+	serve(h, "POST", "/api/users/"+alice.ID.String()+"/follow", "", carolToken)
+
+	postChirp(t, h, `{"body":"for everyone"}`, aliceToken)
+	secret := postChirp(t, h, `{"body":"for friends","visibility":"mutual"}`, aliceToken)
+	if secret.Visibility != "mutual" {
+		t.Errorf("got visibility %q, want mutual", secret.Visibility)
+	}
+
+	count := func(path, token string) int {
+		t.Helper()
+		rec := serve(h, "GET", path, "", token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: got status %d", path, rec.Code)
+		}
+		var chirps []chirpResp
+		var page struct {
+			Chirps []chirpResp `json:"chirps"`
+		}
+		if json.Unmarshal(rec.Body.Bytes(), &chirps) != nil {
+			json.Unmarshal(rec.Body.Bytes(), &page)
+			chirps = page.Chirps
+		}
+		return len(chirps)
+	}
+	for _, tt := range []struct {
+		viewer string
+		token  string
+		want   int
+	}{
+		{"author", aliceToken, 2},
+		{"mutual follower", bobToken, 2},
+		{"one-way follower", carolToken, 1},
+		{"anonymous", "", 1},
+	} {
+		if got := count("/api/chirps", tt.token); got != tt.want {
+			t.Errorf("%s: GET /api/chirps returned %d chirps, want %d", tt.viewer, got, tt.want)
+		}
+		if got := count("/api/users/"+alice.ID.String()+"/chirps", tt.token); got != tt.want {
+			t.Errorf("%s: user timeline returned %d chirps, want %d", tt.viewer, got, tt.want)
+		}
+	}
+	if got := count("/api/feed", carolToken); got != 1 {
+		t.Errorf("one-way follower home feed returned %d chirps, want 1", got)
+	}
+	if got := count("/api/feed", bobToken); got != 2 {
+		t.Errorf("mutual follower home feed returned %d chirps, want 2", got)
+	}
+
+	path := "/api/chirps/" + secret.ID.String()
+	if rec := serve(h, "POST", path+"/like", "", carolToken); rec.Code != http.StatusNotFound {
+		t.Errorf("like by non-mutual: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "POST", "/api/chirps", `{"body":"reply","parent_id":"`+secret.ID.String()+`"}`, carolToken); rec.Code != http.StatusNotFound {
+		t.Errorf("reply by non-mutual: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "GET", "/share/chirps/"+secret.ID.String(), "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("share page: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "GET", path+"/embed", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("embed: got status %d, want 404", rec.Code)
+	}
+}
+
+func TestCreateChirpInvalidVisibility(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+	rec := serve(newServer("0", cfg).Handler, "POST", "/api/chirps", `{"body":"hi","visibility":"secret"}`, token)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want 400", rec.Code)
+	}
+}
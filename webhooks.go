@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultWebhookWorkers         = 5
+	defaultWebhookDeliveryTimeout = 10 * time.Second
+	webhookQueueSize              = 100
+	maxWebhookRetryQueue          = 1000
+	maxWebhookAttempts            = 5
+	maxWebhookBackoff             = 5 * time.Minute
+	webhookRetryInterval          = time.Second
+)
+
+type webhookTask struct {
+	webhook database.Webhook
+	chirp   database.Chirp
+	body    []byte
+	// attempt counts deliveries tried so far.
+	attempt   int
+	notBefore time.Time
+}
+
+// webhookDispatcher delivers chirp events to registered webhooks from a
+// fixed pool of workers. Failed deliveries wait in an in-memory retry queue
+// with exponential backoff until maxWebhookAttempts is reached.
+type webhookDispatcher struct {
+	db      database.Querier
+	workers int
+	timeout time.Duration
+	tasks   chan webhookTask
+	// send performs one delivery; tests replace it.
+	send func(ctx context.Context, t webhookTask) (int, error)
+
+	mu      sync.Mutex
+	retries []webhookTask
+}
+
+func newWebhookDispatcher(db database.Querier, workers int, timeout time.Duration) *webhookDispatcher {
+	d := &webhookDispatcher{
+		db:      db,
+		workers: workers,
+		timeout: timeout,
+		tasks:   make(chan webhookTask, webhookQueueSize),
+	}
+	client := &http.Client{}
+	d.send = func(ctx context.Context, t webhookTask) (int, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.webhook.Url, bytes.NewReader(t.body))
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		res, err := client.Do(req)
+		if err != nil {
+			return 0, err
+		}
+		res.Body.Close()
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return res.StatusCode, fmt.Errorf("webhook responded %d", res.StatusCode)
+		}
+		return res.StatusCode, nil
+	}
+	return d
+}
+
+// webhookBackoff is the wait before retrying a task that has failed attempt
+// times: 1s doubling per failure, capped at maxWebhookBackoff.
+func webhookBackoff(attempt int) time.Duration {
+	if attempt <= 0 {
+		return 0
+	}
+	if attempt > 20 {
+		return maxWebhookBackoff
+	}
+	return min(time.Second<<(attempt-1), maxWebhookBackoff)
+}
+
+// enqueue hands t to the workers, parking it in the retry queue if they are
+// all busy and the channel is full.
+func (d *webhookDispatcher) enqueue(t webhookTask) {
+	select {
+	case d.tasks <- t:
+	default:
+		d.enqueueRetry(t, time.Now())
+	}
+}
+
+// enqueueRetry schedules t after its backoff. It reports false, dropping
+// the task, when t has used up its attempts or the queue is full.
+func (d *webhookDispatcher) enqueueRetry(t webhookTask, now time.Time) bool {
+	if t.attempt >= maxWebhookAttempts {
+		log.Printf("Giving up on webhook %s for chirp %s after %d attempts", t.webhook.ID, t.chirp.ID, t.attempt)
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.retries) >= maxWebhookRetryQueue {
+		log.Printf("Webhook retry queue full, dropping delivery to %s", t.webhook.ID)
+		return false
+	}
+	t.notBefore = now.Add(webhookBackoff(t.attempt))
+	d.retries = append(d.retries, t)
+	return true
+}
+
+// requeueDue moves retries whose backoff has elapsed back onto the task
+// channel, leaving the rest, and anything that does not fit, queued.
+func (d *webhookDispatcher) requeueDue(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	kept := d.retries[:0]
+	for _, t := range d.retries {
+		if now.Before(t.notBefore) {
+			kept = append(kept, t)
+			continue
+		}
+		select {
+		case d.tasks <- t:
+		default:
+			kept = append(kept, t)
+		}
+	}
+	d.retries = kept
+}
+
+func (d *webhookDispatcher) runRetries(ctx context.Context, tick <-chan time.Time) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now, ok := <-tick:
+			if !ok {
+				return
+			}
+			d.requeueDue(now)
+		}
+	}
+}
+
+// run starts the worker pool and blocks until ctx is done.
+func (d *webhookDispatcher) run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for range d.workers {
+		g.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case t := <-d.tasks:
+					d.deliver(ctx, t)
+				}
+			}
+		})
+	}
+	return g.Wait()
+}
+
+// deliver makes one attempt at t within d.timeout, records it, and
+// schedules a retry on failure.
+func (d *webhookDispatcher) deliver(ctx context.Context, t webhookTask) {
+	t.attempt++
+	taskCtx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	status, err := d.send(taskCtx, t)
+	rec := database.RecordWebhookDeliveryParams{
+		WebhookID:     t.webhook.ID,
+		ChirpID:       t.chirp.ID,
+		AttemptNumber: int32(t.attempt),
+		StatusCode:    sql.NullInt32{Int32: int32(status), Valid: status != 0},
+	}
+	if err != nil {
+		rec.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+	if dbErr := d.db.RecordWebhookDelivery(context.Background(), rec); dbErr != nil {
+		log.Printf("Error recording webhook delivery: %s", dbErr)
+	}
+	if err != nil {
+		d.enqueueRetry(t, time.Now())
+	}
+}
+
+// deliverChirpCreated queues a delivery of a new public chirp to every
+// registered webhook.
+func (cfg *apiConfig) deliverChirpCreated(ctx context.Context, p chirpCreatedPayload) {
+	if p.Chirp.Visibility != database.ChirpVisibilityPublic {
+		return
+	}
+	hooks, err := cfg.db.GetWebhooks(ctx)
+	if err != nil {
+		log.Printf("Error loading webhooks: %s", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+	body, _ := json.Marshal(struct {
+		Event string    `json:"event"`
+		Chirp chirpResp `json:"chirp"`
+	}{eventChirpCreated, newChirpResp(p.Chirp)})
+	for _, hook := range hooks {
+		cfg.webhooks.enqueue(webhookTask{webhook: hook, chirp: p.Chirp, body: body})
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/azs06/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func testWebhookTask() webhookTask {
+	return webhookTask{
+		webhook: database.Webhook{ID: uuid.New(), Url: "http://hooks.example.com"},
+		chirp:   database.Chirp{ID: uuid.New()},
+	}
+}
+
+func TestWebhookWorkersBounded(t *testing.T) {
+	store := newMemStore()
+	d := newWebhookDispatcher(store, 3, time.Second)
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+	reachedLimit := make(chan struct{})
+	var once sync.Once
+	d.send = func(ctx context.Context, t webhookTask) (int, error) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		if inFlight == 3 {
+			once.Do(func() { close(reachedLimit) })
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return http.StatusOK, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.run(ctx) }()
+
+	for range 10 {
+		d.enqueue(testWebhookTask())
+	}
+	<-reachedLimit
+	time.Sleep(20 * time.Millisecond) // give a fourth worker the chance to misbehave
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		store.mu.Lock()
+		n := len(store.deliveries)
+		store.mu.Unlock()
+		if n == 10 || time.Now().After(deadline) {
+			if n != 10 {
+				t.Errorf("got %d deliveries recorded, want 10", n)
+			}
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("run returned %v, want context.Canceled", err)
+	}
+	if maxInFlight != 3 {
+		t.Errorf("got %d concurrent deliveries, want 3", maxInFlight)
+	}
+}
+
+func TestWebhookDeliveryTimeout(t *testing.T) {
+	store := newMemStore()
+	d := newWebhookDispatcher(store, 1, 10*time.Millisecond)
+	d.send = func(ctx context.Context, t webhookTask) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+	d.deliver(context.Background(), testWebhookTask())
+	if len(store.deliveries) != 1 || !strings.Contains(store.deliveries[0].Error.String, "deadline") {
+		t.Errorf("got deliveries %+v, want one timed out", store.deliveries)
+	}
+}
+
+func TestWebhookFailureEnqueuesRetry(t *testing.T) {
+	store := newMemStore()
+	d := newWebhookDispatcher(store, 1, time.Second)
+	d.send = func(ctx context.Context, t webhookTask) (int, error) {
+		return http.StatusBadGateway, errors.New("webhook responded 502")
+	}
+	task := testWebhookTask()
+	before := time.Now()
+	d.deliver(context.Background(), task)
+
+	if len(d.retries) != 1 {
+		t.Fatalf("got %d retries, want 1", len(d.retries))
+	}
+	retry := d.retries[0]
+	if retry.attempt != 1 || retry.notBefore.Before(before.Add(time.Second)) {
+		t.Errorf("got attempt %d due %v, want attempt 1 due a second after %v", retry.attempt, retry.notBefore, before)
+	}
+	rec := store.deliveries[0]
+	if rec.AttemptNumber != 1 || rec.StatusCode.Int32 != http.StatusBadGateway || !rec.Error.Valid {
+		t.Errorf("got delivery record %+v", rec)
+	}
+
+	d.requeueDue(before)
+	if len(d.tasks) != 0 || len(d.retries) != 1 {
+		t.Error("retry requeued before its backoff elapsed")
+	}
+	d.requeueDue(retry.notBefore)
+	if len(d.tasks) != 1 || len(d.retries) != 0 {
+		t.Fatal("due retry was not requeued")
+	}
+	d.deliver(context.Background(), <-d.tasks)
+	if got := d.retries[0]; got.attempt != 2 || got.notBefore.Sub(time.Now()) <= time.Second {
+		t.Errorf("second failure: got attempt %d due in %v, want attempt 2 due in ~2s", got.attempt, time.Until(got.notBefore))
+	}
+}
+
+func TestWebhookRetryLimits(t *testing.T) {
+	d := newWebhookDispatcher(newMemStore(), 1, time.Second)
+	now := time.Now()
+
+	spent := testWebhookTask()
+	spent.attempt = maxWebhookAttempts
+	if d.enqueueRetry(spent, now) {
+		t.Error("task past maxWebhookAttempts was queued")
+	}
+
+	for i := range maxWebhookRetryQueue {
+		if !d.enqueueRetry(testWebhookTask(), now) {
+			t.Fatalf("retry %d rejected before the queue was full", i)
+		}
+	}
+	if d.enqueueRetry(testWebhookTask(), now) {
+		t.Error("retry queued past maxWebhookRetryQueue")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		0:  0,
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		9:  256 * time.Second,
+		10: maxWebhookBackoff,
+		64: maxWebhookBackoff,
+	}
+	for attempt, want := range tests {
+		if got := webhookBackoff(attempt); got != want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestChirpCreatedQueuesWebhooks(t *testing.T) {
+	store := newMemStore()
+	cfg := newTestConfig(store)
+	cfg.webhooks = newWebhookDispatcher(store, 1, time.Second)
+	h := newServer("0", cfg).Handler
+	_, admin := seedAdmin(t, cfg, store, "admin@example.com")
+	_, token := seedUser(t, cfg, store, "alice@example.com")
+
+	rec := serve(h, "POST", "/admin/webhooks", `{"url":"https://hooks.example.com/chirps"}`, admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create webhook: got status %d: %s", rec.Code, rec.Body.String())
+	}
+	var hook webhookResp
+	json.Unmarshal(rec.Body.Bytes(), &hook)
+
+	chirp := postChirp(t, h, `{"body":"hello hooks"}`, token)
+	postChirp(t, h, `{"body":"friends only","visibility":"mutual"}`, token)
+	runJobs(t, cfg)
+	if len(cfg.webhooks.tasks) != 1 {
+		t.Fatalf("got %d queued deliveries, want 1 for the public chirp", len(cfg.webhooks.tasks))
+	}
+	task := <-cfg.webhooks.tasks
+	if task.webhook.ID != hook.ID || !strings.Contains(string(task.body), chirp.ID.String()) {
+		t.Errorf("got task for %s with body %s", task.webhook.ID, task.body)
+	}
+
+	cfg.webhooks.send = func(ctx context.Context, t webhookTask) (int, error) { return http.StatusOK, nil }
+	cfg.webhooks.deliver(context.Background(), task)
+	rec = serve(h, "GET", "/admin/webhooks/"+hook.ID.String()+"/deliveries", "", admin)
+	var deliveries []webhookDeliveryResp
+	if err := json.Unmarshal(rec.Body.Bytes(), &deliveries); err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 || deliveries[0].ChirpID != chirp.ID || *deliveries[0].StatusCode != http.StatusOK || deliveries[0].Error != nil {
+		t.Errorf("got deliveries %s", rec.Body.String())
+	}
+	if rec := serve(h, "GET", "/admin/webhooks/"+uuid.NewString()+"/deliveries", "", admin); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown webhook: got status %d, want 404", rec.Code)
+	}
+	if rec := serve(h, "POST", "/admin/webhooks", `{"url":"ftp://example.com"}`, admin); rec.Code != http.StatusBadRequest {
+		t.Errorf("ftp url: got status %d, want 400", rec.Code)
+	}
+}